@@ -2,14 +2,21 @@ package main
 
 import (
 	"context"
+	"io"
 	"log"
+	"log/slog"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"syscall"
+	"time"
 
+	"github.com/elsanchez/smart-download/internal/config"
+	"github.com/elsanchez/smart-download/internal/cookies"
 	"github.com/elsanchez/smart-download/internal/daemon"
 	"github.com/elsanchez/smart-download/internal/downloader"
+	"github.com/elsanchez/smart-download/internal/logging"
+	"github.com/elsanchez/smart-download/internal/notify"
 	"github.com/elsanchez/smart-download/internal/postprocessor"
 	"github.com/elsanchez/smart-download/internal/repository/sqlite"
 	"github.com/elsanchez/smart-download/pkg/client"
@@ -32,6 +39,37 @@ func main() {
 	}
 	log.Println("✓ Dependencies check passed (yt-dlp, gallery-dl, ffmpeg)")
 
+	// Cargar configuración (defaults si no existe el archivo)
+	configPath := config.DefaultPath()
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	// Logging estructurado según la configuración
+	logLevel := cfg.Log.Level
+	if cfg.Debug {
+		logLevel = "debug"
+	}
+	// Archivo con rotación; stderr solo en ejecuciones interactivas (bajo systemd iría a journald)
+	var logOutput io.Writer = os.Stderr
+	if cfg.Log.File != "" {
+		logFile, err := logging.RotatingFile(cfg.Log.File, cfg.Log.MaxSizeMB, cfg.Log.MaxBackups)
+		if err != nil {
+			log.Fatalf("Failed to open log file: %v", err)
+		}
+		defer logFile.Close()
+
+		logOutput = logFile
+		if logging.IsTerminal(os.Stderr) {
+			logOutput = io.MultiWriter(os.Stderr, logFile)
+		}
+	}
+	if err := logging.Setup(logOutput, logLevel, cfg.Log.Format); err != nil {
+		log.Fatalf("Failed to set up logging: %v", err)
+	}
+	slog.Info("Config loaded", "path", configPath, "log_level", logLevel, "log_format", cfg.Log.Format, "log_file", cfg.Log.File)
+
 	// Obtener directorios
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
@@ -39,12 +77,13 @@ func main() {
 	}
 
 	dataDir := filepath.Join(homeDir, ".local", "share", "smart-download")
-	outputDir := filepath.Join(homeDir, "Downloads", "download_video")
-	cookiesDir := filepath.Join(homeDir, "Documents", "cookies")
+	outputDir := cfg.OutputDir
+	cookiesDir := cfg.CookiesDir
 	tempDir := filepath.Join(dataDir, "temp")
+	archiveDir := filepath.Join(dataDir, "archive")
 
 	// Crear directorios
-	for _, dir := range []string{dataDir, outputDir, cookiesDir, tempDir} {
+	for _, dir := range []string{dataDir, outputDir, cookiesDir, tempDir, archiveDir} {
 		if err := os.MkdirAll(dir, 0755); err != nil {
 			log.Fatalf("Failed to create directory %s: %v", dir, err)
 		}
@@ -64,7 +103,11 @@ func main() {
 	log.Println("✓ Database initialized")
 
 	// Crear downloader manager
-	downloaderMgr := downloader.NewManager(outputDir, cookiesDir, db.AccountRepo)
+	if err := downloader.ConfigureRouting(cfg.Routing.GalleryDLSites, cfg.Routing.Overrides); err != nil {
+		log.Fatalf("Invalid routing config: %v", err)
+	}
+	downloader.SetDefaultProxy(cfg.Defaults.Proxy)
+	downloaderMgr := downloader.NewManager(outputDir, cookiesDir, archiveDir, db.AccountRepo)
 	log.Println("✓ Downloader manager initialized")
 
 	// Crear post-processor
@@ -72,18 +115,58 @@ func main() {
 	log.Println("✓ Post-processor initialized")
 
 	// Crear queue manager
-	workers := 3 // Configurable
+	workers := cfg.Workers
 	queueMgr := daemon.NewQueueManager(db.DownloadRepo, downloaderMgr, postproc, workers)
+	queueMgr.SetPollInterval(cfg.PollInterval)
+	queueMgr.SetShutdownTimeout(cfg.ShutdownTimeout)
+	queueMgr.SetDownloadTimeout(cfg.DownloadTimeout)
+	queueMgr.SetClipboard(cfg.Clipboard)
+	queueMgr.SetAccountRepository(db.AccountRepo)
+	notifier := notify.New(cfg.Notifications)
+	queueMgr.SetNotifier(notifier)
+
+	// Webhook al terminar cada descarga (opt-in)
+	var webhook *daemon.Webhook
+	if cfg.Webhook.URL != "" {
+		webhook = daemon.NewWebhook(cfg.Webhook.URL)
+		queueMgr.SetWebhook(webhook)
+		log.Println("✓ Webhook enabled")
+	}
+
+	// Métricas de Prometheus (opt-in)
+	var metricsServer *daemon.MetricsServer
+	if cfg.Metrics.Listen != "" {
+		metrics := daemon.NewMetrics(queueMgr)
+		queueMgr.SetMetrics(metrics)
+		metricsServer = daemon.NewMetricsServer(cfg.Metrics.Listen, metrics)
+		if err := metricsServer.Start(); err != nil {
+			log.Printf("Metrics endpoint disabled: %v", err)
+			metricsServer = nil
+		} else {
+			log.Printf("✓ Metrics available at http://%s/metrics", cfg.Metrics.Listen)
+		}
+	}
+
 	queueMgr.Start()
-	defer queueMgr.Stop()
 	log.Printf("✓ Queue manager started (%d workers)", workers)
 
 	// Crear handlers
-	handlers := daemon.NewHandlers(db.DownloadRepo, db.AccountRepo, queueMgr)
+	handlers := daemon.NewHandlers(db.DownloadRepo, db.AccountRepo, queueMgr, cfg.Defaults.Options())
+	handlers.SetVersion(version)
+
+	// Token de autenticación opcional
+	tokenPath := client.GetDefaultTokenPath()
+	token, err := client.LoadToken(tokenPath)
+	if err != nil {
+		log.Fatalf("Failed to load auth token: %v", err)
+	}
+	if token != "" {
+		log.Printf("✓ Auth token loaded from %s", tokenPath)
+	}
 
 	// Crear servidor
 	socketPath := client.GetDefaultSocketPath()
-	server := daemon.NewServer(socketPath, queueMgr, handlers)
+	server := daemon.NewServer(socketPath, queueMgr, handlers, token)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -91,9 +174,58 @@ func main() {
 	if err := server.Start(ctx); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
-	defer server.Stop()
 
 	log.Println("✓ Server started")
+
+	// API HTTP opcional, con los mismos handlers que el socket
+	var apiServer *daemon.APIServer
+	if cfg.API.Listen != "" {
+		apiToken := cfg.API.Token
+		if apiToken == "" {
+			apiToken = token
+		}
+		if apiToken == "" {
+			log.Printf("Warning: HTTP API on %s has no token, any local user can add downloads", cfg.API.Listen)
+		}
+
+		apiServer = daemon.NewAPIServer(cfg.API.Listen, handlers, apiToken)
+		if err := apiServer.Start(); err != nil {
+			log.Printf("HTTP API disabled: %v", err)
+			apiServer = nil
+		} else {
+			log.Printf("✓ HTTP API available at http://%s", cfg.API.Listen)
+		}
+	}
+
+	// Revisión periódica de cookies expiradas
+	var cookieChecker *daemon.CookieChecker
+	if cfg.Cookies.ExpiryCheckInterval > 0 {
+		cookieChecker = daemon.NewCookieChecker(db.AccountRepo, cookies.NewCookieValidator(), notifier, cfg.Cookies.ExpiryCheckInterval)
+		cookieChecker.Start(ctx)
+	} else {
+		log.Println("Cookie expiry check disabled")
+	}
+
+	// Revalidación de cookies al cambiar sus archivos (opt-in)
+	var cookieWatcher *daemon.CookieWatcher
+	if cfg.Cookies.WatchFiles {
+		cookieWatcher, err = daemon.NewCookieWatcher(db.AccountRepo, cookies.NewCookieValidator())
+		if err == nil {
+			err = cookieWatcher.Start(ctx)
+		}
+		if err != nil {
+			log.Printf("Cookie file watcher disabled: %v", err)
+			cookieWatcher = nil
+		}
+	}
+
+	// Retención: limpieza periódica del historial
+	var pruner *daemon.Pruner
+	if cfg.Retention.Days > 0 {
+		pruner = daemon.NewPruner(db.DownloadRepo, time.Duration(cfg.Retention.Days)*24*time.Hour, cfg.Retention.DeleteFiles, cfg.Retention.IncludeFailed)
+		pruner.Start(ctx)
+	}
+
 	log.Printf("Socket: %s", socketPath)
 	log.Println("smart-downloadd is ready")
 
@@ -105,5 +237,25 @@ func main() {
 	log.Printf("Received signal: %v", sig)
 	log.Println("Shutting down gracefully...")
 
+	// Dejar de aceptar conexiones y esperar a las descargas activas
 	cancel()
+	server.Stop()
+	if apiServer != nil {
+		apiServer.Stop()
+	}
+	if cookieChecker != nil {
+		cookieChecker.Stop()
+	}
+	if cookieWatcher != nil {
+		cookieWatcher.Stop()
+	}
+	if pruner != nil {
+		pruner.Stop()
+	}
+	if metricsServer != nil {
+		metricsServer.Stop()
+	}
+	queueMgr.Stop()
+	webhook.Wait()
+	log.Println("smart-downloadd stopped")
 }
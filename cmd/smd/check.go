@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"os"
+	"sync"
+
+	"github.com/elsanchez/smart-download/internal/domain"
+	"github.com/elsanchez/smart-download/internal/postprocessor"
+)
+
+// checkReport es el resultado de smd convert --check-only --json para un archivo
+type checkReport struct {
+	File        string `json:"file"`
+	Compatible  bool   `json:"compatible"`
+	Reason      string `json:"reason,omitempty"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	VideoCodec  string `json:"video_codec"`
+	PixelFormat string `json:"pixel_format"`
+	HDR         bool   `json:"hdr"`
+	AudioCodec  string `json:"audio_codec"`
+	Channels    int    `json:"audio_channels"`
+	Size        int64  `json:"size"`
+	Error       string `json:"error,omitempty"` // ffprobe no pudo leer el archivo
+}
+
+// checkFiles analiza los archivos (jobs a la vez) sin convertir nada. El reporte respeta el
+// orden de files
+func checkFiles(ctx context.Context, processor *postprocessor.FFmpegProcessor, files []string, target postprocessor.Target, options *domain.DownloadOptions, jobs int) []checkReport {
+	reports := make([]checkReport, len(files))
+
+	var wg sync.WaitGroup
+	workerPool := make(chan struct{}, jobs)
+	for i, path := range files {
+		workerPool <- struct{}{}
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			defer func() { <-workerPool }()
+			reports[i] = checkFile(ctx, processor, path, target, options)
+		}()
+	}
+	wg.Wait()
+
+	return reports
+}
+
+// checkFile arma el reporte de un archivo
+func checkFile(ctx context.Context, processor *postprocessor.FFmpegProcessor, path string, target postprocessor.Target, options *domain.DownloadOptions) checkReport {
+	report := checkReport{File: path}
+	if stat, err := os.Stat(path); err == nil {
+		report.Size = stat.Size()
+	}
+
+	info, err := processor.GetVideoInfo(ctx, path)
+	if err != nil {
+		report.Error = err.Error()
+		return report
+	}
+
+	report.Width = info.Width
+	report.Height = info.Height
+	report.VideoCodec = info.VideoCodec
+	report.PixelFormat = info.PixelFormat
+	report.HDR = info.IsHDR()
+	report.AudioCodec = info.AudioCodec
+	report.Channels = info.AudioChannels
+	report.Compatible, report.Reason = target.Check(info, options)
+	return report
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/elsanchez/smart-download/internal/config"
+)
+
+func printConfigUsage() {
+	fmt.Println(`Usage: smd config <subcommand>
+
+Subcommands:
+  path    Print the resolved config file location`)
+}
+
+func handleConfig(args []string) {
+	if len(args) == 0 {
+		printConfigUsage()
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "path":
+		path := config.DefaultPath()
+		if jsonOutput {
+			_, err := os.Stat(path)
+			printJSON(map[string]interface{}{"path": path, "exists": err == nil})
+			return
+		}
+		fmt.Println(path)
+	case "help":
+		printConfigUsage()
+	default:
+		fmt.Printf("Unknown config subcommand: %s\n", args[0])
+		printConfigUsage()
+		os.Exit(1)
+	}
+}
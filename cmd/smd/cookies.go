@@ -0,0 +1,558 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/elsanchez/smart-download/internal/config"
+	"github.com/elsanchez/smart-download/internal/cookies"
+	"github.com/elsanchez/smart-download/internal/domain"
+	"github.com/elsanchez/smart-download/internal/repository/sqlite"
+	cookiestui "github.com/elsanchez/smart-download/internal/tui/cookies"
+)
+
+func printCookiesUsage() {
+	fmt.Println(`Usage: smd cookies <subcommand> [args]
+
+Subcommands:
+  list                               List all accounts with validation status
+  tui                                Interactive cookie manager
+  import <file> [options]            Import a Netscape or JSON cookie file
+  merge <file> <file>... [options]   Merge several cookie files into one account
+  export <platform> <name> <output>  Export an account's cookie file
+         [--format netscape|json]    (default: json for a .json output, else netscape)
+  activate <platform> <name>         Set the active account for a platform
+  delete <platform> <name>           Delete an account
+  extract --domain <d> [options]     Extract cookies from an installed browser
+  validate [options]                 Validate all accounts and update their status
+                                     (exits non-zero if any account is invalid or expired)
+
+Import Options:
+  --platform <name>    Platform (auto-detected from cookie domains if empty)
+  --name <name>        Account name (auto-generated if empty)
+  --activate           Set as active account after import
+  --no-validate        Skip expiration validation
+  --force              Overwrite an existing account with the same name
+
+Merge Options:
+  Same as import. Duplicate cookies (same domain and name) keep the latest
+  expiration; differing values are reported as conflicts
+
+Extract Options:
+  --browser <name>     chrome, chromium, firefox, edge or opera
+                       (default: the browser with the most cookies for the domain)
+  --domain <domain>    Cookie domain, e.g. instagram.com (required)
+  --output <file>      Netscape file to write (default: <domain>_<browser>_cookies.txt)
+  --import             Import the extracted cookies as an account
+  --platform, --name, --activate   Same as import (with --import)
+
+Validate Options:
+  --http               Check with an authenticated HTTP request (falls back to
+                       expiration dates for platforms without an endpoint)
+  --platform <name>    Only validate accounts for this platform
+  --expired-only       Only re-check accounts currently marked expired`)
+}
+
+// openDatabase abre la base de datos del daemon directamente (sin socket)
+func openDatabase() (*sqlite.Database, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("get home directory: %w", err)
+	}
+
+	dataDir := filepath.Join(homeDir, ".local", "share", "smart-download")
+	return sqlite.NewDatabase(dataDir)
+}
+
+func handleCookies(args []string) {
+	if len(args) == 0 {
+		printCookiesUsage()
+		os.Exit(1)
+	}
+
+	db, err := openDatabase()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	switch args[0] {
+	case "list":
+		err = cookiesList(db)
+	case "tui":
+		err = cookiesTUI(db)
+	case "import":
+		err = cookiesImport(db, args[1:])
+	case "merge":
+		err = cookiesMerge(db, args[1:])
+	case "export":
+		err = cookiesExport(db, args[1:])
+	case "activate":
+		err = cookiesActivate(db, args[1:])
+	case "delete":
+		err = cookiesDelete(db, args[1:])
+	case "validate":
+		err = cookiesValidate(db, args[1:])
+	case "extract":
+		err = cookiesExtract(db, args[1:])
+	case "help":
+		printCookiesUsage()
+	default:
+		fmt.Printf("Unknown cookies subcommand: %s\n", args[0])
+		printCookiesUsage()
+		db.Close()
+		os.Exit(1)
+	}
+
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		db.Close()
+		os.Exit(1)
+	}
+}
+
+func cookiesList(db *sqlite.Database) error {
+	ctx := context.Background()
+
+	platforms, err := db.AccountRepo.ListPlatforms(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(platforms) == 0 {
+		fmt.Println("No accounts found. Import one with: smd cookies import <file>")
+		return nil
+	}
+
+	now := time.Now()
+	for _, platform := range platforms {
+		accounts, err := db.AccountRepo.GetAll(ctx, platform)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%s (%d):\n", platform, len(accounts))
+		for _, acc := range accounts {
+			active := " "
+			if acc.IsActive {
+				active = "*"
+			}
+			fmt.Printf("  %s %-20s %-8s %-20s %s\n", active, acc.Name, acc.ValidationStatus,
+				cookies.DescribeValidationAge(acc, now), acc.CookiePath)
+			if acc.ValidationError != nil {
+				fmt.Printf("      %s\n", *acc.ValidationError)
+			}
+		}
+		fmt.Println()
+	}
+
+	return nil
+}
+
+// newCookieValidator crea un validator con los endpoints HTTP del archivo de configuración
+func newCookieValidator() (*cookies.CookieValidator, error) {
+	cfg, err := config.Load(config.DefaultPath())
+	if err != nil {
+		return nil, err
+	}
+
+	validator := cookies.NewCookieValidator()
+	validator.SetEndpoints(cfg.Cookies.ValidationEndpoints)
+	return validator, nil
+}
+
+func cookiesTUI(db *sqlite.Database) error {
+	validator, err := newCookieValidator()
+	if err != nil {
+		return err
+	}
+
+	p := tea.NewProgram(cookiestui.NewModel(db.AccountRepo, validator), tea.WithAltScreen())
+	if _, err := p.Run(); err != nil {
+		return fmt.Errorf("run tui: %w", err)
+	}
+	return nil
+}
+
+func cookiesImport(db *sqlite.Database, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("cookie file path is required (usage: smd cookies import <file> [options])")
+	}
+
+	importFlags := flag.NewFlagSet("cookies import", flag.ExitOnError)
+	platform := importFlags.String("platform", "", "Platform (auto-detect if empty)")
+	name := importFlags.String("name", "", "Account name (auto-generate if empty)")
+	activate := importFlags.Bool("activate", false, "Set as active account")
+	noValidate := importFlags.Bool("no-validate", false, "Skip expiration validation")
+	force := importFlags.Bool("force", false, "Overwrite existing account")
+
+	filePath := args[0]
+	if len(args) > 1 {
+		importFlags.Parse(args[1:])
+	}
+
+	importer := cookies.NewCookieImporter(db.AccountRepo)
+	account, err := importer.Import(context.Background(), cookies.ImportOptions{
+		FilePath: filePath,
+		Platform: *platform,
+		Name:     *name,
+		Activate: *activate,
+		Validate: !*noValidate,
+		Force:    *force,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Imported %s/%s\n", account.Platform, account.Name)
+	fmt.Printf("  Cookie file: %s\n", account.CookiePath)
+	fmt.Printf("  Validation:  %s\n", account.ValidationStatus)
+	if account.ValidationError != nil {
+		fmt.Printf("  Message:     %s\n", *account.ValidationError)
+	}
+	if account.IsActive {
+		fmt.Println("  Active:      yes")
+	}
+
+	return nil
+}
+
+func cookiesMerge(db *sqlite.Database, args []string) error {
+	// Archivos primero, luego los flags
+	var paths []string
+	for len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		paths = append(paths, args[0])
+		args = args[1:]
+	}
+	if len(paths) < 2 {
+		return fmt.Errorf("at least two cookie files are required (usage: smd cookies merge <file> <file>... [options])")
+	}
+
+	mergeFlags := flag.NewFlagSet("cookies merge", flag.ExitOnError)
+	platform := mergeFlags.String("platform", "", "Platform (auto-detect if empty)")
+	name := mergeFlags.String("name", "", "Account name (auto-generate if empty)")
+	activate := mergeFlags.Bool("activate", false, "Set as active account")
+	noValidate := mergeFlags.Bool("no-validate", false, "Skip expiration validation")
+	force := mergeFlags.Bool("force", false, "Overwrite existing account")
+	mergeFlags.Parse(args)
+
+	importer := cookies.NewCookieImporter(db.AccountRepo)
+	account, merged, err := importer.ImportMerged(context.Background(), paths, cookies.ImportOptions{
+		Platform: *platform,
+		Name:     *name,
+		Activate: *activate,
+		Validate: !*noValidate,
+		Force:    *force,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, conflict := range merged.Conflicts {
+		fmt.Printf("⚠ Conflicting values for %s %s in %s (kept %s)\n",
+			conflict.Domain, conflict.Name, strings.Join(conflict.Files, ", "), conflict.Kept)
+	}
+
+	fmt.Printf("✓ Merged %d cookies from %d files into %s/%s\n", len(merged.Cookies), len(paths), account.Platform, account.Name)
+	fmt.Printf("  Cookie file: %s\n", account.CookiePath)
+	fmt.Printf("  Validation:  %s\n", account.ValidationStatus)
+	if account.ValidationError != nil {
+		fmt.Printf("  Message:     %s\n", *account.ValidationError)
+	}
+	if account.IsActive {
+		fmt.Println("  Active:      yes")
+	}
+
+	return nil
+}
+
+func cookiesExport(db *sqlite.Database, args []string) error {
+	if len(args) < 3 {
+		return fmt.Errorf("usage: smd cookies export <platform> <name> <output> [--format netscape|json]")
+	}
+
+	exportFlags := flag.NewFlagSet("cookies export", flag.ExitOnError)
+	format := exportFlags.String("format", "", "Output format: netscape or json (default: from the output extension)")
+	exportFlags.Parse(args[3:])
+
+	// Sin --format, un .json se exporta como JSON (para extensiones del navegador)
+	if *format == "" && strings.EqualFold(filepath.Ext(args[2]), ".json") {
+		*format = cookies.FormatJSON
+	}
+
+	exporter := cookies.NewCookieExporter(db.AccountRepo)
+	if err := exporter.Export(context.Background(), args[0], args[1], args[2], *format); err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Exported %s/%s to %s\n", args[0], args[1], args[2])
+	return nil
+}
+
+func cookiesActivate(db *sqlite.Database, args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("usage: smd cookies activate <platform> <name>")
+	}
+
+	if err := db.AccountRepo.SetActive(context.Background(), args[0], args[1]); err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Activated %s/%s\n", args[0], args[1])
+	return nil
+}
+
+func cookiesDelete(db *sqlite.Database, args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("usage: smd cookies delete <platform> <name>")
+	}
+
+	ctx := context.Background()
+	accounts, err := db.AccountRepo.GetAll(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	for _, acc := range accounts {
+		if acc.Name == args[1] {
+			if err := db.AccountRepo.Delete(ctx, acc.ID); err != nil {
+				return err
+			}
+			fmt.Printf("✓ Deleted %s/%s\n", args[0], args[1])
+			return nil
+		}
+	}
+
+	return fmt.Errorf("account not found: %s/%s", args[0], args[1])
+}
+
+// accountValidation es el resultado de validar una cuenta (también la salida de --json)
+type accountValidation struct {
+	Platform string `json:"platform"`
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Message  string `json:"message"`
+}
+
+func cookiesValidate(db *sqlite.Database, args []string) error {
+	validateFlags := flag.NewFlagSet("cookies validate", flag.ExitOnError)
+	useHTTP := validateFlags.Bool("http", false, "Validate with an authenticated HTTP request instead of expiration dates")
+	platform := validateFlags.String("platform", "", "Only validate accounts for this platform")
+	expiredOnly := validateFlags.Bool("expired-only", false, "Only re-check accounts currently marked expired")
+	validateFlags.Parse(args)
+
+	ctx := context.Background()
+
+	validator, err := newCookieValidator()
+	if err != nil {
+		return err
+	}
+
+	// Plataformas a validar
+	platforms := []string{*platform}
+	if *platform == "" {
+		platforms, err = db.AccountRepo.ListPlatforms(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	var results []accountValidation
+	failed := 0
+
+	for _, p := range platforms {
+		accounts, err := db.AccountRepo.GetAll(ctx, p)
+		if err != nil {
+			return err
+		}
+
+		for _, acc := range accounts {
+			if *expiredOnly && acc.ValidationStatus != domain.ValidationStatusExpired {
+				continue
+			}
+
+			result, err := validateAccount(ctx, validator, acc, *useHTTP)
+			if err != nil {
+				return fmt.Errorf("validate %s/%s: %w", acc.Platform, acc.Name, err)
+			}
+
+			var validationErr *string
+			if !result.IsValid {
+				validationErr = &result.Message
+			}
+			if err := db.AccountRepo.UpdateValidation(ctx, acc.ID, result.Status, validationErr); err != nil {
+				return fmt.Errorf("update validation: %w", err)
+			}
+
+			if result.Status == domain.ValidationStatusInvalid || result.Status == domain.ValidationStatusExpired {
+				failed++
+			}
+
+			results = append(results, accountValidation{
+				Platform: acc.Platform,
+				Name:     acc.Name,
+				Status:   result.Status,
+				Message:  result.Message,
+			})
+		}
+	}
+
+	if jsonOutput {
+		printJSON(map[string]interface{}{"accounts": results, "failed": failed})
+	} else if len(results) == 0 {
+		fmt.Println("No accounts to validate")
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "PLATFORM\tNAME\tSTATUS\tMESSAGE")
+		for _, r := range results {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Platform, r.Name, r.Status, r.Message)
+		}
+		w.Flush()
+	}
+
+	// Exit code distinto de 0 para cron/CI
+	if failed > 0 {
+		return fmt.Errorf("%d of %d account(s) failed validation", failed, len(results))
+	}
+
+	return nil
+}
+
+// validateAccount valida una cuenta por expiración o por HTTP. Si la plataforma no
+// soporta validación HTTP se usa la de expiración
+func validateAccount(ctx context.Context, validator *cookies.CookieValidator, acc *domain.Account, useHTTP bool) (*cookies.ValidationResult, error) {
+	if !useHTTP {
+		return validator.ValidateAccount(acc)
+	}
+
+	result, err := validator.ValidateAccountHTTP(ctx, acc)
+	if err != nil || result.Status != domain.ValidationStatusUnknown {
+		return result, err
+	}
+
+	fallback, err := validator.ValidateAccount(acc)
+	if err != nil {
+		return nil, err
+	}
+	fallback.Message = fmt.Sprintf("%s (%s, checked expiration instead)", fallback.Message, result.Message)
+	return fallback, nil
+}
+
+func cookiesExtract(db *sqlite.Database, args []string) error {
+	extractFlags := flag.NewFlagSet("cookies extract", flag.ExitOnError)
+	browser := extractFlags.String("browser", "", "Browser to read from (default: the one with the most cookies)")
+	domainFilter := extractFlags.String("domain", "", "Cookie domain, e.g. instagram.com (required)")
+	output := extractFlags.String("output", "", "Netscape file to write (default: <domain>_<browser>_cookies.txt)")
+	doImport := extractFlags.Bool("import", false, "Import the extracted cookies as an account")
+	platform := extractFlags.String("platform", "", "Platform for --import (auto-detect if empty)")
+	name := extractFlags.String("name", "", "Account name for --import (auto-generate if empty)")
+	activate := extractFlags.Bool("activate", false, "Set the imported account as active")
+	extractFlags.Parse(args)
+
+	if *domainFilter == "" {
+		return fmt.Errorf("--domain is required (e.g. smd cookies extract --browser firefox --domain instagram.com)")
+	}
+
+	extractor := cookies.NewBrowserExtractor()
+
+	// Cookies por navegador
+	fmt.Printf("Cookies for %s:\n", *domainFilter)
+	best, bestCount := "", 0
+	locked := map[string]bool{}
+	for _, b := range extractor.SupportedBrowsers() {
+		count, err := extractor.GetBrowserCookieCount(b, *domainFilter)
+		if err != nil {
+			if errors.Is(err, cookies.ErrBrowserLocked) {
+				fmt.Printf("  %-10s locked (close the browser)\n", b)
+				locked[b] = true
+				continue
+			}
+			if errors.Is(err, cookies.ErrBrowserNotFound) {
+				fmt.Printf("  %-10s not installed\n", b)
+				continue
+			}
+			fmt.Printf("  %-10s error: %v\n", b, err)
+			continue
+		}
+		fmt.Printf("  %-10s %d\n", b, count)
+		if count > bestCount {
+			best, bestCount = b, count
+		}
+	}
+	fmt.Println()
+
+	if locked[*browser] {
+		return fmt.Errorf("%s: %w", *browser, cookies.ErrBrowserLocked)
+	}
+	if *browser == "" {
+		if best == "" {
+			return fmt.Errorf("no browser has cookies for %s (log in first)", *domainFilter)
+		}
+		*browser = best
+	}
+
+	// Archivo de salida: temporal si solo se importa
+	outputPath := *output
+	if outputPath == "" {
+		if *doImport {
+			tmp, err := os.CreateTemp("", "smd-cookies-*.txt")
+			if err != nil {
+				return fmt.Errorf("create temp file: %w", err)
+			}
+			tmp.Close()
+			defer os.Remove(tmp.Name())
+			outputPath = tmp.Name()
+		} else {
+			outputPath = fmt.Sprintf("%s_%s_cookies.txt", strings.TrimPrefix(*domainFilter, "."), *browser)
+		}
+	}
+
+	extracted, err := extractor.Extract(cookies.ExtractOptions{
+		Browser:    *browser,
+		Domain:     *domainFilter,
+		OutputPath: outputPath,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Extracted %d cookies from %s\n", len(extracted), *browser)
+	if *output != "" || !*doImport {
+		fmt.Printf("  Cookie file: %s\n", outputPath)
+	}
+
+	if !*doImport {
+		return nil
+	}
+
+	importer := cookies.NewCookieImporter(db.AccountRepo)
+	account, err := importer.Import(context.Background(), cookies.ImportOptions{
+		FilePath: outputPath,
+		Platform: *platform,
+		Name:     *name,
+		Activate: *activate,
+		Validate: true,
+	})
+	if err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+
+	fmt.Printf("✓ Imported %s/%s\n", account.Platform, account.Name)
+	fmt.Printf("  Cookie file: %s\n", account.CookiePath)
+	fmt.Printf("  Validation:  %s\n", account.ValidationStatus)
+	if account.IsActive {
+		fmt.Println("  Active:      yes")
+	}
+
+	return nil
+}
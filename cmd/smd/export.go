@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/elsanchez/smart-download/pkg/client"
+)
+
+// exportRecord es una descarga exportada; en JSON conserva todos los campos para reimportarla
+type exportRecord struct {
+	ID           int64           `json:"id"`
+	URL          string          `json:"url"`
+	Title        string          `json:"title,omitempty"`
+	Uploader     string          `json:"uploader,omitempty"`
+	UploadDate   string          `json:"upload_date,omitempty"`
+	Platform     string          `json:"platform"`
+	Username     string          `json:"username,omitempty"`
+	Status       string          `json:"status"`
+	Priority     int             `json:"priority,omitempty"`
+	Tags         []string        `json:"tags,omitempty"`
+	Options      json.RawMessage `json:"options,omitempty"`
+	AccountID    *int64          `json:"account_id,omitempty"`
+	OutputPath   string          `json:"output_path,omitempty"`
+	Files        []string        `json:"files,omitempty"`
+	Size         int64           `json:"size"`
+	CreatedAt    time.Time       `json:"created_at"`
+	CompletedAt  *time.Time      `json:"completed_at,omitempty"`
+	ErrorMessage string          `json:"error_message,omitempty"`
+	ErrorType    string          `json:"error_type,omitempty"`
+	Backend      string          `json:"backend,omitempty"`
+	ParentID     *int64          `json:"parent_id,omitempty"`
+}
+
+// exportCSVHeader son las columnas del export CSV
+var exportCSVHeader = []string{"id", "url", "platform", "status", "created_at", "completed_at", "output_path", "size"}
+
+func handleExport(c *client.Client, args []string) {
+	exportFlags := flag.NewFlagSet("export", flag.ExitOnError)
+	format := exportFlags.String("format", "csv", "Output format: csv or json")
+	output := exportFlags.String("output", "", "Output file (default: stdout)")
+	platform := exportFlags.String("platform", "", "Only export downloads from this platform")
+	status := exportFlags.String("status", "", "Only export downloads with this status")
+	tag := exportFlags.String("tag", "", "Only export downloads with this tag")
+	exportFlags.Parse(args)
+
+	if *format != "csv" && *format != "json" {
+		fmt.Printf("Error: Invalid format: %s (use csv or json)\n", *format)
+		os.Exit(1)
+	}
+
+	payload := map[string]interface{}{}
+	if *platform != "" {
+		payload["platform"] = *platform
+	}
+	if *status != "" {
+		payload["status"] = *status
+	}
+	if *tag != "" {
+		payload["tag"] = *tag
+	}
+
+	data, err := c.Call("export", payload)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	var result struct {
+		Downloads []exportRecord `json:"downloads"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		fmt.Printf("Error: unmarshal response: %v\n", err)
+		os.Exit(1)
+	}
+
+	w := io.Writer(os.Stdout)
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if *format == "json" {
+		err = writeExportJSON(w, result.Downloads)
+	} else {
+		err = writeExportCSV(w, result.Downloads)
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Con --output el resumen no se mezcla con los datos
+	if *output != "" {
+		fmt.Printf("✓ Exported %d download(s) to %s\n", len(result.Downloads), *output)
+	}
+}
+
+// writeExportCSV escribe una fila por descarga (fechas en RFC 3339, size en bytes)
+func writeExportCSV(w io.Writer, records []exportRecord) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(exportCSVHeader); err != nil {
+		return fmt.Errorf("write csv: %w", err)
+	}
+
+	for _, r := range records {
+		completedAt := ""
+		if r.CompletedAt != nil {
+			completedAt = r.CompletedAt.Format(time.RFC3339)
+		}
+
+		row := []string{
+			strconv.FormatInt(r.ID, 10),
+			r.URL,
+			r.Platform,
+			r.Status,
+			r.CreatedAt.Format(time.RFC3339),
+			completedAt,
+			r.OutputPath,
+			strconv.FormatInt(r.Size, 10),
+		}
+		if err := cw.Write(row); err != nil {
+			return fmt.Errorf("write csv: %w", err)
+		}
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("write csv: %w", err)
+	}
+
+	return nil
+}
+
+// writeExportJSON escribe un array JSON con todos los campos de cada descarga
+func writeExportJSON(w io.Writer, records []exportRecord) error {
+	if records == nil {
+		records = []exportRecord{}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(records); err != nil {
+		return fmt.Errorf("write json: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/elsanchez/smart-download/internal/postprocessor"
+)
+
+// imageExts son las extensiones con las que --output se toma como archivo (y no directorio)
+var imageExts = map[string]bool{".jpg": true, ".jpeg": true, ".png": true}
+
+// handleFrames extrae un fotograma (--frame) o varios repartidos por el video (--frames)
+// en lugar de convertir. Retorna cuántos videos fallaron
+func handleFrames(ctx context.Context, processor *postprocessor.FFmpegProcessor, videoFiles []string, atTime string, count int, output string) int {
+	// --output thumb.jpg: un archivo concreto, solo tiene sentido con un video y --frame
+	outputFile := ""
+	if imageExts[strings.ToLower(filepath.Ext(output))] {
+		if count > 0 || len(videoFiles) > 1 {
+			fmt.Println("Error: --output <image> only works with --frame and a single video (use a directory)")
+			os.Exit(1)
+		}
+		outputFile = output
+		output = ""
+	}
+	if output != "" {
+		os.MkdirAll(output, 0755)
+	}
+
+	failed := 0
+	for i, inputPath := range videoFiles {
+		fmt.Printf("[%d/%d] %s\n", i+1, len(videoFiles), filepath.Base(inputPath))
+
+		if count > 0 {
+			frames, err := processor.ExtractFrames(ctx, inputPath, count, output)
+			if err != nil {
+				fmt.Printf("  ✗ Frame extraction failed: %v\n", err)
+				failed++
+			}
+			for _, frame := range frames {
+				fmt.Printf("  ✓ %s\n", frame)
+			}
+			continue
+		}
+
+		path := outputFile
+		if path == "" && output != "" {
+			base := strings.TrimSuffix(filepath.Base(inputPath), filepath.Ext(inputPath))
+			path = filepath.Join(output, fmt.Sprintf("%s_frame_%s.jpg", base, strings.ReplaceAll(atTime, ":", "-")))
+		}
+
+		framePath, err := processor.ExtractFrame(ctx, inputPath, atTime, path)
+		if err != nil {
+			fmt.Printf("  ✗ Frame extraction failed: %v\n", err)
+			failed++
+			continue
+		}
+		fmt.Printf("  ✓ %s\n", framePath)
+	}
+
+	return failed
+}
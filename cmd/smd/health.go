@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/elsanchez/smart-download/pkg/client"
+)
+
+// handleHealth muestra el estado del daemon y sale con 1 si algo falla (para watchdogs)
+func handleHealth(c *client.Client) {
+	info, err := c.Health()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if jsonOutput {
+		printJSON(info)
+	} else {
+		printHealth(info)
+	}
+
+	if !info.Healthy {
+		os.Exit(1)
+	}
+}
+
+// printHealth imprime el estado del daemon
+func printHealth(info *client.HealthInfo) {
+	status := "healthy"
+	if !info.Healthy {
+		status = "unhealthy"
+	}
+
+	fmt.Printf("Daemon:    %s (v%s, up %s)\n", status, info.Version, info.Uptime)
+	fmt.Printf("Workers:   %d / %d busy\n", info.WorkersBusy, info.WorkersTotal)
+	fmt.Printf("Database:  %s\n", info.Database)
+	fmt.Println("Dependencies:")
+
+	names := make([]string, 0, len(info.Dependencies))
+	for name := range info.Dependencies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		icon := "✓"
+		if info.Dependencies[name] != "ok" {
+			icon = "✗"
+		}
+		fmt.Printf("  %s %-11s %s\n", icon, name, info.Dependencies[name])
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/elsanchez/smart-download/internal/domain"
+	"github.com/elsanchez/smart-download/pkg/client"
+)
+
+// handleInfo muestra título, duración, formatos disponibles y si hacen falta cookies,
+// sin descargar nada
+func handleInfo(c *client.Client, args []string) {
+	if len(args) == 0 {
+		fmt.Println("Error: URL is required")
+		fmt.Println("Usage: smd info <url> [--account <name>] [--proxy <url>]")
+		os.Exit(1)
+	}
+
+	infoFlags := flag.NewFlagSet("info", flag.ExitOnError)
+	account := infoFlags.String("account", "", "Use the cookies of this account instead of the active one")
+	proxy := infoFlags.String("proxy", "", "Query the URL through this proxy")
+	url := args[0]
+	infoFlags.Parse(args[1:])
+
+	if *proxy != "" {
+		if err := domain.ValidateProxy(*proxy); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	payload := map[string]string{"url": url, "account": *account, "proxy": *proxy}
+	if jsonOutput {
+		printRawResponse(c, "info", payload)
+		return
+	}
+
+	info, err := c.Info(url, *account, *proxy)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	printURLInfo(info)
+}
+
+// printURLInfo imprime el resultado de smd info
+func printURLInfo(info *client.URLInfo) {
+	if info.Title != "" {
+		fmt.Println(info.Title)
+	} else {
+		fmt.Println(info.URL)
+	}
+	if info.Uploader != "" {
+		fmt.Printf("Uploader:   %s\n", info.Uploader)
+	}
+	if info.UploadDate != "" {
+		fmt.Printf("Uploaded:   %s\n", info.UploadDate)
+	}
+	if info.IsLive {
+		fmt.Println("Duration:   live stream (use smd add --live)")
+	} else if info.Duration > 0 {
+		fmt.Printf("Duration:   %s\n", (time.Duration(info.Duration) * time.Second).String())
+	}
+	fmt.Printf("Platform:   %s (%s)\n", info.Platform, info.Backend)
+
+	switch {
+	case info.NeedsCookies:
+		fmt.Println("Cookies:    needed")
+		fmt.Printf("Error:      %s\n", info.Error)
+		if info.Account == "" {
+			fmt.Printf("Hint:       import cookies for %s (smd cookies import)\n", info.Platform)
+		} else {
+			fmt.Printf("Hint:       the cookies of %q may have expired (smd cookies validate)\n", info.Account)
+		}
+		return
+	case info.Account != "":
+		fmt.Printf("Cookies:    account %q\n", info.Account)
+	default:
+		fmt.Println("Cookies:    not needed")
+	}
+
+	if info.Backend == "gallery-dl" {
+		fmt.Printf("Files:      %d\n", info.FileCount)
+		return
+	}
+
+	if len(info.Formats) == 0 {
+		return
+	}
+
+	fmt.Println("\nFormats (pick one with smd add --format <id>, or combine video+audio: 137+140):")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tEXT\tRESOLUTION\tFPS\tVCODEC\tACODEC\tSIZE\tNOTE")
+	for _, f := range info.Formats {
+		fps, size := "", ""
+		if f.FPS > 0 {
+			fps = fmt.Sprintf("%g", f.FPS)
+		}
+		if f.FileSize > 0 {
+			size = formatBytes(f.FileSize)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			f.ID, f.Ext, f.Resolution, fps, f.VideoCodec, f.AudioCodec, size, f.Note)
+	}
+	w.Flush()
+}
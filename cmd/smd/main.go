@@ -1,14 +1,23 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/elsanchez/smart-download/internal/config"
+	"github.com/elsanchez/smart-download/internal/domain"
+	"github.com/elsanchez/smart-download/internal/downloader"
+	"github.com/elsanchez/smart-download/internal/logging"
 	"github.com/elsanchez/smart-download/internal/postprocessor"
 	"github.com/elsanchez/smart-download/pkg/client"
 )
@@ -17,7 +26,18 @@ const (
 	version = "0.1.0"
 )
 
+// jsonOutput hace que los comandos impriman el Data crudo del daemon (--json)
+var jsonOutput bool
+
+// autostart inicia el daemon si no está corriendo (--autostart o client.autostart)
+var autostart bool
+
+// timeoutFlag es el plazo de cada petición al daemon (--timeout o SMD_TIMEOUT; "" = por defecto)
+var timeoutFlag string
+
 func main() {
+	os.Args = parseGlobalFlags(os.Args)
+
 	if len(os.Args) < 2 {
 		printUsage()
 		os.Exit(1)
@@ -25,21 +45,50 @@ func main() {
 
 	// Crear cliente
 	c := client.NewDefaultClient()
+	configureAutostart(c)
+	if err := configureTimeout(c); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
 
 	switch os.Args[1] {
 	case "add":
 		handleAdd(c, os.Args[2:])
+	case "info":
+		handleInfo(c, os.Args[2:])
 	case "status":
 		handleStatus(c, os.Args[2:])
 	case "list":
 		handleList(c, os.Args[2:])
 	case "stats":
-		handleStats(c)
+		handleStats(c, os.Args[2:])
+	case "workers":
+		handleWorkers(c, os.Args[2:])
+	case "pause", "resume":
+		handlePause(c, os.Args[1])
+	case "health":
+		handleHealth(c)
+	case "tag":
+		handleTag(c, os.Args[2:])
+	case "export":
+		handleExport(c, os.Args[2:])
+	case "prune":
+		handlePrune(c, os.Args[2:])
+	case "requeue":
+		handleRequeue(c, os.Args[2:])
+	case "tui":
+		handleTUI(c)
 	case "convert":
 		handleConvert(os.Args[2:])
 	case "cookies":
 		handleCookies(os.Args[2:])
+	case "config":
+		handleConfig(os.Args[2:])
 	case "version":
+		if jsonOutput {
+			printJSON(map[string]string{"version": version})
+			return
+		}
 		fmt.Printf("smd v%s\n", version)
 	case "help":
 		printUsage()
@@ -55,31 +104,204 @@ func main() {
 	}
 }
 
+// parseGlobalFlags extrae los flags globales (--json, --autostart) de cualquier posición.
+// --timeout solo se reconoce antes del comando: smd add ya tiene su propio --timeout
+func parseGlobalFlags(args []string) []string {
+	filtered := make([]string, 0, len(args))
+	command := false
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--json" || arg == "-json" {
+			jsonOutput = true
+			continue
+		}
+		if arg == "--autostart" || arg == "-autostart" {
+			autostart = true
+			continue
+		}
+		if !command && i > 0 {
+			if value, ok := strings.CutPrefix(arg, "--timeout="); ok {
+				timeoutFlag = value
+				continue
+			}
+			if arg == "--timeout" && i+1 < len(args) {
+				timeoutFlag = args[i+1]
+				i++
+				continue
+			}
+			command = !strings.HasPrefix(arg, "-")
+		}
+		filtered = append(filtered, arg)
+	}
+	return filtered
+}
+
+// configureTimeout aplica --timeout o, si no se pasó, SMD_TIMEOUT ("0" = sin límite)
+func configureTimeout(c *client.Client) error {
+	value, source := timeoutFlag, "--timeout"
+	if value == "" {
+		value, source = os.Getenv("SMD_TIMEOUT"), "SMD_TIMEOUT"
+	}
+	if value == "" {
+		return nil
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout < 0 {
+		return fmt.Errorf("invalid %s %q (use a duration like 30s or 2m, 0 = no limit)", source, value)
+	}
+	c.SetTimeout(timeout)
+	return nil
+}
+
+// configureAutostart habilita el autostart del daemon según --autostart y la sección client
+// del config. Un config inválido no impide usar smd: el daemon ya informa esos errores
+func configureAutostart(c *client.Client) {
+	daemonPath := ""
+	if cfg, err := config.Load(config.DefaultPath()); err == nil {
+		autostart = autostart || cfg.Client.Autostart
+		daemonPath = cfg.Client.DaemonPath
+	}
+	c.SetAutostart(autostart, daemonPath)
+}
+
+// printJSON imprime un valor como JSON indentado (json.RawMessage se re-indenta tal cual)
+func printJSON(v interface{}) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(string(data))
+}
+
+// printRawResponse envía la acción al daemon e imprime su Data sin decorar
+func printRawResponse(c *client.Client, action string, payload interface{}) {
+	data, err := c.Call(action, payload)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	printJSON(data)
+}
+
 func printUsage() {
 	fmt.Println(`Smart Media Downloader (smd) v` + version + `
 
-Usage: smd <command> [args]
+Usage: smd [--json] [--autostart] [--timeout <d>] <command> [args]
 
 Commands:
   add <url> [options]    Add download to queue
-  convert <files...>     Convert local files to WhatsApp MP4
+  info <url>             Show title, duration, available formats (or file count) and
+                         whether cookies are needed, without downloading
+                         (--account <name>, --proxy <url>)
+  convert <files...>     Convert local files to WhatsApp MP4 (--target telegram|instagram
+                         for other platforms)
+                         (--frame <time> / --frames <n>: save frames as images instead)
   cookies <subcommand>   Manage authentication cookies
-  status <id>            Get download status
+  config path            Print the config file location
+  status <id>            Get download status (a channel/profile also lists its items)
+  tag <id> +tag -tag     Add (+) or remove (-) tags of a download
   list [limit] [options] List recent downloads (default: 50, most recent first)
-  stats                  Show queue statistics
+  stats [--by-platform]  Show queue statistics, bytes downloaded and success rates
+                         (--by-platform: table per platform)
+  workers [n]            Show busy/total workers, or download n URLs in parallel from now
+                         on without restarting the daemon (1-32)
+  pause                  Stop starting pending downloads (running ones finish normally)
+  resume                 Start pending downloads again
+  health                 Show daemon health (exits 1 if a dependency or the database fails)
+  tui                    Interactive downloads dashboard (add, cancel, retry, delete)
+  export [options]       Export download history (--format csv|json, --output <file>,
+                         --platform, --status, --tag)
+  prune --older-than <age> [--delete-files] [--failed]
+                         Delete old completed (--failed: also failed) downloads from history
+  requeue [--platform <name>] [--error-type <type>]
+                         Retry every failed download (e.g. after renewing cookies),
+                         optionally only one platform and/or error type (auth...)
   version                Show version
   help                   Show this help
 
+Global Options:
+  --json                 Print the raw daemon response as indented JSON
+                         (add, info, status, list, stats, health, version)
+  --autostart            Start smart-downloadd if it isn't running (or set
+                         client.autostart: true in the config file)
+  --timeout <d>          How long to wait for the daemon (default: 10s, 0 = no limit;
+                         also SMD_TIMEOUT). Must come before the command: after
+                         it, e.g. smd add --timeout, it is that command's own flag
+
 List Options:
   --details              Show error details for failed downloads
+  --limit <n>            Maximum number of downloads to show (default: 50)
+  --offset <n>           Skip the n most recent matches (paging)
+  --platform <name>      Only show downloads from this platform
+  --status <status>      Only show pending, downloading, processing, completed or failed
+  --tag <tag>            Only show downloads with this tag
+  --since <when>         Only downloads added since then: a date (2024-01-01, yesterday,
+                         today), a date and time (2024-01-01T15:04) or an age (24h, 7d)
+  --until <when>         Only downloads added before then; a date includes the whole day
+  --flat                 Show the items of a channel/profile as separate entries
+                         (by default they are summarized under it, unless --status is used)
 
 Add Options:
   --clip-start <time>  Start time for clipping (optional, format: 30s, 1m30s, or 00:01:30)
   --clip-end <time>    End time for clipping (optional, format: 30s, 1m30s, or 00:01:30)
   --gif [width]        Convert to GIF (default width: 480px)
+  --gif-start <time>   Where the GIF starts (00:10, 1m30s or seconds; after --clip-*,
+                       relative to the clip)
+  --gif-duration <time> Length of the GIF (e.g. 5 or 00:05; default: to the end)
+  --gif-fps <n>        GIF frames per second, 1-50 (default: 15; lower = smaller file)
+  --gif-dither <mode>  GIF dithering: bayer (default), floyd_steinberg, sierra2, sierra2_4a,
+                       heckbert, none (sierra3, burkes, atkinson need ffmpeg 6+)
   --no-convert         Skip auto-conversion to WhatsApp MP4
+  --target <name>      Convert for this platform: whatsapp (default), telegram
+                       (keeps HEVC/Opus, up to 4K) or instagram
   --resolution <res>   Video resolution (1080p, 720p, 480p)
+  --format <id>        Exact yt-dlp format id, e.g. 137+140 (see smd info); bypasses
+                       the --resolution presets
   --audio-only         Extract audio only
+  --audio-format <f>   Audio format with --audio-only: mp3, opus, m4a, flac (default: mp3)
+  --audio-bitrate <k>  Audio bitrate in kbps with --audio-only (default: 192)
+  --no-audio           Remove the audio track (silent video, smaller file)
+  --ensure-audio       Add a silent audio track if the video has none (for platforms
+                       that reject or loop videos without audio, e.g. Instagram)
+  --keep-channels      Keep surround audio (5.1, 7.1) instead of downmixing it to stereo
+  --no-tonemap         Don't convert HDR videos to SDR (they look gray on non-HDR screens)
+  --strip-metadata     Remove metadata tags from the converted video (kept by default)
+  --title <text>       Set the title tag of the converted video
+  --comment <text>     Set the comment tag of the converted video
+  --force              Add even if the same URL is already queued or downloaded
+  --archive            Skip items already recorded in the download archive
+                       (incremental channel/profile syncs)
+  --account <name>     Use the cookies of this account for the URL's platform
+                       instead of the active one
+  --priority <n>       Queue priority: higher values are downloaded first (default: 0)
+  --after <HH:MM>      Don't start before the next occurrence of this time of day
+  --at <date time>     Don't start before this moment (e.g. 2025-06-01T02:00)
+  --live               Record a segment if the URL is a live stream (rejected otherwise)
+  --duration <d>       Length of the live recording (default: 10m)
+  --profile            Treat the URL as a channel/profile: queue its latest items as
+                       separate downloads (gallery-dl profiles use --range 1-<max>)
+  --max <n>            With --profile: how many of the latest items (default: 50);
+                       items downloaded by a previous --profile add are skipped
+  --range <range>      Only download these gallery-dl items (e.g. 1-20: the first 20,
+                       usually the latest posts of a profile; also 5, 10- or 1-5,10)
+  --timeout <d>        Fail the download if it takes longer than this
+                       (e.g. 3h; default: the daemon's download_timeout)
+  --proxy <url>        Download through this proxy, e.g. socks5://127.0.0.1:1080 or
+                       http://host:8080 (default: defaults.proxy from the config)
+  --filename-template <t>
+                       Name the file from these fields instead of
+                       platform_username_DDMMYYYY: %(platform)s, %(username)s,
+                       %(date)s (day queued), %(uploader)s, %(title)s, %(upload_date)s
+                       (default: defaults.filename_template from the config)
+  --tag <tag>          Tag the download, e.g. --tag work --tag urgent (repeatable)
+  --sponsorblock[=cats] Remove SponsorBlock segments, YouTube only
+                       (default: sponsor; e.g. --sponsorblock=sponsor,intro,selfpromo)
+  --yt-arg <arg>       Extra argument passed verbatim to yt-dlp/gallery-dl
+                       (repeatable, not validated; use --yt-arg=--flag=value)
+  --dry-run            Show the platform, downloader, cookie account and final options
+                       that would be used, without queuing anything
 
 Clipping behavior:
   --clip-start only    Clip from start time to end of video
@@ -93,16 +315,38 @@ Examples:
   smd add https://youtube.com/watch?v=xxx --clip-start 1m
   smd add https://youtube.com/watch?v=xxx --clip-end 30s
   smd add https://youtube.com/watch?v=xxx --gif 480
+  smd add https://youtube.com/watch?v=xxx --gif --gif-start 00:10 --gif-duration 5
   smd add https://youtube.com/watch?v=xxx --no-convert
+  smd add https://youtube.com/watch?v=xxx --target telegram
+  smd add https://youtube.com/watch?v=xxx --sponsorblock=sponsor,intro
+  smd add https://youtube.com/watch?v=xxx --after 02:00
+  smd add https://youtube.com/watch?v=xxx --yt-arg=--geo-bypass --yt-arg=--format-sort=res
+  smd add https://www.reddit.com/r/pics/comments/xxx --dry-run
+  smd add https://www.youtube.com/@channel --profile --max 20
   smd https://youtube.com/watch?v=xxx          (shorthand for 'add')
   smd convert video.mp4
   smd convert *.mp4 --clip-start 10s --clip-end 30s
   smd convert /path/to/videos/ --recursive
   smd convert video.mp4 --clip-start 1m
   smd convert video.mp4 --clip-end 2m
+  smd convert /path/to/videos/ --recursive --skip-existing --jobs 4
+  smd convert video.mp4 --rotate 90 --crop auto
+  smd convert video.mp4 --speed 4
+  smd convert video.mp4 --no-audio
+  smd convert video.mp4 --target telegram
+  smd convert /path/to/videos/ --check-only --json
+  smd convert a.mp4 b.mp4 --concat --output joined.mp4
+  smd convert video.mp4 --strip-metadata --title "Holidays 2024"
+  smd convert video.mp4 --frame 00:10 --output thumb.jpg
+  smd convert video.mp4 --frames 5 --output contact/
+  smd info https://youtube.com/watch?v=xxx
+  smd add https://youtube.com/watch?v=xxx --format 137+140
   smd status 123
   smd list 10
-  smd stats`)
+  smd stats
+  smd stats --by-platform
+  smd list --json
+  smd requeue --platform twitter --error-type auth`)
 }
 
 func handleAdd(c *client.Client, args []string) {
@@ -118,9 +362,45 @@ func handleAdd(c *client.Client, args []string) {
 	clipEnd := addFlags.String("clip-end", "", "Clip end time (HH:MM:SS or seconds)")
 	convertToGIF := addFlags.Bool("gif", false, "Convert to GIF")
 	gifWidth := addFlags.Int("gif-width", 480, "GIF width in pixels")
-	noConvert := addFlags.Bool("no-convert", false, "Skip WhatsApp MP4 conversion")
+	gifStart := addFlags.String("gif-start", "", "Where the GIF starts (e.g. 00:10, 1m30s or 90)")
+	gifDuration := addFlags.String("gif-duration", "", "Length of the GIF (e.g. 5, 5s or 00:05)")
+	gifFPS := addFlags.Int("gif-fps", 0, "GIF frames per second (default: 15)")
+	gifDither := addFlags.String("gif-dither", "", "GIF dithering: "+strings.Join(postprocessor.GIFDitherModes, ", ")+" (default: bayer)")
+	noConvert := addFlags.Bool("no-convert", false, "Skip WhatsApp MP4 conversion (--no-convert=false overrides defaults.no_convert)")
+	target := addFlags.String("target", "", "Conversion target: "+strings.Join(postprocessor.TargetNames, ", ")+" (default: whatsapp)")
 	resolution := addFlags.String("resolution", "", "Video resolution (1080p, 720p, 480p)")
+	formatID := addFlags.String("format", "", "Exact yt-dlp format id (e.g. 137+140, see smd info)")
 	audioOnly := addFlags.Bool("audio-only", false, "Extract audio only")
+	audioFormat := addFlags.String("audio-format", "", "Audio format with --audio-only (mp3, opus, m4a, flac)")
+	audioBitrate := addFlags.Int("audio-bitrate", 0, "Audio bitrate in kbps with --audio-only")
+	noAudio := addFlags.Bool("no-audio", false, "Remove the audio track from the converted video")
+	ensureAudio := addFlags.Bool("ensure-audio", false, "Add a silent audio track if the video has none")
+	keepChannels := addFlags.Bool("keep-channels", false, "Keep surround audio instead of downmixing it to stereo")
+	noTonemap := addFlags.Bool("no-tonemap", false, "Don't convert HDR videos to SDR")
+	stripMetadata := addFlags.Bool("strip-metadata", false, "Remove all metadata tags from the converted video")
+	metadataTitle := addFlags.String("title", "", "Set the title tag of the converted video")
+	metadataComment := addFlags.String("comment", "", "Set the comment tag of the converted video")
+	force := addFlags.Bool("force", false, "Add even if the URL was already downloaded")
+	dryRun := addFlags.Bool("dry-run", false, "Show how the URL would be downloaded without queuing it")
+	archive := addFlags.Bool("archive", false, "Skip items already recorded in the download archive")
+	account := addFlags.String("account", "", "Use the cookies of this account instead of the active one")
+	priority := addFlags.Int("priority", 0, "Queue priority (higher is downloaded first)")
+	after := addFlags.String("after", "", "Don't start before this time of day (HH:MM)")
+	at := addFlags.String("at", "", "Don't start before this date and time")
+	live := addFlags.Bool("live", false, "Record a segment if the URL is a live stream")
+	liveDuration := addFlags.String("duration", "", "Length of the live recording (e.g. 10m)")
+	timeout := addFlags.String("timeout", "", "Maximum time for this download (e.g. 3h)")
+	proxy := addFlags.String("proxy", "", "Proxy for this download (e.g. socks5://127.0.0.1:1080)")
+	filenameTemplate := addFlags.String("filename-template", "", "Output filename template (e.g. %(uploader)s_%(title)s)")
+	itemRange := addFlags.String("range", "", "Only download these gallery-dl items (e.g. 1-20)")
+	profile := addFlags.Bool("profile", false, "Queue the latest items of a channel/profile URL")
+	maxItems := addFlags.Int("max", 0, "With --profile: how many of the latest items to queue (default: 50)")
+	var sponsorBlock sponsorBlockFlag
+	addFlags.Var(&sponsorBlock, "sponsorblock", "Remove SponsorBlock segments (YouTube only, default: sponsor)")
+	var tags stringSlice
+	addFlags.Var(&tags, "tag", "Tag the download (repeatable)")
+	var extraArgs stringSlice
+	addFlags.Var(&extraArgs, "yt-arg", "Extra argument passed verbatim to yt-dlp/gallery-dl (repeatable)")
 
 	// URL es el primer argumento
 	url := args[0]
@@ -130,8 +410,103 @@ func handleAdd(c *client.Client, args []string) {
 		addFlags.Parse(args[1:])
 	}
 
-	// Validación de clip: al menos uno debe estar especificado si se quiere hacer clipping
-	// Both, one, or neither flag can be specified - ClipVideo handles all cases
+	scheduledAfter, err := parseSchedule(*after, *at, time.Now())
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *itemRange != "" {
+		if err := downloader.ValidateRange(*itemRange); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	formatSet, noConvertSet := false, false
+	addFlags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "format":
+			formatSet = true
+		case "no-convert":
+			noConvertSet = true
+		}
+	})
+	if formatSet && strings.TrimSpace(*formatID) == "" {
+		fmt.Println("Error: --format requires a yt-dlp format id (see smd info <url>)")
+		os.Exit(1)
+	}
+	if *formatID != "" && *resolution != "" {
+		fmt.Println("Warning: --format bypasses the resolution presets, --resolution is ignored")
+	}
+
+	if (*audioFormat != "" || *audioBitrate != 0) && !*audioOnly {
+		fmt.Println("Error: --audio-format and --audio-bitrate require --audio-only")
+		os.Exit(1)
+	}
+
+	// Las combinaciones inválidas las rechaza el mismo Validate que usa el daemon
+	addOpts := domain.DownloadOptions{
+		AudioOnly:        *audioOnly,
+		AudioFormat:      *audioFormat,
+		AudioBitrate:     *audioBitrate,
+		ClipStart:        *clipStart,
+		ClipEnd:          *clipEnd,
+		ConvertToGIF:     *convertToGIF,
+		GIFWidth:         *gifWidth,
+		RemoveAudio:      *noAudio,
+		EnsureAudio:      *ensureAudio,
+		KeepChannels:     *keepChannels,
+		Profile:          *profile,
+		MaxItems:         *maxItems,
+		FilenameTemplate: *filenameTemplate,
+	}
+	if err := addOpts.Validate(); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *timeout != "" {
+		if d, err := time.ParseDuration(*timeout); err != nil || d <= 0 {
+			fmt.Printf("Error: invalid --timeout %q (use e.g. 90m or 3h)\n", *timeout)
+			os.Exit(1)
+		}
+	}
+
+	gifOpts := domain.DownloadOptions{
+		ConvertToGIF: *convertToGIF,
+		GIFStart:     *gifStart,
+		GIFDuration:  *gifDuration,
+		GIFFPS:       *gifFPS,
+		GIFDither:    *gifDither,
+	}
+	if err := postprocessor.ValidateGIF(&gifOpts); err != nil {
+		fmt.Printf("Error: %v (--gif-* options need --gif)\n", err)
+		os.Exit(1)
+	}
+
+	if err := postprocessor.ValidateTarget(*target); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *proxy != "" {
+		if err := domain.ValidateProxy(*proxy); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if *liveDuration != "" {
+		if !*live {
+			fmt.Println("Error: --duration requires --live")
+			os.Exit(1)
+		}
+		if d, err := time.ParseDuration(*liveDuration); err != nil || d <= 0 {
+			fmt.Printf("Error: invalid --duration %q (use e.g. 10m or 1h30m)\n", *liveDuration)
+			os.Exit(1)
+		}
+	}
 
 	// Construir options
 	options := make(map[string]interface{})
@@ -139,8 +514,17 @@ func handleAdd(c *client.Client, args []string) {
 	if *resolution != "" {
 		options["resolution"] = *resolution
 	}
+	if *formatID != "" {
+		options["format_id"] = strings.TrimSpace(*formatID)
+	}
 	if *audioOnly {
 		options["audio_only"] = true
+		if *audioFormat != "" {
+			options["audio_format"] = *audioFormat
+		}
+		if *audioBitrate != 0 {
+			options["audio_bitrate"] = *audioBitrate
+		}
 	}
 	if *clipStart != "" || *clipEnd != "" {
 		options["clip_start"] = *clipStart
@@ -151,23 +535,119 @@ func handleAdd(c *client.Client, args []string) {
 		if *gifWidth > 0 {
 			options["gif_width"] = *gifWidth
 		}
+		if *gifStart != "" {
+			options["gif_start"] = *gifStart
+		}
+		if *gifDuration != "" {
+			options["gif_duration"] = *gifDuration
+		}
+		if *gifFPS != 0 {
+			options["gif_fps"] = *gifFPS
+		}
+		if *gifDither != "" {
+			options["gif_dither"] = *gifDither
+		}
+	}
+	if *noAudio {
+		options["remove_audio"] = true
+	}
+	if *ensureAudio {
+		options["ensure_audio"] = true
+	}
+	if *keepChannels {
+		options["keep_channels"] = true
+	}
+	if *noTonemap {
+		options["no_tonemap"] = true
+	}
+	if *stripMetadata {
+		options["strip_metadata"] = true
+	}
+	if *metadataTitle != "" {
+		options["metadata_title"] = *metadataTitle
+	}
+	if *metadataComment != "" {
+		options["metadata_comment"] = *metadataComment
+	}
+	// Explícito también en false, para ganarle a defaults.no_convert
+	if noConvertSet {
+		options["no_convert"] = *noConvert
 	}
-	if *noConvert {
-		options["no_convert"] = true
+	if *target != "" {
+		options["target"] = strings.ToLower(*target)
+	}
+	if *archive {
+		options["archive"] = true
+	}
+	if len(sponsorBlock.categories) > 0 {
+		options["sponsorblock"] = sponsorBlock.categories
+	}
+	if len(extraArgs) > 0 {
+		options["extra_args"] = []string(extraArgs)
+	}
+	if *itemRange != "" {
+		options["range"] = *itemRange
+	}
+	if *profile {
+		options["profile"] = true
+	}
+	if *maxItems > 0 {
+		options["max_items"] = *maxItems
+	}
+	if *timeout != "" {
+		options["timeout"] = *timeout
+	}
+	if *proxy != "" {
+		options["proxy"] = *proxy
+	}
+	if *filenameTemplate != "" {
+		options["filename_template"] = *filenameTemplate
+	}
+	if *live {
+		options["live"] = true
+		if *liveDuration != "" {
+			options["live_duration"] = *liveDuration
+		}
 	}
 
 	payload := &client.AddDownloadPayload{
-		URL:     url,
-		Options: options,
+		URL:            url,
+		Options:        options,
+		Account:        *account,
+		Priority:       *priority,
+		ScheduledAfter: scheduledAfter,
+		Tags:           tags,
+		Force:          *force,
+	}
+
+	if *dryRun {
+		if jsonOutput {
+			printRawResponse(c, "resolve", payload)
+			return
+		}
+		printResolve(c, payload)
+		return
 	}
 
-	id, err := c.AddDownload(payload)
+	if jsonOutput {
+		printRawResponse(c, "add", payload)
+		return
+	}
+
+	result, err := c.AddDownload(payload)
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Printf("✓ Download added with ID: %d\n", id)
+	if result.Duplicate {
+		fmt.Printf("⚠ Already in queue with ID: %d (status: %s)\n", result.ID, result.Status)
+		fmt.Printf("  URL: %s\n", url)
+		fmt.Println("  Use --force to download it again")
+		return
+	}
+
+	fmt.Printf("✓ Download added with ID: %d\n", result.ID)
 	fmt.Printf("  URL: %s\n", url)
 
 	// Mostrar opciones configuradas
@@ -177,22 +657,170 @@ func handleAdd(c *client.Client, args []string) {
 			fmt.Printf("    Clip: %s - %s\n", *clipStart, *clipEnd)
 		}
 		if *convertToGIF {
-			fmt.Printf("    GIF: %dpx width\n", *gifWidth)
+			fmt.Printf("    GIF: %dpx width", *gifWidth)
+			if *gifStart != "" {
+				fmt.Printf(", from %s", *gifStart)
+			}
+			if *gifDuration != "" {
+				fmt.Printf(", %s long", *gifDuration)
+			}
+			if *gifFPS != 0 {
+				fmt.Printf(", %d fps", *gifFPS)
+			}
+			if *gifDither != "" {
+				fmt.Printf(", %s dither", *gifDither)
+			}
+			fmt.Println()
 		}
 		if *noConvert {
 			fmt.Println("    Skip WhatsApp conversion")
+		} else if *target != "" {
+			fmt.Printf("    Target: %s\n", strings.ToLower(*target))
 		}
 		if *resolution != "" {
 			fmt.Printf("    Resolution: %s\n", *resolution)
 		}
+		if *formatID != "" {
+			fmt.Printf("    Format: %s (bypasses the resolution presets)\n", strings.TrimSpace(*formatID))
+		}
 		if *audioOnly {
 			fmt.Println("    Audio only")
 		}
+		if *archive {
+			fmt.Println("    Download archive: enabled")
+		}
+		if len(sponsorBlock.categories) > 0 {
+			fmt.Printf("    SponsorBlock: %s\n", sponsorBlock.String())
+		}
+		if len(extraArgs) > 0 {
+			fmt.Printf("    Extra args: %s\n", strings.Join(extraArgs, " "))
+		}
+		if *itemRange != "" {
+			fmt.Printf("    Range: %s\n", *itemRange)
+		}
+		if *profile {
+			max := *maxItems
+			if max == 0 {
+				max = downloader.DefaultProfileMax
+			}
+			fmt.Printf("    Profile: queue the latest %d items\n", max)
+		}
+		if *timeout != "" {
+			fmt.Printf("    Timeout: %s\n", *timeout)
+		}
+		if *live {
+			duration := *liveDuration
+			if duration == "" {
+				duration = downloader.DefaultLiveDuration.String()
+			}
+			fmt.Printf("    Live: record %s if the stream is live\n", duration)
+		}
 	}
 
+	if scheduledAfter != nil {
+		fmt.Printf("  Status: pending, scheduled for %s\n", scheduledAfter.Format("2006-01-02 15:04"))
+		return
+	}
 	fmt.Println("  Status: pending")
 }
 
+// printResolve muestra cómo se descargaría la URL (smd add --dry-run)
+func printResolve(c *client.Client, payload *client.AddDownloadPayload) {
+	result, err := c.Resolve(payload)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("Dry run (nothing was queued)")
+	fmt.Printf("  URL:        %s\n", result.URL)
+	fmt.Printf("  Platform:   %s\n", result.Platform)
+	fmt.Printf("  Username:   %s\n", result.Username)
+	fmt.Printf("  Downloader: %s\n", result.Backend)
+	if result.HasAccount {
+		fmt.Printf("  Cookies:    account %q\n", result.Account)
+	} else {
+		fmt.Println("  Cookies:    none (no active account for this platform)")
+	}
+
+	if len(result.Options) > 0 {
+		keys := make([]string, 0, len(result.Options))
+		for key := range result.Options {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		fmt.Println("  Options:")
+		for _, key := range keys {
+			fmt.Printf("    %s: %v\n", key, result.Options[key])
+		}
+	}
+
+	if result.DuplicateOf != 0 {
+		fmt.Printf("  ⚠ Already in queue with ID: %d (use --force to download it again)\n", result.DuplicateOf)
+	}
+}
+
+// stringSlice implementa flag.Value para flags repetibles
+type stringSlice []string
+
+func (s *stringSlice) String() string {
+	return strings.Join(*s, " ")
+}
+
+func (s *stringSlice) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// sponsorBlockFlag implementa flag.Value para --sponsorblock[=categorías]
+type sponsorBlockFlag struct {
+	categories []string
+}
+
+func (f *sponsorBlockFlag) String() string {
+	return strings.Join(f.categories, ",")
+}
+
+// IsBoolFlag permite usar --sponsorblock sin valor
+func (f *sponsorBlockFlag) IsBoolFlag() bool {
+	return true
+}
+
+func (f *sponsorBlockFlag) Set(value string) error {
+	switch value {
+	case "true":
+		f.categories = []string{"sponsor"}
+		return nil
+	case "false":
+		f.categories = nil
+		return nil
+	}
+
+	f.categories = nil
+	for _, cat := range strings.Split(value, ",") {
+		cat = strings.TrimSpace(cat)
+		if cat == "" {
+			continue
+		}
+
+		valid := false
+		for _, known := range downloader.SponsorBlockCategories {
+			if cat == known {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("unknown category %q (valid: %s)", cat, strings.Join(downloader.SponsorBlockCategories, ", "))
+		}
+
+		f.categories = append(f.categories, cat)
+	}
+
+	return nil
+}
+
 func handleStatus(c *client.Client, args []string) {
 	if len(args) == 0 {
 		fmt.Println("Error: Download ID is required")
@@ -206,114 +834,253 @@ func handleStatus(c *client.Client, args []string) {
 		os.Exit(1)
 	}
 
-	status, err := c.GetDownloadStatus(id)
+	if jsonOutput {
+		printRawResponse(c, "status", map[string]int64{"id": id})
+		return
+	}
+
+	info, err := c.GetDownload(id)
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Printf("Status: %s\n", status)
+	if info.Children != nil {
+		fmt.Printf("%s — %s\n", info.DisplayName(), info.Children)
+	} else {
+		fmt.Printf("%s\n", info.DisplayName())
+	}
+	if info.Uploader != "" {
+		fmt.Printf("Uploader: %s\n", info.Uploader)
+	}
+	if info.UploadDate != "" {
+		fmt.Printf("Uploaded: %s\n", info.UploadDate)
+	}
+
+	fmt.Printf("Status: %s\n", info.StatusLabel())
+
+	if info.Backend != "" {
+		fmt.Printf("Backend: %s\n", info.Backend)
+	}
+
+	if info.Account != "" {
+		if info.AccountStatus == "expired" || info.AccountStatus == "invalid" {
+			fmt.Printf("Account: %s (cookies %s)\n", info.Account, info.AccountStatus)
+		} else {
+			fmt.Printf("Account: %s\n", info.Account)
+		}
+	}
+
+	if info.ErrorMessage != "" {
+		fmt.Printf("Error: %s\n", info.ErrorMessage)
+	}
+
+	if info.Hint != "" {
+		fmt.Printf("Hint: %s\n", info.Hint)
+	}
+
+	if info.Priority != 0 {
+		fmt.Printf("Priority: %d\n", info.Priority)
+	}
+
+	if info.ScheduledAfter != nil && info.Status == "pending" {
+		fmt.Printf("Scheduled: %s\n", info.ScheduledAfter.Local().Format("2006-01-02 15:04"))
+	}
+
+	if len(info.Tags) > 0 {
+		fmt.Printf("Tags: %s\n", strings.Join(info.Tags, ", "))
+	}
+
+	if info.OutputPath != "" {
+		fmt.Printf("Output: %s\n", info.OutputPath)
+	}
+
+	// Galleries: list every downloaded file
+	if len(info.Files) > 1 {
+		fmt.Printf("Files (%d):\n", len(info.Files))
+		for _, file := range info.Files {
+			fmt.Printf("  %s\n", file)
+		}
+	}
+
+	// Channels/profiles: list the queued items
+	if len(info.Items) > 0 {
+		fmt.Printf("Items (%d):\n", len(info.Items))
+		for _, item := range info.Items {
+			fmt.Printf("  %-6d %-22s %s\n", item.ID, item.StatusLabel(), item.DisplayName())
+		}
+	}
 }
 
 func handleList(c *client.Client, args []string) {
 	// Parse flags
 	listFlags := flag.NewFlagSet("list", flag.ExitOnError)
 	details := listFlags.Bool("details", false, "Show error details for failed downloads")
-
-	// Find limit (first non-flag argument)
-	limit := 50
-	var flagArgs []string
-	for _, arg := range args {
-		if strings.HasPrefix(arg, "-") {
-			flagArgs = append(flagArgs, arg)
-		} else {
-			if _, err := fmt.Sscanf(arg, "%d", &limit); err != nil {
-				fmt.Printf("Error: Invalid limit: %s\n", arg)
-				os.Exit(1)
-			}
+	limit := listFlags.Int("limit", 50, "Maximum number of downloads to show")
+	offset := listFlags.Int("offset", 0, "Skip this many downloads (most recent first)")
+	platform := listFlags.String("platform", "", "Only show downloads from this platform")
+	status := listFlags.String("status", "", "Only show downloads with this status (pending, downloading, processing, completed, failed)")
+	tag := listFlags.String("tag", "", "Only show downloads with this tag")
+	flat := listFlags.Bool("flat", false, "Show the items of a channel/profile as separate entries")
+	since := listFlags.String("since", "", "Only show downloads added since this date or age (e.g. 2024-01-01, yesterday, 24h, 7d)")
+	until := listFlags.String("until", "", "Only show downloads added before the end of this date (e.g. 2024-01-07)")
+
+	// El límite también se acepta como argumento posicional (smd list 20), antes o después de los flags
+	rest := args
+	for {
+		listFlags.Parse(rest)
+		rest = listFlags.Args()
+		if len(rest) == 0 {
+			break
+		}
+		if _, err := fmt.Sscanf(rest[0], "%d", limit); err != nil {
+			fmt.Printf("Error: Invalid limit: %s\n", rest[0])
+			os.Exit(1)
 		}
+		rest = rest[1:]
+	}
+
+	payload := map[string]interface{}{"limit": *limit}
+	if *offset > 0 {
+		payload["offset"] = *offset
+	}
+	if *platform != "" {
+		payload["platform"] = *platform
 	}
-	if len(flagArgs) > 0 {
-		listFlags.Parse(flagArgs)
+	if *status != "" {
+		payload["status"] = *status
+	}
+	if *tag != "" {
+		payload["tag"] = *tag
+	}
+	if !*flat {
+		payload["group"] = true
 	}
 
-	downloads, err := c.ListRecentDownloads(limit)
+	now := time.Now()
+	sinceTime, err := parseDateBound("since", *since, false, now)
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
 	}
+	untilTime, err := parseDateBound("until", *until, true, now)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if sinceTime != nil && untilTime != nil && !sinceTime.Before(*untilTime) {
+		fmt.Println("Error: --since must be before --until")
+		os.Exit(1)
+	}
+	if sinceTime != nil {
+		payload["since"] = sinceTime
+	}
+	if untilTime != nil {
+		payload["until"] = untilTime
+	}
 
-	if len(downloads) == 0 {
-		fmt.Println("No downloads found")
+	// JSON: reenviar el payload sin interpretarlo
+	if jsonOutput {
+		printRawResponse(c, "list", payload)
 		return
 	}
 
+	data, err := c.Call("list", payload)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := printDownloadList(os.Stdout, data, *details); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// printDownloadList imprime la respuesta de "list"; las entradas malformadas se omiten
+// (con un aviso) en lugar de abortar todo el listado
+func printDownloadList(w io.Writer, data json.RawMessage, details bool) error {
+	downloads, invalid, err := client.DecodeDownloadList(data)
+	if err != nil {
+		return err
+	}
+
+	if len(downloads) == 0 && invalid == 0 {
+		fmt.Fprintln(w, "No downloads found")
+		return nil
+	}
+
 	// Server already returns in DESC order (most recent first)
-	fmt.Printf("Recent downloads (%d):\n\n", len(downloads))
+	var page struct {
+		Total  int `json:"total"`
+		Offset int `json:"offset"`
+	}
+	json.Unmarshal(data, &page) // Sin total (daemon anterior): encabezado simple
+
+	if page.Offset > 0 || page.Total > len(downloads)+invalid {
+		fmt.Fprintf(w, "Downloads %d-%d of %d:\n\n", page.Offset+1, page.Offset+len(downloads)+invalid, page.Total)
+	} else {
+		fmt.Fprintf(w, "Recent downloads (%d):\n\n", len(downloads))
+	}
 
 	for _, dl := range downloads {
-		id := int64(dl["id"].(float64))
-		url := dl["url"].(string)
-		status := dl["status"].(string)
-		platform := dl["platform"].(string)
+		platform := dl.Platform
+		if platform == "" {
+			platform = "unknown"
+		}
 
-		fmt.Printf("ID: %d\n", id)
-		fmt.Printf("  Platform: %s\n", platform)
-		fmt.Printf("  URL: %s\n", url)
-		fmt.Printf("  Status: %s\n", status)
+		fmt.Fprintf(w, "ID: %d\n", dl.ID)
+		if dl.Backend != "" {
+			fmt.Fprintf(w, "  Platform: %s (%s)\n", platform, dl.Backend)
+		} else {
+			fmt.Fprintf(w, "  Platform: %s\n", platform)
+		}
+		// Title when the daemon could extract it; the URL stays available with --details.
+		// Channels/profiles summarize their items (smd status <id> lists them)
+		if dl.Children != nil {
+			fmt.Fprintf(w, "  Title: %s — %s\n", dl.DisplayName(), dl.Children)
+		} else if dl.Title != "" {
+			fmt.Fprintf(w, "  Title: %s\n", dl.Title)
+		}
+		if dl.Title == "" || details {
+			fmt.Fprintf(w, "  URL: %s\n", dl.URL)
+		}
+		fmt.Fprintf(w, "  Status: %s\n", dl.StatusLabel())
 
-		if outputPath, ok := dl["output_path"].(string); ok && outputPath != "" {
-			fmt.Printf("  Output: %s\n", outputPath)
+		if dl.OutputPath != "" {
+			fmt.Fprintf(w, "  Output: %s\n", dl.OutputPath)
 		}
 
-		// Only show error if --details flag is set
-		if *details {
-			if errMsg, ok := dl["error_message"].(string); ok && errMsg != "" {
-				fmt.Printf("  Error: %s\n", errMsg)
-			}
+		// Galleries: show how many files were downloaded
+		if dl.FileCount > 1 {
+			fmt.Fprintf(w, "  Files: %d\n", dl.FileCount)
 		}
 
-		fmt.Println()
-	}
-}
+		if len(dl.Tags) > 0 {
+			fmt.Fprintf(w, "  Tags: %s\n", strings.Join(dl.Tags, ", "))
+		}
 
-func handleStats(c *client.Client) {
-	payload, err := c.Send(&client.Request{
-		Action: "stats",
-	})
-	if err != nil {
-		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
-	}
+		// Only show error if --details flag is set
+		if details && dl.ErrorMessage != "" {
+			fmt.Fprintf(w, "  Error: %s\n", dl.ErrorMessage)
+		}
 
-	if !payload.Success {
-		fmt.Printf("Error: %s\n", payload.Error)
-		os.Exit(1)
+		fmt.Fprintln(w)
 	}
 
-	fmt.Println("Queue Statistics:")
-	fmt.Println()
-
-	// Parse stats
-	var stats map[string]interface{}
-	if err := json.Unmarshal(payload.Data, &stats); err != nil {
-		fmt.Printf("Error parsing stats: %v\n", err)
-		os.Exit(1)
+	if invalid > 0 {
+		fmt.Fprintf(w, "⚠ Skipped %d malformed entries (use --json to inspect the raw response)\n", invalid)
 	}
 
-	fmt.Printf("  Pending:      %d\n", int(stats["pending"].(float64)))
-	fmt.Printf("  Downloading:  %d\n", int(stats["downloading"].(float64)))
-	fmt.Printf("  Processing:   %d\n", int(stats["processing"].(float64)))
-	fmt.Printf("  Completed:    %d\n", int(stats["completed"].(float64)))
-	fmt.Printf("  Failed:       %d\n", int(stats["failed"].(float64)))
-	fmt.Println()
-	fmt.Printf("  Workers:      %d / %d busy\n", int(stats["workers_busy"].(float64)), int(stats["workers_total"].(float64)))
+	return nil
 }
 
 func handleConvert(args []string) {
 	if len(args) == 0 {
 		fmt.Println("Error: At least one file or directory is required")
 		fmt.Println("Usage: smd convert <files...> [--recursive] [--output <dir>] [--clip-start <time> --clip-end <time>]")
+		fmt.Println("       smd convert <files...> --frame <time> [--output <image|dir>] | --frames <n> [--output <dir>]")
+		fmt.Println("       smd convert <files...> --concat --output <file.mp4>")
 		os.Exit(1)
 	}
 
@@ -322,8 +1089,24 @@ func handleConvert(args []string) {
 	recursive := convertFlags.Bool("recursive", false, "Process directories recursively")
 	outputDir := convertFlags.String("output", "", "Output directory (default: same as input)")
 	checkOnly := convertFlags.Bool("check-only", false, "Only check which files need conversion")
+	skipExisting := convertFlags.Bool("skip-existing", false, "Skip files whose output already exists and is newer than the source")
+	jobs := convertFlags.Int("jobs", 1, "Number of files converted in parallel")
 	clipStart := convertFlags.String("clip-start", "", "Clip start time (HH:MM:SS or seconds)")
 	clipEnd := convertFlags.String("clip-end", "", "Clip end time (HH:MM:SS or seconds)")
+	frame := convertFlags.String("frame", "", "Save the frame at this time as an image instead of converting")
+	frames := convertFlags.Int("frames", 0, "Save N evenly spaced frames instead of converting")
+	rotate := convertFlags.Int("rotate", 0, "Rotate clockwise by 90, 180 or 270 degrees")
+	crop := convertFlags.String("crop", "", "Crop to w:h:x:y, or auto to remove black borders")
+	speed := convertFlags.Float64("speed", 0, "Speed factor (2 = timelapse at double speed, 0.5 = slow motion)")
+	noAudio := convertFlags.Bool("no-audio", false, "Remove the audio track")
+	ensureAudio := convertFlags.Bool("ensure-audio", false, "Add a silent audio track if the video has none")
+	keepChannels := convertFlags.Bool("keep-channels", false, "Keep surround audio instead of downmixing it to stereo")
+	noTonemap := convertFlags.Bool("no-tonemap", false, "Don't convert HDR videos to SDR")
+	concat := convertFlags.Bool("concat", false, "Join all inputs into a single MP4 (requires --output <file>)")
+	stripMetadata := convertFlags.Bool("strip-metadata", false, "Remove all metadata tags (privacy)")
+	metadataTitle := convertFlags.String("title", "", "Set the title tag of the output")
+	metadataComment := convertFlags.String("comment", "", "Set the comment tag of the output")
+	targetName := convertFlags.String("target", "", "Convert for this platform: "+strings.Join(postprocessor.TargetNames, ", ")+" (default: whatsapp)")
 
 	// Separar manualmente input paths de flags
 	var inputPaths []string
@@ -344,15 +1127,73 @@ func handleConvert(args []string) {
 	// Validación de clip: al menos uno debe estar especificado si se quiere hacer clipping
 	// Both, one, or neither flag can be specified - ClipVideo handles all cases
 
+	// Ediciones de imagen aplicadas en la conversión
+	convertOpts := &domain.DownloadOptions{
+		Rotate:          *rotate,
+		Crop:            *crop,
+		Speed:           *speed,
+		RemoveAudio:     *noAudio,
+		EnsureAudio:     *ensureAudio,
+		KeepChannels:    *keepChannels,
+		NoTonemap:       *noTonemap,
+		StripMetadata:   *stripMetadata,
+		MetadataTitle:   *metadataTitle,
+		MetadataComment: *metadataComment,
+		Target:          *targetName,
+	}
+	target, err := postprocessor.TargetFor(convertOpts)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := postprocessor.ValidateRotate(*rotate); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := postprocessor.ValidateSpeed(*speed); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *crop != "" {
+		if err := postprocessor.ValidateCrop(*crop); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if err := convertOpts.Validate(); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := domain.ValidateClip(*clipStart, *clipEnd); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *jobs < 1 {
+		fmt.Println("Error: --jobs must be at least 1")
+		os.Exit(1)
+	}
+
+	hasEdits := *rotate != 0 || *crop != "" || (*speed != 0 && *speed != 1) ||
+		*stripMetadata || *metadataTitle != "" || *metadataComment != ""
+
 	// Recolectar todos los archivos de video
-	videoFiles := collectVideoFiles(inputPaths, *recursive)
+	videoFiles, subdirs := collectVideoFiles(inputPaths, *recursive)
+
+	// --check-only --json: reporte para scripts, sin texto ni conversiones
+	checkJSON := *checkOnly && jsonOutput
 
 	if len(videoFiles) == 0 {
+		if checkJSON {
+			printJSON([]checkReport{})
+			return
+		}
 		fmt.Println("No video files found")
 		return
 	}
 
-	fmt.Printf("Found %d video file(s)\n\n", len(videoFiles))
+	if !checkJSON {
+		fmt.Printf("Found %d video file(s)\n\n", len(videoFiles))
+	}
 
 	// Crear post-processor
 	homeDir, _ := os.UserHomeDir()
@@ -361,137 +1202,319 @@ func handleConvert(args []string) {
 	processor := postprocessor.NewFFmpegProcessor(tempDir)
 
 	ctx := context.Background()
+
+	if checkJSON {
+		printJSON(checkFiles(ctx, processor, videoFiles, target, convertOpts, *jobs))
+		return
+	}
+
+	// Fotogramas en lugar de conversión
+	if *frame != "" || *frames > 0 {
+		if *frame != "" && *frames > 0 {
+			fmt.Println("Error: use either --frame or --frames")
+			os.Exit(1)
+		}
+		if failed := handleFrames(ctx, processor, videoFiles, *frame, *frames, *outputDir); failed > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Unir todos los videos en uno (en el orden dado)
+	if *concat {
+		if len(videoFiles) < 2 {
+			fmt.Println("Error: --concat needs at least 2 videos")
+			os.Exit(1)
+		}
+		if *outputDir == "" || strings.ToLower(filepath.Ext(*outputDir)) != ".mp4" {
+			fmt.Println("Error: --concat requires --output <file.mp4>")
+			os.Exit(1)
+		}
+
+		os.MkdirAll(filepath.Dir(*outputDir), 0755)
+		fmt.Printf("  → Joining %d videos...\n", len(videoFiles))
+		joinedPath, err := processor.Concat(ctx, videoFiles, *outputDir)
+		if err != nil {
+			fmt.Printf("  ✗ Concat failed: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("  ✓ Joined: %s\n", joinedPath)
+		return
+	}
+
 	var stats struct {
 		total      int
 		converted  int
 		compatible int
+		skipped    int
 		failed     int
 	}
 	stats.total = len(videoFiles)
 
-	// Procesar cada archivo
+	job := &convertJob{
+		processor:    processor,
+		options:      convertOpts,
+		target:       target,
+		outputDir:    *outputDir,
+		subdirs:      subdirs,
+		clipStart:    *clipStart,
+		clipEnd:      *clipEnd,
+		checkOnly:    *checkOnly,
+		skipExisting: *skipExisting,
+		hasEdits:     hasEdits,
+		showProgress: *jobs == 1 && logging.IsTerminal(os.Stdout),
+	}
+
+	// Procesar los archivos con un pool de *jobs workers (mismo esquema que el QueueManager).
+	// Con más de un worker la salida de cada archivo se acumula y se imprime entera al terminar
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	workerPool := make(chan struct{}, *jobs)
+
 	for i, inputPath := range videoFiles {
-		fmt.Printf("[%d/%d] Processing: %s\n", i+1, len(videoFiles), filepath.Base(inputPath))
-
-		currentFile := inputPath
-
-		// Hacer clip si se especificó
-		if *clipStart != "" || *clipEnd != "" {
-			// Generate description of clipping operation
-			var clipMsg string
-			if *clipStart != "" && *clipEnd != "" {
-				clipMsg = fmt.Sprintf("%s - %s", *clipStart, *clipEnd)
-			} else if *clipStart != "" {
-				clipMsg = fmt.Sprintf("%s - end", *clipStart)
-			} else {
-				clipMsg = fmt.Sprintf("0 - %s", *clipEnd)
+		workerPool <- struct{}{} // Obtener slot de worker
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			defer func() { <-workerPool }() // Liberar slot
+
+			var buf bytes.Buffer
+			var w io.Writer = os.Stdout
+			if *jobs > 1 {
+				w = &buf
 			}
-			fmt.Printf("  → Clipping segment (%s)...\n", clipMsg)
 
-			clippedPath, err := processor.ClipVideo(ctx, currentFile, *clipStart, *clipEnd)
-			if err != nil {
-				fmt.Printf("  ✗ Clipping failed: %v\n", err)
+			fmt.Fprintf(w, "[%d/%d] Processing: %s\n", i+1, len(videoFiles), filepath.Base(inputPath))
+			result := job.convertFile(ctx, w, inputPath)
+
+			mu.Lock()
+			defer mu.Unlock()
+			os.Stdout.Write(buf.Bytes())
+
+			switch result {
+			case convertConverted:
+				stats.converted++
+			case convertCompatible:
+				stats.compatible++
+			case convertSkipped:
+				stats.skipped++
+			case convertFailed:
 				stats.failed++
-				continue
 			}
-			currentFile = clippedPath
-			defer os.Remove(clippedPath) // Limpiar archivo temporal
-		}
+		}()
+	}
+	wg.Wait()
 
-		// Verificar compatibilidad
-		compatible, reason, err := processor.IsWhatsAppCompatible(ctx, currentFile)
-		if err != nil {
-			fmt.Printf("  ✗ Error checking: %v\n", err)
-			stats.failed++
-			continue
-		}
+	// Resumen
+	fmt.Println("\n" + strings.Repeat("=", 50))
+	fmt.Println("Conversion Summary:")
+	fmt.Printf("  Total files:      %d\n", stats.total)
+	fmt.Printf("  Converted:        %d\n", stats.converted)
+	if stats.compatible > 0 {
+		fmt.Printf("  Already compatible: %d\n", stats.compatible)
+	}
+	if stats.skipped > 0 {
+		fmt.Printf("  Up to date:       %d\n", stats.skipped)
+	}
+	if stats.failed > 0 {
+		fmt.Printf("  Failed:           %d\n", stats.failed)
+	}
+	fmt.Println(strings.Repeat("=", 50))
+}
 
-		if compatible && *clipStart == "" && *clipEnd == "" {
-			fmt.Printf("  ✓ Already compatible (H.264 + AAC)\n")
-			stats.compatible++
-			continue
-		}
+// convertResult es el resultado de convertir un archivo, para el resumen
+type convertResult int
 
-		if *checkOnly {
-			if compatible {
-				fmt.Printf("  ✓ Already compatible (H.264 + AAC)\n")
-			} else {
-				fmt.Printf("  ⚠ Needs conversion: %s\n", reason)
-			}
-			continue
-		}
+const (
+	convertConverted convertResult = iota
+	convertCompatible
+	convertSkipped
+	convertFailed
+	convertChecked // --check-only: solo se informó
+)
 
-		// Determinar output path
-		var outPath string
-		baseName := filepath.Base(inputPath)
-		ext := filepath.Ext(baseName)
-		baseName = strings.TrimSuffix(baseName, ext)
-
-		// Agregar sufijo de clip si aplica (match ClipVideo naming)
-		if *clipStart != "" || *clipEnd != "" {
-			if *clipStart != "" && *clipEnd != "" {
-				// Both specified
-				start := strings.ReplaceAll(*clipStart, ":", "-")
-				end := strings.ReplaceAll(*clipEnd, ":", "-")
-				baseName = fmt.Sprintf("%s_clip_%s_%s", baseName, start, end)
-			} else if *clipStart != "" {
-				// Only start specified
-				start := strings.ReplaceAll(*clipStart, ":", "-")
-				baseName = fmt.Sprintf("%s_clip_%s_end", baseName, start)
-			} else {
-				// Only end specified
-				end := strings.ReplaceAll(*clipEnd, ":", "-")
-				baseName = fmt.Sprintf("%s_clip_0_%s", baseName, end)
-			}
-		}
+// convertJob son las opciones de conversión comunes a todos los archivos de smd convert
+type convertJob struct {
+	processor    *postprocessor.FFmpegProcessor
+	options      *domain.DownloadOptions
+	target       postprocessor.Target
+	outputDir    string
+	subdirs      map[string]string // Subdirectorio de cada archivo respecto de su directorio de entrada (--recursive)
+	clipStart    string
+	clipEnd      string
+	checkOnly    bool
+	skipExisting bool
+	hasEdits     bool
+	showProgress bool // Barra de progreso durante la conversión (solo con un worker y en una terminal)
+}
 
-		if *outputDir != "" {
-			os.MkdirAll(*outputDir, 0755)
-			outPath = filepath.Join(*outputDir, baseName+"_whatsapp.mp4")
-		} else {
-			outPath = filepath.Join(filepath.Dir(inputPath), baseName+"_whatsapp.mp4")
-		}
+// convertFile procesa un archivo escribiendo el progreso en w
+func (j *convertJob) convertFile(ctx context.Context, w io.Writer, inputPath string) convertResult {
+	// Determinar output path
+	outputDir := j.outputDirFor(inputPath)
+	outPath := convertOutputPath(inputPath, outputDir, j.clipStart, j.clipEnd, j.target)
+
+	// Saltar si la salida ya existe y es más nueva que el original
+	if j.skipExisting && isUpToDate(outPath, inputPath) {
+		fmt.Fprintf(w, "  ⏭ Skipped (up to date): %s\n", filepath.Base(outPath))
+		return convertSkipped
+	}
+
+	currentFile := inputPath
 
-		// Convertir
-		if !compatible {
-			fmt.Printf("  → Converting to WhatsApp MP4...\n")
-			fmt.Printf("    Reason: %s\n", reason)
+	// Hacer clip si se especificó
+	if j.clipStart != "" || j.clipEnd != "" {
+		// Generate description of clipping operation
+		var clipMsg string
+		if j.clipStart != "" && j.clipEnd != "" {
+			clipMsg = fmt.Sprintf("%s - %s", j.clipStart, j.clipEnd)
+		} else if j.clipStart != "" {
+			clipMsg = fmt.Sprintf("%s - end", j.clipStart)
 		} else {
-			fmt.Printf("  → Saving clipped video as WhatsApp MP4...\n")
+			clipMsg = fmt.Sprintf("0 - %s", j.clipEnd)
 		}
+		fmt.Fprintf(w, "  → Clipping segment (%s)...\n", clipMsg)
 
-		convertedPath, err := processor.ConvertToWhatsAppMP4(ctx, currentFile)
+		clippedPath, err := j.processor.ClipVideo(ctx, currentFile, j.clipStart, j.clipEnd)
 		if err != nil {
-			fmt.Printf("  ✗ Conversion failed: %v\n", err)
-			stats.failed++
-			continue
+			fmt.Fprintf(w, "  ✗ Clipping failed: %v\n", err)
+			return convertFailed
 		}
+		currentFile = clippedPath
+		defer os.Remove(clippedPath) // Limpiar archivo temporal
+	}
 
-		// Mover a la ubicación final
-		if convertedPath != outPath {
-			os.Rename(convertedPath, outPath)
-			convertedPath = outPath
+	// Verificar compatibilidad
+	compatible, reason, err := j.processor.IsCompatible(ctx, currentFile, j.target, j.options)
+	if err != nil {
+		fmt.Fprintf(w, "  ✗ Error checking: %v\n", err)
+		return convertFailed
+	}
+
+	if compatible && j.clipStart == "" && j.clipEnd == "" && !j.hasEdits {
+		fmt.Fprintf(w, "  ✓ Already compatible with %s\n", j.target.Label)
+		return convertCompatible
+	}
+
+	if j.checkOnly {
+		if compatible {
+			fmt.Fprintf(w, "  ✓ Already compatible with %s\n", j.target.Label)
+		} else {
+			fmt.Fprintf(w, "  ⚠ Needs conversion: %s\n", reason)
 		}
+		return convertChecked
+	}
 
-		fmt.Printf("  ✓ Converted: %s\n", filepath.Base(convertedPath))
-		stats.converted++
+	if outputDir != "" {
+		os.MkdirAll(outputDir, 0755)
 	}
 
-	// Resumen
-	fmt.Println("\n" + strings.Repeat("=", 50))
-	fmt.Println("Conversion Summary:")
-	fmt.Printf("  Total files:      %d\n", stats.total)
-	fmt.Printf("  Converted:        %d\n", stats.converted)
-	if stats.compatible > 0 {
-		fmt.Printf("  Already compatible: %d\n", stats.compatible)
+	// Convertir
+	if !compatible {
+		fmt.Fprintf(w, "  → Converting to %s MP4...\n", j.target.Label)
+		fmt.Fprintf(w, "    Reason: %s\n", reason)
+	} else if j.hasEdits {
+		fmt.Fprintf(w, "  → Applying edits (crop/rotation/speed/metadata)...\n")
+	} else {
+		fmt.Fprintf(w, "  → Saving clipped video as %s MP4...\n", j.target.Label)
 	}
-	if stats.failed > 0 {
-		fmt.Printf("  Failed:           %d\n", stats.failed)
+
+	convertCtx := ctx
+	drawn := false
+	if j.showProgress {
+		convertCtx = postprocessor.WithProgress(ctx, func(p postprocessor.Progress) {
+			fmt.Fprintf(w, "\r    %s", progressBar(p.Percent, progressBarWidth))
+			drawn = true
+		})
 	}
-	fmt.Println(strings.Repeat("=", 50))
+
+	convertedPath, err := j.processor.Convert(convertCtx, currentFile, j.target, j.options)
+	if drawn {
+		fmt.Fprintln(w)
+	}
+	if err != nil {
+		fmt.Fprintf(w, "  ✗ Conversion failed: %v\n", err)
+		return convertFailed
+	}
+
+	// Mover a la ubicación final
+	if convertedPath != outPath {
+		os.Rename(convertedPath, outPath)
+		convertedPath = outPath
+	}
+
+	fmt.Fprintf(w, "  ✓ Converted: %s\n", filepath.Base(convertedPath))
+	return convertConverted
 }
 
-func collectVideoFiles(paths []string, recursive bool) []string {
+// outputDirFor retorna el directorio de salida de inputPath: con --output y --recursive se
+// replica la estructura de subdirectorios de la entrada, para que archivos con el mismo
+// nombre en carpetas distintas no se pisen. "" = junto al original
+func (j *convertJob) outputDirFor(inputPath string) string {
+	if j.outputDir == "" {
+		return ""
+	}
+	return filepath.Join(j.outputDir, j.subdirs[inputPath])
+}
+
+// progressBarWidth es el ancho en caracteres de la barra de smd convert
+const progressBarWidth = 30
+
+// progressBar dibuja el avance como "[#####-----]  42%"
+func progressBar(percent float64, width int) string {
+	percent = max(0, min(percent, 100))
+	filled := int(percent / 100 * float64(width))
+	return fmt.Sprintf("[%s%s] %3d%%", strings.Repeat("#", filled), strings.Repeat("-", width-filled), int(percent))
+}
+
+// convertOutputPath retorna el path del MP4 convertido: <nombre>[_clip_...]_<target>.mp4 en
+// outputDir o, si está vacío, junto al original. El sufijo de clip sigue el de ClipVideo
+func convertOutputPath(inputPath, outputDir, clipStart, clipEnd string, target postprocessor.Target) string {
+	baseName := filepath.Base(inputPath)
+	ext := filepath.Ext(baseName)
+	baseName = strings.TrimSuffix(baseName, ext)
+
+	// Agregar sufijo de clip si aplica (match ClipVideo naming)
+	if clipStart != "" || clipEnd != "" {
+		if clipStart != "" && clipEnd != "" {
+			// Both specified
+			start := strings.ReplaceAll(clipStart, ":", "-")
+			end := strings.ReplaceAll(clipEnd, ":", "-")
+			baseName = fmt.Sprintf("%s_clip_%s_%s", baseName, start, end)
+		} else if clipStart != "" {
+			// Only start specified
+			start := strings.ReplaceAll(clipStart, ":", "-")
+			baseName = fmt.Sprintf("%s_clip_%s_end", baseName, start)
+		} else {
+			// Only end specified
+			end := strings.ReplaceAll(clipEnd, ":", "-")
+			baseName = fmt.Sprintf("%s_clip_0_%s", baseName, end)
+		}
+	}
+
+	if outputDir == "" {
+		outputDir = filepath.Dir(inputPath)
+	}
+	return filepath.Join(outputDir, baseName+target.Suffix())
+}
+
+// isUpToDate indica si outputPath existe y es más nuevo que sourcePath
+func isUpToDate(outputPath, sourcePath string) bool {
+	out, err := os.Stat(outputPath)
+	if err != nil {
+		return false
+	}
+	src, err := os.Stat(sourcePath)
+	if err != nil {
+		return false
+	}
+	return out.ModTime().After(src.ModTime())
+}
+
+// collectVideoFiles retorna los videos de paths (archivos o directorios) sin repetidos y, para
+// los encontrados recorriendo un directorio con recursive, su subdirectorio relativo a ese directorio
+func collectVideoFiles(paths []string, recursive bool) ([]string, map[string]string) {
 	videoExts := map[string]bool{
 		".mp4": true, ".mkv": true, ".avi": true, ".mov": true,
 		".webm": true, ".flv": true, ".wmv": true, ".m4v": true,
@@ -500,6 +1523,7 @@ func collectVideoFiles(paths []string, recursive bool) []string {
 
 	var files []string
 	seen := make(map[string]bool)
+	subdirs := make(map[string]string)
 
 	for _, path := range paths {
 		info, err := os.Stat(path)
@@ -520,6 +1544,9 @@ func collectVideoFiles(paths []string, recursive bool) []string {
 						if !seen[absPath] {
 							files = append(files, p)
 							seen[absPath] = true
+							if rel, err := filepath.Rel(path, filepath.Dir(p)); err == nil && rel != "." {
+								subdirs[p] = rel
+							}
 						}
 					}
 					return nil
@@ -551,5 +1578,5 @@ func collectVideoFiles(paths []string, recursive bool) []string {
 		}
 	}
 
-	return files
+	return files, subdirs
 }
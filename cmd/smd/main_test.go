@@ -0,0 +1,606 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elsanchez/smart-download/internal/domain"
+	"github.com/elsanchez/smart-download/internal/postprocessor"
+	"github.com/elsanchez/smart-download/pkg/client"
+)
+
+func TestPrintDownloadList_MalformedEntries(t *testing.T) {
+	// La segunda entrada no tiene platform, la tercera tiene un id inválido
+	data := json.RawMessage(`{
+		"downloads": [
+			{"id": 3, "url": "https://www.youtube.com/watch?v=abc", "platform": "youtube", "status": "completed", "output_path": "/tmp/a.mp4"},
+			{"id": 2, "url": "https://example.com/video", "status": "failed", "error_message": null},
+			{"id": "oops", "url": null},
+			{"id": 1, "url": "https://www.instagram.com/p/XYZ/", "platform": "instagram", "status": "pending", "file_count": 3}
+		],
+		"count": 4
+	}`)
+
+	var buf bytes.Buffer
+	if err := printDownloadList(&buf, data, true); err != nil {
+		t.Fatalf("printDownloadList() error: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"Recent downloads (3)",
+		"ID: 3",
+		"https://www.youtube.com/watch?v=abc",
+		"ID: 2",
+		"Platform: unknown",
+		"ID: 1",
+		"Files: 3",
+		"Skipped 1 malformed entries",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestPrintDownloadList_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := printDownloadList(&buf, json.RawMessage(`{"downloads": [], "count": 0}`), false); err != nil {
+		t.Fatalf("printDownloadList() error: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "No downloads found") {
+		t.Errorf("expected empty message, got %q", buf.String())
+	}
+}
+
+func TestPrintDownloadList_Page(t *testing.T) {
+	data := json.RawMessage(`{
+		"downloads": [
+			{"id": 25, "url": "https://example.com/a", "platform": "youtube", "status": "failed"},
+			{"id": 24, "url": "https://example.com/b", "platform": "youtube", "status": "failed"}
+		],
+		"count": 2,
+		"total": 120,
+		"offset": 50
+	}`)
+
+	var buf bytes.Buffer
+	if err := printDownloadList(&buf, data, false); err != nil {
+		t.Fatalf("printDownloadList() error: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "Downloads 51-52 of 120") {
+		t.Errorf("expected page header, got:\n%s", buf.String())
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Duration
+		wantErr  bool
+	}{
+		{"30d", 30 * 24 * time.Hour, false},
+		{"12h", 12 * time.Hour, false},
+		{"1h30m", 90 * time.Minute, false},
+		{"0d", 0, true},
+		{"-5d", 0, true},
+		{"abc", 0, true},
+		{"xd", 0, true},
+	}
+
+	for _, tt := range tests {
+		age, err := parseAge(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseAge(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if age != tt.expected {
+			t.Errorf("parseAge(%q) = %s, want %s", tt.input, age, tt.expected)
+		}
+	}
+}
+
+func TestParseTagArgs(t *testing.T) {
+	add, remove, err := parseTagArgs([]string{"+work", "-urgent", "misc"})
+	if err != nil {
+		t.Fatalf("parseTagArgs() error: %v", err)
+	}
+
+	if len(add) != 2 || add[0] != "work" || add[1] != "misc" {
+		t.Errorf("add = %v, want [work misc]", add)
+	}
+	if len(remove) != 1 || remove[0] != "urgent" {
+		t.Errorf("remove = %v, want [urgent]", remove)
+	}
+
+	if _, _, err := parseTagArgs([]string{"+"}); err == nil {
+		t.Error("expected error for empty tag")
+	}
+}
+
+func TestWriteExport(t *testing.T) {
+	completed := time.Date(2025, 3, 1, 12, 5, 0, 0, time.UTC)
+	records := []exportRecord{
+		{
+			ID: 2, URL: "https://example.com/a,b", Platform: "youtube", Status: "completed",
+			Tags: []string{"work"}, Options: json.RawMessage(`{"resolution":"720p"}`),
+			OutputPath: "/tmp/a.mp4", Size: 2048,
+			CreatedAt: time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC), CompletedAt: &completed,
+		},
+		{ID: 1, URL: "https://example.com/c", Platform: "twitter", Status: "failed", CreatedAt: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	var csvBuf bytes.Buffer
+	if err := writeExportCSV(&csvBuf, records); err != nil {
+		t.Fatalf("writeExportCSV() error: %v", err)
+	}
+
+	want := "id,url,platform,status,created_at,completed_at,output_path,size\n" +
+		"2,\"https://example.com/a,b\",youtube,completed,2025-03-01T12:00:00Z,2025-03-01T12:05:00Z,/tmp/a.mp4,2048\n" +
+		"1,https://example.com/c,twitter,failed,2025-02-01T00:00:00Z,,,0\n"
+	if csvBuf.String() != want {
+		t.Errorf("CSV output:\n%s\nwant:\n%s", csvBuf.String(), want)
+	}
+
+	// El JSON exportado se vuelve a leer sin pérdidas
+	var jsonBuf bytes.Buffer
+	if err := writeExportJSON(&jsonBuf, records); err != nil {
+		t.Fatalf("writeExportJSON() error: %v", err)
+	}
+
+	var decoded []exportRecord
+	if err := json.Unmarshal(jsonBuf.Bytes(), &decoded); err != nil {
+		t.Fatalf("exported JSON is invalid: %v", err)
+	}
+	if len(decoded) != 2 || decoded[0].URL != records[0].URL || decoded[0].Tags[0] != "work" ||
+		!decoded[0].CompletedAt.Equal(completed) {
+		t.Fatalf("JSON round trip mismatch: %+v", decoded)
+	}
+
+	var options struct {
+		Resolution string `json:"resolution"`
+	}
+	if err := json.Unmarshal(decoded[0].Options, &options); err != nil || options.Resolution != "720p" {
+		t.Errorf("options = %s, want resolution 720p", decoded[0].Options)
+	}
+}
+
+func TestParseSchedule(t *testing.T) {
+	now := time.Date(2025, 6, 1, 22, 30, 0, 0, time.Local)
+
+	tests := []struct {
+		after, at string
+		expected  time.Time
+		wantErr   bool
+	}{
+		{"02:00", "", time.Date(2025, 6, 2, 2, 0, 0, 0, time.Local), false},   // Ya pasó hoy: mañana
+		{"23:15", "", time.Date(2025, 6, 1, 23, 15, 0, 0, time.Local), false}, // Todavía hoy
+		{"", "2025-06-03T04:00", time.Date(2025, 6, 3, 4, 0, 0, 0, time.Local), false},
+		{"", "2025-06-03 04:00", time.Date(2025, 6, 3, 4, 0, 0, 0, time.Local), false},
+		{"", "2025-06-03T04:00:00Z", time.Date(2025, 6, 3, 4, 0, 0, 0, time.UTC), false},
+		{"25:00", "", time.Time{}, true},
+		{"", "tomorrow", time.Time{}, true},
+		{"", "2025-05-01T04:00", time.Time{}, true}, // En el pasado
+		{"02:00", "2025-06-03T04:00", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseSchedule(tt.after, tt.at, now)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseSchedule(%q, %q) error = %v, wantErr %v", tt.after, tt.at, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if got == nil || !got.Equal(tt.expected) {
+			t.Errorf("parseSchedule(%q, %q) = %v, want %s", tt.after, tt.at, got, tt.expected)
+		}
+	}
+
+	if got, err := parseSchedule("", "", now); got != nil || err != nil {
+		t.Errorf("parseSchedule without flags = %v, %v; want nil, nil", got, err)
+	}
+}
+
+func TestParseDateBound(t *testing.T) {
+	now := time.Date(2025, 6, 10, 15, 30, 0, 0, time.Local)
+
+	tests := []struct {
+		value    string
+		until    bool
+		expected time.Time
+		wantErr  bool
+	}{
+		{"24h", false, time.Date(2025, 6, 9, 15, 30, 0, 0, time.Local), false},
+		{"7d", false, time.Date(2025, 6, 3, 15, 30, 0, 0, time.Local), false},
+		{"2024-01-01", false, time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local), false},
+		{"2024-01-07", true, time.Date(2024, 1, 8, 0, 0, 0, 0, time.Local), false}, // Incluye todo el día
+		{"yesterday", false, time.Date(2025, 6, 9, 0, 0, 0, 0, time.Local), false},
+		{"yesterday", true, time.Date(2025, 6, 10, 0, 0, 0, 0, time.Local), false},
+		{"today", false, time.Date(2025, 6, 10, 0, 0, 0, 0, time.Local), false},
+		{"2024-01-07T15:04", true, time.Date(2024, 1, 7, 15, 4, 0, 0, time.Local), false},
+		{"2024-01-07T15:04:00Z", false, time.Date(2024, 1, 7, 15, 4, 0, 0, time.UTC), false},
+		{"last week", false, time.Time{}, true},
+		{"2024-13-01", false, time.Time{}, true},
+		{"-7d", false, time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseDateBound("since", tt.value, tt.until, now)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseDateBound(%q, until=%v) error = %v, wantErr %v", tt.value, tt.until, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if got == nil || !got.Equal(tt.expected) {
+			t.Errorf("parseDateBound(%q, until=%v) = %v, want %s", tt.value, tt.until, got, tt.expected)
+		}
+	}
+
+	if got, err := parseDateBound("since", "", false, now); got != nil || err != nil {
+		t.Errorf("parseDateBound(\"\") = %v, %v; want nil, nil", got, err)
+	}
+}
+
+func TestConvertOutputPath(t *testing.T) {
+	tests := []struct {
+		input, outputDir, clipStart, clipEnd string
+		want                                 string
+	}{
+		{"/videos/a.mkv", "", "", "", "/videos/a_whatsapp.mp4"},
+		{"/videos/a.mkv", "/out", "", "", "/out/a_whatsapp.mp4"},
+		{"/videos/a.mp4", "", "00:01:00", "00:02:00", "/videos/a_clip_00-01-00_00-02-00_whatsapp.mp4"},
+		{"/videos/a.mp4", "", "10s", "", "/videos/a_clip_10s_end_whatsapp.mp4"},
+		{"/videos/a.mp4", "", "", "30s", "/videos/a_clip_0_30s_whatsapp.mp4"},
+	}
+
+	whatsapp, _ := postprocessor.TargetFor(nil)
+	for _, tt := range tests {
+		if got := convertOutputPath(tt.input, tt.outputDir, tt.clipStart, tt.clipEnd, whatsapp); got != tt.want {
+			t.Errorf("convertOutputPath(%q, %q, %q, %q) = %q, want %q", tt.input, tt.outputDir, tt.clipStart, tt.clipEnd, got, tt.want)
+		}
+	}
+
+	telegram, _ := postprocessor.TargetFor(&domain.DownloadOptions{Target: postprocessor.TargetTelegram})
+	if got := convertOutputPath("/videos/a.mkv", "", "", "", telegram); got != "/videos/a_telegram.mp4" {
+		t.Errorf("convertOutputPath() with telegram = %q, want /videos/a_telegram.mp4", got)
+	}
+}
+
+func TestIsUpToDate(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "a.mkv")
+	output := filepath.Join(dir, "a_whatsapp.mp4")
+	os.WriteFile(source, []byte("x"), 0644)
+
+	if isUpToDate(output, source) {
+		t.Error("isUpToDate() = true without output")
+	}
+
+	os.WriteFile(output, []byte("x"), 0644)
+	now := time.Now()
+	os.Chtimes(source, now.Add(-time.Hour), now.Add(-time.Hour))
+	if !isUpToDate(output, source) {
+		t.Error("isUpToDate() = false with a newer output")
+	}
+
+	// Original modificado después de convertir: hay que volver a convertir
+	os.Chtimes(source, now.Add(time.Hour), now.Add(time.Hour))
+	if isUpToDate(output, source) {
+		t.Error("isUpToDate() = true with a newer source")
+	}
+}
+
+func TestConvertJob_SkipExisting(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "a.mkv")
+	os.WriteFile(source, []byte("x"), 0644)
+	past := time.Now().Add(-time.Hour)
+	os.Chtimes(source, past, past)
+	os.WriteFile(filepath.Join(dir, "a_whatsapp.mp4"), []byte("x"), 0644)
+
+	// Sin processor: un archivo al día no debe llegar a ffmpeg
+	whatsapp, _ := postprocessor.TargetFor(nil)
+	job := &convertJob{skipExisting: true, target: whatsapp}
+	var out bytes.Buffer
+	if result := job.convertFile(context.Background(), &out, source); result != convertSkipped {
+		t.Errorf("convertFile() = %v, want convertSkipped", result)
+	}
+	if !strings.Contains(out.String(), "Skipped (up to date)") {
+		t.Errorf("output = %q, want a skipped message", out.String())
+	}
+}
+
+func TestConvertJob_OutputDirMirrorsTree(t *testing.T) {
+	in := t.TempDir()
+	for _, rel := range []string{"a/clip.mp4", "b/clip.mp4", "b/c/clip.mp4", "top.mkv", "a/notes.txt"} {
+		path := filepath.Join(in, rel)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte("x"), 0644)
+	}
+
+	files, subdirs := collectVideoFiles([]string{in}, true)
+	if len(files) != 4 {
+		t.Fatalf("collectVideoFiles() = %v, want 4 videos", files)
+	}
+
+	whatsapp, _ := postprocessor.TargetFor(nil)
+	job := &convertJob{outputDir: "/out", subdirs: subdirs, target: whatsapp}
+
+	want := map[string]string{
+		filepath.Join(in, "a", "clip.mp4"):      "/out/a/clip_whatsapp.mp4",
+		filepath.Join(in, "b", "clip.mp4"):      "/out/b/clip_whatsapp.mp4",
+		filepath.Join(in, "b", "c", "clip.mp4"): "/out/b/c/clip_whatsapp.mp4",
+		filepath.Join(in, "top.mkv"):            "/out/top_whatsapp.mp4",
+	}
+	for _, file := range files {
+		got := convertOutputPath(file, job.outputDirFor(file), "", "", whatsapp)
+		if got != want[file] {
+			t.Errorf("output of %s = %q, want %q", file, got, want[file])
+		}
+	}
+
+	// Sin --output la salida queda junto al original
+	job.outputDir = ""
+	if got := job.outputDirFor(filepath.Join(in, "a", "clip.mp4")); got != "" {
+		t.Errorf("outputDirFor() without --output = %q, want \"\"", got)
+	}
+}
+
+func TestProgressBar(t *testing.T) {
+	tests := []struct {
+		percent float64
+		want    string
+	}{
+		{0, "[----------]   0%"},
+		{42.7, "[####------]  42%"},
+		{100, "[##########] 100%"},
+		{130, "[##########] 100%"},
+		{-5, "[----------]   0%"},
+	}
+
+	for _, tt := range tests {
+		if got := progressBar(tt.percent, 10); got != tt.want {
+			t.Errorf("progressBar(%v) = %q, want %q", tt.percent, got, tt.want)
+		}
+	}
+}
+
+func TestPrintDownloadList_Children(t *testing.T) {
+	data := json.RawMessage(`{
+		"downloads": [
+			{"id": 7, "url": "https://www.youtube.com/@channel", "title": "Channel - Videos", "platform": "youtube", "status": "completed",
+			 "children": {"total": 12, "counts": {"completed": 10, "failed": 2}}}
+		],
+		"count": 1
+	}`)
+
+	var buf bytes.Buffer
+	if err := printDownloadList(&buf, data, false); err != nil {
+		t.Fatalf("printDownloadList() error: %v", err)
+	}
+
+	if want := "Title: Channel - Videos — 12 items, 10 completed, 2 failed"; !strings.Contains(buf.String(), want) {
+		t.Errorf("output missing %q:\n%s", want, buf.String())
+	}
+}
+
+func TestConfigureAutostart(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	socketPath := filepath.Join(t.TempDir(), "smart-download.sock")
+	fakeDaemon := filepath.Join(t.TempDir(), "fake-daemon")
+
+	t.Cleanup(func() { autostart = false })
+
+	// Sin --autostart ni config: el error sugiere iniciar el daemon
+	autostart = false
+	c := client.NewClient(socketPath)
+	configureAutostart(c)
+	if _, err := c.Call("stats", nil); err == nil || !strings.Contains(err.Error(), "daemon is not running") {
+		t.Errorf("without autostart: error = %v", err)
+	}
+
+	// client.autostart y client.daemon_path del config: intenta lanzar ese ejecutable
+	configDir := filepath.Join(configHome, "smart-download")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	config := "client:\n  autostart: true\n  daemon_path: " + fakeDaemon + "\n"
+	if err := os.WriteFile(filepath.Join(configDir, "config.yaml"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c = client.NewClient(socketPath)
+	configureAutostart(c)
+	_, err := c.Call("stats", nil)
+	if err == nil || !strings.Contains(err.Error(), "autostart failed") || !strings.Contains(err.Error(), fakeDaemon) {
+		t.Errorf("with client.autostart: error = %v, want an autostart failure for %s", err, fakeDaemon)
+	}
+}
+
+func TestParseGlobalFlags(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		want      []string
+		json      bool
+		autostart bool
+		timeout   string
+	}{
+		{"no flags", []string{"smd", "list"}, []string{"smd", "list"}, false, false, ""},
+		{"json anywhere", []string{"smd", "status", "3", "--json"}, []string{"smd", "status", "3"}, true, false, ""},
+		{"autostart before command", []string{"smd", "--autostart", "stats"}, []string{"smd", "stats"}, false, true, ""},
+		{"timeout before command", []string{"smd", "--timeout", "30s", "list"}, []string{"smd", "list"}, false, false, "30s"},
+		{"timeout with equals", []string{"smd", "--timeout=1m", "--json", "stats"}, []string{"smd", "stats"}, true, false, "1m"},
+		{"timeout after command belongs to it", []string{"smd", "add", "https://x.com/a", "--timeout", "3h"}, []string{"smd", "add", "https://x.com/a", "--timeout", "3h"}, false, false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jsonOutput, autostart, timeoutFlag = false, false, ""
+			t.Cleanup(func() { jsonOutput, autostart, timeoutFlag = false, false, "" })
+
+			got := parseGlobalFlags(tt.args)
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("parseGlobalFlags() = %q, want %q", got, tt.want)
+			}
+			if jsonOutput != tt.json || autostart != tt.autostart || timeoutFlag != tt.timeout {
+				t.Errorf("json=%v autostart=%v timeout=%q, want %v %v %q", jsonOutput, autostart, timeoutFlag, tt.json, tt.autostart, tt.timeout)
+			}
+		})
+	}
+}
+
+func TestConfigureTimeout(t *testing.T) {
+	t.Cleanup(func() { timeoutFlag = "" })
+
+	// Un daemon que acepta la conexión y nunca responde
+	socketPath := filepath.Join(t.TempDir(), "smart-download.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	tests := []struct {
+		name    string
+		flag    string
+		env     string
+		wantErr string // Error de configureTimeout
+		wantMsg string // Plazo que informa el error de la petición
+	}{
+		{"env", "", "100ms", "", "100ms"},
+		{"flag wins over env", "150ms", "1h", "", "150ms"},
+		{"invalid env", "", "soon", "invalid SMD_TIMEOUT", ""},
+		{"invalid flag", "-1s", "", "invalid --timeout", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timeoutFlag = tt.flag
+			t.Setenv("SMD_TIMEOUT", tt.env)
+
+			c := client.NewClient(socketPath)
+			err := configureTimeout(c)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("configureTimeout() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("configureTimeout() error: %v", err)
+			}
+
+			if _, err := c.Call("stats", nil); err == nil || !strings.Contains(err.Error(), "within "+tt.wantMsg) {
+				t.Errorf("Call() error = %v, want a timeout after %s", err, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestCheckFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	// ffprobe falso: la info depende del contenido del archivo (el último argumento); el
+	// H.264 tarda más para que termine último con varios jobs
+	h264 := `{"streams": [{"codec_type": "video", "codec_name": "h264", "pix_fmt": "yuv420p", "width": 1280, "height": 720}, {"codec_type": "audio", "codec_name": "aac", "channels": 2}], "format": {"duration": "5"}}`
+	hevc := `{"streams": [{"codec_type": "video", "codec_name": "hevc", "pix_fmt": "yuv420p", "width": 1920, "height": 1080}], "format": {"duration": "5"}}`
+	binDir := filepath.Join(dir, "bin")
+	if err := os.MkdirAll(binDir, 0755); err != nil {
+		t.Fatalf("failed to create bin dir: %v", err)
+	}
+	script := "#!/bin/sh\nfor f; do :; done\ncase \"$(cat \"$f\" 2>/dev/null)\" in\n" +
+		"h264) sleep 0.2; echo '" + h264 + "' ;;\n" +
+		"hevc) echo '" + hevc + "' ;;\n" +
+		"*) exit 1 ;;\nesac\n"
+	if err := os.WriteFile(filepath.Join(binDir, "ffprobe"), []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write fake ffprobe: %v", err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	files := []string{
+		filepath.Join(dir, "a.mp4"),
+		filepath.Join(dir, "b.mp4"),
+		filepath.Join(dir, "c.mp4"),
+		filepath.Join(dir, "missing.mp4"),
+	}
+	for path, content := range map[string]string{files[0]: "h264", files[1]: "hevc", files[2]: "garbage"} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+
+	whatsapp, _ := postprocessor.TargetFor(nil)
+	reports := checkFiles(context.Background(), postprocessor.NewFFmpegProcessor(dir), files, whatsapp, &domain.DownloadOptions{}, 4)
+
+	if len(reports) != len(files) {
+		t.Fatalf("got %d reports, want %d", len(reports), len(files))
+	}
+	for i, report := range reports {
+		if report.File != files[i] {
+			t.Errorf("report %d is for %s, want %s (input order)", i, report.File, files[i])
+		}
+	}
+
+	if r := reports[0]; !r.Compatible || r.Error != "" || r.Width != 1280 || r.AudioCodec != "aac" || r.Channels != 2 || r.Size != 4 {
+		t.Errorf("H.264 report = %+v", r)
+	}
+	if r := reports[1]; r.Compatible || !strings.Contains(r.Reason, "hevc") || r.Error != "" {
+		t.Errorf("HEVC report = %+v, want incompatible because of the codec", r)
+	}
+	if r := reports[2]; r.Error == "" || r.Compatible || r.Size != 7 {
+		t.Errorf("unreadable file report = %+v, want error and size", r)
+	}
+	if r := reports[3]; r.Error == "" || r.Size != 0 {
+		t.Errorf("missing file report = %+v, want error", r)
+	}
+
+	// Forma del JSON de --check-only --json
+	data, err := json.Marshal(reports)
+	if err != nil {
+		t.Fatalf("marshal reports: %v", err)
+	}
+	var decoded []map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal reports: %v", err)
+	}
+	for _, key := range []string{"file", "compatible", "width", "height", "video_codec", "pixel_format", "hdr", "audio_codec", "audio_channels", "size"} {
+		if _, ok := decoded[0][key]; !ok {
+			t.Errorf("report JSON has no %q: %v", key, decoded[0])
+		}
+	}
+	for _, key := range []string{"reason", "error"} {
+		if _, ok := decoded[0][key]; ok {
+			t.Errorf("compatible report JSON has %q: %v", key, decoded[0])
+		}
+	}
+	if _, ok := decoded[1]["reason"]; !ok {
+		t.Errorf("incompatible report JSON has no reason: %v", decoded[1])
+	}
+	if _, ok := decoded[2]["error"]; !ok {
+		t.Errorf("unreadable file report JSON has no error: %v", decoded[2])
+	}
+}
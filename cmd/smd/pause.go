@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/elsanchez/smart-download/pkg/client"
+)
+
+// handlePause pausa (action "pause") o reanuda ("resume") la cola: en pausa no se inician
+// descargas pendientes, pero las que están en curso terminan normalmente
+func handlePause(c *client.Client, action string) {
+	if jsonOutput {
+		printRawResponse(c, action, nil)
+		return
+	}
+
+	if action == "resume" {
+		if _, err := c.Resume(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("✓ Queue resumed")
+		return
+	}
+
+	state, err := c.Pause()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println("✓ Queue paused: pending downloads won't start until smd resume")
+	if state.WorkersBusy > 0 {
+		fmt.Printf("  %d running download(s) will finish normally\n", state.WorkersBusy)
+	}
+}
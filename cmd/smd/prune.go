@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elsanchez/smart-download/pkg/client"
+)
+
+func handlePrune(c *client.Client, args []string) {
+	pruneFlags := flag.NewFlagSet("prune", flag.ExitOnError)
+	olderThan := pruneFlags.String("older-than", "", "Prune downloads that finished longer ago than this (e.g. 30d, 12h)")
+	deleteFiles := pruneFlags.Bool("delete-files", false, "Also delete the downloaded files")
+	failed := pruneFlags.Bool("failed", false, "Also prune failed downloads (only completed ones by default)")
+	pruneFlags.Parse(args)
+
+	if *olderThan == "" {
+		fmt.Println("Error: --older-than is required")
+		fmt.Println("Usage: smd prune --older-than 30d [--delete-files] [--failed]")
+		os.Exit(1)
+	}
+
+	maxAge, err := parseAge(*olderThan)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	payload := map[string]interface{}{
+		"older_than":   maxAge.String(),
+		"delete_files": *deleteFiles,
+		"failed":       *failed,
+	}
+
+	if jsonOutput {
+		printRawResponse(c, "prune", payload)
+		return
+	}
+
+	data, err := c.Call("prune", payload)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	var result struct {
+		Rows  int64 `json:"rows"`
+		Files int   `json:"files"`
+		Bytes int64 `json:"bytes"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		fmt.Printf("Error: unmarshal response: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("✓ Pruned %d download(s) older than %s\n", result.Rows, *olderThan)
+	if *deleteFiles {
+		fmt.Printf("  Deleted %d file(s), %s reclaimed\n", result.Files, formatBytes(result.Bytes))
+	}
+}
+
+// parseAge acepta días ("30d") además de duraciones Go ("12h", "90m")
+func parseAge(s string) (time.Duration, error) {
+	var age time.Duration
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid age %q (use e.g. 30d or 12h)", s)
+		}
+		age = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid age %q (use e.g. 30d or 12h)", s)
+		}
+		age = d
+	}
+
+	if age <= 0 {
+		return 0, fmt.Errorf("age must be positive, got %q", s)
+	}
+
+	return age, nil
+}
+
+// formatBytes formatea un tamaño en bytes en la unidad más legible
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
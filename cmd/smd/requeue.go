@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/elsanchez/smart-download/pkg/client"
+)
+
+// handleRequeue vuelve a encolar todas las descargas fallidas, opcionalmente solo las de una
+// plataforma y/o un tipo de error (p.ej. tras renovar las cookies de una cuenta)
+func handleRequeue(c *client.Client, args []string) {
+	requeueFlags := flag.NewFlagSet("requeue", flag.ExitOnError)
+	platform := requeueFlags.String("platform", "", "Only requeue failed downloads from this platform")
+	errorType := requeueFlags.String("error-type", "", "Only requeue failures of this type (auth, needs_cookies, network...)")
+	requeueFlags.Parse(args)
+
+	payload := map[string]interface{}{}
+	if *platform != "" {
+		payload["platform"] = *platform
+	}
+	if *errorType != "" {
+		payload["error_type"] = *errorType
+	}
+
+	if jsonOutput {
+		printRawResponse(c, "requeue", payload)
+		return
+	}
+
+	data, err := c.Call("requeue", payload)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	var result struct {
+		Requeued int64 `json:"requeued"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		fmt.Printf("Error: unmarshal response: %v\n", err)
+		os.Exit(1)
+	}
+
+	if result.Requeued == 0 {
+		fmt.Println("No failed downloads to requeue")
+		return
+	}
+	fmt.Printf("✓ Requeued %d failed download(s)\n", result.Requeued)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// Formatos aceptados por --at (hora local salvo que incluyan zona)
+var scheduleLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+}
+
+// parseSchedule convierte --after (hora del día, "02:00") o --at (fecha y hora) en el
+// momento a partir del cual se puede despachar la descarga. nil = sin programar
+func parseSchedule(after, at string, now time.Time) (*time.Time, error) {
+	switch {
+	case after != "" && at != "":
+		return nil, fmt.Errorf("--after and --at are mutually exclusive")
+
+	case after != "":
+		clock, err := time.Parse("15:04", after)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --after %q (use HH:MM, e.g. 02:00)", after)
+		}
+
+		// Próxima ocurrencia de esa hora: hoy si aún no pasó, si no mañana
+		t := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, now.Location())
+		if !t.After(now) {
+			t = t.AddDate(0, 0, 1)
+		}
+		return &t, nil
+
+	case at != "":
+		for _, layout := range scheduleLayouts {
+			t, err := time.ParseInLocation(layout, at, now.Location())
+			if err != nil {
+				continue
+			}
+			if !t.After(now) {
+				return nil, fmt.Errorf("--at %s is in the past", at)
+			}
+			return &t, nil
+		}
+		return nil, fmt.Errorf("invalid --at %q (use e.g. 2025-06-01T02:00)", at)
+	}
+
+	return nil, nil
+}
+
+// dateLayout es el formato de --since/--until para un día completo
+const dateLayout = "2006-01-02"
+
+// parseDateBound convierte --since/--until de smd list en un momento: una antigüedad
+// relativa a now ("24h", "7d"), un día ("2024-01-07", "today", "yesterday") o una fecha y
+// hora. Un día como --until lo incluye completo (hasta la medianoche siguiente). nil = sin límite
+func parseDateBound(flagName, value string, until bool, now time.Time) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	if age, err := parseAge(value); err == nil {
+		t := now.Add(-age)
+		return &t, nil
+	}
+
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	var day time.Time
+	switch value {
+	case "today":
+		day = midnight
+	case "yesterday":
+		day = midnight.AddDate(0, 0, -1)
+	default:
+		t, err := time.ParseInLocation(dateLayout, value, now.Location())
+		if err != nil {
+			for _, layout := range scheduleLayouts {
+				if t, err := time.ParseInLocation(layout, value, now.Location()); err == nil {
+					return &t, nil
+				}
+			}
+			return nil, fmt.Errorf("invalid --%s %q (use e.g. 24h, 7d, yesterday, 2024-01-07 or 2024-01-07T15:04)", flagName, value)
+		}
+		day = t
+	}
+
+	if until {
+		day = day.AddDate(0, 0, 1)
+	}
+	return &day, nil
+}
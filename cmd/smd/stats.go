@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/elsanchez/smart-download/pkg/client"
+)
+
+func handleStats(c *client.Client, args []string) {
+	statsFlags := flag.NewFlagSet("stats", flag.ExitOnError)
+	byPlatform := statsFlags.Bool("by-platform", false, "Break down the downloads by platform")
+	statsFlags.Parse(args)
+
+	if jsonOutput {
+		printRawResponse(c, "stats", nil)
+		return
+	}
+
+	stats, err := c.Stats()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("Queue Statistics:")
+	fmt.Println()
+	fmt.Printf("  Pending:      %d\n", stats.Pending)
+	fmt.Printf("  Downloading:  %d\n", stats.Downloading)
+	fmt.Printf("  Processing:   %d\n", stats.Processing)
+	fmt.Printf("  Completed:    %d\n", stats.Completed)
+	fmt.Printf("  Failed:       %d\n", stats.Failed)
+	fmt.Println()
+	fmt.Printf("  Workers:      %d / %d busy\n", stats.WorkersBusy, stats.WorkersTotal)
+	if stats.Paused {
+		fmt.Println("  Queue:        paused (smd resume to start pending downloads)")
+	}
+	fmt.Printf("  Downloaded:   %s\n", formatBytes(stats.TotalBytes))
+	fmt.Println()
+	fmt.Printf("  Last 24h:     %s\n", formatFinished(stats.Last24h))
+	fmt.Printf("  Last 7 days:  %s\n", formatFinished(stats.Last7d))
+
+	if !*byPlatform {
+		return
+	}
+
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PLATFORM\tTOTAL\tCOMPLETED\tFAILED\tACTIVE\tSIZE")
+	for _, p := range stats.Platforms {
+		active := p.Pending + p.Downloading + p.Processing
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%s\n", p.Platform, p.Total, p.Completed, p.Failed, active, formatBytes(p.Bytes))
+	}
+	w.Flush()
+}
+
+// formatFinished resume las descargas terminadas en una ventana, p.ej. "12 completed, 3 failed (80% success)"
+func formatFinished(s client.FinishedStats) string {
+	if s.Completed+s.Failed == 0 {
+		return "no downloads finished"
+	}
+	return fmt.Sprintf("%d completed, %d failed (%.0f%% success)", s.Completed, s.Failed, s.SuccessRate)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/elsanchez/smart-download/pkg/client"
+)
+
+func handleTag(c *client.Client, args []string) {
+	if len(args) < 2 {
+		fmt.Println("Error: Download ID and at least one tag are required")
+		fmt.Println("Usage: smd tag <id> +add -remove ...")
+		os.Exit(1)
+	}
+
+	var id int64
+	if _, err := fmt.Sscanf(args[0], "%d", &id); err != nil {
+		fmt.Printf("Error: Invalid ID: %s\n", args[0])
+		os.Exit(1)
+	}
+
+	add, remove, err := parseTagArgs(args[1:])
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	payload := map[string]interface{}{"id": id, "add": add, "remove": remove}
+
+	if jsonOutput {
+		printRawResponse(c, "tag", payload)
+		return
+	}
+
+	data, err := c.Call("tag", payload)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	var result struct {
+		Tags []string `json:"tags"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		fmt.Printf("Error: unmarshal response: %v\n", err)
+		os.Exit(1)
+	}
+
+	if len(result.Tags) == 0 {
+		fmt.Printf("✓ Download %d has no tags\n", id)
+		return
+	}
+	fmt.Printf("✓ Download %d tags: %s\n", id, strings.Join(result.Tags, ", "))
+}
+
+// parseTagArgs separa "+tag" (o "tag") para añadir y "-tag" para quitar
+func parseTagArgs(args []string) (add, remove []string, err error) {
+	for _, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, "-"):
+			remove = append(remove, strings.TrimPrefix(arg, "-"))
+		default:
+			add = append(add, strings.TrimPrefix(arg, "+"))
+		}
+	}
+
+	for _, tag := range append(add, remove...) {
+		if strings.TrimSpace(tag) == "" {
+			return nil, nil, fmt.Errorf("empty tag in %v", args)
+		}
+	}
+
+	return add, remove, nil
+}
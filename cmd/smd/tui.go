@@ -0,0 +1,26 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	downloadstui "github.com/elsanchez/smart-download/internal/tui/downloads"
+	"github.com/elsanchez/smart-download/pkg/client"
+)
+
+// handleTUI abre el dashboard interactivo de descargas
+func handleTUI(c *client.Client) {
+	// Fallar antes de abrir la pantalla si el daemon no responde
+	if _, err := c.Call("ping", nil); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	p := tea.NewProgram(downloadstui.NewModel(c), tea.WithAltScreen())
+	if _, err := p.Run(); err != nil {
+		fmt.Printf("Error: run tui: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/elsanchez/smart-download/pkg/client"
+)
+
+// handleWorkers muestra los workers del daemon o, con <n>, cambia cuántas descargas corren
+// en paralelo sin reiniciarlo
+func handleWorkers(c *client.Client, args []string) {
+	if len(args) == 0 {
+		stats, err := c.Stats()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if jsonOutput {
+			printJSON(map[string]int{"workers_busy": stats.WorkersBusy, "workers_total": stats.WorkersTotal})
+			return
+		}
+		fmt.Printf("Workers: %d / %d busy\n", stats.WorkersBusy, stats.WorkersTotal)
+		return
+	}
+
+	workers, err := strconv.Atoi(args[0])
+	if err != nil || workers < 1 {
+		fmt.Printf("Error: invalid worker count %q (use a number greater than 0)\n", args[0])
+		fmt.Println("Usage: smd workers [n]")
+		os.Exit(1)
+	}
+
+	payload := map[string]int{"workers": workers}
+	if jsonOutput {
+		printRawResponse(c, "set-workers", payload)
+		return
+	}
+
+	if err := c.SetWorkers(workers); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("✓ Workers set to %d (running downloads are not interrupted)\n", workers)
+}
@@ -0,0 +1,264 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/elsanchez/smart-download/internal/domain"
+	"github.com/elsanchez/smart-download/internal/downloader"
+	"github.com/elsanchez/smart-download/internal/logging"
+)
+
+// Config es la configuración del daemon (~/.config/smart-download/config.yaml)
+type Config struct {
+	Workers         int           `yaml:"workers"`
+	OutputDir       string        `yaml:"output_dir"`
+	CookiesDir      string        `yaml:"cookies_dir"`
+	PollInterval    time.Duration `yaml:"poll_interval"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	DownloadTimeout time.Duration `yaml:"download_timeout"` // Tiempo máximo por descarga (0 = sin límite)
+	Clipboard       bool          `yaml:"clipboard"`        // Copiar el path final al clipboard
+	Notifications   bool          `yaml:"notifications"`
+	Debug           bool          `yaml:"debug"` // Equivale a log.level: debug
+	Log             Log           `yaml:"log"`
+	Defaults        Defaults      `yaml:"defaults"`
+	Cookies         Cookies       `yaml:"cookies"`
+	Retention       Retention     `yaml:"retention"`
+	Metrics         Metrics       `yaml:"metrics"`
+	API             API           `yaml:"api"`
+	Webhook         Webhook       `yaml:"webhook"`
+	Routing         Routing       `yaml:"routing"`
+	Client          Client        `yaml:"client"`
+}
+
+// Client configura el cliente smd
+type Client struct {
+	Autostart  bool   `yaml:"autostart"`   // Iniciar el daemon si no está corriendo (como smd --autostart)
+	DaemonPath string `yaml:"daemon_path"` // Ejecutable del daemon ("" = smart-downloadd del PATH)
+}
+
+// Routing ajusta qué downloader (yt-dlp o gallery-dl) se usa para cada sitio
+type Routing struct {
+	GalleryDLSites []string          `yaml:"gallery_dl_sites"` // Sitios añadidos a los que ya van a gallery-dl
+	Overrides      map[string]string `yaml:"overrides"`        // Dominio → "yt-dlp" o "gallery-dl" (gana sobre todo lo demás)
+}
+
+// Webhook configura el POST enviado al completarse o fallar una descarga
+type Webhook struct {
+	URL string `yaml:"url"` // "" = deshabilitado
+}
+
+// API configura la API HTTP/REST opcional
+type API struct {
+	Listen string `yaml:"listen"` // Dirección HTTP, p.ej. "127.0.0.1:8080" ("" = deshabilitada)
+	Token  string `yaml:"token"`  // Bearer token ("" = usar el token del socket)
+}
+
+// Metrics configura el endpoint /metrics de Prometheus
+type Metrics struct {
+	Listen string `yaml:"listen"` // Dirección HTTP, p.ej. "127.0.0.1:9090" ("" = deshabilitado)
+}
+
+// Log configura el logging estructurado del daemon
+type Log struct {
+	Level      string `yaml:"level"`       // debug, info, warn o error
+	Format     string `yaml:"format"`      // text (legible) o json
+	File       string `yaml:"file"`        // Archivo de log con rotación ("" = solo stderr)
+	MaxSizeMB  int    `yaml:"max_size_mb"` // Tamaño a partir del cual se rota el archivo
+	MaxBackups int    `yaml:"max_backups"` // Archivos rotados que se conservan (0 = todos)
+}
+
+// Retention configura la limpieza automática del historial de descargas
+type Retention struct {
+	Days          int  `yaml:"days"`           // Eliminar descargas completadas más antiguas (0 = nunca)
+	DeleteFiles   bool `yaml:"delete_files"`   // Eliminar también sus archivos
+	IncludeFailed bool `yaml:"include_failed"` // Eliminar también las descargas fallidas
+}
+
+// Cookies agrupa la configuración de validación de cookies
+type Cookies struct {
+	// ValidationEndpoints reemplaza o añade endpoints de validación HTTP por plataforma
+	// (URL vacía = deshabilitar la validación HTTP de esa plataforma)
+	ValidationEndpoints map[string]string `yaml:"validation_endpoints"`
+	// ExpiryCheckInterval es cada cuánto el daemon revisa las cookies de las cuentas activas (0 = deshabilitado)
+	ExpiryCheckInterval time.Duration `yaml:"expiry_check_interval"`
+	// WatchFiles revalida una cuenta en cuanto su archivo de cookies cambia en disco
+	WatchFiles bool `yaml:"watch_files"`
+}
+
+// Defaults son las opciones de conversión aplicadas cuando la petición no las especifica
+type Defaults struct {
+	Resolution string `yaml:"resolution"`
+	NoConvert  bool   `yaml:"no_convert"`
+	GIFWidth   int    `yaml:"gif_width"`
+	Proxy      string `yaml:"proxy"` // Proxy de todas las descargas salvo que indiquen otro
+	// Nombre de los archivos (ver domain.FilenameFields) salvo que la descarga indique otro
+	FilenameTemplate string `yaml:"filename_template"`
+}
+
+// Options convierte los defaults a opciones de descarga. El proxy no: se aplica al ejecutar
+// la descarga (downloader.SetDefaultProxy) para no guardar sus credenciales en cada una
+func (d Defaults) Options() domain.DownloadOptions {
+	opts := domain.DownloadOptions{
+		Resolution:       d.Resolution,
+		GIFWidth:         d.GIFWidth,
+		FilenameTemplate: d.FilenameTemplate,
+	}
+	if d.NoConvert {
+		opts.NoConvert = &d.NoConvert
+	}
+	return opts
+}
+
+// DefaultPath retorna el path del archivo de configuración
+func DefaultPath() string {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		homeDir, _ := os.UserHomeDir()
+		configDir = filepath.Join(homeDir, ".config")
+	}
+
+	return filepath.Join(configDir, "smart-download", "config.yaml")
+}
+
+// Default retorna la configuración por defecto (la usada antes de existir el archivo)
+func Default() *Config {
+	homeDir, _ := os.UserHomeDir()
+
+	return &Config{
+		Workers:         3,
+		OutputDir:       filepath.Join(homeDir, "Downloads", "download_video"),
+		CookiesDir:      filepath.Join(homeDir, "Documents", "cookies"),
+		PollInterval:    5 * time.Second,
+		ShutdownTimeout: 30 * time.Second,
+		DownloadTimeout: time.Hour,
+		Clipboard:       true,
+		Notifications:   true,
+		Log: Log{
+			Level:      "info",
+			Format:     logging.FormatText,
+			File:       filepath.Join(homeDir, ".local", "share", "smart-download", "logs", "daemon.log"),
+			MaxSizeMB:  10,
+			MaxBackups: 5,
+		},
+		Defaults: Defaults{
+			GIFWidth: 480,
+		},
+		Cookies: Cookies{
+			ExpiryCheckInterval: 6 * time.Hour,
+		},
+	}
+}
+
+// Load lee la configuración de path. Si el archivo no existe retorna los defaults;
+// los campos ausentes en el archivo conservan su valor por defecto
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parse config %s: %w", path, err)
+	}
+
+	cfg.OutputDir = ExpandHome(cfg.OutputDir)
+	cfg.CookiesDir = ExpandHome(cfg.CookiesDir)
+	cfg.Log.File = ExpandHome(cfg.Log.File)
+	cfg.Client.DaemonPath = ExpandHome(cfg.Client.DaemonPath)
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+// Validate verifica que los valores sean utilizables
+func (c *Config) Validate() error {
+	if c.Workers <= 0 {
+		return fmt.Errorf("workers must be greater than 0, got %d", c.Workers)
+	}
+	if c.OutputDir == "" {
+		return fmt.Errorf("output_dir is required")
+	}
+	if c.CookiesDir == "" {
+		return fmt.Errorf("cookies_dir is required")
+	}
+	if c.PollInterval <= 0 {
+		return fmt.Errorf("poll_interval must be positive, got %s", c.PollInterval)
+	}
+	if c.ShutdownTimeout < 0 {
+		return fmt.Errorf("shutdown_timeout must not be negative, got %s", c.ShutdownTimeout)
+	}
+	if c.DownloadTimeout < 0 {
+		return fmt.Errorf("download_timeout must not be negative, got %s", c.DownloadTimeout)
+	}
+	if _, err := logging.ParseLevel(c.Log.Level); err != nil {
+		return fmt.Errorf("log.level: %w", err)
+	}
+	if c.Log.Format != logging.FormatText && c.Log.Format != logging.FormatJSON {
+		return fmt.Errorf("log.format must be %s or %s, got %q", logging.FormatText, logging.FormatJSON, c.Log.Format)
+	}
+	if c.Log.File != "" && c.Log.MaxSizeMB <= 0 {
+		return fmt.Errorf("log.max_size_mb must be greater than 0, got %d", c.Log.MaxSizeMB)
+	}
+	if c.Log.MaxBackups < 0 {
+		return fmt.Errorf("log.max_backups must not be negative, got %d", c.Log.MaxBackups)
+	}
+	if c.Cookies.ExpiryCheckInterval < 0 {
+		return fmt.Errorf("cookies.expiry_check_interval must not be negative, got %s", c.Cookies.ExpiryCheckInterval)
+	}
+	if c.Retention.Days < 0 {
+		return fmt.Errorf("retention.days must not be negative, got %d", c.Retention.Days)
+	}
+	if c.Webhook.URL != "" {
+		if u, err := url.Parse(c.Webhook.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("webhook.url must be an http(s) URL, got %q", c.Webhook.URL)
+		}
+	}
+	for site, backend := range c.Routing.Overrides {
+		if err := downloader.ValidateBackend(backend); err != nil {
+			return fmt.Errorf("routing.overrides.%s: %w", site, err)
+		}
+	}
+	if c.Defaults.GIFWidth < 0 {
+		return fmt.Errorf("defaults.gif_width must not be negative, got %d", c.Defaults.GIFWidth)
+	}
+	if c.Defaults.Proxy != "" {
+		if err := domain.ValidateProxy(c.Defaults.Proxy); err != nil {
+			return fmt.Errorf("defaults.proxy: %w", err)
+		}
+	}
+	if c.Defaults.FilenameTemplate != "" {
+		if err := domain.ValidateFilenameTemplate(c.Defaults.FilenameTemplate); err != nil {
+			return fmt.Errorf("defaults.filename_template: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ExpandHome reemplaza un "~/" inicial por el home del usuario
+func ExpandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return path
+		}
+		return filepath.Join(homeDir, strings.TrimPrefix(path, "~"))
+	}
+
+	return path
+}
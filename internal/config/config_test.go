@@ -0,0 +1,149 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoad_MissingFile(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), "config.yaml"))
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	def := Default()
+	if cfg.Workers != def.Workers || cfg.OutputDir != def.OutputDir || cfg.PollInterval != def.PollInterval {
+		t.Errorf("expected defaults, got %+v", cfg)
+	}
+}
+
+func TestLoad_PartialFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "workers: 5\noutput_dir: ~/Videos/smd\npoll_interval: 2s\ndefaults:\n  no_convert: true\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	homeDir, _ := os.UserHomeDir()
+	if cfg.Workers != 5 {
+		t.Errorf("workers = %d, want 5", cfg.Workers)
+	}
+	if want := filepath.Join(homeDir, "Videos", "smd"); cfg.OutputDir != want {
+		t.Errorf("output_dir = %s, want %s", cfg.OutputDir, want)
+	}
+	if cfg.PollInterval != 2*time.Second {
+		t.Errorf("poll_interval = %s, want 2s", cfg.PollInterval)
+	}
+	if !cfg.Defaults.NoConvert {
+		t.Error("defaults.no_convert = false, want true")
+	}
+
+	// Campos ausentes conservan el default
+	if cfg.CookiesDir != Default().CookiesDir {
+		t.Errorf("cookies_dir = %s, want default", cfg.CookiesDir)
+	}
+	if cfg.Defaults.GIFWidth != 480 {
+		t.Errorf("defaults.gif_width = %d, want 480", cfg.Defaults.GIFWidth)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("workers: 0\n"), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	if _, err := Load(path); err == nil {
+		t.Error("expected error for workers: 0")
+	}
+
+	if err := os.WriteFile(path, []byte("defaults:\n  proxy: 127.0.0.1:1080\n"), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("expected error for a proxy without scheme")
+	}
+
+	if err := os.WriteFile(path, []byte("defaults:\n  filename_template: \"%(id)s\"\n"), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("expected error for an unknown filename template field")
+	}
+}
+
+func TestLoad_CookieExpiryCheck(t *testing.T) {
+	if Default().Cookies.ExpiryCheckInterval != 6*time.Hour {
+		t.Errorf("default cookies.expiry_check_interval = %s, want 6h", Default().Cookies.ExpiryCheckInterval)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("cookies:\n  expiry_check_interval: 0s\n"), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.Cookies.ExpiryCheckInterval != 0 {
+		t.Errorf("cookies.expiry_check_interval = %s, want 0 (disabled)", cfg.Cookies.ExpiryCheckInterval)
+	}
+}
+
+func TestLoad_Log(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("log:\n  format: json\n"), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.Log.Format != "json" || cfg.Log.Level != "info" {
+		t.Errorf("log = %+v, want json format with the default info level", cfg.Log)
+	}
+	if cfg.Log.File != Default().Log.File || cfg.Log.MaxSizeMB != 10 || cfg.Log.MaxBackups != 5 {
+		t.Errorf("log = %+v, want the default file and rotation", cfg.Log)
+	}
+
+	for _, content := range []string{"log:\n  level: verbose\n", "log:\n  format: xml\n", "log:\n  max_size_mb: 0\n"} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+		if _, err := Load(path); err == nil {
+			t.Errorf("expected error for %q", content)
+		}
+	}
+}
+
+func TestLoad_Routing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "routing:\n  gallery_dl_sites: [example-gallery.net]\n  overrides:\n    reddit.com: yt-dlp\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if len(cfg.Routing.GalleryDLSites) != 1 || cfg.Routing.Overrides["reddit.com"] != "yt-dlp" {
+		t.Errorf("unexpected routing: %+v", cfg.Routing)
+	}
+
+	if err := os.WriteFile(path, []byte("routing:\n  overrides:\n    reddit.com: wget\n"), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("expected error for an unknown routing backend")
+	}
+}
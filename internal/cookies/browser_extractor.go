@@ -2,6 +2,7 @@ package cookies
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -14,6 +15,22 @@ import (
 	_ "github.com/browserutils/kooky/browser/opera"
 )
 
+// ErrBrowserLocked is returned when a browser's cookie database is locked,
+// usually because the browser is running
+var ErrBrowserLocked = errors.New("browser cookie database is locked (close the browser and try again)")
+
+// ErrBrowserNotFound is returned when no cookie store exists for the requested browser
+var ErrBrowserNotFound = errors.New("browser not installed (no cookie store found)")
+
+// wrapReadError turns sqlite lock errors from kooky into ErrBrowserLocked
+func wrapReadError(err error) error {
+	msg := strings.ToLower(err.Error())
+	if strings.Contains(msg, "database is locked") || strings.Contains(msg, "sqlite_busy") {
+		return fmt.Errorf("%w: %v", ErrBrowserLocked, err)
+	}
+	return err
+}
+
 // BrowserExtractor handles extraction of cookies from web browsers
 type BrowserExtractor struct {
 	parser *CookieParser
@@ -58,7 +75,7 @@ func (e *BrowserExtractor) Extract(opts ExtractOptions) ([]NetscapeCookie, error
 
 	// Read cookies from browser
 	ctx := context.Background()
-	cookies, err := kooky.ReadCookies(ctx, filters...)
+	cookies, err := readBrowserCookies(ctx, browser, filters...)
 	if err != nil {
 		return nil, fmt.Errorf("read cookies from browser: %w", err)
 	}
@@ -70,25 +87,12 @@ func (e *BrowserExtractor) Extract(opts ExtractOptions) ([]NetscapeCookie, error
 	// Convert kooky cookies to Netscape format
 	netscapeCookies := make([]NetscapeCookie, 0, len(cookies))
 	for _, cookie := range cookies {
-		// Skip cookies from different browsers if specified
-		if browser != "" && cookie.Browser != nil {
-			cookieBrowser := strings.ToLower(cookie.Browser.Browser())
-			if !strings.Contains(cookieBrowser, browser) {
-				continue
-			}
-		}
-
 		// Convert to Netscape format
 		domain := cookie.Domain
 		if !strings.HasPrefix(domain, ".") && domain != "" {
 			domain = "." + domain
 		}
 
-		httpOnly := "FALSE"
-		if cookie.HttpOnly {
-			httpOnly = "TRUE"
-		}
-
 		expiration := cookie.Expires.Unix()
 		if expiration < 0 {
 			expiration = 0
@@ -96,12 +100,13 @@ func (e *BrowserExtractor) Extract(opts ExtractOptions) ([]NetscapeCookie, error
 
 		netscapeCookies = append(netscapeCookies, NetscapeCookie{
 			Domain:     domain,
-			Flag:       httpOnly,
+			Flag:       "TRUE", // Domain always has a leading dot, so it applies to subdomains
 			Path:       cookie.Path,
 			Secure:     cookie.Secure,
 			Expiration: expiration,
 			Name:       cookie.Name,
 			Value:      cookie.Value,
+			HttpOnly:   cookie.HttpOnly,
 		})
 	}
 
@@ -121,61 +126,56 @@ func (e *BrowserExtractor) Extract(opts ExtractOptions) ([]NetscapeCookie, error
 
 // saveCookies saves cookies to a file in Netscape format
 func (e *BrowserExtractor) saveCookies(cookies []NetscapeCookie, path string) error {
-	file, err := os.Create(path)
-	if err != nil {
-		return fmt.Errorf("create file: %w", err)
-	}
-	defer file.Close()
-
-	// Write Netscape cookie file header
-	if _, err := file.WriteString("# Netscape HTTP Cookie File\n"); err != nil {
-		return fmt.Errorf("write header: %w", err)
-	}
-
-	// Write cookies
-	for _, cookie := range cookies {
-		secure := "FALSE"
-		if cookie.Secure {
-			secure = "TRUE"
-		}
-
-		line := fmt.Sprintf("%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
-			cookie.Domain,
-			cookie.Flag,
-			cookie.Path,
-			secure,
-			cookie.Expiration,
-			cookie.Name,
-			cookie.Value,
-		)
-
-		if _, err := file.WriteString(line); err != nil {
-			return fmt.Errorf("write cookie: %w", err)
-		}
-	}
-
-	return nil
+	return e.parser.WriteFile(path, cookies)
 }
 
 // GetBrowserCookieCount returns the number of cookies for a domain in a browser
 func (e *BrowserExtractor) GetBrowserCookieCount(browser, domain string) (int, error) {
 	ctx := context.Background()
-	filters := []kooky.Filter{kooky.DomainHasSuffix(domain)}
-	cookies, err := kooky.ReadCookies(ctx, filters...)
+	cookies, err := readBrowserCookies(ctx, strings.ToLower(browser), kooky.DomainHasSuffix(domain))
 	if err != nil {
 		return 0, fmt.Errorf("read cookies: %w", err)
 	}
 
-	count := 0
-	browser = strings.ToLower(browser)
-	for _, cookie := range cookies {
-		if cookie.Browser != nil {
-			cookieBrowser := strings.ToLower(cookie.Browser.Browser())
-			if strings.Contains(cookieBrowser, browser) {
-				count++
+	return len(cookies), nil
+}
+
+// readBrowserCookies reads cookies from the cookie stores of one browser (all browsers if
+// empty). Stores that don't exist are ignored; read errors only fail the call when no
+// cookies could be read at all, so one broken profile doesn't hide the others
+func readBrowserCookies(ctx context.Context, browser string, filters ...kooky.Filter) ([]*kooky.Cookie, error) {
+	var (
+		cookies []*kooky.Cookie
+		errs    []error
+		found   bool
+	)
+
+	for _, store := range kooky.FindAllCookieStores(ctx) {
+		if browser != "" && !strings.Contains(strings.ToLower(store.Browser()), browser) {
+			store.Close()
+			continue
+		}
+
+		storeCookies, err := store.TraverseCookies(filters...).ReadAllCookies(ctx)
+		store.Close()
+		if err != nil {
+			// Some browsers report default locations that don't exist on this system
+			if errors.Is(err, os.ErrNotExist) {
+				continue
 			}
+			errs = append(errs, fmt.Errorf("%s (%s): %w", store.Browser(), store.Profile(), err))
 		}
+		found = true
+		cookies = append(cookies, storeCookies...)
+	}
+
+	if !found {
+		return nil, ErrBrowserNotFound
+	}
+
+	if len(cookies) == 0 && len(errs) > 0 {
+		return nil, wrapReadError(errors.Join(errs...))
 	}
 
-	return count, nil
+	return cookies, nil
 }
@@ -0,0 +1,120 @@
+package cookies
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/elsanchez/smart-download/internal/domain"
+	"github.com/elsanchez/smart-download/internal/repository"
+)
+
+// EditOptions contains the new values for an account; empty fields keep the current ones
+type EditOptions struct {
+	Platform   string
+	Name       string
+	CookiePath string // Point the account at another cookie file
+}
+
+// EditResult describes what Edit changed
+type EditResult struct {
+	Account     *domain.Account
+	PathChanged bool   // The account uses another cookie file and should be re-validated
+	RenamedFrom string // Previous path of the stored cookie file, when it was renamed
+}
+
+// CookieEditor renames accounts and points them at other cookie files
+type CookieEditor struct {
+	parser      *CookieParser
+	accountRepo repository.AccountRepository
+}
+
+// NewCookieEditor creates a new cookie editor
+func NewCookieEditor(accountRepo repository.AccountRepository) *CookieEditor {
+	return &CookieEditor{
+		parser:      NewCookieParser(),
+		accountRepo: accountRepo,
+	}
+}
+
+// Edit updates the account. When it's renamed and its cookie file is the one the importer
+// stored (platform_name.txt), the file is renamed too to keep the convention. A new cookie
+// path must be a file the parser can read
+func (e *CookieEditor) Edit(ctx context.Context, id int64, opts EditOptions) (*EditResult, error) {
+	acc, err := e.accountRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get account: %w", err)
+	}
+
+	updated := *acc
+	if platform := strings.TrimSpace(opts.Platform); platform != "" {
+		updated.Platform = platform
+	}
+	if name := strings.TrimSpace(opts.Name); name != "" {
+		updated.Name = name
+	}
+	if path := strings.TrimSpace(opts.CookiePath); path != "" {
+		if updated.CookiePath, err = filepath.Abs(path); err != nil {
+			return nil, fmt.Errorf("resolve cookie path: %w", err)
+		}
+	}
+
+	result := &EditResult{Account: acc}
+	renamed := updated.Platform != acc.Platform || updated.Name != acc.Name
+	if !renamed && updated.CookiePath == acc.CookiePath {
+		return result, nil
+	}
+
+	// The new name must be free on the platform
+	if renamed {
+		existing, err := e.accountRepo.GetAll(ctx, updated.Platform)
+		if err != nil {
+			return nil, fmt.Errorf("check existing accounts: %w", err)
+		}
+		for _, other := range existing {
+			if other.Name == updated.Name && other.ID != acc.ID {
+				return nil, fmt.Errorf("account already exists: %s/%s", updated.Platform, updated.Name)
+			}
+		}
+	}
+
+	if updated.CookiePath != acc.CookiePath {
+		if _, err := e.parser.ParseFile(updated.CookiePath); err != nil {
+			return nil, fmt.Errorf("parse cookie file: %w", err)
+		}
+		result.PathChanged = true
+	} else if filepath.Base(acc.CookiePath) == storedFileName(acc.Platform, acc.Name) {
+		newPath := filepath.Join(filepath.Dir(acc.CookiePath), storedFileName(updated.Platform, updated.Name))
+		if _, err := os.Stat(newPath); err == nil {
+			return nil, fmt.Errorf("cookie file already exists: %s", newPath)
+		}
+		if err := os.Rename(acc.CookiePath, newPath); err != nil {
+			return nil, fmt.Errorf("rename cookie file: %w", err)
+		}
+		updated.CookiePath = newPath
+		result.RenamedFrom = acc.CookiePath
+	}
+
+	// Each platform has a single active account: a moved account doesn't take over
+	if updated.Platform != acc.Platform {
+		updated.IsActive = false
+	}
+
+	if err := e.accountRepo.Update(ctx, &updated); err != nil {
+		// Put the file back so the account keeps pointing at it
+		if result.RenamedFrom != "" {
+			os.Rename(updated.CookiePath, result.RenamedFrom)
+		}
+		return nil, fmt.Errorf("update account: %w", err)
+	}
+
+	result.Account = &updated
+	return result, nil
+}
+
+// storedFileName is the name the importer gives to an account's cookie file
+func storedFileName(platform, name string) string {
+	return fmt.Sprintf("%s_%s.txt", platform, name)
+}
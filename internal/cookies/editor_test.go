@@ -0,0 +1,105 @@
+package cookies
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/elsanchez/smart-download/internal/domain"
+	"github.com/elsanchez/smart-download/internal/repository/sqlite"
+)
+
+func TestCookieEditor_Edit(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	db, err := sqlite.NewDatabase(dir)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	write := func(name string) string {
+		path := filepath.Join(dir, name)
+		content := "# Netscape HTTP Cookie File\n.x.com\tTRUE\t/\tTRUE\t1893456000\tauth_token\tabc\n"
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write cookie file: %v", err)
+		}
+		return path
+	}
+
+	create := func(platform, name, path string, active bool) int64 {
+		id, err := db.AccountRepo.Create(ctx, &domain.Account{Platform: platform, Name: name, CookiePath: path, IsActive: active})
+		if err != nil {
+			t.Fatalf("failed to create account: %v", err)
+		}
+		return id
+	}
+
+	stored := write("twitter_old.txt")
+	id := create("twitter", "old", stored, true)
+	create("twitter", "taken", write("twitter_taken.txt"), false)
+
+	editor := NewCookieEditor(db.AccountRepo)
+
+	// Renaming moves the stored file along
+	result, err := editor.Edit(ctx, id, EditOptions{Name: "main"})
+	if err != nil {
+		t.Fatalf("Edit() error: %v", err)
+	}
+	renamed := filepath.Join(dir, "twitter_main.txt")
+	if result.Account.Name != "main" || result.Account.CookiePath != renamed || result.RenamedFrom != stored {
+		t.Errorf("unexpected result after rename: %+v", result)
+	}
+	if result.PathChanged {
+		t.Error("a rename should not ask for re-validation")
+	}
+	if _, err := os.Stat(renamed); err != nil {
+		t.Errorf("renamed cookie file missing: %v", err)
+	}
+	if _, err := os.Stat(stored); !os.IsNotExist(err) {
+		t.Errorf("old cookie file should be gone, stat err = %v", err)
+	}
+
+	acc, err := db.AccountRepo.GetByID(ctx, id)
+	if err != nil {
+		t.Fatalf("GetByID() error: %v", err)
+	}
+	if acc.Name != "main" || acc.CookiePath != renamed || !acc.IsActive {
+		t.Errorf("account not updated in the database: %+v", acc)
+	}
+
+	// Names must stay unique per platform
+	if _, err := editor.Edit(ctx, id, EditOptions{Name: "taken"}); err == nil {
+		t.Error("Edit() to an existing name should fail")
+	}
+
+	// Pointing at another file keeps it in place and asks for re-validation
+	other := write("exported.txt")
+	result, err = editor.Edit(ctx, id, EditOptions{CookiePath: other})
+	if err != nil {
+		t.Fatalf("Edit() error: %v", err)
+	}
+	if !result.PathChanged || result.Account.CookiePath != other || result.RenamedFrom != "" {
+		t.Errorf("unexpected result after path change: %+v", result)
+	}
+
+	// A file that isn't a cookie file is rejected
+	bogus := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(bogus, []byte("not cookies"), 0600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if _, err := editor.Edit(ctx, id, EditOptions{CookiePath: bogus}); err == nil {
+		t.Error("Edit() with a non-cookie file should fail")
+	}
+
+	// Moving to another platform drops the active flag
+	result, err = editor.Edit(ctx, id, EditOptions{Platform: "x"})
+	if err != nil {
+		t.Fatalf("Edit() error: %v", err)
+	}
+	if result.Account.IsActive {
+		t.Error("account moved to another platform should not stay active")
+	}
+}
@@ -4,24 +4,32 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/elsanchez/smart-download/internal/repository"
 )
 
+// ExportFormats are the formats accepted by Export, in help order
+var ExportFormats = []string{FormatNetscape, FormatJSON}
+
 // CookieExporter handles exporting cookies from the database
 type CookieExporter struct {
+	parser      *CookieParser
 	accountRepo repository.AccountRepository
 }
 
 // NewCookieExporter creates a new cookie exporter
 func NewCookieExporter(accountRepo repository.AccountRepository) *CookieExporter {
 	return &CookieExporter{
+		parser:      NewCookieParser(),
 		accountRepo: accountRepo,
 	}
 }
 
-// Export exports a cookie file from the database to the specified path
-func (e *CookieExporter) Export(ctx context.Context, platform, name, outputPath string) error {
+// Export exports an account's cookie file to the specified path, in Netscape format (what
+// yt-dlp and gallery-dl read) or JSON (what browser extensions like Cookie-Editor import).
+// An empty format means Netscape
+func (e *CookieExporter) Export(ctx context.Context, platform, name, outputPath, format string) error {
 	// Get all accounts for platform
 	accounts, err := e.accountRepo.GetAll(ctx, platform)
 	if err != nil {
@@ -29,64 +37,70 @@ func (e *CookieExporter) Export(ctx context.Context, platform, name, outputPath
 	}
 
 	// Find account by name
-	var account *struct {
-		CookiePath string
-	}
-
 	for _, acc := range accounts {
 		if acc.Name == name {
-			account = &struct{ CookiePath string }{
-				CookiePath: acc.CookiePath,
-			}
-			break
+			return e.writeExport(acc.CookiePath, outputPath, format)
 		}
 	}
 
-	if account == nil {
-		return fmt.Errorf("account not found: %s/%s", platform, name)
-	}
-
-	// Check source cookie file exists
-	if _, err := os.Stat(account.CookiePath); os.IsNotExist(err) {
-		return fmt.Errorf("cookie file not found: %s", account.CookiePath)
-	}
-
-	// Read source file
-	data, err := os.ReadFile(account.CookiePath)
-	if err != nil {
-		return fmt.Errorf("read cookie file: %w", err)
-	}
-
-	// Write to output path
-	if err := os.WriteFile(outputPath, data, 0600); err != nil {
-		return fmt.Errorf("write output file: %w", err)
-	}
-
-	return nil
+	return fmt.Errorf("account not found: %s/%s", platform, name)
 }
 
 // ExportByID exports a cookie file by account ID
-func (e *CookieExporter) ExportByID(ctx context.Context, accountID int64, outputPath string) error {
+func (e *CookieExporter) ExportByID(ctx context.Context, accountID int64, outputPath, format string) error {
 	// Get account by ID
 	account, err := e.accountRepo.GetByID(ctx, accountID)
 	if err != nil {
 		return fmt.Errorf("get account: %w", err)
 	}
 
+	return e.writeExport(account.CookiePath, outputPath, format)
+}
+
+// writeExport writes the cookie file at cookiePath to outputPath in the requested format.
+// A Netscape file exported as Netscape is copied verbatim (comments included)
+func (e *CookieExporter) writeExport(cookiePath, outputPath, format string) error {
+	format = strings.ToLower(format)
+	if format == "" {
+		format = FormatNetscape
+	}
+	if format != FormatNetscape && format != FormatJSON {
+		return fmt.Errorf("invalid export format %q (use %s)", format, strings.Join(ExportFormats, " or "))
+	}
+
 	// Check source cookie file exists
-	if _, err := os.Stat(account.CookiePath); os.IsNotExist(err) {
-		return fmt.Errorf("cookie file not found: %s", account.CookiePath)
+	if _, err := os.Stat(cookiePath); os.IsNotExist(err) {
+		return fmt.Errorf("cookie file not found: %s", cookiePath)
 	}
 
 	// Read source file
-	data, err := os.ReadFile(account.CookiePath)
+	data, err := os.ReadFile(cookiePath)
 	if err != nil {
 		return fmt.Errorf("read cookie file: %w", err)
 	}
 
-	// Write to output path
-	if err := os.WriteFile(outputPath, data, 0600); err != nil {
-		return fmt.Errorf("write output file: %w", err)
+	if format == FormatNetscape && e.parser.DetectFormat(data) == FormatNetscape {
+		if err := os.WriteFile(outputPath, data, 0600); err != nil {
+			return fmt.Errorf("write output file: %w", err)
+		}
+	} else {
+		cookies, err := e.parser.Parse(data)
+		if err != nil {
+			return fmt.Errorf("parse cookie file: %w", err)
+		}
+
+		write := e.parser.WriteFile
+		if format == FormatJSON {
+			write = e.parser.WriteJSONFile
+		}
+		if err := write(outputPath, cookies); err != nil {
+			return fmt.Errorf("write output file: %w", err)
+		}
+	}
+
+	// WriteFile keeps the mode of an existing file: cookies are credentials
+	if err := os.Chmod(outputPath, 0600); err != nil {
+		return fmt.Errorf("set output file permissions: %w", err)
 	}
 
 	return nil
@@ -0,0 +1,87 @@
+package cookies
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/elsanchez/smart-download/internal/domain"
+	"github.com/elsanchez/smart-download/internal/repository/sqlite"
+)
+
+func TestCookieExporter_Export(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	db, err := sqlite.NewDatabase(dir)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	stored := filepath.Join(dir, "x_main.txt")
+	content := "# Netscape HTTP Cookie File\n" +
+		"#HttpOnly_.x.com\tTRUE\t/\tTRUE\t1893456000\tauth_token\tabc\n" +
+		"x.com\tFALSE\t/i\tFALSE\t0\tlang\ten\n"
+	if err := os.WriteFile(stored, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write cookie file: %v", err)
+	}
+	if _, err := db.AccountRepo.Create(ctx, &domain.Account{Platform: "x", Name: "main", CookiePath: stored}); err != nil {
+		t.Fatalf("failed to create account: %v", err)
+	}
+
+	exporter := NewCookieExporter(db.AccountRepo)
+	parser := NewCookieParser()
+
+	// Netscape is copied verbatim
+	netscape := filepath.Join(dir, "out.txt")
+	if err := exporter.Export(ctx, "x", "main", netscape, ""); err != nil {
+		t.Fatalf("Export() error: %v", err)
+	}
+	if data, _ := os.ReadFile(netscape); string(data) != content {
+		t.Errorf("netscape export = %q, want the stored file", data)
+	}
+
+	// JSON round-trips through the parser, and an existing file ends up 0600
+	jsonPath := filepath.Join(dir, "out.json")
+	if err := os.WriteFile(jsonPath, []byte("old"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := exporter.Export(ctx, "x", "main", jsonPath, FormatJSON); err != nil {
+		t.Fatalf("Export() error: %v", err)
+	}
+
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatalf("failed to read export: %v", err)
+	}
+	if parser.DetectFormat(data) != FormatJSON {
+		t.Fatalf("export is not JSON: %s", data)
+	}
+
+	want, _ := parser.ParseFile(stored)
+	got, err := parser.Parse(data)
+	if err != nil {
+		t.Fatalf("Parse() of the JSON export error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("JSON round-trip = %+v, want %+v", got, want)
+	}
+
+	info, err := os.Stat(jsonPath)
+	if err != nil {
+		t.Fatalf("failed to stat export: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("export permissions = %v, want 0600", info.Mode().Perm())
+	}
+
+	if err := exporter.Export(ctx, "x", "main", jsonPath, "csv"); err == nil {
+		t.Error("Export() with an unknown format should fail")
+	}
+	if err := exporter.Export(ctx, "x", "missing", jsonPath, ""); err == nil {
+		t.Error("Export() of a missing account should fail")
+	}
+}
@@ -3,8 +3,10 @@ package cookies
 import (
 	"context"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/elsanchez/smart-download/internal/domain"
 	"github.com/elsanchez/smart-download/internal/repository"
@@ -98,8 +100,7 @@ func (i *CookieImporter) Import(ctx context.Context, opts ImportOptions) (*domai
 	}
 
 	// Generate unique filename: platform_name.txt
-	cookieFileName := fmt.Sprintf("%s_%s.txt", platform, name)
-	cookiePath := filepath.Join(cookieDir, cookieFileName)
+	cookiePath := filepath.Join(cookieDir, storedFileName(platform, name))
 
 	// Copy file if source is different from destination
 	absFilePath, _ := filepath.Abs(opts.FilePath)
@@ -111,7 +112,13 @@ func (i *CookieImporter) Import(ctx context.Context, opts ImportOptions) (*domai
 			return nil, fmt.Errorf("read source cookie file: %w", err)
 		}
 
-		if err := os.WriteFile(cookiePath, sourceData, 0600); err != nil {
+		// yt-dlp and gallery-dl only read Netscape files, so JSON exports are converted
+		// (and Windows files lose their BOM and CRLF line endings)
+		if i.parser.DetectFormat(sourceData) == FormatJSON || i.parser.NeedsNormalizing(sourceData) {
+			if err := i.parser.WriteFile(cookiePath, cookies); err != nil {
+				return nil, fmt.Errorf("write cookie file: %w", err)
+			}
+		} else if err := os.WriteFile(cookiePath, sourceData, 0600); err != nil {
 			return nil, fmt.Errorf("write cookie file: %w", err)
 		}
 	}
@@ -199,3 +206,117 @@ func (i *CookieImporter) generateUniqueName(ctx context.Context, platform string
 
 	return "", fmt.Errorf("could not generate unique name after 1000 attempts")
 }
+
+// MergeConflict is a cookie that had different values in the merged files
+type MergeConflict struct {
+	Domain string
+	Name   string
+	Files  []string // Files with a differing value, in merge order
+	Kept   string   // File whose value was kept
+}
+
+// MergeResult contains the combined cookies of several files
+type MergeResult struct {
+	Cookies   []NetscapeCookie
+	Conflicts []MergeConflict
+}
+
+// Merge parses the cookie files in paths and combines them, deduplicating by (domain, name).
+// When a cookie appears more than once the one with the latest expiration is kept, counting
+// session cookies (expiration 0) as never expiring; the later file wins ties. Cookies whose
+// value differs between files are reported as conflicts
+func (i *CookieImporter) Merge(paths []string) (*MergeResult, error) {
+	if len(paths) < 2 {
+		return nil, fmt.Errorf("at least two cookie files are required to merge")
+	}
+
+	type entry struct {
+		cookie NetscapeCookie
+		file   string
+	}
+
+	var order []string // Keys in first-seen order, so the output is stable
+	entries := make(map[string]*entry)
+	conflicts := make(map[string]*MergeConflict)
+
+	for _, path := range paths {
+		cookies, err := i.parser.ParseFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", path, err)
+		}
+
+		for _, cookie := range cookies {
+			// ".x.com" and "x.com" are the same cookie once exported for yt-dlp/gallery-dl
+			key := strings.TrimPrefix(cookie.Domain, ".") + "\t" + cookie.Name
+
+			existing, ok := entries[key]
+			if !ok {
+				order = append(order, key)
+				entries[key] = &entry{cookie: cookie, file: path}
+				continue
+			}
+
+			if existing.cookie.Value != cookie.Value {
+				conflict, ok := conflicts[key]
+				if !ok {
+					conflict = &MergeConflict{Domain: cookie.Domain, Name: cookie.Name, Files: []string{existing.file}}
+					conflicts[key] = conflict
+				}
+				conflict.Files = append(conflict.Files, path)
+			}
+
+			if effectiveExpiration(cookie) >= effectiveExpiration(existing.cookie) {
+				entries[key] = &entry{cookie: cookie, file: path}
+			}
+		}
+	}
+
+	result := &MergeResult{Cookies: make([]NetscapeCookie, 0, len(order))}
+	for _, key := range order {
+		result.Cookies = append(result.Cookies, entries[key].cookie)
+
+		if conflict, ok := conflicts[key]; ok {
+			conflict.Kept = entries[key].file
+			result.Conflicts = append(result.Conflicts, *conflict)
+		}
+	}
+
+	return result, nil
+}
+
+// effectiveExpiration returns the cookie's expiration for comparing merge candidates:
+// session cookies (0) last until the browser closes, so they rank above any timestamp
+func effectiveExpiration(cookie NetscapeCookie) int64 {
+	if cookie.Expiration == 0 {
+		return math.MaxInt64
+	}
+	return cookie.Expiration
+}
+
+// ImportMerged merges the cookie files in paths and imports the combined file as one account
+func (i *CookieImporter) ImportMerged(ctx context.Context, paths []string, opts ImportOptions) (*domain.Account, *MergeResult, error) {
+	merged, err := i.Merge(paths)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tmp, err := os.CreateTemp("", "smd-merged-*.txt")
+	if err != nil {
+		return nil, nil, fmt.Errorf("create temp file: %w", err)
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	if err := i.parser.WriteFile(tmp.Name(), merged.Cookies); err != nil {
+		return nil, nil, fmt.Errorf("write merged cookie file: %w", err)
+	}
+
+	// Import copies the merged file to the cookies dir as the account's cookie source
+	opts.FilePath = tmp.Name()
+	account, err := i.Import(ctx, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return account, merged, nil
+}
@@ -0,0 +1,96 @@
+package cookies
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	dir := t.TempDir()
+
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("# Netscape HTTP Cookie File\n"+content), 0600); err != nil {
+			t.Fatalf("failed to write cookie file: %v", err)
+		}
+		return path
+	}
+
+	auth := write("auth.txt",
+		".x.com\tTRUE\t/\tTRUE\t1893456000\tauth_token\tabc\n"+
+			".x.com\tTRUE\t/\tTRUE\t1700000000\tct0\told\n")
+	session := write("session.txt",
+		"x.com\tFALSE\t/\tTRUE\t1900000000\tct0\tnew\n"+
+			".x.com\tTRUE\t/\tTRUE\t1893456000\tauth_token\tabc\n"+
+			".x.com\tTRUE\t/\tFALSE\t0\tlang\ten\n")
+
+	importer := NewCookieImporter(nil)
+	result, err := importer.Merge([]string{auth, session})
+	if err != nil {
+		t.Fatalf("Merge() error: %v", err)
+	}
+
+	// Deduplicated by (domain, name), in first-seen order
+	if len(result.Cookies) != 3 {
+		t.Fatalf("expected 3 cookies, got %d: %+v", len(result.Cookies), result.Cookies)
+	}
+	if result.Cookies[0].Name != "auth_token" || result.Cookies[1].Name != "ct0" || result.Cookies[2].Name != "lang" {
+		t.Errorf("unexpected order: %+v", result.Cookies)
+	}
+
+	// Latest expiration wins
+	if ct0 := result.Cookies[1]; ct0.Value != "new" || ct0.Expiration != 1900000000 {
+		t.Errorf("ct0 = %+v, want the value with the latest expiration", ct0)
+	}
+
+	// Only ct0 had differing values
+	if len(result.Conflicts) != 1 {
+		t.Fatalf("expected 1 conflict, got %+v", result.Conflicts)
+	}
+	conflict := result.Conflicts[0]
+	if conflict.Name != "ct0" || len(conflict.Files) != 2 || conflict.Kept != session {
+		t.Errorf("unexpected conflict: %+v", conflict)
+	}
+
+	if _, err := importer.Merge([]string{auth}); err == nil {
+		t.Error("Merge() with a single file should fail")
+	}
+}
+
+func TestMerge_SessionCookies(t *testing.T) {
+	dir := t.TempDir()
+
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("# Netscape HTTP Cookie File\n"+content), 0600); err != nil {
+			t.Fatalf("failed to write cookie file: %v", err)
+		}
+		return path
+	}
+
+	// The session cookie comes first: it must win over the dated one despite the file order
+	browser := write("browser.txt",
+		".x.com\tTRUE\t/\tTRUE\t0\tsid\tlive\n"+
+			".x.com\tTRUE\t/\tTRUE\t0\tlang\ten\n")
+	export := write("export.txt",
+		".x.com\tTRUE\t/\tTRUE\t1900000000\tsid\tstale\n"+
+			".x.com\tTRUE\t/\tTRUE\t0\tlang\tes\n")
+
+	result, err := NewCookieImporter(nil).Merge([]string{browser, export})
+	if err != nil {
+		t.Fatalf("Merge() error: %v", err)
+	}
+
+	values := make(map[string]string)
+	for _, cookie := range result.Cookies {
+		values[cookie.Name] = cookie.Value
+	}
+	if values["sid"] != "live" {
+		t.Errorf("sid = %q, want the session cookie (never expires)", values["sid"])
+	}
+	// Two session cookies tie: the later file wins
+	if values["lang"] != "es" {
+		t.Errorf("lang = %q, want the value from the last file", values["lang"])
+	}
+}
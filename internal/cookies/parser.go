@@ -2,13 +2,29 @@ package cookies
 
 import (
 	"bufio"
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 )
 
+// httpOnlyPrefix marks HttpOnly cookies in Netscape files
+const httpOnlyPrefix = "#HttpOnly_"
+
+// utf8BOM starts some files saved on Windows (e.g. by Notepad)
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// Cookie file formats accepted by ParseFile
+const (
+	FormatNetscape = "netscape"
+	FormatJSON     = "json"
+)
+
 // NetscapeCookie represents a single cookie from Netscape format
 type NetscapeCookie struct {
 	Domain     string
@@ -18,6 +34,7 @@ type NetscapeCookie struct {
 	Expiration int64  // Unix timestamp
 	Name       string
 	Value      string
+	HttpOnly   bool   // Written as a "#HttpOnly_" domain prefix (curl, yt-dlp)
 }
 
 // CookieParser handles parsing of Netscape cookie format files
@@ -28,22 +45,56 @@ func NewCookieParser() *CookieParser {
 	return &CookieParser{}
 }
 
-// ParseFile parses a Netscape format cookie file
-// Format: domain	flag	path	secure	expiration	name	value
+// ParseFile parses a cookie file in Netscape or JSON (browser extension export) format.
+// The format is detected from the content
 func (p *CookieParser) ParseFile(path string) ([]NetscapeCookie, error) {
-	file, err := os.Open(path)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("open cookie file: %w", err)
 	}
-	defer file.Close()
 
+	return p.Parse(data)
+}
+
+// Parse parses cookie data in Netscape or JSON format. A leading UTF-8 BOM and CRLF line
+// endings (files exported on Windows) are accepted
+func (p *CookieParser) Parse(data []byte) ([]NetscapeCookie, error) {
+	data = bytes.TrimPrefix(data, utf8BOM)
+	if p.DetectFormat(data) == FormatJSON {
+		return p.parseJSON(data)
+	}
+
+	return p.parseNetscape(bytes.NewReader(data))
+}
+
+// DetectFormat returns FormatJSON if the data looks like a JSON export
+// (leading '[' or '{'), FormatNetscape otherwise
+func (p *CookieParser) DetectFormat(data []byte) string {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, utf8BOM))
+	if len(trimmed) > 0 && (trimmed[0] == '[' || trimmed[0] == '{') {
+		return FormatJSON
+	}
+
+	return FormatNetscape
+}
+
+// parseNetscape parses the Netscape tab-separated format
+// Format: domain	flag	path	secure	expiration	name	value
+func (p *CookieParser) parseNetscape(r io.Reader) ([]NetscapeCookie, error) {
 	var cookies []NetscapeCookie
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(r)
 	lineNum := 0
 
 	for scanner.Scan() {
 		lineNum++
-		line := scanner.Text()
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+
+		// HttpOnly cookies are prefixed with "#HttpOnly_" and would otherwise look like comments
+		httpOnly := false
+		if strings.HasPrefix(line, httpOnlyPrefix) {
+			line = strings.TrimPrefix(line, httpOnlyPrefix)
+			httpOnly = true
+		}
 
 		// Skip comments and empty lines
 		if strings.HasPrefix(line, "#") || strings.TrimSpace(line) == "" {
@@ -83,6 +134,7 @@ func (p *CookieParser) ParseFile(path string) ([]NetscapeCookie, error) {
 			Expiration: expiration,
 			Name:       fields[5],
 			Value:      value,
+			HttpOnly:   httpOnly,
 		}
 
 		cookies = append(cookies, cookie)
@@ -99,6 +151,161 @@ func (p *CookieParser) ParseFile(path string) ([]NetscapeCookie, error) {
 	return cookies, nil
 }
 
+// jsonCookie is a cookie as exported by browser extensions (Cookie-Editor, EditThisCookie)
+// or Playwright/Puppeteer ("expires" instead of "expirationDate")
+type jsonCookie struct {
+	Name           string   `json:"name"`
+	Value          string   `json:"value"`
+	Domain         string   `json:"domain"`
+	Path           string   `json:"path"`
+	Secure         bool     `json:"secure"`
+	HttpOnly       bool     `json:"httpOnly"`
+	HostOnly       bool     `json:"hostOnly"`
+	ExpirationDate *float64 `json:"expirationDate,omitempty"`
+	Expires        *float64 `json:"expires,omitempty"`
+}
+
+// parseJSON parses a JSON cookie export: either an array of cookies or
+// an object with a "cookies" array
+func (p *CookieParser) parseJSON(data []byte) ([]NetscapeCookie, error) {
+	var entries []jsonCookie
+
+	trimmed := bytes.TrimSpace(data)
+	if trimmed[0] == '{' {
+		var wrapper struct {
+			Cookies []jsonCookie `json:"cookies"`
+		}
+		if err := json.Unmarshal(trimmed, &wrapper); err != nil {
+			return nil, fmt.Errorf("invalid JSON cookie file: %w", err)
+		}
+		entries = wrapper.Cookies
+	} else if err := json.Unmarshal(trimmed, &entries); err != nil {
+		return nil, fmt.Errorf("invalid JSON cookie file: %w", err)
+	}
+
+	cookies := make([]NetscapeCookie, 0, len(entries))
+	for i, entry := range entries {
+		if entry.Name == "" || entry.Domain == "" {
+			return nil, fmt.Errorf("cookie %d: missing name or domain", i+1)
+		}
+
+		// Session cookies have no expiration (0, as in Netscape files)
+		var expiration int64
+		if entry.ExpirationDate != nil {
+			expiration = int64(*entry.ExpirationDate)
+		} else if entry.Expires != nil && *entry.Expires > 0 {
+			expiration = int64(*entry.Expires)
+		}
+
+		// Netscape flag: TRUE when the cookie applies to subdomains
+		domain := entry.Domain
+		flag := "FALSE"
+		if !entry.HostOnly {
+			flag = "TRUE"
+			if !strings.HasPrefix(domain, ".") {
+				domain = "." + domain
+			}
+		}
+
+		path := entry.Path
+		if path == "" {
+			path = "/"
+		}
+
+		cookies = append(cookies, NetscapeCookie{
+			Domain:     domain,
+			Flag:       flag,
+			Path:       path,
+			Secure:     entry.Secure,
+			Expiration: expiration,
+			Name:       entry.Name,
+			Value:      entry.Value,
+			HttpOnly:   entry.HttpOnly,
+		})
+	}
+
+	if len(cookies) == 0 {
+		return nil, fmt.Errorf("no valid cookies found in file")
+	}
+
+	return cookies, nil
+}
+
+// WriteFile writes cookies to path in Netscape format
+func (p *CookieParser) WriteFile(path string, cookies []NetscapeCookie) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("create file: %w", err)
+	}
+	defer file.Close()
+
+	// Write Netscape cookie file header
+	if _, err := file.WriteString("# Netscape HTTP Cookie File\n"); err != nil {
+		return fmt.Errorf("write header: %w", err)
+	}
+
+	// Write cookies
+	for _, cookie := range cookies {
+		secure := "FALSE"
+		if cookie.Secure {
+			secure = "TRUE"
+		}
+
+		domain := cookie.Domain
+		if cookie.HttpOnly {
+			domain = httpOnlyPrefix + domain
+		}
+
+		line := fmt.Sprintf("%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			domain,
+			cookie.Flag,
+			cookie.Path,
+			secure,
+			cookie.Expiration,
+			cookie.Name,
+			cookie.Value,
+		)
+
+		if _, err := file.WriteString(line); err != nil {
+			return fmt.Errorf("write cookie: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// WriteJSONFile writes cookies to path as a JSON array in the format of browser extensions
+// such as Cookie-Editor, which parseJSON reads back. Session cookies have no expirationDate
+func (p *CookieParser) WriteJSONFile(path string, cookies []NetscapeCookie) error {
+	entries := make([]jsonCookie, 0, len(cookies))
+	for _, cookie := range cookies {
+		entry := jsonCookie{
+			Name:     cookie.Name,
+			Value:    cookie.Value,
+			Domain:   cookie.Domain,
+			Path:     cookie.Path,
+			Secure:   cookie.Secure,
+			HttpOnly: cookie.HttpOnly,
+			HostOnly: cookie.Flag != "TRUE",
+		}
+		if cookie.Expiration > 0 {
+			expiration := float64(cookie.Expiration)
+			entry.ExpirationDate = &expiration
+		}
+		entries = append(entries, entry)
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode cookies: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("write file: %w", err)
+	}
+	return nil
+}
+
 // FindEarliestExpiration returns the earliest expiration time from a list of cookies
 func (p *CookieParser) FindEarliestExpiration(cookies []NetscapeCookie) time.Time {
 	if len(cookies) == 0 {
@@ -115,6 +322,13 @@ func (p *CookieParser) FindEarliestExpiration(cookies []NetscapeCookie) time.Tim
 	return time.Unix(earliest, 0)
 }
 
+// NeedsNormalizing reports whether a Netscape file has a UTF-8 BOM or CRLF line endings,
+// which this parser accepts but yt-dlp and gallery-dl may not: such files are rewritten
+// with WriteFile on import
+func (p *CookieParser) NeedsNormalizing(data []byte) bool {
+	return bytes.HasPrefix(data, utf8BOM) || bytes.Contains(data, []byte("\r\n"))
+}
+
 // DetectPlatform attempts to detect the platform from cookie domains
 func (p *CookieParser) DetectPlatform(cookies []NetscapeCookie) string {
 	if len(cookies) == 0 {
@@ -142,6 +356,11 @@ func (p *CookieParser) DetectPlatform(cookies []NetscapeCookie) string {
 		"reddit.com":         "reddit",
 		"subscribestar.com":  "subscribestar",
 		"subscribestar.adult": "subscribestar",
+		"vimeo.com":          "vimeo",
+		"twitch.tv":          "twitch",
+		"dailymotion.com":    "dailymotion",
+		"imgur.com":          "imgur",
+		"deviantart.com":     "deviantart",
 	}
 
 	// Find most common matching domain
@@ -165,18 +384,25 @@ func (p *CookieParser) CountCookies(cookies []NetscapeCookie) int {
 	return len(cookies)
 }
 
-// GetDomains returns a list of unique domains in the cookies
+// GetDomains returns the unique domains in the cookies, the ones with the most cookies first
+// (ties in alphabetical order)
 func (p *CookieParser) GetDomains(cookies []NetscapeCookie) []string {
-	domainSet := make(map[string]bool)
+	counts := make(map[string]int)
 	for _, cookie := range cookies {
-		domain := strings.TrimPrefix(cookie.Domain, ".")
-		domainSet[domain] = true
+		counts[strings.TrimPrefix(cookie.Domain, ".")]++
 	}
 
-	domains := make([]string, 0, len(domainSet))
-	for domain := range domainSet {
+	domains := make([]string, 0, len(counts))
+	for domain := range counts {
 		domains = append(domains, domain)
 	}
 
+	sort.Slice(domains, func(i, j int) bool {
+		if counts[domains[i]] != counts[domains[j]] {
+			return counts[domains[i]] > counts[domains[j]]
+		}
+		return domains[i] < domains[j]
+	})
+
 	return domains
 }
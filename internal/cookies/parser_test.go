@@ -0,0 +1,260 @@
+package cookies
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseFile_JSONExport(t *testing.T) {
+	p := NewCookieParser()
+
+	cookies, err := p.ParseFile(filepath.Join("testdata", "cookie_editor.json"))
+	if err != nil {
+		t.Fatalf("ParseFile() error: %v", err)
+	}
+
+	if len(cookies) != 3 {
+		t.Fatalf("expected 3 cookies, got %d", len(cookies))
+	}
+
+	auth := cookies[0]
+	if auth.Name != "auth_token" || auth.Value != "0123456789abcdef" {
+		t.Errorf("unexpected cookie: %+v", auth)
+	}
+	if auth.Domain != ".x.com" || auth.Flag != "TRUE" || auth.Path != "/" || !auth.Secure {
+		t.Errorf("unexpected cookie attributes: %+v", auth)
+	}
+	if auth.Expiration != 1893456000 {
+		t.Errorf("expected expiration 1893456000, got %d", auth.Expiration)
+	}
+
+	// Host-only session cookie
+	lang := cookies[2]
+	if lang.Domain != "x.com" || lang.Flag != "FALSE" || lang.Expiration != 0 {
+		t.Errorf("unexpected host-only session cookie: %+v", lang)
+	}
+
+	if platform := p.DetectPlatform(cookies); platform != "twitter" {
+		t.Errorf("DetectPlatform() = %q, want twitter", platform)
+	}
+}
+
+func TestParse_JSONWrapper(t *testing.T) {
+	p := NewCookieParser()
+
+	// Playwright storageState: object with "cookies", "expires" of -1 means session
+	data := []byte(`{"cookies": [
+		{"name": "sessionid", "value": "abc", "domain": ".instagram.com", "path": "/", "expires": 1893456000, "secure": true},
+		{"name": "csrftoken", "value": "def", "domain": ".instagram.com", "path": "/", "expires": -1, "secure": true}
+	], "origins": []}`)
+
+	cookies, err := p.Parse(data)
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	if len(cookies) != 2 {
+		t.Fatalf("expected 2 cookies, got %d", len(cookies))
+	}
+	if cookies[0].Expiration != 1893456000 || cookies[1].Expiration != 0 {
+		t.Errorf("unexpected expirations: %d, %d", cookies[0].Expiration, cookies[1].Expiration)
+	}
+	if platform := p.DetectPlatform(cookies); platform != "instagram" {
+		t.Errorf("DetectPlatform() = %q, want instagram", platform)
+	}
+}
+
+func TestParse_Netscape(t *testing.T) {
+	p := NewCookieParser()
+
+	data := []byte("# Netscape HTTP Cookie File\n" +
+		".youtube.com\tTRUE\t/\tTRUE\t1893456000\tSID\tvalue1\n" +
+		".youtube.com\tTRUE\t/\tFALSE\t1893456000\tPREF\t\"quoted\"\n")
+
+	cookies, err := p.Parse(data)
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	if len(cookies) != 2 {
+		t.Fatalf("expected 2 cookies, got %d", len(cookies))
+	}
+	if cookies[1].Value != "quoted" {
+		t.Errorf("expected quotes to be stripped, got %q", cookies[1].Value)
+	}
+	if platform := p.DetectPlatform(cookies); platform != "youtube" {
+		t.Errorf("DetectPlatform() = %q, want youtube", platform)
+	}
+}
+
+func TestParse_InvalidJSON(t *testing.T) {
+	p := NewCookieParser()
+
+	for _, data := range []string{`[{"name": "x"`, `[{"value": "no name"}]`, `[]`} {
+		if _, err := p.Parse([]byte(data)); err == nil {
+			t.Errorf("Parse(%q) expected error", data)
+		}
+	}
+}
+
+func TestWriteFile_RoundTrip(t *testing.T) {
+	p := NewCookieParser()
+
+	cookies, err := p.ParseFile(filepath.Join("testdata", "cookie_editor.json"))
+	if err != nil {
+		t.Fatalf("ParseFile() error: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "cookies.txt")
+	if err := p.WriteFile(path, cookies); err != nil {
+		t.Fatalf("WriteFile() error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	if p.DetectFormat(data) != FormatNetscape {
+		t.Fatalf("expected Netscape output")
+	}
+
+	reparsed, err := p.ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile() on written file error: %v", err)
+	}
+	if len(reparsed) != len(cookies) {
+		t.Fatalf("expected %d cookies after round trip, got %d", len(cookies), len(reparsed))
+	}
+	for i := range cookies {
+		if reparsed[i] != cookies[i] {
+			t.Errorf("cookie %d changed after round trip: %+v != %+v", i, reparsed[i], cookies[i])
+		}
+	}
+}
+
+func TestParse_HttpOnlyPrefix(t *testing.T) {
+	p := NewCookieParser()
+
+	// As written by yt-dlp --cookies-from-browser and curl
+	data := []byte("# Netscape HTTP Cookie File\n" +
+		"# This is a generated file! Do not edit.\n" +
+		"#HttpOnly_.x.com\tTRUE\t/\tTRUE\t1893456000\tauth_token\tsecret\n" +
+		".x.com\tTRUE\t/\tTRUE\t1893456000\tct0\tcsrf\n")
+
+	cookies, err := p.Parse(data)
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	if len(cookies) != 2 {
+		t.Fatalf("expected 2 cookies (comments skipped), got %d", len(cookies))
+	}
+
+	auth := cookies[0]
+	if auth.Name != "auth_token" || auth.Domain != ".x.com" || !auth.HttpOnly {
+		t.Errorf("unexpected HttpOnly cookie: %+v", auth)
+	}
+	if cookies[1].HttpOnly {
+		t.Errorf("ct0 should not be HttpOnly")
+	}
+
+	if platform := p.DetectPlatform(cookies); platform != "twitter" {
+		t.Errorf("DetectPlatform() = %q, want twitter", platform)
+	}
+
+	// The prefix is preserved when writing the file back
+	path := filepath.Join(t.TempDir(), "cookies.txt")
+	if err := p.WriteFile(path, cookies); err != nil {
+		t.Fatalf("WriteFile() error: %v", err)
+	}
+	written, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	if !strings.Contains(string(written), "#HttpOnly_.x.com\tTRUE") {
+		t.Errorf("expected #HttpOnly_ prefix in output:\n%s", written)
+	}
+}
+
+func TestGetDomains(t *testing.T) {
+	p := NewCookieParser()
+
+	cookies := []NetscapeCookie{
+		{Domain: ".google.com", Name: "NID"},
+		{Domain: ".instagram.com", Name: "sessionid"},
+		{Domain: "www.instagram.com", Name: "csrftoken"},
+		{Domain: "instagram.com", Name: "ds_user_id"},
+		{Domain: ".facebook.com", Name: "c_user"},
+		{Domain: ".instagram.com", Name: "mid"},
+	}
+
+	got := p.GetDomains(cookies)
+	want := []string{"instagram.com", "facebook.com", "google.com", "www.instagram.com"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("GetDomains() = %v, want %v", got, want)
+	}
+
+	if got := p.GetDomains(nil); len(got) != 0 {
+		t.Errorf("GetDomains(nil) = %v, want empty", got)
+	}
+}
+
+func TestParse_WindowsLineEndings(t *testing.T) {
+	p := NewCookieParser()
+
+	netscape := "# Netscape HTTP Cookie File\r\n" +
+		"\r\n" +
+		".x.com\tTRUE\t/\tTRUE\t1893456000\tauth_token\tabc\r\n" +
+		"#HttpOnly_.x.com\tTRUE\t/\tTRUE\t1893456000\tct0\t\"csrf\"\r\n"
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"CRLF", netscape},
+		{"BOM and CRLF", "\xef\xbb\xbf" + netscape},
+		{"BOM before a cookie line", "\xef\xbb\xbf.x.com\tTRUE\t/\tTRUE\t1893456000\tauth_token\tabc\n" +
+			"#HttpOnly_.x.com\tTRUE\t/\tTRUE\t1893456000\tct0\tcsrf\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cookies, err := p.Parse([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("Parse() error: %v", err)
+			}
+			if len(cookies) != 2 {
+				t.Fatalf("expected 2 cookies, got %d: %+v", len(cookies), cookies)
+			}
+			if cookies[0].Domain != ".x.com" || cookies[0].Value != "abc" {
+				t.Errorf("first cookie = %+v, want .x.com auth_token=abc", cookies[0])
+			}
+			if !cookies[1].HttpOnly || cookies[1].Value != "csrf" {
+				t.Errorf("second cookie = %+v, want HttpOnly ct0=csrf", cookies[1])
+			}
+			if !p.NeedsNormalizing([]byte(tt.data)) {
+				t.Error("NeedsNormalizing() = false, want true")
+			}
+		})
+	}
+
+	// A BOM'd JSON export is still detected as JSON
+	path := filepath.Join(t.TempDir(), "cookies.json")
+	data := "\xef\xbb\xbf[{\"name\":\"sessionid\",\"value\":\"abc\",\"domain\":\".instagram.com\"}]\r\n"
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatalf("failed to write cookie file: %v", err)
+	}
+	cookies, err := p.ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile() error: %v", err)
+	}
+	if len(cookies) != 1 || cookies[0].Value != "abc" {
+		t.Errorf("unexpected cookies: %+v", cookies)
+	}
+
+	if p.NeedsNormalizing([]byte("# Netscape HTTP Cookie File\n.x.com\tTRUE\t/\tTRUE\t0\ta\tb\n")) {
+		t.Error("NeedsNormalizing() = true for a Unix file")
+	}
+}
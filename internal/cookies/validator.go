@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
@@ -18,19 +19,69 @@ type ValidationResult struct {
 	ExpiresAt *time.Time
 }
 
+// DefaultHTTPEndpoints are pages or API endpoints that only succeed with a logged-in session.
+// Endpoints that redirect to a login page when logged out are fine: the redirect is detected
+var DefaultHTTPEndpoints = map[string]string{
+	domain.PlatformTwitter:       "https://api.twitter.com/1.1/account/verify_credentials.json",
+	domain.PlatformInstagram:     "https://i.instagram.com/api/v1/users/web_profile_info/",
+	domain.PlatformPixiv:         "https://www.pixiv.net/ajax/user/self",
+	domain.PlatformYouTube:       "https://www.youtube.com/feed/account",
+	domain.PlatformFanbox:        "https://api.fanbox.cc/user.me",
+	domain.PlatformFantia:        "https://fantia.jp/api/v1/me",
+	domain.PlatformDiscord:       "https://discord.com/api/v9/users/@me",
+	domain.PlatformTikTok:        "https://www.tiktok.com/api/user/detail/",
+	domain.PlatformReddit:        "https://oauth.reddit.com/api/v1/me",
+	domain.PlatformSubscribeStar: "https://www.subscribestar.com/api/graphql/user",
+	domain.PlatformVimeo:         "https://vimeo.com/settings",
+	domain.PlatformImgur:         "https://imgur.com/account/settings",
+	domain.PlatformDeviantArt:    "https://www.deviantart.com/settings/general",
+	"facebook":                   "https://www.facebook.com/me",
+}
+
+// unsupportedHTTPPlatforms explains why some known platforms can't be checked over HTTP
+var unsupportedHTTPPlatforms = map[string]string{
+	// The web app is rendered client-side (every page returns 200) and the API only
+	// accepts the auth-token cookie as an "Authorization: OAuth" header
+	domain.PlatformTwitch: "Twitch pages are client-rendered and its API needs an OAuth header, not cookies",
+	// Same situation: a client-rendered app backed by an API that takes bearer tokens
+	domain.PlatformDailymotion: "Dailymotion pages are client-rendered and its API needs a bearer token, not cookies",
+}
+
+// loginPathMarkers identify the login page a session-less request gets redirected to
+var loginPathMarkers = []string{"login", "log_in", "signin", "sign_in"}
+
 // CookieValidator handles validation of cookies
 type CookieValidator struct {
 	parser     *CookieParser
 	httpClient *http.Client
+	endpoints  map[string]string
 }
 
 // NewCookieValidator creates a new cookie validator
 func NewCookieValidator() *CookieValidator {
+	endpoints := make(map[string]string, len(DefaultHTTPEndpoints))
+	for platform, endpoint := range DefaultHTTPEndpoints {
+		endpoints[platform] = endpoint
+	}
+
 	return &CookieValidator{
 		parser: NewCookieParser(),
 		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
+		endpoints: endpoints,
+	}
+}
+
+// SetEndpoints overrides or adds HTTP validation endpoints per platform.
+// An empty URL disables HTTP validation for that platform
+func (v *CookieValidator) SetEndpoints(overrides map[string]string) {
+	for platform, endpoint := range overrides {
+		if endpoint == "" {
+			delete(v.endpoints, platform)
+			continue
+		}
+		v.endpoints[platform] = endpoint
 	}
 }
 
@@ -109,23 +160,16 @@ func (v *CookieValidator) ValidateExpiration(cookies []NetscapeCookie) *Validati
 // ValidateHTTP performs HTTP validation by making a test request to the platform
 // This is optional and platform-specific
 func (v *CookieValidator) ValidateHTTP(ctx context.Context, platform string, cookiePath string) (*ValidationResult, error) {
-	// Platform-specific test endpoints that require authentication
-	testEndpoints := map[string]string{
-		domain.PlatformTwitter:       "https://api.twitter.com/1.1/account/verify_credentials.json",
-		domain.PlatformInstagram:     "https://i.instagram.com/api/v1/users/web_profile_info/",
-		domain.PlatformPixiv:         "https://www.pixiv.net/ajax/user/self",
-		domain.PlatformYouTube:       "https://www.youtube.com/feed/account",
-		domain.PlatformFanbox:        "https://api.fanbox.cc/user.me",
-		domain.PlatformFantia:        "https://fantia.jp/api/v1/me",
-		domain.PlatformDiscord:       "https://discord.com/api/v9/users/@me",
-		domain.PlatformTikTok:        "https://www.tiktok.com/api/user/detail/",
-		domain.PlatformReddit:        "https://oauth.reddit.com/api/v1/me",
-		domain.PlatformSubscribeStar: "https://www.subscribestar.com/api/graphql/user",
-		"facebook":                   "https://www.facebook.com/me",
-	}
-
-	endpoint, ok := testEndpoints[platform]
+	endpoint, ok := v.endpoints[platform]
 	if !ok {
+		if reason, known := unsupportedHTTPPlatforms[platform]; known {
+			return &ValidationResult{
+				IsValid: false,
+				Status:  domain.ValidationStatusUnknown,
+				Message: fmt.Sprintf("HTTP validation not supported: %s", reason),
+			}, nil
+		}
+
 		return &ValidationResult{
 			IsValid: false,
 			Status:  domain.ValidationStatusUnknown,
@@ -133,6 +177,12 @@ func (v *CookieValidator) ValidateHTTP(ctx context.Context, platform string, coo
 		}, nil
 	}
 
+	return v.validateEndpoint(ctx, endpoint, cookiePath)
+}
+
+// validateEndpoint requests endpoint with the cookies from cookiePath and
+// interprets the response status
+func (v *CookieValidator) validateEndpoint(ctx context.Context, endpoint string, cookiePath string) (*ValidationResult, error) {
 	// Create request
 	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
 	if err != nil {
@@ -150,20 +200,8 @@ func (v *CookieValidator) ValidateHTTP(ctx context.Context, platform string, coo
 	}
 
 	// Add cookies to request
-	// Skip cookies with invalid characters (backslashes, quotes, etc.)
-	for _, cookie := range cookies {
-		// Skip cookies with problematic characters that Go's net/http doesn't accept
-		if strings.ContainsAny(cookie.Value, "\\\"") {
-			continue
-		}
-
-		req.AddCookie(&http.Cookie{
-			Name:   cookie.Name,
-			Value:  cookie.Value,
-			Domain: cookie.Domain,
-			Path:   cookie.Path,
-			Secure: cookie.Secure,
-		})
+	if header := buildCookieHeader(cookies); header != "" {
+		req.Header.Set("Cookie", header)
 	}
 
 	// Make request
@@ -177,6 +215,15 @@ func (v *CookieValidator) ValidateHTTP(ctx context.Context, platform string, coo
 	}
 	defer resp.Body.Close()
 
+	// Logged-out sessions on web pages end up on the login page (with a 200)
+	if resp.Request != nil && isLoginPage(resp.Request.URL) {
+		return &ValidationResult{
+			IsValid: false,
+			Status:  domain.ValidationStatusInvalid,
+			Message: "authentication failed (redirected to login page)",
+		}, nil
+	}
+
 	// Check response status
 	if resp.StatusCode == http.StatusOK {
 		return &ValidationResult{
@@ -201,6 +248,38 @@ func (v *CookieValidator) ValidateHTTP(ctx context.Context, platform string, coo
 	}, nil
 }
 
+// isLoginPage reports whether u looks like a login page
+func isLoginPage(u *url.URL) bool {
+	if u == nil {
+		return false
+	}
+
+	path := strings.ToLower(u.Path)
+	for _, marker := range loginPathMarkers {
+		if strings.Contains(path, marker) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// buildCookieHeader joins cookies into a Cookie header value. The header is built by hand
+// because req.AddCookie drops values containing characters like '\' or '"', which
+// platforms such as Twitter use in auth tokens. Only CR/LF are skipped, since they
+// would break the request
+func buildCookieHeader(cookies []NetscapeCookie) string {
+	pairs := make([]string, 0, len(cookies))
+	for _, cookie := range cookies {
+		if strings.ContainsAny(cookie.Name+cookie.Value, "\r\n") {
+			continue
+		}
+		pairs = append(pairs, cookie.Name+"="+cookie.Value)
+	}
+
+	return strings.Join(pairs, "; ")
+}
+
 // ValidateAccount validates an account's cookies by checking expiration timestamps
 func (v *CookieValidator) ValidateAccount(account *domain.Account) (*ValidationResult, error) {
 	return v.ValidateFile(account.CookiePath)
@@ -215,3 +294,32 @@ func (v *CookieValidator) ValidateAccountHTTP(ctx context.Context, account *doma
 	// The HTTP request will tell us if the cookies actually work
 	return v.ValidateHTTP(ctx, account.Platform, account.CookiePath)
 }
+
+// DescribeValidationAge returns a short human description of when an account was last
+// validated, like "validated 3h ago" or "never validated"
+func DescribeValidationAge(account *domain.Account, now time.Time) string {
+	age, ok := account.ValidationAge(now)
+	if !ok {
+		return "never validated"
+	}
+
+	if age < time.Minute {
+		return "validated just now"
+	}
+	return "validated " + FormatAge(age) + " ago"
+}
+
+// FormatAge formats a duration in its largest whole unit for display, like "45m", "24h"
+// or "8d" (up to two days it stays in hours)
+func FormatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d/time.Second))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	default:
+		return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	}
+}
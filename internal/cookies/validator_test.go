@@ -0,0 +1,164 @@
+package cookies
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elsanchez/smart-download/internal/domain"
+)
+
+func TestValidateEndpoint_SpecialCharacters(t *testing.T) {
+	var gotHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Get("Cookie")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "cookies.txt")
+	content := "# Netscape HTTP Cookie File\n" +
+		".x.com\tTRUE\t/\tTRUE\t1893456000\tauth_token\tabc\\def\n" +
+		".x.com\tTRUE\t/\tTRUE\t1893456000\tct0\tcsrf\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write cookie file: %v", err)
+	}
+
+	v := NewCookieValidator()
+	result, err := v.validateEndpoint(context.Background(), server.URL, path)
+	if err != nil {
+		t.Fatalf("validateEndpoint() error: %v", err)
+	}
+
+	if !strings.Contains(gotHeader, `auth_token=abc\def`) {
+		t.Errorf("Cookie header missing backslash cookie: %q", gotHeader)
+	}
+	if !strings.Contains(gotHeader, "ct0=csrf") {
+		t.Errorf("Cookie header missing ct0: %q", gotHeader)
+	}
+	if !result.IsValid || result.Status != domain.ValidationStatusValid {
+		t.Errorf("expected valid result, got %+v", result)
+	}
+}
+
+func TestBuildCookieHeader(t *testing.T) {
+	cookies := []NetscapeCookie{
+		{Name: "a", Value: "1"},
+		{Name: "quoted", Value: `"x"y`},
+		{Name: "broken", Value: "line\nbreak"},
+	}
+
+	got := buildCookieHeader(cookies)
+	want := `a=1; quoted="x"y`
+	if got != want {
+		t.Errorf("buildCookieHeader() = %q, want %q", got, want)
+	}
+}
+
+func TestValidateHTTP_Endpoints(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Logged-out requests are sent to the login page, which itself returns 200
+		if r.URL.Path == "/settings" {
+			http.Redirect(w, r, "/log_in?next=/settings", http.StatusFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "cookies.txt")
+	if err := os.WriteFile(path, []byte(".vimeo.com\tTRUE\t/\tTRUE\t1893456000\tvimeo\tabc\n"), 0600); err != nil {
+		t.Fatalf("failed to write cookie file: %v", err)
+	}
+
+	v := NewCookieValidator()
+	v.SetEndpoints(map[string]string{
+		domain.PlatformVimeo: server.URL + "/settings",
+		"custom":             server.URL + "/me",
+		domain.PlatformImgur: "",
+	})
+
+	tests := []struct {
+		platform string
+		status   string
+	}{
+		{domain.PlatformVimeo, domain.ValidationStatusInvalid},
+		{"custom", domain.ValidationStatusValid},
+		{domain.PlatformImgur, domain.ValidationStatusUnknown},
+		{domain.PlatformTwitch, domain.ValidationStatusUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.platform, func(t *testing.T) {
+			result, err := v.ValidateHTTP(context.Background(), tt.platform, path)
+			if err != nil {
+				t.Fatalf("ValidateHTTP() error: %v", err)
+			}
+			if result.Status != tt.status {
+				t.Errorf("status = %s, want %s (%s)", result.Status, tt.status, result.Message)
+			}
+		})
+	}
+
+	// Overrides don't leak into other validators
+	if NewCookieValidator().endpoints[domain.PlatformImgur] == "" {
+		t.Error("default imgur endpoint was modified")
+	}
+}
+
+func TestDescribeValidationAge(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time {
+		t := now.Add(-d)
+		return &t
+	}
+
+	tests := []struct {
+		validatedAt *time.Time
+		want        string
+		stale       bool
+	}{
+		{nil, "never validated", true},
+		{at(10 * time.Second), "validated just now", false},
+		{at(45 * time.Minute), "validated 45m ago", false},
+		{at(3 * time.Hour), "validated 3h ago", false},
+		{at(30 * time.Hour), "validated 30h ago", true},
+		{at(8 * 24 * time.Hour), "validated 8d ago", true},
+	}
+
+	for _, tt := range tests {
+		acc := &domain.Account{ValidatedAt: tt.validatedAt}
+		if got := DescribeValidationAge(acc, now); got != tt.want {
+			t.Errorf("DescribeValidationAge() = %q, want %q", got, tt.want)
+		}
+		if got := acc.IsValidationStale(now, domain.StaleValidationAge); got != tt.stale {
+			t.Errorf("IsValidationStale() for %q = %v, want %v", tt.want, got, tt.stale)
+		}
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	tests := []struct {
+		age  time.Duration
+		want string
+	}{
+		{30 * time.Second, "30s"},
+		{90 * time.Second, "1m"},
+		{45 * time.Minute, "45m"},
+		{domain.StaleValidationAge, "24h"},
+		{47*time.Hour + 59*time.Minute, "47h"},
+		{48 * time.Hour, "2d"},
+		{8*24*time.Hour + 5*time.Hour, "8d"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatAge(tt.age); got != tt.want {
+			t.Errorf("FormatAge(%s) = %q, want %q", tt.age, got, tt.want)
+		}
+	}
+}
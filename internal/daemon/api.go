@@ -0,0 +1,255 @@
+package daemon
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxAPIBody limita el tamaño del cuerpo de POST /downloads
+const maxAPIBody = 1 << 20
+
+// APIServer expone un subconjunto de las acciones del socket por HTTP/JSON.
+// Usa los mismos Handlers, así el comportamiento es idéntico al del socket
+type APIServer struct {
+	server   *http.Server
+	handlers *Handlers
+	token    string // Bearer token; vacío = auth deshabilitada
+}
+
+// NewAPIServer crea el servidor HTTP en addr (ej: "127.0.0.1:8080").
+// Si token no está vacío, las peticiones deben incluir "Authorization: Bearer <token>".
+// Sin token solo se puede escuchar en loopback (ver Start)
+func NewAPIServer(addr string, handlers *Handlers, token string) *APIServer {
+	s := &APIServer{handlers: handlers, token: token}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /downloads", s.handleAdd)
+	mux.HandleFunc("GET /downloads", s.handleList)
+	mux.HandleFunc("GET /downloads/{id}", s.handleStatus)
+	mux.HandleFunc("GET /stats", s.handleStats)
+
+	s.server = &http.Server{
+		Addr:              addr,
+		Handler:           s.authenticate(mux),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	return s
+}
+
+// Start abre el puerto y empieza a servir en segundo plano. Sin token se niega a escuchar
+// fuera de loopback: cualquiera en la red podría añadir descargas
+func (s *APIServer) Start() error {
+	if s.token == "" && !isLoopbackAddr(s.server.Addr) {
+		return fmt.Errorf("refusing to serve %s without a token (set api.token or listen on 127.0.0.1)", s.server.Addr)
+	}
+
+	listener, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return fmt.Errorf("listen on %s: %w", s.server.Addr, err)
+	}
+
+	slog.Info("HTTP API listening", "addr", listener.Addr().String(), "auth", s.token != "")
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("HTTP API failed", "error", err)
+		}
+	}()
+
+	return nil
+}
+
+// Stop cierra el servidor HTTP
+func (s *APIServer) Stop() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return s.server.Shutdown(ctx)
+}
+
+// isLoopbackAddr indica si addr ("host:port") solo es accesible desde la propia máquina.
+// Un host vacío (":8080") escucha en todas las interfaces
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// authenticate rechaza las peticiones sin el bearer token (solo si hay token configurado)
+func (s *APIServer) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.token != "" {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+				slog.Warn("Rejected unauthorized HTTP request", "method", r.Method, "path", r.URL.Path)
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				writeAPIResponse(w, http.StatusUnauthorized, Response{Success: false, Error: "unauthorized"})
+				return
+			}
+		}
+
+		slog.Debug("Received HTTP request", "method", r.Method, "path", r.URL.Path)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleAdd: POST /downloads con el mismo cuerpo que la acción "add". Responde 201 con la
+// descarga creada, o 200 si ya existía
+func (s *APIServer) handleAdd(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxAPIBody))
+	if err != nil {
+		writeAPIResponse(w, http.StatusBadRequest, Response{Success: false, Error: fmt.Sprintf("read body: %v", err)})
+		return
+	}
+
+	if err := checkRemoteAdd(body); err != nil {
+		writeAPIResponse(w, http.StatusBadRequest, Response{Success: false, Error: err.Error()})
+		return
+	}
+
+	resp := s.handlers.HandleAdd(r.Context(), body)
+	if !resp.Success {
+		writeAPIResponse(w, http.StatusBadRequest, resp)
+		return
+	}
+
+	var added struct {
+		ID        int64 `json:"id"`
+		Duplicate bool  `json:"duplicate"`
+	}
+	json.Unmarshal(resp.Data, &added)
+	if added.Duplicate {
+		writeAPIResponse(w, http.StatusOK, resp)
+		return
+	}
+
+	w.Header().Set("Location", "/downloads/"+strconv.FormatInt(added.ID, 10))
+	writeAPIResponse(w, http.StatusCreated, resp)
+}
+
+// checkRemoteAdd rechaza las opciones que por HTTP no son seguras: extra_args llega tal cual
+// a yt-dlp/gallery-dl, donde -o/-P escriben en cualquier path y --exec ejecuta comandos
+func checkRemoteAdd(body []byte) error {
+	var req AddDownloadPayload
+	if err := json.Unmarshal(body, &req); err != nil {
+		return fmt.Errorf("invalid payload: %v", err)
+	}
+
+	if req.Options != nil && len(req.Options.ExtraArgs) > 0 {
+		return errors.New("extra_args is not allowed over the HTTP API (use the socket)")
+	}
+
+	return nil
+}
+
+// handleStatus: GET /downloads/{id}
+func (s *APIServer) handleStatus(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		writeAPIResponse(w, http.StatusBadRequest, Response{Success: false, Error: "invalid id"})
+		return
+	}
+
+	payload, _ := json.Marshal(StatusPayload{ID: id})
+	resp := s.handlers.HandleStatus(r.Context(), payload)
+	s.reply(w, resp, http.StatusOK, http.StatusNotFound)
+}
+
+// handleList: GET /downloads?limit=&offset=&platform=&status=&tag=&since=&until=
+func (s *APIServer) handleList(w http.ResponseWriter, r *http.Request) {
+	req, err := listPayloadFromQuery(r)
+	if err != nil {
+		writeAPIResponse(w, http.StatusBadRequest, Response{Success: false, Error: err.Error()})
+		return
+	}
+
+	payload, _ := json.Marshal(req)
+	resp := s.handlers.HandleList(r.Context(), payload)
+	s.reply(w, resp, http.StatusOK, http.StatusBadRequest)
+}
+
+// handleStats: GET /stats
+func (s *APIServer) handleStats(w http.ResponseWriter, r *http.Request) {
+	resp := s.handlers.HandleStats(r.Context())
+	s.reply(w, resp, http.StatusOK, http.StatusInternalServerError)
+}
+
+// reply escribe la respuesta del handler con el código HTTP según su resultado
+func (s *APIServer) reply(w http.ResponseWriter, resp Response, okStatus, failStatus int) {
+	if resp.Success {
+		writeAPIResponse(w, okStatus, resp)
+	} else {
+		writeAPIResponse(w, failStatus, resp)
+	}
+}
+
+// listPayloadFromQuery convierte los parámetros de la URL al payload de "list"
+func listPayloadFromQuery(r *http.Request) (ListPayload, error) {
+	query := r.URL.Query()
+	req := ListPayload{
+		Platform: query.Get("platform"),
+		Status:   query.Get("status"),
+		Tag:      query.Get("tag"),
+	}
+
+	for name, dst := range map[string]*int{"limit": &req.Limit, "offset": &req.Offset} {
+		if value := query.Get(name); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return ListPayload{}, fmt.Errorf("invalid %s: %s", name, value)
+			}
+			*dst = n
+		}
+	}
+
+	if value := query.Get("parent_id"); value != "" {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return ListPayload{}, fmt.Errorf("invalid parent_id: %s", value)
+		}
+		req.ParentID = id
+	}
+	if value := query.Get("group"); value != "" {
+		group, err := strconv.ParseBool(value)
+		if err != nil {
+			return ListPayload{}, fmt.Errorf("invalid group: %s", value)
+		}
+		req.Group = group
+	}
+
+	for name, dst := range map[string]**time.Time{"since": &req.Since, "until": &req.Until} {
+		if value := query.Get(name); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return ListPayload{}, fmt.Errorf("invalid %s (want RFC 3339): %s", name, value)
+			}
+			*dst = &t
+		}
+	}
+
+	return req, nil
+}
+
+// writeAPIResponse serializa la Response con el mismo formato que el socket
+func writeAPIResponse(w http.ResponseWriter, status int, resp Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Error("Failed to encode HTTP response", "error", err)
+	}
+}
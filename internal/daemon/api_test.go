@@ -0,0 +1,170 @@
+package daemon
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/elsanchez/smart-download/internal/domain"
+	"github.com/elsanchez/smart-download/internal/repository/sqlite"
+)
+
+func TestAPIServer(t *testing.T) {
+	db, err := sqlite.NewDatabase(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	queue := NewQueueManager(db.DownloadRepo, nil, nil, 2)
+	handlers := NewHandlers(db.DownloadRepo, db.AccountRepo, queue, domain.DownloadOptions{})
+	api := NewAPIServer("127.0.0.1:0", handlers, "secret")
+
+	server := httptest.NewServer(api.server.Handler)
+	defer server.Close()
+
+	do := func(method, path, token, body string) (int, Response) {
+		t.Helper()
+		req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("failed to build request: %v", err)
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		res, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", method, path, err)
+		}
+		defer res.Body.Close()
+
+		var resp Response
+		if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+			t.Fatalf("%s %s: failed to decode response: %v", method, path, err)
+		}
+		return res.StatusCode, resp
+	}
+
+	// Sin token o con un token incorrecto
+	if status, _ := do("GET", "/stats", "", ""); status != http.StatusUnauthorized {
+		t.Errorf("GET /stats without token = %d, want 401", status)
+	}
+	if status, _ := do("GET", "/stats", "wrong", ""); status != http.StatusUnauthorized {
+		t.Errorf("GET /stats with wrong token = %d, want 401", status)
+	}
+
+	// Añadir una descarga
+	status, resp := do("POST", "/downloads", "secret", `{"url":"https://www.youtube.com/watch?v=abc123","tags":["api"]}`)
+	if status != http.StatusCreated || !resp.Success {
+		t.Fatalf("POST /downloads = %d %+v", status, resp)
+	}
+	var added struct {
+		ID int64 `json:"id"`
+	}
+	json.Unmarshal(resp.Data, &added)
+
+	// La misma URL no crea otra descarga
+	if status, _ := do("POST", "/downloads", "secret", `{"url":"https://www.youtube.com/watch?v=abc123"}`); status != http.StatusOK {
+		t.Errorf("POST /downloads duplicate = %d, want 200", status)
+	}
+
+	if status, _ := do("POST", "/downloads", "secret", `{}`); status != http.StatusBadRequest {
+		t.Errorf("POST /downloads without url = %d, want 400", status)
+	}
+
+	// extra_args llegaría tal cual a yt-dlp (--exec, -o): solo por el socket
+	status, resp = do("POST", "/downloads", "secret", `{"url":"https://example.com/other","options":{"extra_args":["--exec","touch /tmp/pwned"]}}`)
+	if status != http.StatusBadRequest || !strings.Contains(resp.Error, "extra_args") {
+		t.Errorf("POST /downloads with extra_args = %d %+v, want 400", status, resp)
+	}
+
+	// Detalle
+	status, resp = do("GET", "/downloads/"+strconv.FormatInt(added.ID, 10), "secret", "")
+	if status != http.StatusOK {
+		t.Fatalf("GET /downloads/{id} = %d %+v", status, resp)
+	}
+	var info struct {
+		Status string   `json:"status"`
+		Tags   []string `json:"tags"`
+	}
+	json.Unmarshal(resp.Data, &info)
+	if info.Status != string(domain.StatusPending) || len(info.Tags) != 1 || info.Tags[0] != "api" {
+		t.Errorf("unexpected download: %+v", info)
+	}
+
+	if status, _ := do("GET", "/downloads/999", "secret", ""); status != http.StatusNotFound {
+		t.Errorf("GET /downloads/999 = %d, want 404", status)
+	}
+	if status, _ := do("GET", "/downloads/abc", "secret", ""); status != http.StatusBadRequest {
+		t.Errorf("GET /downloads/abc = %d, want 400", status)
+	}
+
+	// Listado con filtros
+	status, resp = do("GET", "/downloads?status=pending&tag=api&limit=10", "secret", "")
+	if status != http.StatusOK {
+		t.Fatalf("GET /downloads = %d %+v", status, resp)
+	}
+	var list struct {
+		Count int `json:"count"`
+	}
+	json.Unmarshal(resp.Data, &list)
+	if list.Count != 1 {
+		t.Errorf("list count = %d, want 1", list.Count)
+	}
+
+	if status, _ := do("GET", "/downloads?since=yesterday", "secret", ""); status != http.StatusBadRequest {
+		t.Errorf("GET /downloads with invalid since = %d, want 400", status)
+	}
+
+	// Stats
+	status, resp = do("GET", "/stats", "secret", "")
+	if status != http.StatusOK {
+		t.Fatalf("GET /stats = %d %+v", status, resp)
+	}
+	var stats map[string]int
+	json.Unmarshal(resp.Data, &stats)
+	if stats["pending"] != 1 {
+		t.Errorf("stats = %v, want 1 pending", stats)
+	}
+}
+
+func TestAPIServer_StartWithoutToken(t *testing.T) {
+	handlers := NewHandlers(nil, nil, nil, domain.DownloadOptions{})
+
+	if err := NewAPIServer("0.0.0.0:0", handlers, "").Start(); err == nil {
+		t.Error("expected error serving on all interfaces without a token")
+	}
+
+	api := NewAPIServer("127.0.0.1:0", handlers, "")
+	if err := api.Start(); err != nil {
+		t.Fatalf("Start() on loopback without token: %v", err)
+	}
+	api.Stop()
+}
+
+func TestIsLoopbackAddr(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"127.0.0.1:8080", true},
+		{"[::1]:8080", true},
+		{"localhost:8080", true},
+		{"0.0.0.0:8080", false},
+		{":8080", false},
+		{"192.168.1.10:8080", false},
+		{"invalid", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			if got := isLoopbackAddr(tt.addr); got != tt.want {
+				t.Errorf("isLoopbackAddr(%q) = %v, want %v", tt.addr, got, tt.want)
+			}
+		})
+	}
+}
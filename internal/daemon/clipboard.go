@@ -0,0 +1,66 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// errNoClipboardTool indica que no hay ninguna herramienta de clipboard instalada
+var errNoClipboardTool = errors.New("no clipboard tool available")
+
+// clipboardTool es un comando que lee el texto a copiar por stdin
+type clipboardTool struct {
+	name string
+	args []string
+}
+
+// clipboardTools retorna las herramientas a probar, en orden de preferencia, según el SO
+// y si hay sesión Wayland
+func clipboardTools(goos string, wayland bool) []clipboardTool {
+	if goos == "darwin" {
+		return []clipboardTool{{name: "pbcopy"}}
+	}
+
+	x11 := []clipboardTool{
+		{name: "xsel", args: []string{"-b", "-i"}},
+		{name: "xclip", args: []string{"-selection", "clipboard"}},
+	}
+
+	if wayland {
+		// xsel/xclip siguen funcionando con XWayland
+		return append([]clipboardTool{{name: "wl-copy"}}, x11...)
+	}
+
+	return x11
+}
+
+// copyToClipboard copia text con la primera herramienta disponible y retorna su nombre
+func copyToClipboard(text string) (string, error) {
+	tools := clipboardTools(runtime.GOOS, os.Getenv("WAYLAND_DISPLAY") != "")
+
+	var lastErr error
+	for _, tool := range tools {
+		if _, err := exec.LookPath(tool.name); err != nil {
+			continue
+		}
+
+		cmd := exec.Command(tool.name, tool.args...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err != nil {
+			lastErr = fmt.Errorf("%s: %w", tool.name, err)
+			continue
+		}
+
+		return tool.name, nil
+	}
+
+	if lastErr != nil {
+		return "", lastErr
+	}
+
+	return "", errNoClipboardTool
+}
@@ -0,0 +1,31 @@
+package daemon
+
+import "testing"
+
+func TestClipboardTools(t *testing.T) {
+	tests := []struct {
+		name     string
+		goos     string
+		wayland  bool
+		expected []string
+	}{
+		{"macOS", "darwin", false, []string{"pbcopy"}},
+		{"Linux X11", "linux", false, []string{"xsel", "xclip"}},
+		{"Linux Wayland", "linux", true, []string{"wl-copy", "xsel", "xclip"}},
+		{"FreeBSD X11", "freebsd", false, []string{"xsel", "xclip"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tools := clipboardTools(tt.goos, tt.wayland)
+			if len(tools) != len(tt.expected) {
+				t.Fatalf("clipboardTools(%q, %v) returned %d tools, want %d", tt.goos, tt.wayland, len(tools), len(tt.expected))
+			}
+			for i, tool := range tools {
+				if tool.name != tt.expected[i] {
+					t.Errorf("tool %d = %s, want %s", i, tool.name, tt.expected[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/elsanchez/smart-download/internal/cookies"
+	"github.com/elsanchez/smart-download/internal/notify"
+	"github.com/elsanchez/smart-download/internal/repository"
+)
+
+// DefaultCookieCheckInterval es cada cuánto se revisan las cookies de las cuentas activas
+const DefaultCookieCheckInterval = 6 * time.Hour
+
+// CookieChecker revisa periódicamente la expiración de las cookies y avisa con una
+// notificación de las plataformas cuya cuenta activa expiró, antes de que falle una descarga
+type CookieChecker struct {
+	accountRepo repository.AccountRepository
+	validator   *cookies.CookieValidator
+	notifier    notify.Notifier
+	interval    time.Duration
+	wg          sync.WaitGroup
+}
+
+// NewCookieChecker crea un checker de expiración de cookies
+func NewCookieChecker(
+	accountRepo repository.AccountRepository,
+	validator *cookies.CookieValidator,
+	notifier notify.Notifier,
+	interval time.Duration,
+) *CookieChecker {
+	if interval <= 0 {
+		interval = DefaultCookieCheckInterval
+	}
+
+	return &CookieChecker{
+		accountRepo: accountRepo,
+		validator:   validator,
+		notifier:    notifier,
+		interval:    interval,
+	}
+}
+
+// Start lanza la revisión periódica (la primera al iniciar). Termina al cancelar ctx
+func (c *CookieChecker) Start(ctx context.Context) {
+	slog.Info("Cookie expiry check enabled", "interval", c.interval)
+	c.wg.Add(1)
+	go c.loop(ctx)
+}
+
+// Stop espera a que termine la revisión en curso (cancelar antes el ctx de Start)
+func (c *CookieChecker) Stop() {
+	c.wg.Wait()
+}
+
+// loop ejecuta Check en cada tick
+func (c *CookieChecker) loop(ctx context.Context) {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		c.run(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// run ejecuta una revisión y notifica si hay plataformas con la cuenta activa expirada
+func (c *CookieChecker) run(ctx context.Context) {
+	platforms, err := c.Check(ctx)
+	if err != nil {
+		slog.Error("Cookie expiry check failed", "error", err)
+		return
+	}
+	if len(platforms) == 0 {
+		slog.Debug("Cookie expiry check: all active accounts valid")
+		return
+	}
+
+	slog.Warn("Expired cookies for active accounts", "platforms", platforms)
+	err = c.notifier.Notify(notify.Notification{
+		Title:   "Cookies Expired",
+		Message: fmt.Sprintf("Re-import cookies for: %s", strings.Join(platforms, ", ")),
+	})
+	if err != nil {
+		slog.Warn("Failed to send notification", "error", err)
+	}
+}
+
+// Check revalida las cookies de las cuentas activas, guarda el resultado y retorna
+// las plataformas cuya cuenta activa está expirada
+func (c *CookieChecker) Check(ctx context.Context) ([]string, error) {
+	platforms, err := c.accountRepo.ListPlatforms(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list platforms: %w", err)
+	}
+
+	// Validación fresca de la cuenta activa de cada plataforma
+	for _, platform := range platforms {
+		account, err := c.accountRepo.GetActive(ctx, platform)
+		if err != nil {
+			return nil, fmt.Errorf("get active account for %s: %w", platform, err)
+		}
+		if account == nil {
+			continue
+		}
+
+		result, err := c.validator.ValidateFile(account.CookiePath)
+		if err != nil {
+			slog.Warn("Failed to validate cookies", "platform", account.Platform, "account", account.Name, "error", err)
+			continue
+		}
+
+		var validationErr *string
+		if !result.IsValid {
+			validationErr = &result.Message
+		}
+		if err := c.accountRepo.UpdateValidation(ctx, account.ID, result.Status, validationErr); err != nil {
+			return nil, fmt.Errorf("update validation of %s/%s: %w", account.Platform, account.Name, err)
+		}
+	}
+
+	// Incluye el estado recién guardado de las cuentas activas
+	expired, err := c.accountRepo.GetExpiredAccounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []string
+	for _, account := range expired {
+		if !account.IsActive {
+			slog.Debug("Inactive account has expired cookies", "platform", account.Platform, "account", account.Name)
+			continue
+		}
+		result = append(result, account.Platform)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,100 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/elsanchez/smart-download/internal/cookies"
+	"github.com/elsanchez/smart-download/internal/domain"
+	"github.com/elsanchez/smart-download/internal/notify"
+	"github.com/elsanchez/smart-download/internal/repository/sqlite"
+)
+
+// recordingNotifier guarda las notificaciones enviadas
+type recordingNotifier struct {
+	sent []notify.Notification
+}
+
+func (r *recordingNotifier) Notify(n notify.Notification) error {
+	r.sent = append(r.sent, n)
+	return nil
+}
+
+func TestCookieChecker_Check(t *testing.T) {
+	tmpDir := t.TempDir()
+	db, err := sqlite.NewDatabase(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	past := time.Now().Add(-time.Hour).Unix()
+	future := time.Now().Add(24 * time.Hour).Unix()
+
+	// Cuentas activas: twitter expirada, instagram válida. La inactiva expirada no cuenta
+	accounts := []struct {
+		platform string
+		name     string
+		expires  int64
+		active   bool
+	}{
+		{domain.PlatformTwitter, "main", past, true},
+		{domain.PlatformInstagram, "main", future, true},
+		{domain.PlatformPixiv, "old", past, false},
+	}
+
+	for _, a := range accounts {
+		path := filepath.Join(tmpDir, a.platform+"_"+a.name+".txt")
+		content := fmt.Sprintf("# Netscape HTTP Cookie File\n.example.com\tTRUE\t/\tTRUE\t%d\tsession\tabc\n", a.expires)
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write cookie file: %v", err)
+		}
+
+		acc := &domain.Account{Platform: a.platform, Name: a.name, CookiePath: path, IsActive: a.active}
+		if _, err := db.AccountRepo.Create(ctx, acc); err != nil {
+			t.Fatalf("failed to create account: %v", err)
+		}
+	}
+
+	// La cuenta inactiva ya estaba marcada como expirada
+	pixiv, err := db.AccountRepo.GetAll(ctx, domain.PlatformPixiv)
+	if err != nil || len(pixiv) != 1 {
+		t.Fatalf("failed to get pixiv account: %v", err)
+	}
+	if err := db.AccountRepo.UpdateValidation(ctx, pixiv[0].ID, domain.ValidationStatusExpired, nil); err != nil {
+		t.Fatalf("failed to update validation: %v", err)
+	}
+
+	notifier := &recordingNotifier{}
+	checker := NewCookieChecker(db.AccountRepo, cookies.NewCookieValidator(), notifier, time.Hour)
+
+	platforms, err := checker.Check(ctx)
+	if err != nil {
+		t.Fatalf("Check() error: %v", err)
+	}
+	if len(platforms) != 1 || platforms[0] != domain.PlatformTwitter {
+		t.Errorf("Check() = %v, want [twitter]", platforms)
+	}
+
+	// El resultado de la validación queda guardado
+	instagram, err := db.AccountRepo.GetActive(ctx, domain.PlatformInstagram)
+	if err != nil {
+		t.Fatalf("failed to get instagram account: %v", err)
+	}
+	if instagram.ValidationStatus != domain.ValidationStatusValid {
+		t.Errorf("instagram validation status = %s, want valid", instagram.ValidationStatus)
+	}
+
+	checker.run(ctx)
+	if len(notifier.sent) != 1 {
+		t.Fatalf("sent %d notifications, want 1", len(notifier.sent))
+	}
+	if want := "Re-import cookies for: twitter"; notifier.sent[0].Message != want {
+		t.Errorf("notification message = %q, want %q", notifier.sent[0].Message, want)
+	}
+}
@@ -0,0 +1,193 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/elsanchez/smart-download/internal/cookies"
+	"github.com/elsanchez/smart-download/internal/domain"
+	"github.com/elsanchez/smart-download/internal/repository"
+)
+
+const (
+	// cookieWatchDebounce agrupa las escrituras sucesivas de un mismo archivo en una sola validación
+	cookieWatchDebounce = time.Second
+	// cookieWatchResync es cada cuánto se añaden los directorios de cuentas nuevas
+	cookieWatchResync = 5 * time.Minute
+)
+
+// CookieWatcher revalida las cookies de una cuenta cuando su cookie_path cambia en disco,
+// para que el estado de validación no quede viejo tras re-exportar las cookies
+type CookieWatcher struct {
+	accountRepo repository.AccountRepository
+	validator   *cookies.CookieValidator
+	watcher     *fsnotify.Watcher
+	dirs        map[string]bool // Directorios observados
+	wg          sync.WaitGroup
+}
+
+// NewCookieWatcher crea un watcher de archivos de cookies
+func NewCookieWatcher(accountRepo repository.AccountRepository, validator *cookies.CookieValidator) (*CookieWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("create file watcher: %w", err)
+	}
+
+	return &CookieWatcher{
+		accountRepo: accountRepo,
+		validator:   validator,
+		watcher:     watcher,
+		dirs:        make(map[string]bool),
+	}, nil
+}
+
+// Start empieza a observar los archivos de cookies de todas las cuentas. Termina al cancelar ctx
+func (w *CookieWatcher) Start(ctx context.Context) error {
+	// Se observan los directorios y no los archivos: los exports suelen reemplazar el
+	// archivo (rename), lo que rompe un watch sobre el archivo original
+	if err := w.sync(ctx); err != nil {
+		w.watcher.Close()
+		return err
+	}
+
+	slog.Info("Watching cookie files for changes", "directories", len(w.dirs))
+	w.wg.Add(1)
+	go w.loop(ctx)
+	return nil
+}
+
+// Stop espera a que termine el watcher (cancelar antes el ctx de Start)
+func (w *CookieWatcher) Stop() {
+	w.wg.Wait()
+}
+
+// sync añade al watcher los directorios de las cuentas que aún no se observan
+func (w *CookieWatcher) sync(ctx context.Context) error {
+	accounts, err := w.allAccounts(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, acc := range accounts {
+		dir := filepath.Dir(acc.CookiePath)
+		if w.dirs[dir] {
+			continue
+		}
+		if err := w.watcher.Add(dir); err != nil {
+			slog.Warn("Failed to watch cookie directory", "dir", dir, "error", err)
+			continue
+		}
+		w.dirs[dir] = true
+	}
+
+	return nil
+}
+
+// loop procesa los eventos del watcher con debounce por archivo
+func (w *CookieWatcher) loop(ctx context.Context) {
+	defer w.wg.Done()
+	defer w.watcher.Close()
+
+	ticker := time.NewTicker(cookieWatchDebounce / 2)
+	defer ticker.Stop()
+
+	resync := time.NewTicker(cookieWatchResync)
+	defer resync.Stop()
+
+	// Último evento por archivo, pendiente de validar
+	pending := make(map[string]time.Time)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Has(fsnotify.Write) || event.Has(fsnotify.Create) || event.Has(fsnotify.Rename) {
+				pending[filepath.Clean(event.Name)] = time.Now()
+			}
+
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			slog.Error("Cookie watcher error", "error", err)
+
+		case now := <-ticker.C:
+			for path, last := range pending {
+				if now.Sub(last) < cookieWatchDebounce {
+					continue
+				}
+				delete(pending, path)
+
+				if err := w.revalidate(ctx, path); err != nil {
+					slog.Error("Failed to revalidate cookies", "path", path, "error", err)
+				}
+			}
+
+		case <-resync.C:
+			if err := w.sync(ctx); err != nil {
+				slog.Error("Cookie watcher resync failed", "error", err)
+			}
+		}
+	}
+}
+
+// revalidate valida el archivo y actualiza las cuentas que lo usan (ninguna si no es de una cuenta)
+func (w *CookieWatcher) revalidate(ctx context.Context, path string) error {
+	accounts, err := w.allAccounts(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, acc := range accounts {
+		if filepath.Clean(acc.CookiePath) != path {
+			continue
+		}
+
+		result, err := w.validator.ValidateFile(path)
+		if err != nil {
+			return err
+		}
+
+		var validationErr *string
+		if !result.IsValid {
+			validationErr = &result.Message
+		}
+		if err := w.accountRepo.UpdateValidation(ctx, acc.ID, result.Status, validationErr); err != nil {
+			return fmt.Errorf("update validation of %s/%s: %w", acc.Platform, acc.Name, err)
+		}
+
+		slog.Info("Cookie file changed, revalidated", "platform", acc.Platform, "account", acc.Name, "status", result.Status)
+	}
+
+	return nil
+}
+
+// allAccounts retorna las cuentas de todas las plataformas
+func (w *CookieWatcher) allAccounts(ctx context.Context) ([]*domain.Account, error) {
+	platforms, err := w.accountRepo.ListPlatforms(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list platforms: %w", err)
+	}
+
+	var accounts []*domain.Account
+	for _, platform := range platforms {
+		list, err := w.accountRepo.GetAll(ctx, platform)
+		if err != nil {
+			return nil, fmt.Errorf("get accounts for %s: %w", platform, err)
+		}
+		accounts = append(accounts, list...)
+	}
+
+	return accounts, nil
+}
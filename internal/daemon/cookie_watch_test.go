@@ -0,0 +1,80 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/elsanchez/smart-download/internal/cookies"
+	"github.com/elsanchez/smart-download/internal/domain"
+	"github.com/elsanchez/smart-download/internal/repository/sqlite"
+)
+
+func TestCookieWatcher_RevalidatesOnChange(t *testing.T) {
+	tmpDir := t.TempDir()
+	db, err := sqlite.NewDatabase(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	writeCookies := func(path string, expires int64) {
+		content := fmt.Sprintf("# Netscape HTTP Cookie File\n.example.com\tTRUE\t/\tTRUE\t%d\tsession\tabc\n", expires)
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write cookie file: %v", err)
+		}
+	}
+
+	// Cuenta con cookies expiradas
+	cookieDir := filepath.Join(tmpDir, "cookies")
+	if err := os.MkdirAll(cookieDir, 0755); err != nil {
+		t.Fatalf("failed to create cookie dir: %v", err)
+	}
+	path := filepath.Join(cookieDir, "twitter_main.txt")
+	writeCookies(path, time.Now().Add(-time.Hour).Unix())
+
+	acc := &domain.Account{Platform: domain.PlatformTwitter, Name: "main", CookiePath: path, IsActive: true}
+	id, err := db.AccountRepo.Create(ctx, acc)
+	if err != nil {
+		t.Fatalf("failed to create account: %v", err)
+	}
+	if err := db.AccountRepo.UpdateValidation(ctx, id, domain.ValidationStatusExpired, nil); err != nil {
+		t.Fatalf("failed to update validation: %v", err)
+	}
+
+	watcher, err := NewCookieWatcher(db.AccountRepo, cookies.NewCookieValidator())
+	if err != nil {
+		t.Fatalf("NewCookieWatcher() error: %v", err)
+	}
+	if err := watcher.Start(ctx); err != nil {
+		t.Fatalf("Start() error: %v", err)
+	}
+	defer func() {
+		cancel()
+		watcher.Stop()
+	}()
+
+	// Re-exportar cookies válidas al mismo path
+	writeCookies(path, time.Now().Add(24*time.Hour).Unix())
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		got, err := db.AccountRepo.GetByID(ctx, id)
+		if err != nil {
+			t.Fatalf("failed to get account: %v", err)
+		}
+		if got.ValidationStatus == domain.ValidationStatusValid {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("validation status = %s after change, want valid", got.ValidationStatus)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
@@ -4,10 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/elsanchez/smart-download/internal/domain"
 	"github.com/elsanchez/smart-download/internal/downloader"
+	"github.com/elsanchez/smart-download/internal/postprocessor"
 	"github.com/elsanchez/smart-download/internal/repository"
 )
 
@@ -16,26 +19,43 @@ type Handlers struct {
 	downloadRepo repository.DownloadRepository
 	accountRepo  repository.AccountRepository
 	queue        *QueueManager
+	defaults     domain.DownloadOptions
+	version      string
+	startedAt    time.Time
 }
 
-// NewHandlers crea un nuevo conjunto de handlers
+// NewHandlers crea un nuevo conjunto de handlers.
+// defaults se aplica a las opciones que la petición deja vacías
 func NewHandlers(
 	downloadRepo repository.DownloadRepository,
 	accountRepo repository.AccountRepository,
 	queue *QueueManager,
+	defaults domain.DownloadOptions,
 ) *Handlers {
 	return &Handlers{
 		downloadRepo: downloadRepo,
 		accountRepo:  accountRepo,
 		queue:        queue,
+		defaults:     defaults,
+		startedAt:    time.Now(),
 	}
 }
 
+// SetVersion configura la versión del daemon que reporta health
+func (h *Handlers) SetVersion(version string) {
+	h.version = version
+}
+
 // AddDownloadPayload es el payload para añadir una descarga
 type AddDownloadPayload struct {
-	URL       string                 `json:"url"`
-	Options   *domain.DownloadOptions `json:"options,omitempty"`
-	AccountID *int64                 `json:"account_id,omitempty"`
+	URL            string                  `json:"url"`
+	Options        *domain.DownloadOptions `json:"options,omitempty"`
+	AccountID      *int64                  `json:"account_id,omitempty"`
+	Priority       int                     `json:"priority,omitempty"`        // Mayor = se despacha antes
+	ScheduledAfter *time.Time              `json:"scheduled_after,omitempty"` // No se despacha antes de este momento
+	Tags           []string                `json:"tags,omitempty"`
+	Account        string                  `json:"account,omitempty"` // Nombre de la cuenta de la plataforma detectada (alternativa a account_id)
+	Force          bool                    `json:"force,omitempty"`   // Añadir aunque ya exista una descarga con la misma URL
 }
 
 // HandleAdd maneja la petición de añadir una descarga
@@ -49,19 +69,56 @@ func (h *Handlers) HandleAdd(ctx context.Context, payload json.RawMessage) Respo
 		return Response{Success: false, Error: "url is required"}
 	}
 
+	// Normalizar URL para deduplicar
+	normalizedURL := downloader.NormalizeURL(req.URL)
+
+	// Si ya existe una descarga no fallida con la misma URL, retornarla. Un canal/perfil se
+	// puede volver a añadir para encolar lo nuevo (el download archive salta lo ya bajado)
+	profile := req.Options != nil && req.Options.Profile
+	if !req.Force && !profile {
+		existing, err := h.downloadRepo.GetByURL(ctx, normalizedURL)
+		if err != nil {
+			return Response{Success: false, Error: fmt.Sprintf("check duplicate: %v", err)}
+		}
+
+		if existing != nil {
+			data, _ := json.Marshal(map[string]interface{}{
+				"id":        existing.ID,
+				"platform":  existing.Platform,
+				"username":  existing.Username,
+				"status":    existing.Status,
+				"duplicate": true,
+			})
+			return Response{Success: true, Data: data}
+		}
+	}
+
 	// Detectar plataforma y username
-	platform := downloader.DetectPlatform(req.URL)
-	username := downloader.ExtractUsername(req.URL)
+	platform := downloader.DetectPlatform(normalizedURL)
+	username := downloader.ExtractUsername(normalizedURL)
+
+	// Fijar la cuenta por nombre (ignora la cuenta activa)
+	accountID := req.AccountID
+	if req.Account != "" {
+		account, err := h.findAccount(ctx, platform, req.Account)
+		if err != nil {
+			return Response{Success: false, Error: err.Error()}
+		}
+		accountID = &account.ID
+	}
 
 	// Crear descarga
 	dl := &domain.Download{
-		URL:       req.URL,
-		Platform:  platform,
-		Username:  username,
-		Status:    domain.StatusPending,
-		AccountID: req.AccountID,
-		CreatedAt: time.Now(),
+		URL:            normalizedURL,
+		Platform:       platform,
+		Username:       username,
+		Status:         domain.StatusPending,
+		AccountID:      accountID,
+		Priority:       req.Priority,
+		ScheduledAfter: req.ScheduledAfter,
+		CreatedAt:      time.Now(),
 	}
+	dl.AddTags(req.Tags...)
 
 	// Opciones (usar defaults si no se especifican)
 	if req.Options != nil {
@@ -69,6 +126,12 @@ func (h *Handlers) HandleAdd(ctx context.Context, payload json.RawMessage) Respo
 	} else {
 		dl.Options = domain.DownloadOptions{}
 	}
+	h.applyDefaults(&dl.Options)
+
+	if err := validateOptions(&dl.Options); err != nil {
+		return Response{Success: false, Error: err.Error()}
+	}
+	profileAsGallery(&dl.Options, normalizedURL)
 
 	// Insertar en base de datos
 	id, err := h.downloadRepo.Create(ctx, dl)
@@ -87,6 +150,201 @@ func (h *Handlers) HandleAdd(ctx context.Context, payload json.RawMessage) Respo
 	return Response{Success: true, Data: data}
 }
 
+// profileAsGallery adapta un canal/perfil de gallery-dl: se descarga como una sola galería con
+// sus últimos max_items archivos (--range) y el download archive, en lugar de encolar cada item
+func profileAsGallery(opts *domain.DownloadOptions, url string) {
+	if !opts.Profile || downloader.BackendFor(url) != downloader.BackendGalleryDl {
+		return
+	}
+
+	max := opts.MaxItems
+	if max == 0 {
+		max = downloader.DefaultProfileMax
+	}
+	if opts.Range == "" {
+		opts.Range = fmt.Sprintf("1-%d", max)
+	}
+	opts.Archive = true
+	opts.Profile, opts.MaxItems = false, 0
+}
+
+// validateOptions verifica las opciones de una descarga nueva (compartido por "add" y "resolve")
+func validateOptions(opts *domain.DownloadOptions) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	if opts.FormatID != "" && (strings.TrimSpace(opts.FormatID) == "" || strings.ContainsAny(opts.FormatID, " \t")) {
+		return fmt.Errorf("invalid format_id %q (use a yt-dlp format id, e.g. 137+140)", opts.FormatID)
+	}
+
+	if opts.Range != "" {
+		if err := downloader.ValidateRange(opts.Range); err != nil {
+			return err
+		}
+	}
+
+	if err := postprocessor.ValidateRotate(opts.Rotate); err != nil {
+		return err
+	}
+	if err := postprocessor.ValidateSpeed(opts.Speed); err != nil {
+		return err
+	}
+	if err := postprocessor.ValidateTarget(opts.Target); err != nil {
+		return err
+	}
+	if err := postprocessor.ValidateGIF(opts); err != nil {
+		return err
+	}
+	if opts.Crop != "" {
+		if err := postprocessor.ValidateCrop(opts.Crop); err != nil {
+			return err
+		}
+	}
+
+	if opts.Timeout != "" {
+		if d, err := time.ParseDuration(opts.Timeout); err != nil || d <= 0 {
+			return fmt.Errorf("invalid timeout: %s", opts.Timeout)
+		}
+	}
+
+	if opts.Proxy != "" {
+		if err := domain.ValidateProxy(opts.Proxy); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// HandleResolve muestra cómo se procesaría una petición "add" (plataforma, username,
+// downloader, cuenta de cookies y opciones finales) sin crear la descarga
+func (h *Handlers) HandleResolve(ctx context.Context, payload json.RawMessage) Response {
+	var req AddDownloadPayload
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return Response{Success: false, Error: fmt.Sprintf("invalid payload: %v", err)}
+	}
+
+	if req.URL == "" {
+		return Response{Success: false, Error: "url is required"}
+	}
+
+	normalizedURL := downloader.NormalizeURL(req.URL)
+	platform := downloader.DetectPlatform(normalizedURL)
+
+	opts := domain.DownloadOptions{}
+	if req.Options != nil {
+		opts = *req.Options
+	}
+	h.applyDefaults(&opts)
+	if err := validateOptions(&opts); err != nil {
+		return Response{Success: false, Error: err.Error()}
+	}
+	profileAsGallery(&opts, normalizedURL)
+
+	// Cuenta de cookies: la fijada por nombre o id, o la activa de la plataforma
+	var account *domain.Account
+	var err error
+	switch {
+	case req.Account != "":
+		account, err = h.findAccount(ctx, platform, req.Account)
+	case req.AccountID != nil:
+		account, err = h.accountRepo.GetByID(ctx, *req.AccountID)
+	default:
+		account, err = h.accountRepo.GetActive(ctx, platform)
+	}
+	if err != nil {
+		return Response{Success: false, Error: err.Error()}
+	}
+
+	result := map[string]interface{}{
+		"url":         normalizedURL,
+		"platform":    platform,
+		"username":    downloader.ExtractUsername(normalizedURL),
+		"backend":     downloader.BackendFor(normalizedURL),
+		"has_account": account != nil,
+		"options":     opts,
+	}
+	if account != nil {
+		result["account"] = account.Name
+	}
+
+	if !req.Force {
+		existing, err := h.downloadRepo.GetByURL(ctx, normalizedURL)
+		if err != nil {
+			return Response{Success: false, Error: fmt.Sprintf("check duplicate: %v", err)}
+		}
+		if existing != nil {
+			result["duplicate_of"] = existing.ID
+		}
+	}
+
+	data, _ := json.Marshal(result)
+	return Response{Success: true, Data: data}
+}
+
+// InfoPayload es el payload de "info"
+type InfoPayload struct {
+	URL     string `json:"url"`
+	Account string `json:"account,omitempty"` // Nombre de cuenta (si no, la activa de la plataforma)
+	Proxy   string `json:"proxy,omitempty"`   // Si no, el proxy por defecto del config
+}
+
+// HandleInfo consulta la URL con yt-dlp/gallery-dl sin descargarla: título, duración y
+// formatos disponibles (o cantidad de archivos), y si hacen falta cookies
+func (h *Handlers) HandleInfo(ctx context.Context, payload json.RawMessage) Response {
+	var req InfoPayload
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return Response{Success: false, Error: fmt.Sprintf("invalid payload: %v", err)}
+	}
+
+	if req.URL == "" {
+		return Response{Success: false, Error: "url is required"}
+	}
+	if h.queue == nil {
+		return Response{Success: false, Error: "downloader not available"}
+	}
+
+	dl := &domain.Download{URL: req.URL, Options: domain.DownloadOptions{Proxy: req.Proxy}}
+	dl.Platform = downloader.DetectPlatform(downloader.NormalizeURL(req.URL))
+	if dl.Options.Proxy != "" {
+		if err := domain.ValidateProxy(dl.Options.Proxy); err != nil {
+			return Response{Success: false, Error: err.Error()}
+		}
+	}
+	if req.Account != "" {
+		account, err := h.findAccount(ctx, dl.Platform, req.Account)
+		if err != nil {
+			return Response{Success: false, Error: err.Error()}
+		}
+		dl.AccountID = &account.ID
+	}
+
+	info, err := h.queue.downloader.Info(ctx, dl)
+	if err != nil {
+		return Response{Success: false, Error: err.Error()}
+	}
+
+	data, _ := json.Marshal(info)
+	return Response{Success: true, Data: data}
+}
+
+// findAccount busca una cuenta de la plataforma por nombre
+func (h *Handlers) findAccount(ctx context.Context, platform, name string) (*domain.Account, error) {
+	accounts, err := h.accountRepo.GetAll(ctx, platform)
+	if err != nil {
+		return nil, fmt.Errorf("get accounts: %w", err)
+	}
+
+	for _, account := range accounts {
+		if account.Name == name {
+			return account, nil
+		}
+	}
+
+	return nil, fmt.Errorf("account %q not found for platform %s (see smd cookies list)", name, platform)
+}
+
 // StatusPayload es el payload para consultar status
 type StatusPayload struct {
 	ID int64 `json:"id"`
@@ -110,24 +368,198 @@ func (h *Handlers) HandleStatus(ctx context.Context, payload json.RawMessage) Re
 	}
 
 	// Respuesta
-	data, _ := json.Marshal(map[string]interface{}{
-		"id":            dl.ID,
-		"url":           dl.URL,
-		"platform":      dl.Platform,
-		"username":      dl.Username,
-		"status":        dl.Status,
-		"output_path":   dl.OutputPath,
-		"created_at":    dl.CreatedAt,
-		"completed_at":  dl.CompletedAt,
-		"error_message": dl.ErrorMessage,
-	})
+	status := statusData(dl)
+	if hint := h.cookieHint(ctx, dl); hint != "" {
+		status["hint"] = hint
+	}
+
+	// Cuenta cuyas cookies usó el último intento (o, si no empezó, la fijada) y si siguen
+	// siendo válidas
+	accountID := dl.UsedAccountID
+	if accountID == nil {
+		accountID = dl.AccountID
+	}
+	if accountID != nil {
+		if account, err := h.accountRepo.GetByID(ctx, *accountID); err == nil && account != nil {
+			status["account"] = account.Name
+			status["account_status"] = account.ValidationStatus
+		}
+	}
+
+	// Canal/perfil: resumen y lista de sus items
+	children, err := h.childSummaries(ctx, []int64{dl.ID})
+	if err != nil {
+		return Response{Success: false, Error: fmt.Sprintf("get items: %v", err)}
+	}
+	if summary, ok := children[dl.ID]; ok {
+		items, _, err := h.downloadRepo.Query(ctx, repository.DownloadFilter{ParentID: dl.ID})
+		if err != nil {
+			return Response{Success: false, Error: fmt.Sprintf("get items: %v", err)}
+		}
+		status["children"] = summary
+		status["items"] = childItems(items)
+	}
+
+	data, _ := json.Marshal(status)
 
 	return Response{Success: true, Data: data}
 }
 
+// childSummary es el resumen de los items de un canal/perfil
+type childSummary struct {
+	Total  int            `json:"total"`
+	Counts map[string]int `json:"counts"` // Por status
+}
+
+// childSummaries retorna el resumen de cada canal/perfil de ids que tenga items
+func (h *Handlers) childSummaries(ctx context.Context, ids []int64) (map[int64]*childSummary, error) {
+	counts, err := h.downloadRepo.CountChildren(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make(map[int64]*childSummary)
+	for _, c := range counts {
+		summary, ok := summaries[c.ParentID]
+		if !ok {
+			summary = &childSummary{Counts: make(map[string]int)}
+			summaries[c.ParentID] = summary
+		}
+		summary.Total += c.Count
+		summary.Counts[string(c.Status)] = c.Count
+	}
+
+	return summaries, nil
+}
+
+// childItems arma la lista de items de un canal/perfil para "status" (más recientes primero)
+func childItems(downloads []*domain.Download) []map[string]interface{} {
+	items := make([]map[string]interface{}, 0, len(downloads))
+	for _, dl := range downloads {
+		items = append(items, map[string]interface{}{
+			"id":            dl.ID,
+			"url":           dl.URL,
+			"title":         dl.Title,
+			"status":        dl.Status,
+			"progress":      dl.Progress,
+			"error_message": dl.ErrorMessage,
+			"error_type":    dl.ErrorType,
+		})
+	}
+	return items
+}
+
+// cookieHint sugiere importar cookies si la descarga falló por autenticación y la
+// plataforma sigue sin cuenta activa, o revalidarlas si la cuenta que usa está marcada
+// como vencida o inválida ("" si no aplica)
+func (h *Handlers) cookieHint(ctx context.Context, dl *domain.Download) string {
+	if dl.Status != domain.StatusFailed || !downloader.NeedsCookieHint(dl.ErrorType) {
+		return ""
+	}
+
+	if dl.AccountID != nil {
+		account, err := h.accountRepo.GetByID(ctx, *dl.AccountID)
+		if err != nil {
+			return ""
+		}
+		return downloader.RevalidateHint(account)
+	}
+
+	active, err := h.accountRepo.GetActive(ctx, dl.Platform)
+	if err != nil {
+		return ""
+	}
+	if active != nil {
+		return downloader.RevalidateHint(active)
+	}
+
+	return downloader.CookieHint(dl.Platform)
+}
+
+// statusData es el detalle de una descarga, compartido por "status" y el webhook
+func statusData(dl *domain.Download) map[string]interface{} {
+	return map[string]interface{}{
+		"id":              dl.ID,
+		"url":             dl.URL,
+		"title":           dl.Title,
+		"uploader":        dl.Uploader,
+		"upload_date":     dl.UploadDate,
+		"platform":        dl.Platform,
+		"username":        dl.Username,
+		"status":          dl.Status,
+		"progress":        dl.Progress,
+		"priority":        dl.Priority,
+		"scheduled_after": dl.ScheduledAfter,
+		"tags":            dl.Tags,
+		"output_path":     dl.OutputPath,
+		"files":           dl.Files,
+		"file_count":      len(dl.Files),
+		"created_at":      dl.CreatedAt,
+		"completed_at":    dl.CompletedAt,
+		"error_message":   dl.ErrorMessage,
+		"error_type":      dl.ErrorType,
+		"backend":         dl.Backend,
+		"parent_id":       dl.ParentID,
+	}
+}
+
+// applyDefaults completa las opciones vacías con los defaults de la configuración
+func (h *Handlers) applyDefaults(opts *domain.DownloadOptions) {
+	if opts.Resolution == "" {
+		opts.Resolution = h.defaults.Resolution
+	}
+	if opts.GIFWidth == 0 {
+		opts.GIFWidth = h.defaults.GIFWidth
+	}
+	// no_convert explícito (también false) gana sobre el default
+	if opts.NoConvert == nil && h.defaults.SkipsConversion() {
+		noConvert := true
+		opts.NoConvert = &noConvert
+	}
+	if opts.FilenameTemplate == "" {
+		opts.FilenameTemplate = h.defaults.FilenameTemplate
+	}
+}
+
 // ListPayload es el payload para listar descargas
 type ListPayload struct {
-	Limit int `json:"limit"`
+	Limit    int        `json:"limit"`
+	Offset   int        `json:"offset,omitempty"`
+	Platform string     `json:"platform,omitempty"`
+	Status   string     `json:"status,omitempty"`
+	Tag      string     `json:"tag,omitempty"`
+	Since    *time.Time `json:"since,omitempty"` // created_at >= since
+	Until    *time.Time `json:"until,omitempty"` // created_at < until
+	ParentID int64      `json:"parent_id,omitempty"`
+	Group    bool       `json:"group,omitempty"` // Mostrar los items de un canal/perfil solo como resumen del padre
+}
+
+// filter valida el payload y lo convierte en un filtro del repositorio
+func (p ListPayload) filter() (repository.DownloadFilter, error) {
+	if p.Offset < 0 {
+		return repository.DownloadFilter{}, fmt.Errorf("offset must not be negative")
+	}
+
+	status := domain.DownloadStatus(p.Status)
+	switch status {
+	case "", domain.StatusPending, domain.StatusDownloading, domain.StatusProcessing,
+		domain.StatusCompleted, domain.StatusFailed:
+	default:
+		return repository.DownloadFilter{}, fmt.Errorf("invalid status: %s", p.Status)
+	}
+
+	return repository.DownloadFilter{
+		Platform: p.Platform,
+		Status:   status,
+		Tag:      domain.NormalizeTag(p.Tag),
+		Since:    p.Since,
+		Until:    p.Until,
+		ParentID: p.ParentID,
+		// Filtrando por status se listan los items sueltos: el resumen del padre los ocultaría
+		TopLevel: p.Group && p.ParentID == 0 && status == "",
+		Limit:    p.Limit,
+		Offset:   p.Offset,
+	}, nil
 }
 
 // HandleList maneja la petición de listar descargas
@@ -142,25 +574,107 @@ func (h *Handlers) HandleList(ctx context.Context, payload json.RawMessage) Resp
 		req.Limit = 50
 	}
 
-	// Obtener descargas recientes
-	downloads, err := h.downloadRepo.GetRecent(ctx, req.Limit)
+	filter, err := req.filter()
+	if err != nil {
+		return Response{Success: false, Error: err.Error()}
+	}
+
+	// Obtener descargas (más recientes primero) y total sin paginar
+	downloads, total, err := h.downloadRepo.Query(ctx, filter)
+	if err != nil {
+		return Response{Success: false, Error: fmt.Sprintf("get downloads: %v", err)}
+	}
+
+	ids := make([]int64, 0, len(downloads))
+	for _, dl := range downloads {
+		ids = append(ids, dl.ID)
+	}
+	children, err := h.childSummaries(ctx, ids)
 	if err != nil {
 		return Response{Success: false, Error: fmt.Sprintf("get downloads: %v", err)}
 	}
 
 	// Convertir a formato de respuesta
+	items := make([]map[string]interface{}, 0, len(downloads))
+	for _, dl := range downloads {
+		items = append(items, map[string]interface{}{
+			"id":              dl.ID,
+			"url":             dl.URL,
+			"title":           dl.Title,
+			"uploader":        dl.Uploader,
+			"platform":        dl.Platform,
+			"username":        dl.Username,
+			"status":          dl.Status,
+			"scheduled_after": dl.ScheduledAfter,
+			"output_path":     dl.OutputPath,
+			"files":           dl.Files,
+			"file_count":      len(dl.Files),
+			"tags":            dl.Tags,
+			"created_at":      dl.CreatedAt,
+			"completed_at":    dl.CompletedAt,
+			"error_message":   dl.ErrorMessage,
+			"error_type":      dl.ErrorType,
+			"backend":         dl.Backend,
+			"parent_id":       dl.ParentID,
+			"children":        children[dl.ID],
+		})
+	}
+
+	data, _ := json.Marshal(map[string]interface{}{
+		"downloads": items,
+		"count":     len(items),
+		"total":     total,
+		"offset":    req.Offset,
+	})
+
+	return Response{Success: true, Data: data}
+}
+
+// HandleExport retorna el historial completo (o filtrado, mismo payload que list) con todos
+// los campos necesarios para reimportarlo. Sin límite por defecto
+func (h *Handlers) HandleExport(ctx context.Context, payload json.RawMessage) Response {
+	var req ListPayload
+	if len(payload) > 0 {
+		if err := json.Unmarshal(payload, &req); err != nil {
+			return Response{Success: false, Error: fmt.Sprintf("invalid payload: %v", err)}
+		}
+	}
+
+	filter, err := req.filter()
+	if err != nil {
+		return Response{Success: false, Error: err.Error()}
+	}
+
+	downloads, _, err := h.downloadRepo.Query(ctx, filter)
+	if err != nil {
+		return Response{Success: false, Error: fmt.Sprintf("get downloads: %v", err)}
+	}
+
 	items := make([]map[string]interface{}, 0, len(downloads))
 	for _, dl := range downloads {
 		items = append(items, map[string]interface{}{
 			"id":            dl.ID,
 			"url":           dl.URL,
+			"title":         dl.Title,
+			"uploader":      dl.Uploader,
+			"upload_date":   dl.UploadDate,
 			"platform":      dl.Platform,
 			"username":      dl.Username,
 			"status":        dl.Status,
+			"progress":      dl.Progress,
+			"priority":      dl.Priority,
+			"tags":          dl.Tags,
+			"options":       dl.Options,
+			"account_id":    dl.AccountID,
 			"output_path":   dl.OutputPath,
+			"files":         dl.Files,
+			"size":          downloadSize(dl),
 			"created_at":    dl.CreatedAt,
 			"completed_at":  dl.CompletedAt,
 			"error_message": dl.ErrorMessage,
+			"error_type":    dl.ErrorType,
+			"backend":       dl.Backend,
+			"parent_id":     dl.ParentID,
 		})
 	}
 
@@ -172,6 +686,264 @@ func (h *Handlers) HandleList(ctx context.Context, payload json.RawMessage) Resp
 	return Response{Success: true, Data: data}
 }
 
+// downloadSize suma el tamaño de los archivos de una descarga que siguen en disco
+func downloadSize(dl *domain.Download) int64 {
+	// Un único archivo puede haberse post-procesado: el resultado final es OutputPath
+	paths := dl.Files
+	if len(paths) <= 1 && dl.OutputPath != "" {
+		paths = []string{dl.OutputPath}
+	}
+
+	var size int64
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			size += info.Size()
+		}
+	}
+
+	return size
+}
+
+// HandleCancel cancela una descarga pendiente o en curso (queda como failed)
+func (h *Handlers) HandleCancel(ctx context.Context, payload json.RawMessage) Response {
+	dl, resp := h.downloadFromPayload(ctx, payload)
+	if dl == nil {
+		return resp
+	}
+
+	switch dl.Status {
+	case domain.StatusPending:
+		if err := h.downloadRepo.UpdateStatus(ctx, dl.ID, domain.StatusFailed, cancelledMessage); err != nil {
+			return Response{Success: false, Error: fmt.Sprintf("update status: %v", err)}
+		}
+		// Puede tener archivos a medias de una ejecución interrumpida por el shutdown
+		if h.queue != nil {
+			h.queue.removePartial(dl)
+		}
+	case domain.StatusDownloading, domain.StatusProcessing:
+		// El worker registra el estado final al detectar la cancelación
+		if !h.queue.Cancel(dl.ID) {
+			return Response{Success: false, Error: fmt.Sprintf("download %d is not running", dl.ID)}
+		}
+	default:
+		return Response{Success: false, Error: fmt.Sprintf("download %d is already %s", dl.ID, dl.Status)}
+	}
+
+	data, _ := json.Marshal(map[string]interface{}{"id": dl.ID})
+	return Response{Success: true, Data: data}
+}
+
+// HandleRetry vuelve a encolar una descarga fallida
+func (h *Handlers) HandleRetry(ctx context.Context, payload json.RawMessage) Response {
+	dl, resp := h.downloadFromPayload(ctx, payload)
+	if dl == nil {
+		return resp
+	}
+
+	if dl.Status != domain.StatusFailed {
+		return Response{Success: false, Error: fmt.Sprintf("only failed downloads can be retried (download %d is %s)", dl.ID, dl.Status)}
+	}
+
+	if err := h.downloadRepo.UpdateStatus(ctx, dl.ID, domain.StatusPending, ""); err != nil {
+		return Response{Success: false, Error: fmt.Sprintf("update status: %v", err)}
+	}
+
+	data, _ := json.Marshal(map[string]interface{}{"id": dl.ID, "status": domain.StatusPending})
+	return Response{Success: true, Data: data}
+}
+
+// RequeuePayload es el payload de "requeue". Los campos vacíos no filtran
+type RequeuePayload struct {
+	Platform  string `json:"platform,omitempty"`
+	ErrorType string `json:"error_type,omitempty"` // auth, needs_cookies, network...
+}
+
+// HandleRequeue vuelve a encolar todas las descargas fallidas (p.ej. las de una plataforma
+// tras renovar sus cookies)
+func (h *Handlers) HandleRequeue(ctx context.Context, payload json.RawMessage) Response {
+	var req RequeuePayload
+	if len(payload) > 0 {
+		if err := json.Unmarshal(payload, &req); err != nil {
+			return Response{Success: false, Error: fmt.Sprintf("invalid payload: %v", err)}
+		}
+	}
+
+	switch req.ErrorType {
+	case "", domain.ErrorTypeAuth, domain.ErrorTypeNeedsCookies, domain.ErrorTypeAgeRestricted,
+		domain.ErrorTypeNotFound, domain.ErrorTypeNetwork, domain.ErrorTypeTimeout, domain.ErrorTypeUnsupported,
+		domain.ErrorTypeCorrupt:
+	default:
+		return Response{Success: false, Error: fmt.Sprintf("invalid error_type: %s", req.ErrorType)}
+	}
+
+	requeued, err := h.downloadRepo.RequeueFailed(ctx, req.Platform, req.ErrorType)
+	if err != nil {
+		return Response{Success: false, Error: fmt.Sprintf("requeue: %v", err)}
+	}
+
+	data, _ := json.Marshal(map[string]interface{}{"requeued": requeued})
+	return Response{Success: true, Data: data}
+}
+
+// HandleDelete elimina una descarga del historial (no sus archivos, solo los que quedaron a
+// medias). Las descargas en curso deben cancelarse antes
+func (h *Handlers) HandleDelete(ctx context.Context, payload json.RawMessage) Response {
+	dl, resp := h.downloadFromPayload(ctx, payload)
+	if dl == nil {
+		return resp
+	}
+
+	if dl.Status == domain.StatusDownloading || dl.Status == domain.StatusProcessing {
+		return Response{Success: false, Error: fmt.Sprintf("download %d is %s, cancel it first", dl.ID, dl.Status)}
+	}
+
+	if err := h.downloadRepo.Delete(ctx, dl.ID); err != nil {
+		return Response{Success: false, Error: fmt.Sprintf("delete download: %v", err)}
+	}
+	if h.queue != nil {
+		h.queue.removePartial(dl)
+	}
+
+	data, _ := json.Marshal(map[string]interface{}{"id": dl.ID})
+	return Response{Success: true, Data: data}
+}
+
+// downloadFromPayload obtiene la descarga indicada por un payload {"id": N}.
+// Si falla retorna nil y la respuesta de error
+func (h *Handlers) downloadFromPayload(ctx context.Context, payload json.RawMessage) (*domain.Download, Response) {
+	var req StatusPayload
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return nil, Response{Success: false, Error: fmt.Sprintf("invalid payload: %v", err)}
+	}
+
+	if req.ID == 0 {
+		return nil, Response{Success: false, Error: "id is required"}
+	}
+
+	dl, err := h.downloadRepo.GetByID(ctx, req.ID)
+	if err != nil {
+		return nil, Response{Success: false, Error: fmt.Sprintf("get download: %v", err)}
+	}
+
+	return dl, Response{}
+}
+
+// TagPayload es el payload para añadir o quitar etiquetas de una descarga
+type TagPayload struct {
+	ID     int64    `json:"id"`
+	Add    []string `json:"add,omitempty"`
+	Remove []string `json:"remove,omitempty"`
+}
+
+// HandleTag añade y quita etiquetas de una descarga existente
+func (h *Handlers) HandleTag(ctx context.Context, payload json.RawMessage) Response {
+	var req TagPayload
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return Response{Success: false, Error: fmt.Sprintf("invalid payload: %v", err)}
+	}
+
+	if req.ID == 0 {
+		return Response{Success: false, Error: "id is required"}
+	}
+
+	dl, err := h.downloadRepo.GetByID(ctx, req.ID)
+	if err != nil {
+		return Response{Success: false, Error: fmt.Sprintf("get download: %v", err)}
+	}
+
+	dl.RemoveTags(req.Remove...)
+	dl.AddTags(req.Add...)
+
+	if err := h.downloadRepo.UpdateTags(ctx, dl.ID, dl.Tags); err != nil {
+		return Response{Success: false, Error: err.Error()}
+	}
+
+	data, _ := json.Marshal(map[string]interface{}{
+		"id":   dl.ID,
+		"tags": dl.Tags,
+	})
+
+	return Response{Success: true, Data: data}
+}
+
+// PrunePayload es el payload para eliminar descargas antiguas del historial
+type PrunePayload struct {
+	OlderThan   string `json:"older_than"`             // Duración Go (p.ej. "720h")
+	DeleteFiles bool   `json:"delete_files,omitempty"` // Eliminar también los archivos descargados
+	Failed      bool   `json:"failed,omitempty"`       // Eliminar también las descargas fallidas
+}
+
+// HandlePrune elimina las descargas completadas (y las fallidas si failed) terminadas hace más de older_than
+func (h *Handlers) HandlePrune(ctx context.Context, payload json.RawMessage) Response {
+	var req PrunePayload
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return Response{Success: false, Error: fmt.Sprintf("invalid payload: %v", err)}
+	}
+
+	maxAge, err := time.ParseDuration(req.OlderThan)
+	if err != nil {
+		return Response{Success: false, Error: fmt.Sprintf("invalid older_than: %v", err)}
+	}
+
+	result, err := PruneDownloads(ctx, h.downloadRepo, maxAge, req.DeleteFiles, req.Failed)
+	if err != nil {
+		return Response{Success: false, Error: fmt.Sprintf("prune: %v", err)}
+	}
+
+	data, _ := json.Marshal(result)
+	return Response{Success: true, Data: data}
+}
+
+// SetWorkersPayload es el payload de "set-workers"
+type SetWorkersPayload struct {
+	Workers int `json:"workers"`
+}
+
+// HandleSetWorkers cambia la cantidad de descargas en paralelo sin reiniciar el daemon
+func (h *Handlers) HandleSetWorkers(ctx context.Context, payload json.RawMessage) Response {
+	var req SetWorkersPayload
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return Response{Success: false, Error: fmt.Sprintf("invalid payload: %v", err)}
+	}
+	if h.queue == nil {
+		return Response{Success: false, Error: "queue not available"}
+	}
+
+	if err := h.queue.SetWorkers(req.Workers); err != nil {
+		return Response{Success: false, Error: err.Error()}
+	}
+
+	busy, total := h.queue.WorkerUsage()
+	data, _ := json.Marshal(map[string]interface{}{
+		"workers_busy":  busy,
+		"workers_total": total,
+	})
+	return Response{Success: true, Data: data}
+}
+
+// HandlePause deja de iniciar descargas pendientes; las que están en curso siguen.
+// La pausa no sobrevive a un reinicio del daemon
+func (h *Handlers) HandlePause(ctx context.Context) Response {
+	h.queue.Pause()
+	return h.pausedResponse()
+}
+
+// HandleResume reanuda una cola pausada con HandlePause
+func (h *Handlers) HandleResume(ctx context.Context) Response {
+	h.queue.Resume()
+	return h.pausedResponse()
+}
+
+// pausedResponse es la respuesta de "pause" y "resume": el estado de la cola
+func (h *Handlers) pausedResponse() Response {
+	busy, _ := h.queue.WorkerUsage()
+	data, _ := json.Marshal(map[string]interface{}{
+		"paused":       h.queue.Paused(),
+		"workers_busy": busy,
+	})
+	return Response{Success: true, Data: data}
+}
+
 // HandleStats maneja la petición de estadísticas
 func (h *Handlers) HandleStats(ctx context.Context) Response {
 	stats, err := h.queue.GetStats(ctx)
@@ -179,6 +951,74 @@ func (h *Handlers) HandleStats(ctx context.Context) Response {
 		return Response{Success: false, Error: fmt.Sprintf("get stats: %v", err)}
 	}
 
-	data, _ := json.Marshal(stats)
+	result := make(map[string]interface{}, len(stats)+5)
+	for key, value := range stats {
+		result[key] = value
+	}
+	result["paused"] = h.queue.Paused()
+
+	platforms, totalBytes, err := h.platformStats(ctx)
+	if err != nil {
+		return Response{Success: false, Error: fmt.Sprintf("get stats: %v", err)}
+	}
+	result["platforms"] = platforms
+	result["total_bytes"] = totalBytes
+
+	now := time.Now()
+	for key, window := range map[string]time.Duration{"last_24h": 24 * time.Hour, "last_7d": 7 * 24 * time.Hour} {
+		completed, failed, err := h.downloadRepo.CountFinishedSince(ctx, now.Add(-window))
+		if err != nil {
+			return Response{Success: false, Error: fmt.Sprintf("get stats: %v", err)}
+		}
+		result[key] = finishedStats(completed, failed)
+	}
+
+	data, _ := json.Marshal(result)
 	return Response{Success: true, Data: data}
 }
+
+// platformStats agrupa los conteos por plataforma: una entrada por plataforma con una
+// clave por status, total y bytes completados. Retorna además los bytes de todas
+func (h *Handlers) platformStats(ctx context.Context) ([]map[string]interface{}, int64, error) {
+	counts, err := h.downloadRepo.CountByPlatform(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	platforms := make([]map[string]interface{}, 0)
+	byName := make(map[string]map[string]interface{})
+	var totalBytes int64
+	for _, c := range counts {
+		entry, ok := byName[c.Platform]
+		if !ok {
+			entry = map[string]interface{}{"platform": c.Platform, "total": 0, "bytes": int64(0)}
+			for _, status := range []domain.DownloadStatus{domain.StatusPending, domain.StatusDownloading,
+				domain.StatusProcessing, domain.StatusCompleted, domain.StatusFailed} {
+				entry[string(status)] = 0
+			}
+			byName[c.Platform] = entry
+			platforms = append(platforms, entry)
+		}
+
+		entry[string(c.Status)] = c.Count
+		entry["total"] = entry["total"].(int) + c.Count
+		entry["bytes"] = entry["bytes"].(int64) + c.Bytes
+		totalBytes += c.Bytes
+	}
+
+	return platforms, totalBytes, nil
+}
+
+// finishedStats son las descargas terminadas en una ventana de tiempo y su tasa de éxito
+// (porcentaje; 0 si no terminó ninguna)
+func finishedStats(completed, failed int) map[string]interface{} {
+	rate := 0.0
+	if completed+failed > 0 {
+		rate = float64(completed) / float64(completed+failed) * 100
+	}
+	return map[string]interface{}{
+		"completed":    completed,
+		"failed":       failed,
+		"success_rate": rate,
+	}
+}
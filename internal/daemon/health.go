@@ -0,0 +1,62 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/elsanchez/smart-download/internal/domain"
+	"github.com/elsanchez/smart-download/internal/downloader"
+	"github.com/elsanchez/smart-download/internal/postprocessor"
+)
+
+// dependency es una herramienta externa que el daemon necesita
+type dependency struct {
+	name  string
+	check func() error
+}
+
+// dependencies se revisan en cada health (variable para poder reemplazarla en tests)
+var dependencies = []dependency{
+	{"yt-dlp", downloader.CheckYtDlpInstalled},
+	{"gallery-dl", downloader.CheckGalleryDlInstalled},
+	{"ffmpeg", postprocessor.CheckFFmpegInstalled},
+}
+
+// HandleHealth retorna el estado del daemon: versión, uptime, uso de workers,
+// acceso a la base de datos y dependencias instaladas. healthy es false si falla algo
+func (h *Handlers) HandleHealth(ctx context.Context) Response {
+	healthy := true
+
+	// Base de datos: una consulta barata
+	database := "ok"
+	if _, err := h.downloadRepo.CountByStatus(ctx, domain.StatusPending); err != nil {
+		database = err.Error()
+		healthy = false
+	}
+
+	deps := make(map[string]string, len(dependencies))
+	for _, dep := range dependencies {
+		if err := dep.check(); err != nil {
+			deps[dep.name] = err.Error()
+			healthy = false
+			continue
+		}
+		deps[dep.name] = "ok"
+	}
+
+	busy, total := h.queue.WorkerUsage()
+	uptime := time.Since(h.startedAt).Truncate(time.Second)
+
+	data, _ := json.Marshal(map[string]interface{}{
+		"healthy":        healthy,
+		"version":        h.version,
+		"uptime":         uptime.String(),
+		"uptime_seconds": int64(uptime.Seconds()),
+		"workers_busy":   busy,
+		"workers_total":  total,
+		"database":       database,
+		"dependencies":   deps,
+	})
+	return Response{Success: true, Data: data}
+}
@@ -0,0 +1,72 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/elsanchez/smart-download/internal/domain"
+	"github.com/elsanchez/smart-download/internal/repository/sqlite"
+)
+
+func TestHandleHealth(t *testing.T) {
+	db, err := sqlite.NewDatabase(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	// Dependencias simuladas: gallery-dl falta
+	saved := dependencies
+	defer func() { dependencies = saved }()
+	dependencies = []dependency{
+		{"yt-dlp", func() error { return nil }},
+		{"gallery-dl", func() error { return errors.New("gallery-dl not found") }},
+	}
+
+	queue := NewQueueManager(db.DownloadRepo, nil, nil, 2)
+	h := NewHandlers(db.DownloadRepo, db.AccountRepo, queue, domain.DownloadOptions{})
+	h.SetVersion("1.2.3")
+
+	resp := h.HandleHealth(context.Background())
+	if !resp.Success {
+		t.Fatalf("HandleHealth() failed: %s", resp.Error)
+	}
+
+	var health struct {
+		Healthy      bool              `json:"healthy"`
+		Version      string            `json:"version"`
+		WorkersBusy  int               `json:"workers_busy"`
+		WorkersTotal int               `json:"workers_total"`
+		Database     string            `json:"database"`
+		Dependencies map[string]string `json:"dependencies"`
+	}
+	if err := json.Unmarshal(resp.Data, &health); err != nil {
+		t.Fatalf("failed to decode health: %v", err)
+	}
+
+	if health.Healthy {
+		t.Error("healthy = true with a missing dependency")
+	}
+	if health.Version != "1.2.3" || health.WorkersTotal != 2 || health.WorkersBusy != 0 {
+		t.Errorf("unexpected health: %+v", health)
+	}
+	if health.Database != "ok" {
+		t.Errorf("database = %q, want ok", health.Database)
+	}
+	if health.Dependencies["yt-dlp"] != "ok" || health.Dependencies["gallery-dl"] != "gallery-dl not found" {
+		t.Errorf("dependencies = %v", health.Dependencies)
+	}
+
+	// Base de datos cerrada: no alcanzable
+	dependencies = dependencies[:1]
+	db.Close()
+	resp = h.HandleHealth(context.Background())
+	if err := json.Unmarshal(resp.Data, &health); err != nil {
+		t.Fatalf("failed to decode health: %v", err)
+	}
+	if health.Healthy || health.Database == "ok" {
+		t.Errorf("expected unhealthy database, got %+v", health)
+	}
+}
@@ -0,0 +1,146 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Resultados de una descarga para smd_downloads_total
+const (
+	resultCompleted = "completed"
+	resultFailed    = "failed"
+	resultCancelled = "cancelled"
+	resultRequeued  = "requeued" // Interrumpida por el shutdown
+)
+
+// Metrics expone métricas de Prometheus de la cola. Un *Metrics nil no registra nada,
+// así el QueueManager funciona igual con las métricas deshabilitadas
+type Metrics struct {
+	registry  *prometheus.Registry
+	downloads *prometheus.CounterVec
+	duration  *prometheus.HistogramVec
+}
+
+// NewMetrics crea las métricas. El estado de la cola y de los workers se lee de
+// QueueManager.GetStats en cada scrape
+func NewMetrics(queue *QueueManager) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		downloads: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "smd_downloads_total",
+			Help: "Downloads finished by the daemon, by platform and result.",
+		}, []string{"platform", "result"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "smd_download_duration_seconds",
+			Help:    "Time from start to completion of successful downloads, including post-processing.",
+			Buckets: []float64{1, 5, 15, 30, 60, 120, 300, 600, 1800, 3600},
+		}, []string{"platform"}),
+	}
+
+	m.registry.MustRegister(m.downloads, m.duration, &queueCollector{queue: queue})
+	return m
+}
+
+// downloadFinished registra el resultado de una descarga
+func (m *Metrics) downloadFinished(platform, result string, elapsed time.Duration) {
+	if m == nil {
+		return
+	}
+
+	m.downloads.WithLabelValues(platform, result).Inc()
+	if result == resultCompleted {
+		m.duration.WithLabelValues(platform).Observe(elapsed.Seconds())
+	}
+}
+
+// Handler retorna el handler HTTP de /metrics
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+var (
+	queueDownloadsDesc = prometheus.NewDesc("smd_queue_downloads", "Downloads in the database by status.", []string{"status"}, nil)
+	workersBusyDesc    = prometheus.NewDesc("smd_workers_busy", "Workers currently processing a download.", nil, nil)
+	workersTotalDesc   = prometheus.NewDesc("smd_workers_total", "Size of the worker pool.", nil, nil)
+)
+
+// queueCollector reporta las estadísticas de la cola al momento del scrape
+type queueCollector struct {
+	queue *QueueManager
+}
+
+func (c *queueCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- queueDownloadsDesc
+	ch <- workersBusyDesc
+	ch <- workersTotalDesc
+}
+
+func (c *queueCollector) Collect(ch chan<- prometheus.Metric) {
+	stats, err := c.queue.GetStats(context.Background())
+	if err != nil {
+		slog.Error("Failed to collect queue metrics", "error", err)
+		return
+	}
+
+	for key, value := range stats {
+		switch key {
+		case "workers_busy":
+			ch <- prometheus.MustNewConstMetric(workersBusyDesc, prometheus.GaugeValue, float64(value))
+		case "workers_total":
+			ch <- prometheus.MustNewConstMetric(workersTotalDesc, prometheus.GaugeValue, float64(value))
+		default:
+			ch <- prometheus.MustNewConstMetric(queueDownloadsDesc, prometheus.GaugeValue, float64(value), key)
+		}
+	}
+}
+
+// MetricsServer sirve /metrics por HTTP
+type MetricsServer struct {
+	server *http.Server
+}
+
+// NewMetricsServer crea el servidor de métricas en addr (ej: "127.0.0.1:9090")
+func NewMetricsServer(addr string, metrics *Metrics) *MetricsServer {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+
+	return &MetricsServer{
+		server: &http.Server{
+			Addr:              addr,
+			Handler:           mux,
+			ReadHeaderTimeout: 5 * time.Second,
+		},
+	}
+}
+
+// Start abre el puerto y empieza a servir en segundo plano
+func (s *MetricsServer) Start() error {
+	listener, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return fmt.Errorf("listen on %s: %w", s.server.Addr, err)
+	}
+
+	slog.Info("Metrics server listening", "addr", listener.Addr().String())
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Metrics server failed", "error", err)
+		}
+	}()
+
+	return nil
+}
+
+// Stop cierra el servidor de métricas
+func (s *MetricsServer) Stop() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return s.server.Shutdown(ctx)
+}
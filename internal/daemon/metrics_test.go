@@ -0,0 +1,63 @@
+package daemon
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elsanchez/smart-download/internal/domain"
+	"github.com/elsanchez/smart-download/internal/repository/sqlite"
+)
+
+func TestMetrics(t *testing.T) {
+	db, err := sqlite.NewDatabase(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	dl := &domain.Download{URL: "https://example.com/video", Platform: "youtube", Status: domain.StatusPending}
+	if _, err := db.DownloadRepo.Create(ctx, dl); err != nil {
+		t.Fatalf("failed to create download: %v", err)
+	}
+
+	queue := NewQueueManager(db.DownloadRepo, nil, nil, 2)
+	metrics := NewMetrics(queue)
+	metrics.downloadFinished("youtube", resultCompleted, 3*time.Second)
+	metrics.downloadFinished("youtube", resultFailed, time.Second)
+
+	server := httptest.NewServer(metrics.Handler())
+	defer server.Close()
+
+	resp, err := server.Client().Get(server.URL)
+	if err != nil {
+		t.Fatalf("GET /metrics: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read body: %v", err)
+	}
+
+	for _, want := range []string{
+		`smd_downloads_total{platform="youtube",result="completed"} 1`,
+		`smd_downloads_total{platform="youtube",result="failed"} 1`,
+		`smd_download_duration_seconds_count{platform="youtube"} 1`,
+		`smd_queue_downloads{status="pending"} 1`,
+		`smd_workers_busy 0`,
+		`smd_workers_total 2`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("metrics output missing %q", want)
+		}
+	}
+
+	// Un *Metrics nil (métricas deshabilitadas) no debe fallar
+	var disabled *Metrics
+	disabled.downloadFinished("youtube", resultCompleted, time.Second)
+}
@@ -0,0 +1,99 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/elsanchez/smart-download/internal/domain"
+	"github.com/elsanchez/smart-download/internal/downloader"
+)
+
+// expandProfile lista los últimos items del canal/perfil de dl y los encola como descargas
+// hijas. La descarga del perfil no baja nada: queda completada, con el nombre de la lista
+// como título
+func (q *QueueManager) expandProfile(ctx, dbCtx context.Context, dl *domain.Download, started time.Time, logger *slog.Logger) {
+	max := dl.Options.MaxItems
+	if max == 0 {
+		max = downloader.DefaultProfileMax
+	}
+
+	listing, err := q.downloader.ListProfile(ctx, dl, max)
+	if err != nil {
+		if q.workCtx.Err() != nil {
+			logger.Warn("Profile listing interrupted by shutdown, requeued")
+			q.metrics.downloadFinished(dl.Platform, resultRequeued, time.Since(started))
+			q.downloadRepo.UpdateStatus(dbCtx, dl.ID, domain.StatusPending, "")
+			return
+		}
+
+		errType := ""
+		var dlErr *downloader.DownloadError
+		if errors.As(err, &dlErr) {
+			errType = dlErr.Type
+			logger.Debug("Downloader output", "output", dlErr.Output)
+		}
+
+		logger.Error("Failed to list profile", "error", err, "error_type", errType)
+		q.metrics.downloadFinished(dl.Platform, resultFailed, time.Since(started))
+		q.downloadRepo.MarkFailed(dbCtx, dl.ID, errType, err.Error())
+		q.sendNotification("Download Failed", fmt.Sprintf("Couldn't list %s", dl.DisplayName()), "")
+		q.sendWebhook(dbCtx, dl.ID)
+		return
+	}
+
+	// Los items que ya están en la cola o descargados (por otro add) no se repiten
+	queued := 0
+	for _, itemURL := range listing.URLs {
+		child := profileChild(dl, itemURL)
+		if existing, err := q.downloadRepo.GetByURL(dbCtx, child.URL); err == nil && existing != nil {
+			continue
+		}
+		if _, err := q.downloadRepo.Create(dbCtx, child); err != nil {
+			logger.Error("Failed to queue profile item", "url", child.URL, "error", err)
+			continue
+		}
+		queued++
+	}
+
+	if listing.Title != "" {
+		if err := q.downloadRepo.UpdateMetadata(dbCtx, dl.ID, listing.Title, dl.Username, ""); err != nil {
+			logger.Warn("Failed to save metadata", "error", err)
+		}
+	}
+
+	if err := q.downloadRepo.UpdateStatus(dbCtx, dl.ID, domain.StatusCompleted, ""); err != nil {
+		logger.Error("Failed to update status", "error", err)
+		return
+	}
+
+	logger.Info("Profile expanded", "listed", len(listing.URLs), "queued", queued)
+	q.metrics.downloadFinished(dl.Platform, resultCompleted, time.Since(started))
+	q.sendNotification("Profile Queued", fmt.Sprintf("%s: %d new items", dl.DisplayName(), queued), "")
+	q.sendWebhook(dbCtx, dl.ID)
+}
+
+// profileChild arma la descarga de un item del perfil: mismas opciones, cuenta, prioridad,
+// etiquetas y carpeta (username) que el perfil, con download archive para no bajarlo de nuevo
+// en el próximo add
+func profileChild(parent *domain.Download, itemURL string) *domain.Download {
+	opts := parent.Options
+	opts.Profile, opts.MaxItems = false, 0
+	opts.Archive = true
+
+	normalized := downloader.NormalizeURL(itemURL)
+
+	return &domain.Download{
+		URL:       normalized,
+		Platform:  downloader.DetectPlatform(normalized),
+		Username:  parent.Username,
+		Status:    domain.StatusPending,
+		Options:   opts,
+		AccountID: parent.AccountID,
+		Priority:  parent.Priority,
+		Tags:      parent.Tags,
+		ParentID:  &parent.ID,
+	}
+}
@@ -0,0 +1,154 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/elsanchez/smart-download/internal/domain"
+	"github.com/elsanchez/smart-download/internal/repository"
+)
+
+// pruneInterval es cada cuánto se aplica la política de retención
+const pruneInterval = 24 * time.Hour
+
+// PruneResult resume lo eliminado por PruneDownloads
+type PruneResult struct {
+	Rows  int64 `json:"rows"`
+	Files int   `json:"files"`
+	Bytes int64 `json:"bytes"` // Tamaño de los archivos eliminados
+}
+
+// prunableStatuses retorna los estados a eliminar: siempre las completadas y las fallidas
+// solo si se pidió explícitamente (nunca las descargas en cola o en curso)
+func prunableStatuses(includeFailed bool) []domain.DownloadStatus {
+	if includeFailed {
+		return []domain.DownloadStatus{domain.StatusCompleted, domain.StatusFailed}
+	}
+	return []domain.DownloadStatus{domain.StatusCompleted}
+}
+
+// PruneDownloads elimina del historial las descargas completadas (y, si includeFailed, las
+// fallidas) terminadas hace más de maxAge y, si deleteFiles, también sus archivos
+func PruneDownloads(ctx context.Context, repo repository.DownloadRepository, maxAge time.Duration, deleteFiles, includeFailed bool) (*PruneResult, error) {
+	if maxAge <= 0 {
+		return nil, fmt.Errorf("max age must be positive, got %s", maxAge)
+	}
+
+	cutoff := time.Now().Add(-maxAge)
+	result := &PruneResult{}
+
+	for _, status := range prunableStatuses(includeFailed) {
+		// Borrar primero los archivos: una vez eliminada la fila no se conoce su path
+		if deleteFiles {
+			downloads, _, err := repo.Query(ctx, repository.DownloadFilter{Status: status, Finished: &cutoff})
+			if err != nil {
+				return nil, err
+			}
+
+			for _, dl := range downloads {
+				files, bytes := removeDownloadFiles(dl)
+				result.Files += files
+				result.Bytes += bytes
+			}
+		}
+
+		deleted, err := repo.DeleteOlderThan(ctx, status, cutoff)
+		if err != nil {
+			return nil, err
+		}
+		result.Rows += deleted
+	}
+
+	return result, nil
+}
+
+// removeDownloadFiles elimina los archivos de una descarga y retorna cuántos y cuántos bytes
+func removeDownloadFiles(dl *domain.Download) (int, int64) {
+	paths := dl.Files
+	if dl.OutputPath != "" {
+		paths = append([]string{dl.OutputPath}, dl.Files...)
+	}
+
+	removed := 0
+	var bytes int64
+	seen := make(map[string]bool, len(paths))
+
+	for _, path := range paths {
+		if path == "" || seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			continue // Ya eliminado o movido por el usuario
+		}
+
+		if err := os.Remove(path); err != nil {
+			slog.Warn("Failed to remove file", "path", path, "error", err)
+			continue
+		}
+		removed++
+		bytes += info.Size()
+	}
+
+	return removed, bytes
+}
+
+// Pruner aplica periódicamente la política de retención configurada
+type Pruner struct {
+	downloadRepo  repository.DownloadRepository
+	maxAge        time.Duration
+	deleteFiles   bool
+	includeFailed bool
+	wg            sync.WaitGroup
+}
+
+// NewPruner crea un pruner que elimina las descargas terminadas hace más de maxAge
+func NewPruner(downloadRepo repository.DownloadRepository, maxAge time.Duration, deleteFiles, includeFailed bool) *Pruner {
+	return &Pruner{
+		downloadRepo:  downloadRepo,
+		maxAge:        maxAge,
+		deleteFiles:   deleteFiles,
+		includeFailed: includeFailed,
+	}
+}
+
+// Start lanza la limpieza periódica (la primera al iniciar). Termina al cancelar ctx
+func (p *Pruner) Start(ctx context.Context) {
+	slog.Info("Pruning old downloads", "max_age", p.maxAge, "interval", pruneInterval)
+	p.wg.Add(1)
+	go p.loop(ctx)
+}
+
+// Stop espera a que termine la limpieza en curso (cancelar antes el ctx de Start)
+func (p *Pruner) Stop() {
+	p.wg.Wait()
+}
+
+// loop ejecuta PruneDownloads en cada tick
+func (p *Pruner) loop(ctx context.Context) {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+
+	for {
+		result, err := PruneDownloads(ctx, p.downloadRepo, p.maxAge, p.deleteFiles, p.includeFailed)
+		if err != nil {
+			slog.Error("Prune failed", "error", err)
+		} else if result.Rows > 0 {
+			slog.Info("Pruned downloads", "rows", result.Rows, "files", result.Files, "bytes", result.Bytes)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package daemon
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/elsanchez/smart-download/internal/domain"
+	"github.com/elsanchez/smart-download/internal/repository/sqlite"
+)
+
+func TestPruneDownloads(t *testing.T) {
+	tmpDir := t.TempDir()
+	db, err := sqlite.NewDatabase(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+
+	// Archivos: un video y una galería de dos archivos
+	video := filepath.Join(tmpDir, "video.mp4")
+	gallery := []string{filepath.Join(tmpDir, "img_1.jpg"), filepath.Join(tmpDir, "img_2.jpg")}
+	recent := filepath.Join(tmpDir, "recent.mp4")
+	for _, path := range append([]string{video, recent}, gallery...) {
+		if err := os.WriteFile(path, make([]byte, 100), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+
+	downloads := []*domain.Download{
+		{URL: "https://example.com/video", Status: domain.StatusCompleted, OutputPath: video},
+		{URL: "https://example.com/gallery", Status: domain.StatusCompleted, OutputPath: gallery[0], Files: gallery},
+		{URL: "https://example.com/failed", Status: domain.StatusFailed},
+		{URL: "https://example.com/pending", Status: domain.StatusPending},
+		{URL: "https://example.com/recent", Status: domain.StatusCompleted, OutputPath: recent},
+	}
+	for _, dl := range downloads {
+		id, err := db.DownloadRepo.Create(ctx, dl)
+		if err != nil {
+			t.Fatalf("failed to create download: %v", err)
+		}
+		dl.ID = id
+		if err := db.DownloadRepo.Update(ctx, dl); err != nil {
+			t.Fatalf("failed to update download: %v", err)
+		}
+	}
+
+	// Todas creadas hace tiempo; la reciente terminó hoy (la edad se mide desde completed_at)
+	old := time.Now().AddDate(0, 0, -10).Unix()
+	if _, err := db.DB.ExecContext(ctx, "UPDATE downloads SET created_at = ?", old); err != nil {
+		t.Fatalf("failed to set created_at: %v", err)
+	}
+	if _, err := db.DB.ExecContext(ctx, "UPDATE downloads SET completed_at = ? WHERE status IN ('completed', 'failed') AND url != ?", old, "https://example.com/recent"); err != nil {
+		t.Fatalf("failed to set completed_at: %v", err)
+	}
+	if _, err := db.DB.ExecContext(ctx, "UPDATE downloads SET completed_at = ? WHERE url = ?", time.Now().Unix(), "https://example.com/recent"); err != nil {
+		t.Fatalf("failed to set completed_at: %v", err)
+	}
+
+	result, err := PruneDownloads(ctx, db.DownloadRepo, 7*24*time.Hour, true, false)
+	if err != nil {
+		t.Fatalf("PruneDownloads() error: %v", err)
+	}
+
+	// Sin includeFailed solo se eliminan las completadas
+	if result.Rows != 2 {
+		t.Errorf("pruned %d rows, want 2", result.Rows)
+	}
+	if result.Files != 3 || result.Bytes != 300 {
+		t.Errorf("removed %d files (%d bytes), want 3 (300 bytes)", result.Files, result.Bytes)
+	}
+
+	for _, path := range append([]string{video}, gallery...) {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s was not deleted", path)
+		}
+	}
+	if _, err := os.Stat(recent); err != nil {
+		t.Errorf("recent download file was deleted: %v", err)
+	}
+
+	result, err = PruneDownloads(ctx, db.DownloadRepo, 7*24*time.Hour, true, true)
+	if err != nil {
+		t.Fatalf("PruneDownloads() error: %v", err)
+	}
+	if result.Rows != 1 {
+		t.Errorf("pruned %d rows with failed included, want 1", result.Rows)
+	}
+
+	// La pendiente y la reciente se conservan
+	total, err := db.DownloadRepo.CountTotal(ctx)
+	if err != nil {
+		t.Fatalf("CountTotal() error: %v", err)
+	}
+	if total != 2 {
+		t.Errorf("%d downloads left, want 2", total)
+	}
+}
@@ -2,32 +2,59 @@ package daemon
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
-	"os/exec"
-	"strings"
+	"log/slog"
+	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/elsanchez/smart-download/internal/domain"
 	"github.com/elsanchez/smart-download/internal/downloader"
+	"github.com/elsanchez/smart-download/internal/notify"
 	"github.com/elsanchez/smart-download/internal/postprocessor"
 	"github.com/elsanchez/smart-download/internal/repository"
 )
 
 // QueueManager gestiona la cola de descargas con workers paralelos
 type QueueManager struct {
-	downloadRepo  repository.DownloadRepository
-	downloader    *downloader.Manager
-	postprocessor postprocessor.PostProcessor
-	workers       int
-	workerPool    chan struct{}
-	wg            sync.WaitGroup
-	ctx           context.Context
-	cancel        context.CancelFunc
-	pollInterval  time.Duration
+	downloadRepo    repository.DownloadRepository
+	accountRepo     repository.AccountRepository // Opcional: estado de las cookies antes de descargar
+	downloader      *downloader.Manager
+	postprocessor   postprocessor.PostProcessor
+	poolMu          sync.Mutex // Protege workers y busy (el pool se redimensiona en caliente)
+	workers         int
+	busy            int         // Workers ocupados
+	paused          atomic.Bool // Pausada: no se inician descargas nuevas (en memoria, no sobrevive a un reinicio)
+	wg              sync.WaitGroup
+	ctx             context.Context // Loop de la cola (cancelado al iniciar Stop)
+	cancel          context.CancelFunc
+	workCtx         context.Context // Descargas en curso (cancelado solo si vence el timeout)
+	workCancel      context.CancelFunc
+	pollInterval    time.Duration
+	shutdownTimeout time.Duration
+	downloadTimeout time.Duration // Tiempo máximo por descarga (0 = sin límite)
+	clipboard       bool          // Copiar el path final al clipboard
+	notifier        notify.Notifier
+	activeMu        sync.Mutex
+	active          map[int64]context.CancelFunc // Descargas en curso, para cancelarlas individualmente
+	metrics         *Metrics                     // Opcional: contadores de Prometheus
+	webhook         *Webhook                     // Opcional: POST al terminar cada descarga
 }
 
+// cancelledMessage es el error_message de las descargas canceladas por el usuario
+const cancelledMessage = "cancelled by user"
+
+// DefaultShutdownTimeout es el tiempo que Stop espera a las descargas activas
+const DefaultShutdownTimeout = 30 * time.Second
+
+// MaxWorkers es el máximo de descargas en paralelo que acepta SetWorkers
+const MaxWorkers = 32
+
+// DefaultDownloadTimeout es el tiempo máximo de una descarga, incluido el post-procesamiento
+const DefaultDownloadTimeout = time.Hour
+
 // NewQueueManager crea un nuevo gestor de cola
 func NewQueueManager(
 	downloadRepo repository.DownloadRepository,
@@ -36,39 +63,106 @@ func NewQueueManager(
 	workers int,
 ) *QueueManager {
 	ctx, cancel := context.WithCancel(context.Background())
+	workCtx, workCancel := context.WithCancel(context.Background())
 
 	if workers <= 0 {
 		workers = 3 // Default: 3 descargas paralelas
 	}
 
 	return &QueueManager{
-		downloadRepo:  downloadRepo,
-		downloader:    downloaderMgr,
-		postprocessor: postproc,
-		workers:       workers,
-		workerPool:    make(chan struct{}, workers),
-		ctx:           ctx,
-		cancel:        cancel,
-		pollInterval:  5 * time.Second,
+		downloadRepo:    downloadRepo,
+		downloader:      downloaderMgr,
+		postprocessor:   postproc,
+		workers:         workers,
+		ctx:             ctx,
+		cancel:          cancel,
+		workCtx:         workCtx,
+		workCancel:      workCancel,
+		pollInterval:    5 * time.Second,
+		shutdownTimeout: DefaultShutdownTimeout,
+		downloadTimeout: DefaultDownloadTimeout,
+		clipboard:       true,
+		notifier:        notify.New(true),
+		active:          make(map[int64]context.CancelFunc),
 	}
 }
 
+// SetPollInterval configura cada cuánto se buscan descargas pendientes (antes de Start)
+func (q *QueueManager) SetPollInterval(interval time.Duration) {
+	q.pollInterval = interval
+}
+
+// SetClipboard habilita o deshabilita la copia del path al clipboard
+func (q *QueueManager) SetClipboard(enabled bool) {
+	q.clipboard = enabled
+}
+
+// SetNotifier configura el backend de notificaciones de escritorio
+func (q *QueueManager) SetNotifier(notifier notify.Notifier) {
+	q.notifier = notifier
+}
+
+// SetMetrics configura las métricas actualizadas al terminar cada descarga
+func (q *QueueManager) SetMetrics(metrics *Metrics) {
+	q.metrics = metrics
+}
+
+// SetAccountRepository configura las cuentas que se consultan antes de descargar de
+// plataformas que piden cookies, para avisar si faltan o están vencidas
+func (q *QueueManager) SetAccountRepository(accountRepo repository.AccountRepository) {
+	q.accountRepo = accountRepo
+}
+
+// SetWebhook configura el webhook llamado al completarse o fallar una descarga
+func (q *QueueManager) SetWebhook(webhook *Webhook) {
+	q.webhook = webhook
+}
+
+// SetDownloadTimeout configura el tiempo máximo por descarga (0 = sin límite)
+func (q *QueueManager) SetDownloadTimeout(timeout time.Duration) {
+	q.downloadTimeout = timeout
+}
+
+// SetShutdownTimeout configura cuánto espera Stop a las descargas activas
+func (q *QueueManager) SetShutdownTimeout(timeout time.Duration) {
+	q.shutdownTimeout = timeout
+}
+
 // Start inicia el queue manager
 func (q *QueueManager) Start() {
-	log.Printf("Queue manager started with %d workers", q.workers)
+	slog.Info("Queue manager started", "workers", q.workers)
+	q.wg.Add(1) // El loop cuenta en el WaitGroup para que no lance workers durante Stop
 	go q.processLoop()
 }
 
-// Stop detiene el queue manager
+// Stop deja de aceptar descargas nuevas y espera a que terminen las activas.
+// Si vence el timeout, cancela las descargas en curso (vuelven a pending)
 func (q *QueueManager) Stop() {
-	log.Println("Queue manager stopping...")
+	slog.Info("Queue manager stopping")
 	q.cancel()
-	q.wg.Wait()
-	log.Println("Queue manager stopped")
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(q.shutdownTimeout):
+		slog.Warn("Active downloads still running, cancelling them", "timeout", q.shutdownTimeout)
+		q.workCancel()
+		<-done
+	}
+
+	q.workCancel()
+	slog.Info("Queue manager stopped")
 }
 
 // processLoop es el loop principal que busca descargas pendientes
 func (q *QueueManager) processLoop() {
+	defer q.wg.Done()
+
 	ticker := time.NewTicker(q.pollInterval)
 	defer ticker.Stop()
 
@@ -78,7 +172,7 @@ func (q *QueueManager) processLoop() {
 	for {
 		select {
 		case <-q.ctx.Done():
-			log.Println("Process loop shutting down")
+			slog.Debug("Process loop shutting down")
 			return
 
 		case <-ticker.C:
@@ -89,130 +183,532 @@ func (q *QueueManager) processLoop() {
 
 // checkPendingDownloads verifica descargas pendientes y las procesa
 func (q *QueueManager) checkPendingDownloads() {
+	if q.paused.Load() {
+		slog.Debug("Queue paused, not starting pending downloads")
+		return
+	}
+
 	pending, err := q.downloadRepo.GetPending(q.ctx)
 	if err != nil {
-		log.Printf("Error getting pending downloads: %v", err)
+		slog.Error("Failed to get pending downloads", "error", err)
 		return
 	}
 
+	// Las programadas para más tarde esperan a que llegue su hora
+	now := time.Now()
+	ready := pending[:0]
+	for _, dl := range pending {
+		if !dl.IsScheduledLater(now) {
+			ready = append(ready, dl)
+		}
+	}
+	pending = ready
+
 	if len(pending) == 0 {
 		return
 	}
 
-	log.Printf("Found %d pending download(s)", len(pending))
+	slog.Info("Found pending downloads", "count", len(pending))
 
+	// pending viene ordenado por prioridad: si el pool se llena, las de menor prioridad esperan al siguiente tick
 	for _, dl := range pending {
-		select {
-		case <-q.ctx.Done():
+		if q.ctx.Err() != nil {
 			return
-		case q.workerPool <- struct{}{}: // Obtener slot de worker
-			q.wg.Add(1)
-			go q.processDownload(dl)
-		default:
+		}
+
+		if !q.acquireWorker() {
 			// Pool lleno, procesar en siguiente tick
-			log.Printf("Worker pool full, download %d queued for next tick", dl.ID)
+			slog.Debug("Worker pool full, download queued for next tick", "download_id", dl.ID)
+			continue
 		}
+		q.wg.Add(1)
+		go q.processDownload(dl)
 	}
 }
 
+// cookieAccount retorna la cuenta cuyas cookies usará la descarga: la fijada en dl.AccountID
+// o la activa de la plataforma (nil si no hay ninguna o no hay accountRepo). En plataformas
+// que piden cookies (GetCookieRequirementLevel >= 1) avisa si faltan o están marcadas como
+// vencidas o inválidas; la descarga se intenta igual
+func (q *QueueManager) cookieAccount(ctx context.Context, dl *domain.Download, logger *slog.Logger) *domain.Account {
+	if q.accountRepo == nil {
+		return nil
+	}
+
+	var account *domain.Account
+	var err error
+	if dl.AccountID != nil {
+		account, err = q.accountRepo.GetByID(ctx, *dl.AccountID)
+	} else {
+		account, err = q.accountRepo.GetActive(ctx, dl.Platform)
+	}
+	if err != nil {
+		logger.Debug("Failed to look up cookie account", "error", err)
+		return nil
+	}
+
+	if downloader.GetCookieRequirementLevel(dl.Platform) >= 1 {
+		switch {
+		case account == nil:
+			logger.Warn("No cookies for a platform that needs them, the download may fail")
+		case account.ValidationStatus == domain.ValidationStatusExpired || account.ValidationStatus == domain.ValidationStatusInvalid:
+			logger.Warn("Cookie account is not valid, the download may fail",
+				"account", account.Name, "validation_status", account.ValidationStatus)
+		}
+	}
+
+	return account
+}
+
 // processDownload procesa una descarga individual
 func (q *QueueManager) processDownload(dl *domain.Download) {
 	defer q.wg.Done()
-	defer func() { <-q.workerPool }() // Liberar slot
-
-	log.Printf("Processing download %d: %s", dl.ID, dl.URL)
+	defer q.releaseWorker() // Liberar slot
+
+	logger := slog.With("download_id", dl.ID, "platform", dl.Platform)
+	logger.Info("Processing download", "url", dl.URL)
+	started := time.Now()
+
+	// Contexto propio para poder cancelar solo esta descarga, con su tiempo máximo
+	timeout := q.timeoutFor(dl)
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(q.workCtx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(q.workCtx)
+	}
+	q.activeMu.Lock()
+	q.active[dl.ID] = cancel
+	q.activeMu.Unlock()
+	defer func() {
+		q.activeMu.Lock()
+		delete(q.active, dl.ID)
+		q.activeMu.Unlock()
+		cancel()
+	}()
+
+	// Las actualizaciones de estado no dependen del contexto de trabajo,
+	// para poder registrar el resultado aunque la descarga se cancele
+	dbCtx := context.Background()
 
 	// Actualizar status a downloading
-	if err := q.downloadRepo.UpdateStatus(q.ctx, dl.ID, domain.StatusDownloading, ""); err != nil {
-		log.Printf("Failed to update status for download %d: %v", dl.ID, err)
+	if err := q.downloadRepo.UpdateStatus(dbCtx, dl.ID, domain.StatusDownloading, ""); err != nil {
+		logger.Error("Failed to update status", "error", err)
+		return
+	}
+
+	// Cuenta de cookies, para avisar antes de intentarlo y sugerir revalidarla si falla
+	account := q.cookieAccount(dbCtx, dl, logger)
+
+	// Se registra la cuenta que se va a usar, para que status muestre con qué cookies
+	// funcionó o falló la descarga. Va aparte de account_id: los reintentos y requeue
+	// vuelven a tomar la activa en ese momento, salvo que se haya fijado una con --account
+	if account != nil {
+		dl.UsedAccountID = &account.ID
+		if err := q.downloadRepo.UpdateUsedAccount(dbCtx, dl.ID, account.ID); err != nil {
+			logger.Warn("Failed to save cookie account", "error", err)
+		}
+	}
+
+	// Canal/perfil: en lugar de descargarlo se encolan sus items
+	if dl.Options.Profile {
+		q.expandProfile(ctx, dbCtx, dl, started, logger)
 		return
 	}
 
 	// Ejecutar descarga
-	outputPath, err := q.downloader.Download(q.ctx, dl)
+	outputPath, err := q.downloader.Download(ctx, dl)
+
+	if dl.Backend != "" {
+		if err := q.downloadRepo.UpdateBackend(dbCtx, dl.ID, dl.Backend); err != nil {
+			logger.Warn("Failed to save backend", "error", err)
+		}
+	}
+
+	// Metadata (título, autor, fecha): yt-dlp la obtiene antes de descargar, así que
+	// se guarda aunque la descarga falle
+	if dl.Title != "" || dl.Uploader != "" || dl.UploadDate != "" {
+		if err := q.downloadRepo.UpdateMetadata(dbCtx, dl.ID, dl.Title, dl.Uploader, dl.UploadDate); err != nil {
+			logger.Warn("Failed to save metadata", "error", err)
+		}
+	}
+
 	if err != nil {
-		log.Printf("Download %d failed: %v", dl.ID, err)
-		q.downloadRepo.UpdateStatus(q.ctx, dl.ID, domain.StatusFailed, err.Error())
-		q.sendNotification("Download Failed", fmt.Sprintf("Failed to download: %s", dl.URL))
+		// Cancelada por el shutdown: devolver a la cola para el próximo arranque
+		if q.workCtx.Err() != nil {
+			logger.Warn("Download interrupted by shutdown, requeued")
+			q.metrics.downloadFinished(dl.Platform, resultRequeued, time.Since(started))
+			q.downloadRepo.UpdateStatus(dbCtx, dl.ID, domain.StatusPending, "")
+			return
+		}
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			logger.Warn("Download timed out", "timeout", timeout)
+			q.metrics.downloadFinished(dl.Platform, resultFailed, time.Since(started))
+			q.downloadRepo.MarkFailed(dbCtx, dl.ID, domain.ErrorTypeTimeout, timedOutMessage(timeout))
+			q.sendNotification("Download Failed", fmt.Sprintf("Timed out: %s", dl.DisplayName()), "")
+			q.sendWebhook(dbCtx, dl.ID)
+			return
+		}
+		if ctx.Err() != nil {
+			logger.Info("Download cancelled")
+			q.removePartial(dl)
+			q.metrics.downloadFinished(dl.Platform, resultCancelled, time.Since(started))
+			q.downloadRepo.UpdateStatus(dbCtx, dl.ID, domain.StatusFailed, cancelledMessage)
+			q.sendWebhook(dbCtx, dl.ID)
+			return
+		}
+
+		// Errores de yt-dlp/gallery-dl: mensaje corto y categoría; la salida completa solo en debug
+		errType := ""
+		message := fmt.Sprintf("Failed to download: %s", dl.DisplayName())
+		var dlErr *downloader.DownloadError
+		if errors.As(err, &dlErr) {
+			errType = dlErr.Type
+			logger.Debug("Downloader output", "output", dlErr.Output)
+
+			// Error de autenticación sin cookies: sugerir importarlas. Con cookies marcadas
+			// como vencidas o inválidas: sugerir revalidarlas
+			if downloader.NeedsCookieHint(errType) {
+				if !dlErr.UsedCookies {
+					message += "\n" + downloader.CookieHint(dl.Platform)
+				} else if hint := downloader.RevalidateHint(account); hint != "" {
+					message += "\n" + hint
+				}
+			}
+		}
+
+		logger.Error("Download failed", "error", err, "error_type", errType)
+		q.metrics.downloadFinished(dl.Platform, resultFailed, time.Since(started))
+		q.downloadRepo.MarkFailed(dbCtx, dl.ID, errType, err.Error())
+		q.sendNotification("Download Failed", message, "")
+		q.sendWebhook(dbCtx, dl.ID)
 		return
 	}
 
-	log.Printf("Download %d downloaded to: %s", dl.ID, outputPath)
+	logger.Info("Download finished", "path", outputPath)
+
+	// Verificar la salida: yt-dlp a veces termina bien con un archivo vacío o truncado.
+	// Se marca fallida (reintentable) y se borran los archivos. Con --archive la descarga ya
+	// quedó registrada en el archive, así que el reintento la da por descargada
+	if q.postprocessor != nil {
+		if err := q.verifyOutput(ctx, dl, outputPath); errors.Is(err, postprocessor.ErrCorruptOutput) {
+			logger.Error("Downloaded file failed verification", "error", err)
+			q.metrics.downloadFinished(dl.Platform, resultFailed, time.Since(started))
+			q.downloadRepo.MarkFailed(dbCtx, dl.ID, domain.ErrorTypeCorrupt, err.Error())
+			q.sendNotification("Download Failed", fmt.Sprintf("Corrupt or incomplete file: %s", dl.DisplayName()), "")
+			q.sendWebhook(dbCtx, dl.ID)
+			return
+		}
+	}
 
-	// Post-procesamiento (si aplica)
-	if q.postprocessor != nil && !dl.Options.AudioOnly {
-		needsProcessing, err := q.postprocessor.NeedsProcessing(outputPath, &dl.Options)
-		if err != nil {
-			log.Printf("Failed to check processing needs for download %d: %v", dl.ID, err)
+	// Guardar todos los archivos (galerías)
+	if len(dl.Files) > 0 {
+		if err := q.downloadRepo.UpdateFiles(dbCtx, dl.ID, dl.Files); err != nil {
+			logger.Error("Failed to update files", "error", err)
+		}
+	}
+
+	// Post-procesamiento (si aplica). Las galerías con múltiples archivos no se procesan, salvo
+	// en audio-only: gallery-dl solo bajó los videos y se extrae el audio de cada uno
+	if len(dl.Files) > 1 && !dl.Options.AudioOnly {
+		logger.Info("Gallery download, skipping post-processing", "files", len(dl.Files))
+	} else if q.postprocessor != nil {
+		files := []string{outputPath}
+		if len(dl.Files) > 1 {
+			files = dl.Files
 		}
 
-		if needsProcessing || dl.Options.ClipStart != "" || dl.Options.ConvertToGIF {
+		processed := make([]string, 0, len(files))
+		for _, file := range files {
+			needsProcessing, err := q.postprocessor.NeedsProcessing(file, &dl.Options)
+			if err != nil {
+				logger.Warn("Failed to check processing needs", "error", err)
+			}
+
+			if !needsProcessing && dl.Options.ClipStart == "" && !dl.Options.ConvertToGIF {
+				processed = append(processed, file)
+				continue
+			}
+
 			// Actualizar status a processing
-			if err := q.downloadRepo.UpdateStatus(q.ctx, dl.ID, domain.StatusProcessing, ""); err != nil {
-				log.Printf("Failed to update status for download %d: %v", dl.ID, err)
+			if err := q.downloadRepo.UpdateStatus(dbCtx, dl.ID, domain.StatusProcessing, ""); err != nil {
+				logger.Error("Failed to update status", "error", err)
 			}
 
-			log.Printf("Post-processing download %d...", dl.ID)
+			logger.Info("Post-processing download", "path", file)
 
-			processedPath, err := q.postprocessor.Process(q.ctx, outputPath, &dl.Options)
+			progressCtx := postprocessor.WithProgress(ctx, q.progressReporter(dbCtx, dl.ID, logger))
+			processedPath, err := q.postprocessor.Process(progressCtx, file, &dl.Options)
 			if err != nil {
-				log.Printf("Post-processing %d failed: %v", dl.ID, err)
-				q.downloadRepo.UpdateStatus(q.ctx, dl.ID, domain.StatusFailed, fmt.Sprintf("post-processing: %v", err))
-				q.sendNotification("Processing Failed", fmt.Sprintf("Failed to process: %s", outputPath))
+				if q.workCtx.Err() != nil {
+					logger.Warn("Post-processing interrupted by shutdown, requeued")
+					q.metrics.downloadFinished(dl.Platform, resultRequeued, time.Since(started))
+					q.downloadRepo.UpdateStatus(dbCtx, dl.ID, domain.StatusPending, "")
+					return
+				}
+				if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+					logger.Warn("Post-processing timed out", "timeout", timeout)
+					q.metrics.downloadFinished(dl.Platform, resultFailed, time.Since(started))
+					q.downloadRepo.MarkFailed(dbCtx, dl.ID, domain.ErrorTypeTimeout, timedOutMessage(timeout))
+					q.sendNotification("Processing Failed", fmt.Sprintf("Timed out: %s", file), "")
+					q.sendWebhook(dbCtx, dl.ID)
+					return
+				}
+				if ctx.Err() != nil {
+					logger.Info("Post-processing cancelled")
+					q.metrics.downloadFinished(dl.Platform, resultCancelled, time.Since(started))
+					q.downloadRepo.UpdateStatus(dbCtx, dl.ID, domain.StatusFailed, cancelledMessage)
+					q.sendWebhook(dbCtx, dl.ID)
+					return
+				}
+
+				logger.Error("Post-processing failed", "error", err)
+				q.metrics.downloadFinished(dl.Platform, resultFailed, time.Since(started))
+				q.downloadRepo.UpdateStatus(dbCtx, dl.ID, domain.StatusFailed, fmt.Sprintf("post-processing: %v", err))
+				q.sendNotification("Processing Failed", fmt.Sprintf("Failed to process: %s", file), "")
+				q.sendWebhook(dbCtx, dl.ID)
 				return
 			}
 
-			outputPath = processedPath
-			log.Printf("Download %d post-processed to: %s", dl.ID, outputPath)
+			processed = append(processed, processedPath)
+			logger.Info("Download post-processed", "path", processedPath)
+		}
+
+		outputPath = processed[0]
+		if len(dl.Files) > 1 {
+			dl.Files = processed
+			if err := q.downloadRepo.UpdateFiles(dbCtx, dl.ID, dl.Files); err != nil {
+				logger.Error("Failed to update files", "error", err)
+			}
 		}
 	}
 
 	// Actualizar con path de salida final
-	if err := q.downloadRepo.UpdateOutputPath(q.ctx, dl.ID, outputPath); err != nil {
-		log.Printf("Failed to update output path for download %d: %v", dl.ID, err)
+	if err := q.downloadRepo.UpdateOutputPath(dbCtx, dl.ID, outputPath); err != nil {
+		logger.Error("Failed to update output path", "error", err)
+	}
+
+	// Tamaño final, para las estadísticas
+	dl.OutputPath = outputPath
+	if err := q.downloadRepo.UpdateFileSize(dbCtx, dl.ID, downloadSize(dl)); err != nil {
+		logger.Warn("Failed to save file size", "error", err)
 	}
 
 	// Actualizar status a completed
-	if err := q.downloadRepo.UpdateStatus(q.ctx, dl.ID, domain.StatusCompleted, ""); err != nil {
-		log.Printf("Failed to update status for download %d: %v", dl.ID, err)
+	if err := q.downloadRepo.UpdateStatus(dbCtx, dl.ID, domain.StatusCompleted, ""); err != nil {
+		logger.Error("Failed to update status", "error", err)
 		return
 	}
 
-	log.Printf("Download %d completed: %s", dl.ID, outputPath)
-	q.sendNotification("Download Complete", fmt.Sprintf("Ready: %s", outputPath))
+	logger.Info("Download completed", "path", outputPath)
+	q.metrics.downloadFinished(dl.Platform, resultCompleted, time.Since(started))
+	q.notifyCompleted(dl, outputPath)
+	q.sendWebhook(dbCtx, dl.ID)
 
 	// Copiar path al clipboard
 	q.copyToClipboard(outputPath)
 }
 
-// sendNotification envía una notificación al usuario
-func (q *QueueManager) sendNotification(title, message string) {
-	// Usar notify-send en Desktop Linux
-	cmd := exec.Command("notify-send", title, message)
-	if err := cmd.Run(); err != nil {
-		log.Printf("Failed to send notification: %v", err)
+// timeoutFor retorna el tiempo máximo de la descarga: el de sus opciones o el del daemon
+func (q *QueueManager) timeoutFor(dl *domain.Download) time.Duration {
+	if dl.Options.Timeout != "" {
+		d, err := time.ParseDuration(dl.Options.Timeout)
+		if err == nil && d > 0 {
+			return d
+		}
+		slog.Warn("Invalid download timeout, using the default", "download_id", dl.ID, "timeout", dl.Options.Timeout)
+	}
+	return q.downloadTimeout
+}
+
+// verifyOutput verifica los archivos descargados (todos los de una galería). Si alguno
+// está corrupto (ErrCorruptOutput), borra todos los descargados
+func (q *QueueManager) verifyOutput(ctx context.Context, dl *domain.Download, outputPath string) error {
+	files := dl.Files
+	if len(files) == 0 {
+		files = []string{outputPath}
+	}
+
+	for _, file := range files {
+		err := q.postprocessor.VerifyOutput(ctx, file, &dl.Options)
+		if errors.Is(err, postprocessor.ErrCorruptOutput) {
+			for _, f := range files {
+				os.Remove(f)
+			}
+			return err
+		}
+		if err != nil {
+			// Cancelación o timeout: lo resuelve el post-procesamiento
+			return err
+		}
+	}
+
+	return nil
+}
+
+// progressInterval es el mínimo entre dos escrituras del progress de una descarga
+const progressInterval = 2 * time.Second
+
+// progressReporter retorna un ProgressFunc que guarda el avance en la base de datos,
+// solo cuando cambia el porcentaje entero y como mucho cada progressInterval
+func (q *QueueManager) progressReporter(ctx context.Context, id int64, logger *slog.Logger) postprocessor.ProgressFunc {
+	last := 0
+	var lastWrite time.Time
+	return func(p postprocessor.Progress) {
+		percent := int(p.Percent)
+		if percent == last || (percent < 100 && time.Since(lastWrite) < progressInterval) {
+			return
+		}
+		if err := q.downloadRepo.UpdateProgress(ctx, id, percent); err != nil {
+			logger.Warn("Failed to update progress", "error", err)
+			return
+		}
+		last = percent
+		lastWrite = time.Now()
+	}
+}
+
+// timedOutMessage es el error_message de las descargas que superan su tiempo máximo
+func timedOutMessage(timeout time.Duration) string {
+	return fmt.Sprintf("timed out after %s", timeout)
+}
+
+// Cancel cancela una descarga en curso. Retorna false si no se está procesando
+func (q *QueueManager) Cancel(id int64) bool {
+	q.activeMu.Lock()
+	defer q.activeMu.Unlock()
+
+	cancel, ok := q.active[id]
+	if ok {
+		cancel()
 	}
+
+	return ok
 }
 
-// copyToClipboard copia texto al clipboard
+// removePartial descarta los archivos a medias de una descarga que no se va a retomar
+func (q *QueueManager) removePartial(dl *domain.Download) {
+	if q.downloader == nil {
+		return
+	}
+	if err := q.downloader.RemovePartial(dl); err != nil {
+		slog.Warn("Failed to remove partial files", "download_id", dl.ID, "error", err)
+	}
+}
+
+// sendNotification envía una notificación al usuario (file es opcional, para la miniatura)
+func (q *QueueManager) sendNotification(title, message, file string) {
+	if err := q.notifier.Notify(notify.Notification{Title: title, Message: message, File: file}); err != nil {
+		slog.Warn("Failed to send notification", "error", err)
+	}
+}
+
+// notifyCompleted avisa que la descarga terminó, con la ruta en el texto (para copiarla) y
+// la acción "Open" para abrir el archivo donde el escritorio la soporta
+func (q *QueueManager) notifyCompleted(dl *domain.Download, outputPath string) {
+	n := notify.Notification{
+		Title:   "Download Complete",
+		Message: fmt.Sprintf("%s\n%s", dl.DisplayName(), outputPath),
+		File:    outputPath,
+		Open:    outputPath,
+	}
+	if err := q.notifier.Notify(n); err != nil {
+		slog.Warn("Failed to send notification", "error", err)
+	}
+}
+
+// sendWebhook envía al webhook el estado final de la descarga (mismo formato que "status")
+func (q *QueueManager) sendWebhook(ctx context.Context, id int64) {
+	if q.webhook == nil {
+		return
+	}
+
+	dl, err := q.downloadRepo.GetByID(ctx, id)
+	if err != nil {
+		slog.Warn("Failed to load download for webhook", "download_id", id, "error", err)
+		return
+	}
+	q.webhook.Send(statusData(dl))
+}
+
+// copyToClipboard copia texto al clipboard (si está habilitado)
 func (q *QueueManager) copyToClipboard(text string) {
-	// Intentar con xsel primero
-	cmd := exec.Command("xsel", "-b", "-i")
-	cmd.Stdin = strings.NewReader(text)
-	if err := cmd.Run(); err == nil {
-		log.Printf("Path copied to clipboard: %s", text)
+	if !q.clipboard {
 		return
 	}
 
-	// Fallback a xclip
-	cmd = exec.Command("xclip", "-selection", "clipboard")
-	cmd.Stdin = strings.NewReader(text)
-	if err := cmd.Run(); err == nil {
-		log.Printf("Path copied to clipboard: %s", text)
+	tool, err := copyToClipboard(text)
+	if errors.Is(err, errNoClipboardTool) {
+		slog.Debug("No clipboard tool available, skipping copy")
 		return
 	}
+	if err != nil {
+		slog.Warn("Failed to copy to clipboard", "error", err)
+		return
+	}
+
+	slog.Debug("Path copied to clipboard", "tool", tool, "path", text)
+}
+
+// acquireWorker ocupa un worker si hay alguno libre
+func (q *QueueManager) acquireWorker() bool {
+	q.poolMu.Lock()
+	defer q.poolMu.Unlock()
+	if q.busy >= q.workers {
+		return false
+	}
+	q.busy++
+	return true
+}
+
+// releaseWorker libera el worker de una descarga terminada
+func (q *QueueManager) releaseWorker() {
+	q.poolMu.Lock()
+	q.busy--
+	q.poolMu.Unlock()
+}
+
+// SetWorkers cambia la cantidad de descargas en paralelo sin reiniciar el daemon. Al reducirla,
+// las descargas en curso terminan igual: no se inician nuevas hasta quedar por debajo del total
+func (q *QueueManager) SetWorkers(workers int) error {
+	if workers < 1 || workers > MaxWorkers {
+		return fmt.Errorf("workers must be between 1 and %d, got %d", MaxWorkers, workers)
+	}
+
+	q.poolMu.Lock()
+	previous := q.workers
+	q.workers = workers
+	q.poolMu.Unlock()
+
+	slog.Info("Worker count changed", "previous", previous, "workers", workers)
+	return nil
+}
+
+// Pause deja de iniciar descargas pendientes; las que están en curso terminan normalmente
+func (q *QueueManager) Pause() {
+	if !q.paused.Swap(true) {
+		slog.Info("Queue paused")
+	}
+}
+
+// Resume vuelve a iniciar descargas pendientes a partir del siguiente tick
+func (q *QueueManager) Resume() {
+	if q.paused.Swap(false) {
+		slog.Info("Queue resumed")
+	}
+}
+
+// Paused indica si la cola está pausada
+func (q *QueueManager) Paused() bool {
+	return q.paused.Load()
+}
 
-	log.Printf("Failed to copy to clipboard (install xsel or xclip)")
+// WorkerUsage retorna cuántos workers están ocupados y el total
+func (q *QueueManager) WorkerUsage() (busy, total int) {
+	q.poolMu.Lock()
+	defer q.poolMu.Unlock()
+	return q.busy, q.workers
 }
 
 // GetStats retorna estadísticas de la cola
@@ -249,8 +745,7 @@ func (q *QueueManager) GetStats(ctx context.Context) (map[string]int, error) {
 	}
 	stats["failed"] = failed
 
-	stats["workers_total"] = q.workers
-	stats["workers_busy"] = len(q.workerPool)
+	stats["workers_busy"], stats["workers_total"] = q.WorkerUsage()
 
 	return stats, nil
 }
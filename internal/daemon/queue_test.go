@@ -0,0 +1,476 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elsanchez/smart-download/internal/domain"
+	"github.com/elsanchez/smart-download/internal/downloader"
+	"github.com/elsanchez/smart-download/internal/notify"
+	"github.com/elsanchez/smart-download/internal/repository/sqlite"
+)
+
+func TestQueueManager_DownloadTimeout(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// yt-dlp falso que nunca termina
+	binDir := filepath.Join(tmpDir, "bin")
+	if err := os.MkdirAll(binDir, 0755); err != nil {
+		t.Fatalf("failed to create bin dir: %v", err)
+	}
+	script := "#!/bin/sh\nexec sleep 30\n"
+	if err := os.WriteFile(filepath.Join(binDir, "yt-dlp"), []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write fake yt-dlp: %v", err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	db, err := sqlite.NewDatabase(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	id, err := db.DownloadRepo.Create(ctx, &domain.Download{
+		URL:      "https://example.com/video.mp4",
+		Platform: "generic",
+		Status:   domain.StatusPending,
+		Options:  domain.DownloadOptions{Timeout: "200ms"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create download: %v", err)
+	}
+
+	downloaderMgr := downloader.NewManager(filepath.Join(tmpDir, "out"), tmpDir, "", db.AccountRepo)
+	queue := NewQueueManager(db.DownloadRepo, downloaderMgr, nil, 1)
+	queue.SetPollInterval(50 * time.Millisecond)
+	queue.SetNotifier(notify.Noop{})
+	queue.SetClipboard(false)
+	queue.Start()
+	defer queue.Stop()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		dl, err := db.DownloadRepo.GetByID(ctx, id)
+		if err != nil {
+			t.Fatalf("GetByID() error: %v", err)
+		}
+		if dl.Status == domain.StatusFailed {
+			if dl.ErrorMessage != "timed out after 200ms" {
+				t.Errorf("error_message = %q, want %q", dl.ErrorMessage, "timed out after 200ms")
+			}
+			if dl.ErrorType != domain.ErrorTypeTimeout {
+				t.Errorf("error_type = %q, want %q", dl.ErrorType, domain.ErrorTypeTimeout)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("download still %s after 5s, want failed", dl.Status)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestQueueManager_TimeoutFor(t *testing.T) {
+	queue := NewQueueManager(nil, nil, nil, 1)
+	queue.SetDownloadTimeout(time.Hour)
+
+	tests := []struct {
+		option   string
+		expected time.Duration
+	}{
+		{"", time.Hour},
+		{"90m", 90 * time.Minute},
+		{"invalid", time.Hour},
+		{"-5m", time.Hour},
+	}
+
+	for _, tt := range tests {
+		dl := &domain.Download{Options: domain.DownloadOptions{Timeout: tt.option}}
+		if got := queue.timeoutFor(dl); got != tt.expected {
+			t.Errorf("timeoutFor(%q) = %s, want %s", tt.option, got, tt.expected)
+		}
+	}
+}
+
+func TestQueueManager_SetWorkers(t *testing.T) {
+	queue := NewQueueManager(nil, nil, nil, 2)
+
+	if !queue.acquireWorker() || !queue.acquireWorker() {
+		t.Fatal("expected 2 free workers")
+	}
+	if queue.acquireWorker() {
+		t.Fatal("acquired a third worker with 2 workers")
+	}
+
+	// Al reducir, las descargas en curso siguen y no se inician nuevas hasta bajar del total
+	if err := queue.SetWorkers(1); err != nil {
+		t.Fatalf("SetWorkers(1) error: %v", err)
+	}
+	if busy, total := queue.WorkerUsage(); busy != 2 || total != 1 {
+		t.Errorf("WorkerUsage() = %d, %d, want 2, 1", busy, total)
+	}
+	queue.releaseWorker()
+	if queue.acquireWorker() {
+		t.Error("acquired a worker with 1 busy and 1 total")
+	}
+	queue.releaseWorker()
+	if !queue.acquireWorker() {
+		t.Error("expected a free worker after both finished")
+	}
+
+	// Al ampliar hay lugar de inmediato
+	if err := queue.SetWorkers(4); err != nil {
+		t.Fatalf("SetWorkers(4) error: %v", err)
+	}
+	if !queue.acquireWorker() {
+		t.Error("expected a free worker after growing the pool")
+	}
+
+	for _, workers := range []int{0, -1, MaxWorkers + 1} {
+		if err := queue.SetWorkers(workers); err == nil {
+			t.Errorf("SetWorkers(%d) succeeded, want error", workers)
+		}
+	}
+	if _, total := queue.WorkerUsage(); total != 4 {
+		t.Errorf("total = %d after invalid SetWorkers, want 4", total)
+	}
+}
+
+func TestQueueManager_Pause(t *testing.T) {
+	db, err := sqlite.NewDatabase(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	queue := NewQueueManager(db.DownloadRepo, nil, nil, 1)
+	if _, err := db.DownloadRepo.Create(ctx, &domain.Download{URL: "https://example.com/a", Platform: "generic", Status: domain.StatusPending}); err != nil {
+		t.Fatalf("failed to create download: %v", err)
+	}
+
+	// En pausa no se inicia nada aunque haya workers libres
+	queue.Pause()
+	queue.checkPendingDownloads()
+	if busy, _ := queue.WorkerUsage(); busy != 0 {
+		t.Errorf("busy = %d while paused, want 0", busy)
+	}
+	if !queue.Paused() {
+		t.Error("Paused() = false after Pause()")
+	}
+
+	queue.Resume()
+	if queue.Paused() {
+		t.Error("Paused() = true after Resume()")
+	}
+}
+
+func TestQueueManager_RecordsActiveAccount(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// yt-dlp falso que siempre falla: alcanza con que la descarga se intente
+	binDir := filepath.Join(tmpDir, "bin")
+	if err := os.MkdirAll(binDir, 0755); err != nil {
+		t.Fatalf("failed to create bin dir: %v", err)
+	}
+	script := "#!/bin/sh\necho 'ERROR: HTTP Error 403: Forbidden' >&2\nexit 1\n"
+	if err := os.WriteFile(filepath.Join(binDir, "yt-dlp"), []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write fake yt-dlp: %v", err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	db, err := sqlite.NewDatabase(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	accountID, err := db.AccountRepo.Create(ctx, &domain.Account{Platform: "generic", Name: "main", CookiePath: filepath.Join(tmpDir, "cookies.txt"), IsActive: true})
+	if err != nil {
+		t.Fatalf("failed to create account: %v", err)
+	}
+	id, err := db.DownloadRepo.Create(ctx, &domain.Download{URL: "https://example.com/video.mp4", Platform: "generic", Status: domain.StatusPending})
+	if err != nil {
+		t.Fatalf("failed to create download: %v", err)
+	}
+
+	downloaderMgr := downloader.NewManager(filepath.Join(tmpDir, "out"), tmpDir, "", db.AccountRepo)
+	queue := NewQueueManager(db.DownloadRepo, downloaderMgr, nil, 1)
+	queue.SetAccountRepository(db.AccountRepo)
+	queue.SetPollInterval(50 * time.Millisecond)
+	queue.SetNotifier(notify.Noop{})
+	queue.SetClipboard(false)
+	queue.Start()
+	defer queue.Stop()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		dl, err := db.DownloadRepo.GetByID(ctx, id)
+		if err != nil {
+			t.Fatalf("GetByID() error: %v", err)
+		}
+		if dl.Status == domain.StatusFailed {
+			if dl.UsedAccountID == nil || *dl.UsedAccountID != accountID {
+				t.Errorf("UsedAccountID = %v, want the active account %d", dl.UsedAccountID, accountID)
+			}
+			// Sin fijarla: un reintento usa la cuenta activa de ese momento
+			if dl.AccountID != nil {
+				t.Errorf("AccountID = %d, want nil (not pinned)", *dl.AccountID)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("download still %s after 5s, want failed", dl.Status)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestQueueManager_DispatchesByPriority(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// yt-dlp falso que anota la URL de cada descarga (no la consulta de metadatos) y falla
+	binDir := filepath.Join(tmpDir, "bin")
+	if err := os.MkdirAll(binDir, 0755); err != nil {
+		t.Fatalf("failed to create bin dir: %v", err)
+	}
+	logPath := filepath.Join(tmpDir, "order.log")
+	script := "#!/bin/sh\n[ \"$1\" = --dump-json ] && exit 1\nfor url; do :; done\necho \"$url\" >> " + logPath + "\necho 'ERROR: HTTP Error 403: Forbidden' >&2\nexit 1\n"
+	if err := os.WriteFile(filepath.Join(binDir, "yt-dlp"), []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write fake yt-dlp: %v", err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	db, err := sqlite.NewDatabase(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	// Encoladas de menor a mayor prioridad: con un solo worker deben salir al revés
+	ctx := context.Background()
+	var ids []int64
+	for _, dl := range []*domain.Download{
+		{URL: "https://example.com/low.mp4", Priority: 0},
+		{URL: "https://example.com/high.mp4", Priority: 10},
+		{URL: "https://example.com/mid.mp4", Priority: 5},
+	} {
+		dl.Platform, dl.Status = "generic", domain.StatusPending
+		id, err := db.DownloadRepo.Create(ctx, dl)
+		if err != nil {
+			t.Fatalf("failed to create download: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	downloaderMgr := downloader.NewManager(filepath.Join(tmpDir, "out"), tmpDir, "", db.AccountRepo)
+	queue := NewQueueManager(db.DownloadRepo, downloaderMgr, nil, 1)
+	queue.SetPollInterval(50 * time.Millisecond)
+	queue.SetNotifier(notify.Noop{})
+	queue.SetClipboard(false)
+	queue.Start()
+	defer queue.Stop()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for _, id := range ids {
+		for {
+			dl, err := db.DownloadRepo.GetByID(ctx, id)
+			if err != nil {
+				t.Fatalf("GetByID() error: %v", err)
+			}
+			if dl.Status == domain.StatusFailed {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("download %d still %s after 5s, want failed", id, dl.Status)
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+	}
+
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("yt-dlp was not run: %v", err)
+	}
+	got := strings.Fields(string(data))
+	want := []string{"https://example.com/high.mp4", "https://example.com/mid.mp4", "https://example.com/low.mp4"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("dispatch order = %v, want %v", got, want)
+	}
+}
+
+func TestQueueManager_CancelRemovesPartial(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// yt-dlp falso que deja un .part en su directorio temporal y no termina
+	binDir := filepath.Join(tmpDir, "bin")
+	if err := os.MkdirAll(binDir, 0755); err != nil {
+		t.Fatalf("failed to create bin dir: %v", err)
+	}
+	script := "#!/bin/sh\n[ \"$1\" = --dump-json ] && exit 1\nfor a in \"$@\"; do\n  case \"$a\" in temp:*) mkdir -p \"${a#temp:}\"; echo x > \"${a#temp:}/video.mp4.part\" ;; esac\ndone\nexec sleep 30\n"
+	if err := os.WriteFile(filepath.Join(binDir, "yt-dlp"), []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write fake yt-dlp: %v", err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	db, err := sqlite.NewDatabase(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	outDir := filepath.Join(tmpDir, "out")
+	running, _ := db.DownloadRepo.Create(ctx, &domain.Download{URL: "https://example.com/video.mp4", Platform: "generic", Status: domain.StatusPending})
+
+	queue := NewQueueManager(db.DownloadRepo, downloader.NewManager(outDir, tmpDir, "", db.AccountRepo), nil, 1)
+	queue.SetPollInterval(50 * time.Millisecond)
+	queue.SetNotifier(notify.Noop{})
+	queue.SetClipboard(false)
+	queue.Start()
+	defer queue.Stop()
+
+	h := NewHandlers(db.DownloadRepo, db.AccountRepo, queue, domain.DownloadOptions{})
+	partial := filepath.Join(outDir, "generic", ".partial", fmt.Sprint(running))
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(filepath.Join(partial, "video.mp4.part")); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("fake yt-dlp didn't create the partial file after 5s")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	if resp := h.HandleCancel(ctx, json.RawMessage(fmt.Sprintf(`{"id": %d}`, running))); !resp.Success {
+		t.Fatalf("HandleCancel() failed: %s", resp.Error)
+	}
+	for {
+		dl, err := db.DownloadRepo.GetByID(ctx, running)
+		if err != nil {
+			t.Fatalf("GetByID() error: %v", err)
+		}
+		if dl.Status == domain.StatusFailed {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("download still %s after 5s, want failed", dl.Status)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if _, err := os.Stat(partial); !os.IsNotExist(err) {
+		t.Errorf("partial dir of the cancelled download still exists (stat error: %v)", err)
+	}
+
+	// Una descarga fallida con restos de un shutdown: se limpian al eliminarla
+	failed, _ := db.DownloadRepo.Create(ctx, &domain.Download{URL: "https://example.com/other.mp4", Platform: "generic", Status: domain.StatusFailed})
+	leftover := filepath.Join(outDir, "generic", ".partial", fmt.Sprint(failed))
+	if err := os.MkdirAll(leftover, 0755); err != nil {
+		t.Fatalf("failed to create partial dir: %v", err)
+	}
+	if resp := h.HandleDelete(ctx, json.RawMessage(fmt.Sprintf(`{"id": %d}`, failed))); !resp.Success {
+		t.Fatalf("HandleDelete() failed: %s", resp.Error)
+	}
+	if _, err := os.Stat(leftover); !os.IsNotExist(err) {
+		t.Errorf("partial dir of the deleted download still exists (stat error: %v)", err)
+	}
+}
+
+// fakeAudioExtractor simula la extracción de audio: renombra cada archivo a .mp3
+type fakeAudioExtractor struct{}
+
+func (fakeAudioExtractor) Process(ctx context.Context, inputPath string, options *domain.DownloadOptions) (string, error) {
+	outputPath := strings.TrimSuffix(inputPath, filepath.Ext(inputPath)) + ".mp3"
+	return outputPath, os.Rename(inputPath, outputPath)
+}
+
+func (fakeAudioExtractor) NeedsProcessing(inputPath string, options *domain.DownloadOptions) (bool, error) {
+	return filepath.Ext(inputPath) != ".mp3", nil
+}
+
+func (fakeAudioExtractor) VerifyOutput(ctx context.Context, path string, options *domain.DownloadOptions) error {
+	return nil
+}
+
+func TestQueueManager_AudioOnlyGallery(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// gallery-dl falso que baja dos videos en el directorio de -D
+	binDir := filepath.Join(tmpDir, "bin")
+	if err := os.MkdirAll(binDir, 0755); err != nil {
+		t.Fatalf("failed to create bin dir: %v", err)
+	}
+	script := "#!/bin/sh\nwhile [ $# -gt 0 ]; do [ \"$1\" = -D ] && d=\"$2\"; shift; done\n" +
+		"echo a > \"$d/clip_1.mp4\"\necho b > \"$d/clip_2.mp4\"\necho \"$d/clip_1.mp4\"\necho \"$d/clip_2.mp4\"\n"
+	if err := os.WriteFile(filepath.Join(binDir, "gallery-dl"), []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write fake gallery-dl: %v", err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	db, err := sqlite.NewDatabase(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	id, err := db.DownloadRepo.Create(ctx, &domain.Download{
+		URL:      "https://www.reddit.com/gallery/abc123",
+		Platform: "reddit",
+		Status:   domain.StatusPending,
+		Options:  domain.DownloadOptions{AudioOnly: true},
+	})
+	if err != nil {
+		t.Fatalf("failed to create download: %v", err)
+	}
+
+	downloaderMgr := downloader.NewManager(filepath.Join(tmpDir, "out"), tmpDir, "", db.AccountRepo)
+	queue := NewQueueManager(db.DownloadRepo, downloaderMgr, fakeAudioExtractor{}, 1)
+	queue.SetPollInterval(50 * time.Millisecond)
+	queue.SetNotifier(notify.Noop{})
+	queue.SetClipboard(false)
+	queue.Start()
+	defer queue.Stop()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		dl, err := db.DownloadRepo.GetByID(ctx, id)
+		if err != nil {
+			t.Fatalf("GetByID() error: %v", err)
+		}
+		if dl.Status == domain.StatusFailed {
+			t.Fatalf("download failed: %s", dl.ErrorMessage)
+		}
+		if dl.Status == domain.StatusCompleted {
+			if len(dl.Files) != 2 {
+				t.Fatalf("files = %v, want 2", dl.Files)
+			}
+			for _, file := range dl.Files {
+				if filepath.Ext(file) != ".mp3" {
+					t.Errorf("file %s was not converted to audio", file)
+				}
+				if _, err := os.Stat(file); err != nil {
+					t.Errorf("audio file missing: %v", err)
+				}
+			}
+			if dl.OutputPath != dl.Files[0] {
+				t.Errorf("output_path = %q, want %q", dl.OutputPath, dl.Files[0])
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("download still %s after 5s, want completed", dl.Status)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
@@ -2,12 +2,16 @@ package daemon
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net"
 	"os"
 	"path/filepath"
+
+	"github.com/elsanchez/smart-download/internal/protocol"
 )
 
 // Server es el servidor Unix socket
@@ -16,12 +20,14 @@ type Server struct {
 	listener   net.Listener
 	queue      *QueueManager
 	handlers   *Handlers
+	token      string // Token compartido; vacío = auth deshabilitada
 }
 
 // Request representa una petición al daemon
 type Request struct {
 	Action  string          `json:"action"`
 	Payload json.RawMessage `json:"payload"`
+	Token   string          `json:"token,omitempty"`
 }
 
 // Response representa una respuesta del daemon
@@ -31,12 +37,13 @@ type Response struct {
 	Error   string          `json:"error,omitempty"`
 }
 
-// NewServer crea un nuevo servidor
-func NewServer(socketPath string, queue *QueueManager, handlers *Handlers) *Server {
+// NewServer crea un nuevo servidor. Si token no está vacío, las peticiones deben incluirlo
+func NewServer(socketPath string, queue *QueueManager, handlers *Handlers, token string) *Server {
 	return &Server{
 		socketPath: socketPath,
 		queue:      queue,
 		handlers:   handlers,
+		token:      token,
 	}
 }
 
@@ -63,7 +70,7 @@ func (s *Server) Start(ctx context.Context) error {
 		return fmt.Errorf("chmod socket: %w", err)
 	}
 
-	log.Printf("Server listening on %s", s.socketPath)
+	slog.Info("Server listening", "socket", s.socketPath)
 
 	// Accept loop
 	go s.acceptLoop(ctx)
@@ -76,11 +83,14 @@ func (s *Server) acceptLoop(ctx context.Context) {
 	for {
 		conn, err := s.listener.Accept()
 		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
 			select {
 			case <-ctx.Done():
 				return
 			default:
-				log.Printf("Accept error: %v", err)
+				slog.Error("Accept failed", "error", err)
 				continue
 			}
 		}
@@ -94,26 +104,66 @@ func (s *Server) handleConnection(ctx context.Context, conn net.Conn) {
 	defer conn.Close()
 
 	var req Request
-	decoder := json.NewDecoder(conn)
-
-	if err := decoder.Decode(&req); err != nil {
+	if err := protocol.ReadMessage(conn, &req); err != nil {
+		// Cliente antiguo: responder sin framing para que pueda mostrar el error
+		if errors.Is(err, protocol.ErrLegacyMessage) {
+			json.NewEncoder(conn).Encode(Response{
+				Success: false,
+				Error:   fmt.Sprintf("unsupported protocol: daemon requires protocol v%d, please update smd", protocol.Version),
+			})
+			return
+		}
 		s.sendError(conn, fmt.Errorf("decode request: %w", err))
 		return
 	}
 
-	log.Printf("Received request: action=%s", req.Action)
+	// Autenticación (solo si hay token configurado)
+	if s.token != "" && subtle.ConstantTimeCompare([]byte(req.Token), []byte(s.token)) != 1 {
+		slog.Warn("Rejected unauthorized request", "action", req.Action)
+		protocol.WriteMessage(conn, Response{Success: false, Error: "unauthorized"})
+		return
+	}
+
+	// Debug: los clientes que refrescan (smd tui) envían una petición cada pocos segundos
+	slog.Debug("Received request", "action", req.Action)
 
 	// Routing
 	var resp Response
 	switch req.Action {
 	case "add":
 		resp = s.handlers.HandleAdd(ctx, req.Payload)
+	case "resolve":
+		resp = s.handlers.HandleResolve(ctx, req.Payload)
+	case "info":
+		resp = s.handlers.HandleInfo(ctx, req.Payload)
 	case "status":
 		resp = s.handlers.HandleStatus(ctx, req.Payload)
 	case "list":
 		resp = s.handlers.HandleList(ctx, req.Payload)
 	case "stats":
 		resp = s.handlers.HandleStats(ctx)
+	case "set-workers":
+		resp = s.handlers.HandleSetWorkers(ctx, req.Payload)
+	case "pause":
+		resp = s.handlers.HandlePause(ctx)
+	case "resume":
+		resp = s.handlers.HandleResume(ctx)
+	case "cancel":
+		resp = s.handlers.HandleCancel(ctx, req.Payload)
+	case "retry":
+		resp = s.handlers.HandleRetry(ctx, req.Payload)
+	case "requeue":
+		resp = s.handlers.HandleRequeue(ctx, req.Payload)
+	case "delete":
+		resp = s.handlers.HandleDelete(ctx, req.Payload)
+	case "export":
+		resp = s.handlers.HandleExport(ctx, req.Payload)
+	case "tag":
+		resp = s.handlers.HandleTag(ctx, req.Payload)
+	case "prune":
+		resp = s.handlers.HandlePrune(ctx, req.Payload)
+	case "health":
+		resp = s.handlers.HandleHealth(ctx)
 	case "ping":
 		resp = Response{Success: true, Data: json.RawMessage(`{"message":"pong"}`)}
 	default:
@@ -121,9 +171,8 @@ func (s *Server) handleConnection(ctx context.Context, conn net.Conn) {
 	}
 
 	// Enviar respuesta
-	encoder := json.NewEncoder(conn)
-	if err := encoder.Encode(resp); err != nil {
-		log.Printf("Failed to encode response: %v", err)
+	if err := protocol.WriteMessage(conn, resp); err != nil {
+		slog.Error("Failed to encode response", "action", req.Action, "error", err)
 	}
 }
 
@@ -133,12 +182,12 @@ func (s *Server) sendError(conn net.Conn, err error) {
 		Success: false,
 		Error:   err.Error(),
 	}
-	json.NewEncoder(conn).Encode(resp)
+	protocol.WriteMessage(conn, resp)
 }
 
 // Stop detiene el servidor
 func (s *Server) Stop() error {
-	log.Println("Server stopping...")
+	slog.Info("Server stopping")
 	if s.listener != nil {
 		return s.listener.Close()
 	}
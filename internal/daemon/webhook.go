@@ -0,0 +1,111 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Parámetros de envío del webhook: pocos intentos y timeout corto, el envío
+// ocurre fuera del worker pero no debe acumular goroutines colgadas
+const (
+	webhookAttempts = 3
+	webhookTimeout  = 10 * time.Second
+)
+
+// Webhook hace POST del estado de una descarga al terminar (completada o fallida).
+// Un *Webhook nil no envía nada
+type Webhook struct {
+	url     string
+	client  *http.Client
+	backoff time.Duration // Espera antes del segundo intento; se duplica en cada reintento
+	wg      sync.WaitGroup
+}
+
+// NewWebhook crea un webhook que envía a url
+func NewWebhook(url string) *Webhook {
+	return &Webhook{
+		url:     url,
+		client:  &http.Client{Timeout: webhookTimeout},
+		backoff: time.Second,
+	}
+}
+
+// Send envía payload en segundo plano para no bloquear al worker
+func (w *Webhook) Send(payload map[string]interface{}) {
+	if w == nil {
+		return
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		slog.Error("Failed to encode webhook payload", "error", err)
+		return
+	}
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		if err := w.post(body); err != nil {
+			slog.Warn("Webhook failed", "download_id", payload["id"], "error", err)
+		}
+	}()
+}
+
+// Wait espera a los envíos pendientes (usado en el shutdown)
+func (w *Webhook) Wait() {
+	if w == nil {
+		return
+	}
+	w.wg.Wait()
+}
+
+// post envía body con reintentos y backoff exponencial
+func (w *Webhook) post(body []byte) error {
+	var lastErr error
+	backoff := w.backoff
+
+	for attempt := 1; attempt <= webhookAttempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+
+		lastErr = w.postOnce(body)
+		if lastErr == nil {
+			return nil
+		}
+		slog.Debug("Webhook attempt failed", "attempt", attempt, "error", lastErr)
+	}
+
+	return fmt.Errorf("after %d attempts: %w", webhookAttempts, lastErr)
+}
+
+// postOnce hace un único POST; cualquier respuesta que no sea 2xx es un error
+func (w *Webhook) postOnce(body []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "smart-download")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
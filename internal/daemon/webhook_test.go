@@ -0,0 +1,79 @@
+package daemon
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWebhook_RetriesUntilSuccess(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		attempts int
+		received map[string]interface{}
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Content-Type = %q", r.Header.Get("Content-Type"))
+		}
+		json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer server.Close()
+
+	webhook := NewWebhook(server.URL)
+	webhook.backoff = time.Millisecond
+
+	webhook.Send(map[string]interface{}{"id": 7, "status": "completed", "output_path": "/tmp/video.mp4"})
+	webhook.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if attempts != 3 {
+		t.Errorf("attempts = %d, want 3", attempts)
+	}
+	if received["status"] != "completed" || received["output_path"] != "/tmp/video.mp4" {
+		t.Errorf("unexpected payload: %v", received)
+	}
+}
+
+func TestWebhook_GivesUp(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		attempts int
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attempts++
+		mu.Unlock()
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	webhook := NewWebhook(server.URL)
+	webhook.backoff = time.Millisecond
+	if err := webhook.post([]byte(`{}`)); err == nil {
+		t.Error("expected error after failed attempts")
+	}
+
+	if attempts != webhookAttempts {
+		t.Errorf("attempts = %d, want %d", attempts, webhookAttempts)
+	}
+
+	// Un *Webhook nil (deshabilitado) no debe fallar
+	var disabled *Webhook
+	disabled.Send(map[string]interface{}{"id": 1})
+	disabled.Wait()
+}
@@ -18,6 +18,23 @@ type Account struct {
 	ValidationError  *string
 }
 
+// StaleValidationAge es la antigüedad a partir de la cual una validación se considera vieja
+const StaleValidationAge = 24 * time.Hour
+
+// ValidationAge retorna el tiempo transcurrido desde la última validación (false si nunca se validó)
+func (a *Account) ValidationAge(now time.Time) (time.Duration, bool) {
+	if a.ValidatedAt == nil {
+		return 0, false
+	}
+	return now.Sub(*a.ValidatedAt), true
+}
+
+// IsValidationStale indica si la cuenta nunca se validó o su validación tiene más de maxAge
+func (a *Account) IsValidationStale(now time.Time, maxAge time.Duration) bool {
+	age, ok := a.ValidationAge(now)
+	return !ok || age > maxAge
+}
+
 // Platform constants para las plataformas soportadas
 const (
 	PlatformTwitter     = "twitter"
@@ -30,6 +47,11 @@ const (
 	PlatformTikTok      = "tiktok"
 	PlatformReddit      = "reddit"
 	PlatformSubscribeStar = "subscribestar"
+	PlatformVimeo       = "vimeo"
+	PlatformTwitch      = "twitch"
+	PlatformDailymotion = "dailymotion"
+	PlatformImgur       = "imgur"
+	PlatformDeviantArt  = "deviantart"
 )
 
 // Validation status constants
@@ -1,6 +1,14 @@
 package domain
 
-import "time"
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // DownloadStatus representa los estados posibles de una descarga
 type DownloadStatus string
@@ -13,19 +21,46 @@ const (
 	StatusFailed      DownloadStatus = "failed"
 )
 
+// Tipos de error de una descarga fallida (error_type). Vacío = sin clasificar
+const (
+	ErrorTypeAuth          = "auth"           // HTTP 401/403: cookies inválidas o sin permiso
+	ErrorTypeNeedsCookies  = "needs_cookies"  // El sitio pide iniciar sesión (bot check, contenido privado)
+	ErrorTypeAgeRestricted = "age_restricted" // Hay que confirmar la edad: solo con cookies de una cuenta logueada
+	ErrorTypeNotFound      = "not_found"      // 404, video eliminado o no disponible
+	ErrorTypeNetwork       = "network"        // DNS, conexión rechazada, timeouts de red
+	ErrorTypeTimeout       = "timeout"        // Superó su tiempo máximo
+	ErrorTypeUnsupported   = "unsupported"    // Ni yt-dlp ni gallery-dl reconocen la URL
+	ErrorTypeCorrupt       = "corrupt"        // El archivo descargado está vacío, truncado o ffprobe no lo lee
+)
+
 // Download representa una descarga en el sistema
 type Download struct {
-	ID           int64
-	URL          string
-	Platform     string
-	Username     string
-	Status       DownloadStatus
-	OutputPath   string
-	Options      DownloadOptions
-	AccountID    *int64
-	CreatedAt    time.Time
-	CompletedAt  *time.Time
-	ErrorMessage string
+	ID             int64
+	URL            string
+	Platform       string
+	Username       string
+	Status         DownloadStatus
+	OutputPath     string
+	Files          []string // Todos los archivos descargados (galerías con múltiples archivos)
+	Tags           []string // Etiquetas para agrupar descargas (normalizadas, sin duplicados)
+	Options        DownloadOptions
+	AccountID      *int64     // Cuenta fijada con --account (nil = la activa de la plataforma)
+	UsedAccountID  *int64     // Cuenta cuyas cookies usó el último intento (solo informativo)
+	Priority       int        // Mayor = se despacha antes (default 0)
+	Progress       int        // Avance 0-100 de la fase en curso (processing); 0 al cambiar de estado
+	FileSize       int64      // Bytes de los archivos finales, guardado al completarse (0 = desconocido)
+	ScheduledAfter *time.Time // No se despacha antes de este momento (nil = en cuanto haya un worker)
+	CreatedAt      time.Time
+	CompletedAt    *time.Time
+	ErrorMessage   string
+	ErrorType      string // Categoría del error (ErrorType*), solo en descargas fallidas
+	Backend        string // Downloader que la ejecutó (yt-dlp o gallery-dl); vacío = todavía no se intentó
+	ParentID       *int64 // Descarga de canal/perfil que encoló a esta (nil = añadida directamente)
+
+	// Metadatos del contenido (vacíos si no se pudieron extraer)
+	Title      string
+	Uploader   string
+	UploadDate string // YYYY-MM-DD
 }
 
 // DownloadOptions contiene las opciones de procesamiento
@@ -34,16 +69,294 @@ type DownloadOptions struct {
 	Resolution string `json:"resolution,omitempty"` // 1080p, 720p, 480p
 	AudioOnly  bool   `json:"audio_only,omitempty"`
 
+	// Format id exacto de yt-dlp (ej: "137+140", ver smd info); reemplaza el preset de Resolution
+	FormatID string `json:"format_id,omitempty"`
+
+	// Audio-only: formato (AudioFormats) y bitrate en kbps. Vacíos = mp3 a 192 kbps
+	AudioFormat  string `json:"audio_format,omitempty"`
+	AudioBitrate int    `json:"audio_bitrate,omitempty"`
+	Archive      bool   `json:"archive,omitempty"` // Usar download archive para saltar media ya descargada
+
+	// Categorías de SponsorBlock a eliminar (solo YouTube): sponsor, intro, selfpromo...
+	SponsorBlock []string `json:"sponsorblock,omitempty"`
+
+	// Argumentos extra pasados tal cual a yt-dlp/gallery-dl (sin validar)
+	ExtraArgs []string `json:"extra_args,omitempty"`
+
+	// Streams en directo: sin Live se rechazan; con Live se graba un segmento de LiveDuration
+	Live         bool   `json:"live,omitempty"`
+	LiveDuration string `json:"live_duration,omitempty"` // Duración Go, ej: "10m" (default: 10m)
+
+	// Rango de archivos a descargar con gallery-dl (--range), ej: "1-20"
+	Range string `json:"range,omitempty"`
+
+	// Canal/perfil: encolar sus últimos MaxItems items (default: 50) como descargas hijas,
+	// saltando las del download archive
+	Profile  bool `json:"profile,omitempty"`
+	MaxItems int  `json:"max_items,omitempty"`
+
+	// Tiempo máximo de la descarga (duración Go); reemplaza el download_timeout del daemon
+	Timeout string `json:"timeout,omitempty"`
+
+	// Proxy para yt-dlp y gallery-dl, ej: socks5://127.0.0.1:1080 (ver ProxySchemes)
+	Proxy string `json:"proxy,omitempty"`
+
+	// Nombre del archivo con campos al estilo de yt-dlp, ej: "%(uploader)s_%(title)s" (ver
+	// FilenameFields). Vacío = platform_username_DDMMYYYY
+	FilenameTemplate string `json:"filename_template,omitempty"`
+
 	// Clipping
 	ClipStart string `json:"clip_start,omitempty"` // Formato: HH:MM:SS o SS
 	ClipEnd   string `json:"clip_end,omitempty"`   // Formato: HH:MM:SS o SS
 
 	// Conversión a GIF
-	ConvertToGIF bool `json:"convert_to_gif,omitempty"`
-	GIFWidth     int  `json:"gif_width,omitempty"` // Default: 480px
+	ConvertToGIF bool   `json:"convert_to_gif,omitempty"`
+	GIFWidth     int    `json:"gif_width,omitempty"`    // Default: 480px
+	GIFStart     string `json:"gif_start,omitempty"`    // Desde dónde empieza el GIF: 00:10, 1m30s o segundos (default: 0)
+	GIFDuration  string `json:"gif_duration,omitempty"` // Duración del GIF, mismos formatos (default: hasta el final)
+	GIFFPS       int    `json:"gif_fps,omitempty"`      // Cuadros por segundo (default: 15)
+	GIFDither    string `json:"gif_dither,omitempty"`   // Dithering de paletteuse (default: bayer)
 
 	// Post-procesamiento
-	NoConvert bool `json:"no_convert,omitempty"` // Desactivar conversión automática a WhatsApp MP4
+	NoConvert *bool  `json:"no_convert,omitempty"` // Desactivar conversión automática a MP4 (nil = defaults.no_convert)
+	Target    string `json:"target,omitempty"`     // Perfil de la conversión: whatsapp (default), telegram o instagram
+
+	// Edición de imagen en la conversión
+	Rotate int    `json:"rotate,omitempty"` // Grados en sentido horario: 90, 180 o 270
+	Crop   string `json:"crop,omitempty"`   // "w:h:x:y" o "auto" (bordes negros con cropdetect)
+
+	// Factor de velocidad: 2 = el doble de rápido (timelapse), 0.5 = cámara lenta. 0 = sin cambio
+	Speed float64 `json:"speed,omitempty"`
+
+	// Quitar la pista de audio en la conversión (videos sin sonido, más livianos)
+	RemoveAudio bool `json:"remove_audio,omitempty"`
+
+	// Agregar una pista de audio muda si el video no tiene ninguna (algunas plataformas
+	// rechazan o muestran como GIF los videos sin audio)
+	EnsureAudio bool `json:"ensure_audio,omitempty"`
+
+	// Conservar los canales del audio (5.1, 7.1) en lugar de mezclarlos a estéreo
+	KeepChannels bool `json:"keep_channels,omitempty"`
+
+	// No convertir los videos HDR a SDR (por defecto se aplica tonemapping: en pantallas
+	// sin HDR se ven grises)
+	NoTonemap bool `json:"no_tonemap,omitempty"`
+
+	// Metadata en la conversión: por defecto se copian los tags del original; StripMetadata
+	// los quita (privacidad). Título y comentario se fijan en ambos casos
+	StripMetadata   bool   `json:"strip_metadata,omitempty"`
+	MetadataTitle   string `json:"metadata_title,omitempty"`
+	MetadataComment string `json:"metadata_comment,omitempty"`
+}
+
+// PreserveMetadata indica si la conversión copia los tags del original (default: sí)
+func (o *DownloadOptions) PreserveMetadata() bool {
+	return !o.StripMetadata
+}
+
+// Formatos de audio-only soportados (yt-dlp --audio-format y la extracción con ffmpeg)
+var AudioFormats = []string{"mp3", "opus", "m4a", "flac"}
+
+// Defaults de audio-only, los que se usaban antes de poder configurarlos
+const (
+	DefaultAudioFormat  = "mp3"
+	DefaultAudioBitrate = 192 // kbps
+)
+
+// Audio retorna el formato y bitrate (kbps) de audio-only, con los defaults si no se indicaron
+func (o *DownloadOptions) Audio() (format string, bitrate int) {
+	format, bitrate = o.AudioFormat, o.AudioBitrate
+	if format == "" {
+		format = DefaultAudioFormat
+	}
+	if bitrate == 0 {
+		bitrate = DefaultAudioBitrate
+	}
+	return format, bitrate
+}
+
+// SkipsConversion indica si se desactivó la conversión automática al MP4 del perfil
+func (o *DownloadOptions) SkipsConversion() bool {
+	return o.NoConvert != nil && *o.NoConvert
+}
+
+// ValidateAudio verifica el formato y bitrate de audio-only, para no llegar a yt-dlp con un typo
+func (o *DownloadOptions) ValidateAudio() error {
+	if o.AudioFormat != "" && !slices.Contains(AudioFormats, o.AudioFormat) {
+		return fmt.Errorf("unsupported audio format %q (use %s)", o.AudioFormat, strings.Join(AudioFormats, ", "))
+	}
+	if o.AudioBitrate != 0 && (o.AudioBitrate < 32 || o.AudioBitrate > 512) {
+		return fmt.Errorf("invalid audio bitrate %d kbps (use 32-512)", o.AudioBitrate)
+	}
+	return nil
+}
+
+// Validate verifica las combinaciones de opciones que no tienen sentido juntas, para que el
+// CLI y el socket rechacen lo mismo. Lo específico de cada backend (rotación, crop, rango,
+// perfiles) se valida en el daemon con sus paquetes
+func (o *DownloadOptions) Validate() error {
+	if err := o.ValidateAudio(); err != nil {
+		return err
+	}
+
+	if o.ConvertToGIF && o.AudioOnly {
+		return fmt.Errorf("convert_to_gif and audio_only can't be combined")
+	}
+	if o.MaxItems < 0 {
+		return fmt.Errorf("invalid max_items %d (must be positive)", o.MaxItems)
+	}
+	if o.MaxItems > 0 && !o.Profile {
+		return fmt.Errorf("max_items requires profile")
+	}
+	if o.GIFWidth < 0 {
+		return fmt.Errorf("invalid gif_width %d (must be positive)", o.GIFWidth)
+	}
+	if o.RemoveAudio && o.AudioOnly {
+		return fmt.Errorf("remove_audio and audio_only can't be combined")
+	}
+	if o.EnsureAudio && o.RemoveAudio {
+		return fmt.Errorf("ensure_audio and remove_audio can't be combined")
+	}
+	if o.KeepChannels && o.RemoveAudio {
+		return fmt.Errorf("keep_channels and remove_audio can't be combined")
+	}
+	if o.FilenameTemplate != "" {
+		if err := ValidateFilenameTemplate(o.FilenameTemplate); err != nil {
+			return err
+		}
+	}
+
+	// Clip: sin inicio se recorta desde el principio, pero un inicio sin fin no es un clip
+	if o.ClipStart != "" && o.ClipEnd == "" {
+		return fmt.Errorf("clip_start requires clip_end")
+	}
+	return ValidateClip(o.ClipStart, o.ClipEnd)
+}
+
+// ValidateClip verifica los tiempos de un clip: cada extremo es opcional, pero con ambos el
+// fin tiene que ser posterior. smd convert lo usa directo porque ahí un inicio sin fin
+// recorta hasta el final
+func ValidateClip(clipStart, clipEnd string) error {
+	var start, end float64
+	var err error
+	if clipStart != "" {
+		if start, err = ParseSeconds(clipStart); err != nil {
+			return fmt.Errorf("clip_start: %w", err)
+		}
+	}
+	if clipEnd != "" {
+		if end, err = ParseSeconds(clipEnd); err != nil {
+			return fmt.Errorf("clip_end: %w", err)
+		}
+		if end <= start {
+			return fmt.Errorf("clip_end (%s) must be after clip_start (%s)", clipEnd, clipStart)
+		}
+	}
+
+	return nil
+}
+
+// ParseSeconds convierte un tiempo a segundos. Formatos: duración Go ("1m30s"), segundos
+// ("90"), HH:MM:SS ("00:01:30") o MM:SS ("01:30")
+func ParseSeconds(value string) (float64, error) {
+	if duration, err := time.ParseDuration(value); err == nil {
+		return duration.Seconds(), nil
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return seconds, nil
+	}
+
+	parts := strings.Split(value, ":")
+	if len(parts) == 2 {
+		parts = append([]string{"0"}, parts...)
+	}
+	if len(parts) == 3 {
+		h, err1 := strconv.Atoi(parts[0])
+		m, err2 := strconv.Atoi(parts[1])
+		s, err3 := strconv.ParseFloat(parts[2], 64)
+		if err1 == nil && err2 == nil && err3 == nil {
+			return float64(h*3600+m*60) + s, nil
+		}
+	}
+
+	return 0, fmt.Errorf("invalid time format: %s (expected: 1m30s, 90, 01:30 or 00:01:30)", value)
+}
+
+// Esquemas de proxy que aceptan yt-dlp y gallery-dl
+var ProxySchemes = []string{"http", "https", "socks4", "socks4a", "socks5", "socks5h"}
+
+// ValidateProxy verifica que el proxy sea una URL con esquema soportado y host
+func ValidateProxy(proxy string) error {
+	u, err := url.Parse(proxy)
+	if err != nil || !slices.Contains(ProxySchemes, u.Scheme) || u.Host == "" {
+		return fmt.Errorf("invalid proxy %q (use scheme://host:port with %s)", proxy, strings.Join(ProxySchemes, ", "))
+	}
+	return nil
+}
+
+// FilenameFields son los campos de un filename template: platform, username y date (día de
+// la descarga, DDMMYYYY) los completa el daemon; uploader, title y upload_date (YYYYMMDD)
+// salen de los metadatos del downloader
+var FilenameFields = []string{"platform", "username", "date", "uploader", "title", "upload_date"}
+
+// filenameField reconoce un campo del template: %(title)s
+var filenameField = regexp.MustCompile(`%\((\w+)\)s`)
+
+// ValidateFilenameTemplate verifica que el template use solo FilenameFields, al menos uno
+// (si no, todas las descargas tendrían el mismo nombre), y sin directorios: el archivo
+// queda en el directorio de la plataforma
+func ValidateFilenameTemplate(template string) error {
+	if strings.ContainsAny(template, `/\`) {
+		return fmt.Errorf("invalid filename template %q (no directories allowed)", template)
+	}
+
+	matches := filenameField.FindAllStringSubmatch(template, -1)
+	if len(matches) == 0 {
+		return fmt.Errorf("invalid filename template %q (use fields such as %%(title)s: %s)", template, strings.Join(FilenameFields, ", "))
+	}
+	for _, match := range matches {
+		if !slices.Contains(FilenameFields, match[1]) {
+			return fmt.Errorf("invalid filename template field %q (use %s)", match[1], strings.Join(FilenameFields, ", "))
+		}
+	}
+
+	// Un "%" fuera de un campo (p.ej. "%(title)") confundiría a yt-dlp
+	if strings.Contains(filenameField.ReplaceAllString(template, ""), "%") {
+		return fmt.Errorf("invalid filename template %q (fields look like %%(title)s)", template)
+	}
+
+	return nil
+}
+
+// FilenameTemplateFields reemplaza cada campo del template por lo que retorne replace
+func FilenameTemplateFields(template string, replace func(field string) string) string {
+	return filenameField.ReplaceAllStringFunc(template, func(match string) string {
+		return replace(filenameField.FindStringSubmatch(match)[1])
+	})
+}
+
+// NormalizeTag limpia una etiqueta: sin espacios alrededor y en minúsculas
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// AddTags añade etiquetas normalizadas, ignorando las vacías y las repetidas
+func (d *Download) AddTags(tags ...string) {
+	for _, tag := range tags {
+		tag = NormalizeTag(tag)
+		if tag == "" || slices.Contains(d.Tags, tag) {
+			continue
+		}
+		d.Tags = append(d.Tags, tag)
+	}
+}
+
+// RemoveTags elimina etiquetas (las que no existen se ignoran)
+func (d *Download) RemoveTags(tags ...string) {
+	for _, tag := range tags {
+		tag = NormalizeTag(tag)
+		d.Tags = slices.DeleteFunc(d.Tags, func(t string) bool { return t == tag })
+	}
 }
 
 // IsCompleted retorna true si la descarga está completa o falló
@@ -51,6 +364,19 @@ func (d *Download) IsCompleted() bool {
 	return d.Status == StatusCompleted || d.Status == StatusFailed
 }
 
+// DisplayName retorna el título o, si no se conoce, la URL
+func (d *Download) DisplayName() string {
+	if d.Title != "" {
+		return d.Title
+	}
+	return d.URL
+}
+
+// IsScheduledLater retorna true si la descarga está programada para después de now
+func (d *Download) IsScheduledLater(now time.Time) bool {
+	return d.ScheduledAfter != nil && d.ScheduledAfter.After(now)
+}
+
 // IsActive retorna true si la descarga está en proceso
 func (d *Download) IsActive() bool {
 	return d.Status == StatusDownloading || d.Status == StatusProcessing
@@ -0,0 +1,85 @@
+package domain
+
+import "testing"
+
+func TestDownloadOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		options DownloadOptions
+		wantErr bool
+	}{
+		{"empty", DownloadOptions{}, false},
+		{"gif", DownloadOptions{ConvertToGIF: true, GIFWidth: 320}, false},
+		{"gif with audio only", DownloadOptions{ConvertToGIF: true, AudioOnly: true}, true},
+		{"negative gif width", DownloadOptions{ConvertToGIF: true, GIFWidth: -1}, true},
+		{"remove audio with audio only", DownloadOptions{RemoveAudio: true, AudioOnly: true}, true},
+		{"ensure audio with remove audio", DownloadOptions{EnsureAudio: true, RemoveAudio: true}, true},
+		{"keep channels", DownloadOptions{KeepChannels: true}, false},
+		{"keep channels with remove audio", DownloadOptions{KeepChannels: true, RemoveAudio: true}, true},
+		{"unsupported audio format", DownloadOptions{AudioOnly: true, AudioFormat: "wav"}, true},
+		{"clip", DownloadOptions{ClipStart: "10s", ClipEnd: "00:00:30"}, false},
+		{"clip start only", DownloadOptions{ClipStart: "1m"}, true},
+		{"clip end only", DownloadOptions{ClipEnd: "30"}, false},
+		{"clip end before start", DownloadOptions{ClipStart: "1m", ClipEnd: "30s"}, true},
+		{"empty clip", DownloadOptions{ClipStart: "01:00", ClipEnd: "60"}, true},
+		{"invalid clip start", DownloadOptions{ClipStart: "abc"}, true},
+		{"invalid clip end", DownloadOptions{ClipEnd: "1:2:3:4"}, true},
+		{"profile", DownloadOptions{Profile: true, MaxItems: 20}, false},
+		{"max items without profile", DownloadOptions{MaxItems: 20}, true},
+		{"negative max items", DownloadOptions{Profile: true, MaxItems: -1}, true},
+		{"filename template", DownloadOptions{FilenameTemplate: "%(uploader)s - %(title)s [%(upload_date)s]"}, false},
+		{"filename template without fields", DownloadOptions{FilenameTemplate: "video"}, true},
+		{"filename template with unknown field", DownloadOptions{FilenameTemplate: "%(id)s"}, true},
+		{"filename template with directory", DownloadOptions{FilenameTemplate: "%(uploader)s/%(title)s"}, true},
+		{"filename template with stray percent", DownloadOptions{FilenameTemplate: "%(title)s_100%"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.options.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateClip(t *testing.T) {
+	// smd convert acepta un inicio sin fin (recorta hasta el final)
+	if err := ValidateClip("1m", ""); err != nil {
+		t.Errorf("ValidateClip(1m, \"\") error = %v, want nil", err)
+	}
+	if err := ValidateClip("", "30"); err != nil {
+		t.Errorf("ValidateClip(\"\", 30) error = %v, want nil", err)
+	}
+	if err := ValidateClip("1m", "30s"); err == nil {
+		t.Error("ValidateClip(1m, 30s) error = nil, want end before start")
+	}
+}
+
+func TestParseSeconds(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    float64
+		wantErr bool
+	}{
+		{"1m30s", 90, false},
+		{"90", 90, false},
+		{"2.5", 2.5, false},
+		{"00:01:30", 90, false},
+		{"01:30", 90, false},
+		{"1:00:00", 3600, false},
+		{"1:2:3:4", 0, true},
+		{"abc", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseSeconds(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSeconds(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseSeconds(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
@@ -1,12 +1,15 @@
 package downloader
 
 import (
+	"fmt"
 	"net/url"
 	"regexp"
 	"strings"
+
+	"github.com/elsanchez/smart-download/internal/domain"
 )
 
-// Plataformas que funcionan mejor con gallery-dl
+// Plataformas que funcionan mejor con gallery-dl (ampliable con routing.gallery_dl_sites)
 var galleryDLSites = []string{
 	"pixiv.net",
 	"artstation.com",
@@ -24,6 +27,7 @@ var galleryDLSites = []string{
 	"gelbooru.com",
 	"rule34.xxx",
 	"subscribestar.com",
+	"subscribestar.adult",
 	"gumroad.com",
 	"discord.com",
 }
@@ -47,7 +51,7 @@ func DetectPlatform(urlStr string) string {
 		return "dailymotion"
 	case strings.Contains(urlStr, "twitch.tv"):
 		return "twitch"
-	case strings.Contains(urlStr, "reddit.com"):
+	case strings.Contains(urlStr, "reddit.com"), strings.Contains(urlStr, "redd.it"):
 		return "reddit"
 	case strings.Contains(urlStr, "imgur.com"):
 		return "imgur"
@@ -57,22 +61,24 @@ func DetectPlatform(urlStr string) string {
 		return "fanbox"
 	case strings.Contains(urlStr, "fantia.jp"):
 		return "fantia"
+	case strings.Contains(urlStr, "deviantart.com"):
+		return "deviantart"
+	case strings.Contains(urlStr, "subscribestar.com"), strings.Contains(urlStr, "subscribestar.adult"):
+		return "subscribestar"
 	default:
 		return "other"
 	}
 }
 
 // NeedsGalleryDL verifica si la URL debe usar gallery-dl en lugar de yt-dlp
+// (galleryDLSites, reglas de reddit y el routing configurado)
 func NeedsGalleryDL(urlStr string) bool {
-	urlStr = strings.ToLower(urlStr)
-
-	for _, site := range galleryDLSites {
-		if strings.Contains(urlStr, site) {
-			return true
-		}
-	}
+	return BackendFor(urlStr) == BackendGalleryDl
+}
 
-	return false
+// BackendFor retorna el downloader que usará el Manager para la URL (BackendYtDlp o BackendGalleryDl)
+func BackendFor(urlStr string) string {
+	return currentRouting().backend(urlStr)
 }
 
 // ExtractUsername extrae el username desde la URL
@@ -142,3 +148,33 @@ func GetCookieRequirementLevel(platform string) int {
 		return 0 // Opcional
 	}
 }
+
+// CookieHint sugiere importar cookies tras un error de autenticación, con un tono
+// según lo necesarias que sean las cookies en la plataforma
+func CookieHint(platform string) string {
+	command := fmt.Sprintf("run smd cookies import <file> --platform %s --activate", platform)
+
+	switch GetCookieRequirementLevel(platform) {
+	case 2:
+		return fmt.Sprintf("%s requires cookies: %s", platform, command)
+	case 1:
+		return fmt.Sprintf("cookies are recommended for %s: %s", platform, command)
+	default:
+		return fmt.Sprintf("this %s content needs cookies: %s", platform, command)
+	}
+}
+
+// RevalidateHint sugiere revisar las cookies de una cuenta marcada como vencida o inválida,
+// que probablemente causaron el error de autenticación ("" si la cuenta no está marcada así)
+func RevalidateHint(acc *domain.Account) string {
+	if acc == nil || (acc.ValidationStatus != domain.ValidationStatusExpired && acc.ValidationStatus != domain.ValidationStatusInvalid) {
+		return ""
+	}
+	return fmt.Sprintf("the cookies of %s account %q are marked %s: run smd cookies validate --platform %s or re-import them",
+		acc.Platform, acc.Name, acc.ValidationStatus, acc.Platform)
+}
+
+// NeedsCookieHint retorna true si el tipo de error indica que faltan cookies
+func NeedsCookieHint(errType string) bool {
+	return errType == domain.ErrorTypeAuth || errType == domain.ErrorTypeNeedsCookies || errType == domain.ErrorTypeAgeRestricted
+}
@@ -1,6 +1,11 @@
 package downloader
 
-import "testing"
+import (
+	"strings"
+	"testing"
+
+	"github.com/elsanchez/smart-download/internal/domain"
+)
 
 func TestDetectPlatform(t *testing.T) {
 	tests := []struct {
@@ -16,6 +21,9 @@ func TestDetectPlatform(t *testing.T) {
 		{"https://vimeo.com/123456789", "vimeo"},
 		{"https://www.reddit.com/r/videos/comments/abc/", "reddit"},
 		{"https://pixiv.net/en/artworks/123456", "pixiv"},
+		{"https://www.deviantart.com/artist/art/title-123", "deviantart"},
+		{"https://www.subscribestar.com/creator", "subscribestar"},
+		{"https://subscribestar.adult/creator/posts/123", "subscribestar"},
 		{"https://unknown-site.com/video", "other"},
 	}
 
@@ -38,9 +46,11 @@ func TestNeedsGalleryDL(t *testing.T) {
 		{"https://twitter.com/user/status/123", false},
 		{"https://pixiv.net/en/artworks/123456", true},
 		{"https://fanbox.cc/@user/posts/123", true},
-		{"https://www.reddit.com/r/pics/comments/abc/", true},
+		{"https://www.reddit.com/r/pics/comments/abc/", false},
 		{"https://imgur.com/gallery/abc123", true},
 		{"https://kemono.party/patreon/user/123", true},
+		{"https://www.subscribestar.com/creator", true},
+		{"https://subscribestar.adult/creator/posts/123", true},
 		{"https://www.instagram.com/p/ABC123/", false},
 	}
 
@@ -103,6 +113,27 @@ func TestGetCookieRequirementLevel(t *testing.T) {
 	}
 }
 
+func TestCookieHint(t *testing.T) {
+	tests := []struct {
+		platform string
+		prefix   string
+	}{
+		{"twitter", "twitter requires cookies"},
+		{"reddit", "cookies are recommended for reddit"},
+		{"youtube", "this youtube content needs cookies"},
+	}
+
+	for _, tt := range tests {
+		hint := CookieHint(tt.platform)
+		if !strings.HasPrefix(hint, tt.prefix) {
+			t.Errorf("CookieHint(%q) = %q, want prefix %q", tt.platform, hint, tt.prefix)
+		}
+		if !strings.Contains(hint, "smd cookies import <file> --platform "+tt.platform) {
+			t.Errorf("CookieHint(%q) = %q, want the import command", tt.platform, hint)
+		}
+	}
+}
+
 func TestSanitizeFilename(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -125,3 +156,87 @@ func TestSanitizeFilename(t *testing.T) {
 		})
 	}
 }
+
+func TestRouting_Reddit(t *testing.T) {
+	r := newRouting(nil, nil)
+
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://v.redd.it/abc123", BackendYtDlp},
+		{"https://i.redd.it/abc123.jpg", BackendGalleryDl},
+		{"https://preview.redd.it/abc123.png?width=640", BackendGalleryDl},
+		{"https://www.reddit.com/gallery/abc123", BackendGalleryDl},
+		{"https://www.reddit.com/r/pics/comments/abc/some_title/", BackendYtDlp},
+		{"https://www.reddit.com/user/spez/", BackendGalleryDl},
+	}
+
+	for _, tt := range tests {
+		if got := r.backend(tt.url); got != tt.want {
+			t.Errorf("backend(%q) = %s, want %s", tt.url, got, tt.want)
+		}
+	}
+
+	if got := DetectPlatform("https://v.redd.it/abc123"); got != "reddit" {
+		t.Errorf("DetectPlatform(v.redd.it) = %q, want reddit", got)
+	}
+}
+
+func TestRouting_Config(t *testing.T) {
+	r := newRouting([]string{"example-gallery.net"}, map[string]string{
+		"reddit.com":     BackendGalleryDl,
+		"old.reddit.com": BackendYtDlp,
+		"Imgur.com":      BackendYtDlp,
+	})
+
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://example-gallery.net/album/1", BackendGalleryDl},
+		{"https://www.reddit.com/r/pics/comments/abc/", BackendGalleryDl},
+		{"https://old.reddit.com/r/pics/comments/abc/", BackendYtDlp}, // El dominio más específico gana
+		{"https://imgur.com/gallery/abc123", BackendYtDlp},
+		{"https://pixiv.net/en/artworks/123456", BackendGalleryDl},
+		{"https://www.youtube.com/watch?v=123", BackendYtDlp},
+	}
+
+	for _, tt := range tests {
+		if got := r.backend(tt.url); got != tt.want {
+			t.Errorf("backend(%q) = %s, want %s", tt.url, got, tt.want)
+		}
+	}
+
+	if err := ConfigureRouting(nil, map[string]string{"example.com": "wget"}); err == nil {
+		t.Error("ConfigureRouting() with an unknown backend: want an error")
+	}
+}
+
+func TestRevalidateHint(t *testing.T) {
+	tests := []struct {
+		status string
+		want   bool
+	}{
+		{domain.ValidationStatusExpired, true},
+		{domain.ValidationStatusInvalid, true},
+		{domain.ValidationStatusValid, false},
+		{domain.ValidationStatusUnknown, false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		acc := &domain.Account{Platform: "youtube", Name: "main", ValidationStatus: tt.status}
+		hint := RevalidateHint(acc)
+		if got := hint != ""; got != tt.want {
+			t.Errorf("RevalidateHint(status=%q) = %q, want hint: %v", tt.status, hint, tt.want)
+		}
+		if tt.want && !strings.Contains(hint, "smd cookies validate --platform youtube") {
+			t.Errorf("RevalidateHint(status=%q) = %q, want the validate command", tt.status, hint)
+		}
+	}
+
+	if hint := RevalidateHint(nil); hint != "" {
+		t.Errorf("RevalidateHint(nil) = %q, want none", hint)
+	}
+}
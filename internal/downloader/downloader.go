@@ -2,6 +2,11 @@ package downloader
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
 
 	"github.com/elsanchez/smart-download/internal/domain"
 )
@@ -21,3 +26,90 @@ type Result struct {
 	FileSize   int64
 	Duration   float64 // segundos
 }
+
+// resolveAccount retorna la cuenta cuyas cookies usa la descarga: la fijada en
+// dl.AccountID o, si no hay, la activa de la plataforma (nil si no hay ninguna). Una cuenta
+// fijada que ya no existe es un error: usar otras cookies sin avisar bajaría con otra cuenta
+func resolveAccount(ctx context.Context, accountRepo AccountGetter, dl *domain.Download) (*domain.Account, error) {
+	if accountRepo == nil {
+		return nil, nil
+	}
+
+	if dl.AccountID != nil {
+		acc, err := accountRepo.GetByID(ctx, *dl.AccountID)
+		if err != nil || acc == nil {
+			return nil, fmt.Errorf("cookie account %d pinned to this download no longer exists (add it again with --account <name>)", *dl.AccountID)
+		}
+		return acc, nil
+	}
+
+	if acc, err := accountRepo.GetActive(ctx, dl.Platform); err == nil && acc != nil {
+		return acc, nil
+	}
+
+	return nil, nil
+}
+
+var (
+	proxyMu      sync.RWMutex
+	defaultProxy string
+)
+
+// SetDefaultProxy fija el proxy de las descargas que no indican uno (defaults.proxy del
+// config). Se aplica al ejecutar yt-dlp/gallery-dl, sin guardarse en las opciones de cada
+// descarga: puede llevar credenciales y cambiar en el config
+func SetDefaultProxy(proxy string) {
+	proxyMu.Lock()
+	defaultProxy = proxy
+	proxyMu.Unlock()
+}
+
+// proxyFor retorna el proxy de la descarga: el suyo o, si no tiene, el por defecto
+func proxyFor(dl *domain.Download) string {
+	if dl.Options.Proxy != "" {
+		return dl.Options.Proxy
+	}
+	proxyMu.RLock()
+	defer proxyMu.RUnlock()
+	return defaultProxy
+}
+
+// partialDirName es el subdirectorio (oculto) de cada plataforma con las descargas a medias
+const partialDirName = ".partial"
+
+// partialDir retorna el directorio de archivos temporales (.part, fragmentos) de una descarga.
+// Es fijo por descarga para que, si se interrumpe, el reintento continúe donde quedó
+func partialDir(platformDir string, id int64) string {
+	return filepath.Join(platformDir, partialDirName, fmt.Sprint(id))
+}
+
+// isPartialFile reporta si el archivo es un resto de una descarga sin terminar
+func isPartialFile(name string) bool {
+	return strings.HasSuffix(name, ".part") ||
+		strings.HasSuffix(name, ".ytdl") ||
+		strings.HasSuffix(name, ".temp") ||
+		strings.Contains(name, ".part-Frag")
+}
+
+// resumable reporta si vale la pena conservar los archivos a medias tras un error: la
+// descarga se interrumpió (shutdown, timeout) o falló la red, y un reintento puede continuar.
+// Con cualquier otro error se descartan. Una cancelación del usuario tampoco se distingue
+// aquí del shutdown: el daemon los elimina con Manager.RemovePartial
+func resumable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return true
+	}
+	var dlErr *DownloadError
+	return errors.As(err, &dlErr) && (dlErr.Type == domain.ErrorTypeNetwork || dlErr.Type == domain.ErrorTypeTimeout)
+}
+
+// archivePath construye el path del download archive de un backend.
+// Es por cuenta (platform_cuenta) si la descarga usa cookies, si no por plataforma
+func archivePath(archiveDir, backend, platform string, account *domain.Account, ext string) string {
+	name := platform
+	if account != nil && account.Name != "" {
+		name += "_" + sanitizeFilename(account.Name)
+	}
+
+	return filepath.Join(archiveDir, backend+"_"+name+ext)
+}
@@ -0,0 +1,141 @@
+package downloader
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/elsanchez/smart-download/internal/domain"
+)
+
+// maxErrorMessage limita el error_message guardado (la salida completa va al log)
+const maxErrorMessage = 300
+
+// DownloadError es el fallo de un downloader, clasificado a partir de su salida
+type DownloadError struct {
+	Type    string // domain.ErrorType* ("" = sin clasificar)
+	Message string // Línea de error relevante, recortada
+	Output  string // Salida completa de yt-dlp/gallery-dl
+
+	UsedCookies bool // Si la descarga se hizo con las cookies de una cuenta
+}
+
+func (e *DownloadError) Error() string {
+	return e.Message
+}
+
+// errorPatterns asocia fragmentos de la salida (en minúsculas) con un tipo de error.
+// El orden importa: la primera coincidencia gana
+var errorPatterns = []struct {
+	errType  string
+	patterns []string
+}{
+	// Antes que needs_cookies: "sign in to confirm you" también coincide con la edad
+	{domain.ErrorTypeAgeRestricted, []string{
+		"sign in to confirm your age",
+		"age-restricted",
+		"age restricted",
+		"inappropriate for some users",
+	}},
+	{domain.ErrorTypeNeedsCookies, []string{
+		"sign in to confirm you",
+		"login required",
+		"requires authentication",
+		"use --cookies",
+		"authenticationerror",
+		"this video is private",
+		"members-only",
+	}},
+	{domain.ErrorTypeAuth, []string{
+		"http error 401",
+		"http error 403",
+		"401 unauthorized",
+		"403 forbidden",
+		"authorizationerror",
+	}},
+	{domain.ErrorTypeNotFound, []string{
+		"http error 404",
+		"404 not found",
+		"video unavailable",
+		"video is not available", // No "is not available": también sale en "Requested format is not available"
+		"has been removed",
+		"does not exist",
+		"notfounderror",
+	}},
+	{domain.ErrorTypeNetwork, []string{
+		"unable to resolve",
+		"name or service not known",
+		"temporary failure in name resolution",
+		"nodename nor servname",
+		"getaddrinfo failed",
+		"connection refused",
+		"connection reset",
+		"network is unreachable",
+		"no route to host",
+		"timed out",
+	}},
+	// El downloader no tiene extractor para la URL: el Manager prueba con el otro
+	{domain.ErrorTypeUnsupported, []string{
+		"unsupported url",
+		"no suitable extractor",
+	}},
+}
+
+// isUnsupportedURL retorna true si err es un DownloadError porque el downloader no reconoce
+// la URL. Los fallos de autenticación o de red no cuentan: el otro downloader fallaría igual
+func isUnsupportedURL(err error) bool {
+	var dlErr *DownloadError
+	return errors.As(err, &dlErr) && dlErr.Type == domain.ErrorTypeUnsupported
+}
+
+// classifyOutput retorna el tipo de error según la salida del downloader ("" si no se reconoce)
+func classifyOutput(output string) string {
+	lower := strings.ToLower(output)
+	for _, group := range errorPatterns {
+		for _, pattern := range group.patterns {
+			if strings.Contains(lower, pattern) {
+				return group.errType
+			}
+		}
+	}
+	return ""
+}
+
+// newDownloadError construye el error de tool a partir de su salida: el mensaje es la
+// última línea de error (ERROR: en yt-dlp, [error] en gallery-dl) o, si no hay, la última línea
+func newDownloadError(tool string, err error, output string) *DownloadError {
+	message := ""
+	var last string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		last = line
+		if strings.HasPrefix(line, "ERROR:") || strings.Contains(line, "[error]") {
+			message = line
+		}
+	}
+	if message == "" {
+		message = last
+	}
+	if message == "" {
+		message = err.Error()
+	}
+
+	message = truncate(tool+": "+message, maxErrorMessage)
+
+	return &DownloadError{
+		Type:    classifyOutput(output),
+		Message: message,
+		Output:  output,
+	}
+}
+
+// truncate corta s a n runas (no bytes, para no partir un carácter UTF-8) y agrega "…"
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "…"
+}
@@ -0,0 +1,168 @@
+package downloader
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/elsanchez/smart-download/internal/domain"
+)
+
+func TestNewDownloadError(t *testing.T) {
+	exitErr := errors.New("exit status 1")
+
+	tests := []struct {
+		name     string
+		tool     string
+		output   string
+		wantType string
+		wantMsg  string
+	}{
+		{
+			name:     "age restricted",
+			tool:     "yt-dlp",
+			output:   "[youtube] abc: Downloading webpage\nERROR: [youtube] abc: Sign in to confirm your age. This video may be inappropriate for some users.\n",
+			wantType: domain.ErrorTypeAgeRestricted,
+			wantMsg:  "yt-dlp: ERROR: [youtube] abc: Sign in to confirm your age. This video may be inappropriate for some users.",
+		},
+		{
+			name:     "forbidden",
+			tool:     "yt-dlp",
+			output:   "[twitter] 123: Downloading guest token\nERROR: [twitter] 123: HTTP Error 403: Forbidden\n",
+			wantType: domain.ErrorTypeAuth,
+			wantMsg:  "yt-dlp: ERROR: [twitter] 123: HTTP Error 403: Forbidden",
+		},
+		{
+			name:     "unavailable",
+			tool:     "yt-dlp",
+			output:   "ERROR: [youtube] abc: Video unavailable. This video has been removed by the uploader\n",
+			wantType: domain.ErrorTypeNotFound,
+		},
+		{
+			name:     "video not available",
+			tool:     "yt-dlp",
+			output:   "ERROR: [youtube] abc: This video is not available\n",
+			wantType: domain.ErrorTypeNotFound,
+		},
+		{
+			name:     "format not available",
+			tool:     "yt-dlp",
+			output:   "ERROR: [youtube] abc: Requested format is not available. Use --list-formats for a list of available formats\n",
+			wantType: "",
+		},
+		{
+			name:     "dns",
+			tool:     "yt-dlp",
+			output:   "WARNING: retrying\nERROR: Unable to download webpage: <urlopen error [Errno -3] Temporary failure in name resolution>\n",
+			wantType: domain.ErrorTypeNetwork,
+		},
+		{
+			name:     "gallery-dl not found",
+			tool:     "gallery-dl",
+			output:   "[instagram][error] NotFoundError: Requested user could not be found\n",
+			wantType: domain.ErrorTypeNotFound,
+			wantMsg:  "gallery-dl: [instagram][error] NotFoundError: Requested user could not be found",
+		},
+		{
+			name:     "yt-dlp unsupported",
+			tool:     "yt-dlp",
+			output:   "WARNING: [generic] Falling back on generic information extractor\nERROR: Unsupported URL: https://example.com/page\n",
+			wantType: domain.ErrorTypeUnsupported,
+		},
+		{
+			name:     "gallery-dl unsupported",
+			tool:     "gallery-dl",
+			output:   "[gallery-dl][error] No suitable extractor found for 'https://example.com/page'\n",
+			wantType: domain.ErrorTypeUnsupported,
+		},
+		{
+			name:     "unknown",
+			tool:     "yt-dlp",
+			output:   "something odd\nlast line\n",
+			wantType: "",
+			wantMsg:  "yt-dlp: last line",
+		},
+		{
+			name:    "no output",
+			tool:    "yt-dlp",
+			output:  "",
+			wantMsg: "yt-dlp: exit status 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newDownloadError(tt.tool, exitErr, tt.output)
+			if err.Type != tt.wantType {
+				t.Errorf("Type = %q, want %q", err.Type, tt.wantType)
+			}
+			if tt.wantMsg != "" && err.Message != tt.wantMsg {
+				t.Errorf("Message = %q, want %q", err.Message, tt.wantMsg)
+			}
+			if err.Output != tt.output {
+				t.Error("Output should keep the full downloader output")
+			}
+		})
+	}
+}
+
+func TestNewDownloadError_TrimsMessage(t *testing.T) {
+	err := newDownloadError("yt-dlp", errors.New("exit status 1"), "ERROR: "+strings.Repeat("x", 1000))
+	if len(err.Message) > maxErrorMessage+len("…") {
+		t.Errorf("message length = %d, want at most %d", len(err.Message), maxErrorMessage)
+	}
+}
+
+func TestNewDownloadError_TrimsMultibyteMessage(t *testing.T) {
+	err := newDownloadError("yt-dlp", errors.New("exit status 1"), "ERROR: "+strings.Repeat("é", 1000))
+	if !utf8.ValidString(err.Message) {
+		t.Errorf("message %q is not valid UTF-8", err.Message)
+	}
+	if n := utf8.RuneCountInString(err.Message); n != maxErrorMessage+1 {
+		t.Errorf("message has %d runes, want %d", n, maxErrorMessage+1)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{"short", 10, "short"},
+		{"exactly", 7, "exactly"},
+		{"truncated", 5, "trunc…"},
+		{"ñandú ñandú", 5, "ñandú…"},
+		{"日本語のテキスト", 3, "日本語…"},
+	}
+
+	for _, tt := range tests {
+		if got := truncate(tt.s, tt.n); got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestIsUnsupportedURL(t *testing.T) {
+	exitErr := errors.New("exit status 1")
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"unsupported", newDownloadError("yt-dlp", exitErr, "ERROR: Unsupported URL: https://example.com\n"), true},
+		{"wrapped", fmt.Errorf("download: %w", newDownloadError("gallery-dl", exitErr, "[gallery-dl][error] No suitable extractor found for 'x'\n")), true},
+		{"auth is not retried", newDownloadError("yt-dlp", exitErr, "ERROR: HTTP Error 403: Forbidden\n"), false},
+		{"network is not retried", newDownloadError("gallery-dl", exitErr, "[error] Connection refused\n"), false},
+		{"other error", exitErr, false},
+	}
+
+	for _, tt := range tests {
+		if got := isUnsupportedURL(tt.err); got != tt.want {
+			t.Errorf("%s: isUnsupportedURL() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package downloader
+
+import (
+	"strings"
+	"time"
+
+	"github.com/elsanchez/smart-download/internal/domain"
+)
+
+// galleryDLFields traduce los campos de metadatos del filename template a gallery-dl. Sus
+// nombres varían según el extractor: las alternativas (a|b) cubren los más comunes
+var galleryDLFields = map[string]string{
+	"uploader":    "{author[name]|username}",
+	"title":       "{title|id}",
+	"upload_date": "{date:%Y%m%d}",
+}
+
+// filenameDate es la fecha del nombre de archivo (DDMMYYYY): la de creación de la descarga,
+// así el nombre no cambia al reanudarla otro día
+func filenameDate(dl *domain.Download) string {
+	created := dl.CreatedAt
+	if created.IsZero() {
+		created = time.Now()
+	}
+	return created.Format("02012006")
+}
+
+// daemonField retorna el valor de los campos que completa el daemon (platform, username,
+// date); ok = false para los que salen de los metadatos del downloader
+func daemonField(field string, dl *domain.Download) (string, bool) {
+	switch field {
+	case "platform":
+		return dl.Platform, true
+	case "username":
+		if dl.Username == "" {
+			return "user", true
+		}
+		return dl.Username, true
+	case "date":
+		return filenameDate(dl), true
+	}
+	return "", false
+}
+
+// ytdlpFilename arma el -o de yt-dlp desde el filename template: los campos del daemon se
+// reemplazan por su valor (con los "%" escapados) y el resto (%(title)s, %(uploader)s...)
+// lo completa yt-dlp
+func ytdlpFilename(template string, dl *domain.Download) string {
+	return domain.FilenameTemplateFields(template, func(field string) string {
+		if value, ok := daemonField(field, dl); ok {
+			return strings.ReplaceAll(value, "%", "%%")
+		}
+		return "%(" + field + ")s"
+	})
+}
+
+// galleryDLBraces escapa las llaves para que gallery-dl no las tome como campos
+var galleryDLBraces = strings.NewReplacer("{", "{{", "}", "}}")
+
+// galleryDLFilename arma el -f de gallery-dl (sin el "_{num}.{extension}" final) desde el
+// filename template. Las llaves del texto fijo y de los campos del daemon se escapan
+func galleryDLFilename(template string, dl *domain.Download) string {
+	escaped := galleryDLBraces.Replace(template)
+	return domain.FilenameTemplateFields(escaped, func(field string) string {
+		if value, ok := daemonField(field, dl); ok {
+			return galleryDLBraces.Replace(value)
+		}
+		return galleryDLFields[field]
+	})
+}
@@ -0,0 +1,84 @@
+package downloader
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/elsanchez/smart-download/internal/domain"
+)
+
+func TestFilenameTemplate(t *testing.T) {
+	dl := &domain.Download{
+		Platform:  "youtube",
+		Username:  "chan",
+		CreatedAt: time.Date(2025, 3, 9, 12, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		template  string
+		ytdlp     string
+		galleryDL string
+	}{
+		{"%(platform)s_%(username)s_%(date)s", "youtube_chan_09032025", "youtube_chan_09032025"},
+		{"%(upload_date)s - %(title)s", "%(upload_date)s - %(title)s", "{date:%Y%m%d} - {title|id}"},
+		{"%(platform)s_%(uploader)s", "youtube_%(uploader)s", "youtube_{author[name]|username}"},
+		{"{%(title)s}", "{%(title)s}", "{{{title|id}}}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			if got := ytdlpFilename(tt.template, dl); got != tt.ytdlp {
+				t.Errorf("ytdlpFilename() = %q, want %q", got, tt.ytdlp)
+			}
+			if got := galleryDLFilename(tt.template, dl); got != tt.galleryDL {
+				t.Errorf("galleryDLFilename() = %q, want %q", got, tt.galleryDL)
+			}
+		})
+	}
+
+	// Sin username se usa "user", como en el nombre por defecto
+	if got := ytdlpFilename("%(username)s_%(title)s", &domain.Download{Platform: "x"}); got != "user_%(title)s" {
+		t.Errorf("ytdlpFilename() without username = %q", got)
+	}
+
+	// Los valores del daemon no se interpretan como campos del downloader
+	odd := &domain.Download{Platform: "x", Username: "{a}%b"}
+	if got := ytdlpFilename("%(username)s_%(title)s", odd); got != "{a}%%b_%(title)s" {
+		t.Errorf("ytdlpFilename() with special characters = %q", got)
+	}
+	if got := galleryDLFilename("%(username)s_%(title)s", odd); got != "{{a}}%b_{title|id}" {
+		t.Errorf("galleryDLFilename() with special characters = %q", got)
+	}
+}
+
+func TestPrintedFilepath(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.mp4")
+	last := filepath.Join(dir, "last.mp4")
+	for _, path := range []string{first, last} {
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		stdout string
+		want   string
+	}{
+		{"single", last + "\n", last},
+		{"last existing wins", first + "\n" + last + "\n" + filepath.Join(dir, "gone.mp4") + "\n", last},
+		{"relative lines ignored", "last.mp4\n", ""},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := printedFilepath(tt.stdout); got != tt.want {
+				t.Errorf("printedFilepath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,29 +1,38 @@
 package downloader
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/elsanchez/smart-download/internal/domain"
 )
 
+// audioOnlyFilter es el --filter de gallery-dl para audio-only: descarta las imágenes
+const audioOnlyFilter = "extension in ('mp4', 'webm', 'mov', 'm4v', 'mkv', 'mp3', 'm4a', 'ogg', 'opus')"
+
 // GalleryDl implementa Downloader usando gallery-dl
 type GalleryDl struct {
 	outputDir   string
 	cookiesDir  string
+	archiveDir  string
 	accountRepo AccountGetter
 }
 
 // NewGalleryDl crea un nuevo downloader de gallery-dl
-func NewGalleryDl(outputDir string, cookiesDir string, accountRepo AccountGetter) *GalleryDl {
+func NewGalleryDl(outputDir string, cookiesDir string, archiveDir string, accountRepo AccountGetter) *GalleryDl {
 	return &GalleryDl{
 		outputDir:   outputDir,
 		cookiesDir:  cookiesDir,
+		archiveDir:  archiveDir,
 		accountRepo: accountRepo,
 	}
 }
@@ -40,52 +49,199 @@ func (g *GalleryDl) Download(ctx context.Context, dl *domain.Download) (string,
 	filenameBase := g.generateFilename(dl)
 
 	// Construir argumentos
+	// {num} distingue los archivos de una galería (si no, todos tendrían el mismo nombre);
+	// si se bajó uno solo se le quita al final
 	args := []string{
 		"-D", platformDir, // Destination directory
-		"-o", filenameBase + ".{extension}", // Output template
+		"-f", filenameBase + "_{num}.{extension}", // Filename format
 	}
 
-	// Cookies: siempre buscar cuenta activa para la plataforma
-	if g.accountRepo != nil {
-		account, err := g.accountRepo.GetActive(ctx, dl.Platform)
-		if err == nil && account != nil && account.CookiePath != "" {
-			args = append(args, "--cookies", account.CookiePath)
-		}
+	// Cookies: cuenta fijada en la descarga o, si no, la activa de la plataforma
+	account, err := resolveAccount(ctx, g.accountRepo, dl)
+	if err != nil {
+		return "", err
+	}
+	if account != nil && account.CookiePath != "" {
+		args = append(args, "--cookies", account.CookiePath)
+	}
+
+	// Download archive: gallery-dl usa una base SQLite propia (formato distinto al de yt-dlp)
+	if dl.Options.Archive && g.archiveDir != "" {
+		args = append(args, "--download-archive", archivePath(g.archiveDir, "gallery-dl", dl.Platform, account, ".sqlite3"))
+	}
+
+	// Rango: solo los primeros N posts de un perfil grande, etc. Con --download-archive
+	// los ya descargados dentro del rango se saltan igual
+	if dl.Options.Range != "" {
+		args = append(args, "--range", dl.Options.Range)
+	}
+
+	// Proxy (sitios bloqueados por región o salida por VPN)
+	if proxy := proxyFor(dl); proxy != "" {
+		args = append(args, "--proxy", proxy)
 	}
 
 	// Opciones adicionales
+	// --write-metadata deja un <archivo>.json por archivo, de donde se sacan título y autor
 	args = append(args,
 		"--no-check-certificate",
+		"--write-metadata",
 	)
 
-	// Modos especiales
+	// Audio-only: gallery-dl no extrae audio; se bajan solo los videos y el post-procesamiento
+	// (ExtractAudio) los convierte a MP3
 	if dl.Options.AudioOnly {
-		// gallery-dl no soporta audio-only directamente
-		// Descargar normal y luego procesar con ffmpeg
+		args = append(args, "--filter", audioOnlyFilter)
 	}
 
+	// Argumentos extra del usuario (sin validar)
+	args = append(args, dl.Options.ExtraArgs...)
+
 	// URL al final
 	args = append(args, dl.URL)
 
 	// Ejecutar gallery-dl
+	// stdout contiene un path por archivo descargado; stderr los logs
 	cmd := exec.CommandContext(ctx, "gallery-dl", args...)
-	output, err := cmd.CombinedOutput()
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
 
-	if err != nil {
-		return "", fmt.Errorf("gallery-dl failed: %w\nOutput: %s", err, output)
+	if err := cmd.Run(); err != nil {
+		dlErr := newDownloadError("gallery-dl", err, stderr.String()+stdout.String())
+		dlErr.UsedCookies = account != nil && account.CookiePath != ""
+		return "", dlErr
 	}
 
-	// Buscar el archivo descargado
-	outputPath, err := g.findDownloadedFile(platformDir, filenameBase)
-	if err != nil {
-		// Para galleries (múltiples archivos), retornar el directorio
-		if strings.Contains(string(output), "downloaded") {
-			return platformDir, nil
+	// Archivos descargados según la salida de gallery-dl
+	files := parseGalleryDlOutput(stdout.String())
+	if len(files) == 0 {
+		// Con filename template el nombre depende de los metadatos: no se adivina
+		if dl.Options.FilenameTemplate != "" {
+			return "", fmt.Errorf("gallery-dl did not report any downloaded file\ngallery-dl output: %s%s", stderr.String(), stdout.String())
+		}
+
+		// Fallback: buscar el archivo más reciente en el directorio
+		outputPath, err := g.findDownloadedFile(platformDir, filenameBase)
+		if err != nil {
+			return "", fmt.Errorf("find downloaded file: %w\ngallery-dl output: %s%s", err, stderr.String(), stdout.String())
+		}
+		files = []string{outputPath}
+	}
+
+	dl.Title, dl.Uploader, dl.UploadDate = readGalleryMetadata(files)
+
+	// {num} solo hace falta en galerías: un archivo suelto queda sin el "_1"
+	if len(files) == 1 {
+		files[0] = dropNumSuffix(files[0])
+	}
+
+	dl.Files = files
+	return files[0], nil
+}
+
+// dropNumSuffix renombra un archivo "<base>_1.<ext>" a "<base>.<ext>" y retorna el path
+// final. Si ya existe un archivo con ese nombre (o falla el rename) lo deja como está
+func dropNumSuffix(path string) string {
+	ext := filepath.Ext(path)
+	base, ok := strings.CutSuffix(strings.TrimSuffix(path, ext), "_1")
+	if !ok {
+		return path
+	}
+
+	target := base + ext
+	if _, err := os.Stat(target); err == nil {
+		return path
+	}
+	if err := os.Rename(path, target); err != nil {
+		return path
+	}
+	return target
+}
+
+// readGalleryMetadata lee título, autor y fecha del JSON de --write-metadata del primer
+// archivo y elimina los JSON de todos. Los campos dependen del sitio; lo que falte queda vacío
+func readGalleryMetadata(files []string) (title, uploader, uploadDate string) {
+	for i, file := range files {
+		metaPath := file + ".json"
+		if i == 0 {
+			if data, err := os.ReadFile(metaPath); err == nil {
+				title, uploader, uploadDate = parseGalleryMetadata(data)
+			}
+		}
+		os.Remove(metaPath)
+	}
+	return title, uploader, uploadDate
+}
+
+// parseGalleryMetadata extrae los campos comunes del metadata JSON de gallery-dl
+func parseGalleryMetadata(data []byte) (title, uploader, uploadDate string) {
+	var meta map[string]interface{}
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return "", "", ""
+	}
+
+	// Título: "title" o, en redes sociales, la primera línea del texto del post
+	title = metaString(meta, "title")
+	if title == "" {
+		for _, key := range []string{"description", "content", "caption"} {
+			if text := metaString(meta, key); text != "" {
+				title, _, _ = strings.Cut(text, "\n")
+				break
+			}
+		}
+	}
+	title = truncate(title, 200)
+
+	// Autor: string u objeto (user/author suelen ser {"name": ..., "nick": ...})
+	for _, key := range []string{"uploader", "author", "user", "artist", "username", "owner"} {
+		switch v := meta[key].(type) {
+		case string:
+			uploader = v
+		case map[string]interface{}:
+			uploader = metaString(v, "name")
+			if uploader == "" {
+				uploader = metaString(v, "nick")
+			}
+		}
+		if uploader != "" {
+			break
+		}
+	}
+
+	// Fecha: gallery-dl la normaliza como "YYYY-MM-DD HH:MM:SS"
+	if date := metaString(meta, "date"); len(date) >= 10 {
+		if _, err := time.Parse("2006-01-02", date[:10]); err == nil {
+			uploadDate = date[:10]
+		}
+	}
+
+	return strings.TrimSpace(title), uploader, uploadDate
+}
+
+// metaString retorna meta[key] si es un string
+func metaString(meta map[string]interface{}, key string) string {
+	s, _ := meta[key].(string)
+	return s
+}
+
+// parseGalleryDlOutput extrae los paths de archivos descargados de la salida de gallery-dl.
+// Las líneas con prefijo "# " son archivos saltados (ya existían) y se ignoran
+func parseGalleryDlOutput(output string) []string {
+	var files []string
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || !filepath.IsAbs(line) {
+			continue
+		}
+
+		if info, err := os.Stat(line); err == nil && !info.IsDir() {
+			files = append(files, line)
 		}
-		return "", fmt.Errorf("find downloaded file: %w\ngallery-dl output: %s", err, output)
 	}
 
-	return outputPath, nil
+	return files
 }
 
 // Supports verifica si gallery-dl soporta la URL
@@ -95,6 +251,10 @@ func (g *GalleryDl) Supports(url string) bool {
 
 // generateFilename genera el nombre de archivo base
 func (g *GalleryDl) generateFilename(dl *domain.Download) string {
+	if dl.Options.FilenameTemplate != "" {
+		return galleryDLFilename(dl.Options.FilenameTemplate, dl)
+	}
+
 	timestamp := time.Now().Format("02012006")
 
 	username := dl.Username
@@ -117,7 +277,7 @@ func (g *GalleryDl) findDownloadedFile(dir, basePattern string) (string, error)
 	var newestTime time.Time
 
 	for _, entry := range entries {
-		if entry.IsDir() {
+		if entry.IsDir() || strings.HasSuffix(entry.Name(), ".json") || isPartialFile(entry.Name()) {
 			continue
 		}
 
@@ -152,3 +312,25 @@ func CheckGalleryDlInstalled() error {
 	}
 	return nil
 }
+
+// rangePart es un elemento de --range: índice ("5"), rango ("8-20", "10-") o slice ("1:24:3")
+var rangePart = regexp.MustCompile(`^(\d+|\d+-\d*|-\d+|\d*:\d*(:\d+)?)$`)
+
+// ValidateRange verifica la sintaxis de un --range de gallery-dl, p.ej. "1-20" o "1-5,10,15-"
+func ValidateRange(r string) error {
+	for _, part := range strings.Split(r, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" || !rangePart.MatchString(part) {
+			return fmt.Errorf("invalid range %q (use e.g. 1-20, 5, 10- or 1-5,10)", r)
+		}
+
+		if start, end, ok := strings.Cut(part, "-"); ok && start != "" && end != "" {
+			a, _ := strconv.Atoi(start)
+			b, _ := strconv.Atoi(end)
+			if a > b {
+				return fmt.Errorf("invalid range %q: %s starts after it ends", r, part)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package downloader
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseGalleryDlOutput(t *testing.T) {
+	dir := t.TempDir()
+
+	var existing []string
+	for _, name := range []string{"instagram_user_1.jpg", "instagram_user_2.jpg", "instagram_user_3.mp4"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatalf("failed to create file: %v", err)
+		}
+		existing = append(existing, path)
+	}
+
+	output := existing[0] + "\n" +
+		"# " + existing[1] + "\n" + // saltado (ya existía)
+		"\n" +
+		existing[2] + "\r\n" +
+		filepath.Join(dir, "missing.jpg") + "\n" +
+		"[instagram][info] some log line\n"
+
+	files := parseGalleryDlOutput(output)
+
+	expected := []string{existing[0], existing[2]}
+	if len(files) != len(expected) {
+		t.Fatalf("parseGalleryDlOutput() returned %v, want %v", files, expected)
+	}
+	for i := range expected {
+		if files[i] != expected[i] {
+			t.Errorf("file %d = %q, want %q", i, files[i], expected[i])
+		}
+	}
+}
+
+func TestValidateRange(t *testing.T) {
+	valid := []string{"1-20", "5", "10-", "-20", "1-5,10,15-", "1:24:3", "::2", "1-5, 8"}
+	for _, r := range valid {
+		if err := ValidateRange(r); err != nil {
+			t.Errorf("ValidateRange(%q) error: %v", r, err)
+		}
+	}
+
+	invalid := []string{"", "abc", "1-2-3", "20-1", "1,,2", "latest", "1..20"}
+	for _, r := range invalid {
+		if err := ValidateRange(r); err == nil {
+			t.Errorf("ValidateRange(%q) = nil, want error", r)
+		}
+	}
+}
+
+func TestParseGalleryMetadata(t *testing.T) {
+	tests := []struct {
+		name                        string
+		json                        string
+		title, uploader, uploadDate string
+	}{
+		{
+			name:       "title and author object",
+			json:       `{"title": "Sketches", "author": {"name": "artist", "id": 1}, "date": "2024-03-01 12:00:00"}`,
+			title:      "Sketches",
+			uploader:   "artist",
+			uploadDate: "2024-03-01",
+		},
+		{
+			name:       "post text and username",
+			json:       `{"description": "First line\nsecond line", "username": "someone", "date": "2023-12-31 23:59:59"}`,
+			title:      "First line",
+			uploader:   "someone",
+			uploadDate: "2023-12-31",
+		},
+		{
+			name:     "user with nick only, no date",
+			json:     `{"user": {"nick": "nick"}, "date": "unknown"}`,
+			uploader: "nick",
+		},
+		{
+			name:  "long multibyte text",
+			json:  `{"content": "` + strings.Repeat("ñ", 250) + `"}`,
+			title: strings.Repeat("ñ", 200) + "…",
+		},
+		{
+			name: "invalid json",
+			json: `not json`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			title, uploader, uploadDate := parseGalleryMetadata([]byte(tt.json))
+			if title != tt.title || uploader != tt.uploader || uploadDate != tt.uploadDate {
+				t.Errorf("parseGalleryMetadata() = (%q, %q, %q), want (%q, %q, %q)",
+					title, uploader, uploadDate, tt.title, tt.uploader, tt.uploadDate)
+			}
+		})
+	}
+}
+
+func TestReadGalleryMetadata_RemovesJSON(t *testing.T) {
+	dir := t.TempDir()
+	files := []string{filepath.Join(dir, "post_1.jpg"), filepath.Join(dir, "post_2.jpg")}
+	for _, file := range files {
+		os.WriteFile(file, []byte("x"), 0644)
+		os.WriteFile(file+".json", []byte(`{"title": "Post", "user": "someone"}`), 0644)
+	}
+
+	title, uploader, _ := readGalleryMetadata(files)
+	if title != "Post" || uploader != "someone" {
+		t.Errorf("readGalleryMetadata() = (%q, %q), want (Post, someone)", title, uploader)
+	}
+
+	for _, file := range files {
+		if _, err := os.Stat(file + ".json"); !os.IsNotExist(err) {
+			t.Errorf("%s.json was not removed", filepath.Base(file))
+		}
+	}
+}
+
+func TestDropNumSuffix(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		return path
+	}
+
+	// Un archivo suelto pierde el "_1"
+	if got, want := dropNumSuffix(write("post_1.jpg")), filepath.Join(dir, "post.jpg"); got != want {
+		t.Errorf("dropNumSuffix() = %q, want %q", got, want)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "post.jpg")); err != nil {
+		t.Errorf("post.jpg was not created: %v", err)
+	}
+
+	// Sin pisar un archivo existente
+	write("clip.mp4")
+	existing := write("clip_1.mp4")
+	if got := dropNumSuffix(existing); got != existing {
+		t.Errorf("dropNumSuffix() = %q, want %q (target exists)", got, existing)
+	}
+
+	// Otros números no se tocan
+	other := write("post_2.jpg")
+	if got := dropNumSuffix(other); got != other {
+		t.Errorf("dropNumSuffix() = %q, want %q", got, other)
+	}
+}
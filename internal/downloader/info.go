@@ -0,0 +1,205 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/elsanchez/smart-download/internal/domain"
+)
+
+// infoTimeout limita la consulta de smd info (-J lista todos los formatos, tarda más que probe)
+const infoTimeout = 90 * time.Second
+
+// URLInfo es lo que se sabe de una URL sin descargarla (smd info)
+type URLInfo struct {
+	URL          string   `json:"url"`
+	Platform     string   `json:"platform"`
+	Backend      string   `json:"backend"` // yt-dlp o gallery-dl
+	Title        string   `json:"title"`
+	Uploader     string   `json:"uploader"`
+	UploadDate   string   `json:"upload_date"` // YYYY-MM-DD
+	Duration     float64  `json:"duration"`    // Segundos (0 si no aplica)
+	IsLive       bool     `json:"is_live"`
+	Formats      []Format `json:"formats"`    // Solo yt-dlp
+	FileCount    int      `json:"file_count"` // Solo gallery-dl
+	Account      string   `json:"account"`    // Cuenta cuyas cookies se usaron ("" = sin cookies)
+	NeedsCookies bool     `json:"needs_cookies"`
+	Error        string   `json:"error"` // Por qué falló la consulta (p.ej. login requerido)
+}
+
+// Format es un formato de yt-dlp, para elegir calidad antes de descargar
+type Format struct {
+	ID         string  `json:"id"`
+	Ext        string  `json:"ext"`
+	Resolution string  `json:"resolution"` // 1920x1080 o "audio only"
+	Height     int     `json:"height"`
+	FPS        float64 `json:"fps"`
+	VideoCodec string  `json:"vcodec"` // "none" si es solo audio
+	AudioCodec string  `json:"acodec"` // "none" si es solo video
+	FileSize   int64   `json:"filesize"`
+	Note       string  `json:"note"`
+}
+
+// ytdlpInfo son los campos de -J que usa smd info
+type ytdlpInfo struct {
+	mediaInfo
+	Duration float64 `json:"duration"`
+	Formats  []struct {
+		FormatID       string  `json:"format_id"`
+		Ext            string  `json:"ext"`
+		Resolution     string  `json:"resolution"`
+		Height         int     `json:"height"`
+		FPS            float64 `json:"fps"`
+		VCodec         string  `json:"vcodec"`
+		ACodec         string  `json:"acodec"`
+		FileSize       int64   `json:"filesize"`
+		FileSizeApprox int64   `json:"filesize_approx"`
+		FormatNote     string  `json:"format_note"`
+	} `json:"formats"`
+}
+
+// Info consulta la URL con el downloader que le corresponde, sin descargar. Los fallos de
+// login no son un error: se informan en NeedsCookies/Error para que el usuario importe cookies
+func (m *Manager) Info(ctx context.Context, dl *domain.Download) (*URLInfo, error) {
+	dl.URL = NormalizeURL(dl.URL)
+	if dl.Platform == "" {
+		dl.Platform = DetectPlatform(dl.URL)
+	}
+
+	info := &URLInfo{URL: dl.URL, Platform: dl.Platform, Backend: BackendFor(dl.URL)}
+
+	var account *domain.Account
+	var err error
+	if info.Backend == BackendGalleryDl {
+		if account, err = resolveAccount(ctx, m.gallerydl.accountRepo, dl); err != nil {
+			return nil, err
+		}
+		info.FileCount, err = m.gallerydl.countFiles(ctx, dl, account)
+	} else {
+		if account, err = resolveAccount(ctx, m.ytdlp.accountRepo, dl); err != nil {
+			return nil, err
+		}
+		err = m.ytdlp.info(ctx, dl.URL, account, proxyFor(dl), info)
+	}
+	if account != nil && account.CookiePath != "" {
+		info.Account = account.Name
+	}
+
+	var dlErr *DownloadError
+	if errors.As(err, &dlErr) && NeedsCookieHint(dlErr.Type) {
+		info.NeedsCookies = true
+		info.Error = dlErr.Message
+		return info, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// info completa info con la salida de yt-dlp -J
+func (y *YtDlp) info(ctx context.Context, url string, account *domain.Account, proxy string, info *URLInfo) error {
+	ctx, cancel := context.WithTimeout(ctx, infoTimeout)
+	defer cancel()
+
+	args := []string{"-J", "--no-playlist", "--no-warnings"}
+	if account != nil && account.CookiePath != "" {
+		args = append(args, "--cookies", account.CookiePath)
+	}
+	if proxy != "" {
+		args = append(args, "--proxy", proxy)
+	}
+	args = append(args, url)
+
+	cmd := exec.CommandContext(ctx, "yt-dlp", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return newDownloadError("yt-dlp", err, stderr.String())
+	}
+
+	return parseYtdlpInfo(output, info)
+}
+
+// parseYtdlpInfo lee la salida de yt-dlp -J
+func parseYtdlpInfo(output []byte, info *URLInfo) error {
+	var raw ytdlpInfo
+	if err := json.Unmarshal(output, &raw); err != nil {
+		return fmt.Errorf("parse yt-dlp info: %w", err)
+	}
+
+	info.Title, info.Uploader, info.UploadDate = raw.Title, raw.Uploader, raw.uploadDate()
+	info.Duration = raw.Duration
+	info.IsLive = raw.IsLive
+
+	info.Formats = make([]Format, 0, len(raw.Formats))
+	for _, f := range raw.Formats {
+		size := f.FileSize
+		if size == 0 {
+			size = f.FileSizeApprox
+		}
+		info.Formats = append(info.Formats, Format{
+			ID:         f.FormatID,
+			Ext:        f.Ext,
+			Resolution: f.Resolution,
+			Height:     f.Height,
+			FPS:        f.FPS,
+			VideoCodec: f.VCodec,
+			AudioCodec: f.ACodec,
+			FileSize:   size,
+			Note:       f.FormatNote,
+		})
+	}
+
+	return nil
+}
+
+// countFiles cuenta los archivos que bajaría gallery-dl (--get-urls imprime una URL por archivo)
+func (g *GalleryDl) countFiles(ctx context.Context, dl *domain.Download, account *domain.Account) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, infoTimeout)
+	defer cancel()
+
+	args := []string{"--no-download", "--get-urls"}
+	if account != nil && account.CookiePath != "" {
+		args = append(args, "--cookies", account.CookiePath)
+	}
+	if dl.Options.Range != "" {
+		args = append(args, "--range", dl.Options.Range)
+	}
+	if proxy := proxyFor(dl); proxy != "" {
+		args = append(args, "--proxy", proxy)
+	}
+	args = append(args, dl.URL)
+
+	cmd := exec.CommandContext(ctx, "gallery-dl", args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return 0, newDownloadError("gallery-dl", err, stderr.String()+stdout.String())
+	}
+
+	return countURLs(stdout.String()), nil
+}
+
+// countURLs cuenta las líneas de --get-urls (las que empiezan por "|" son URLs alternativas
+// del mismo archivo)
+func countURLs(output string) int {
+	count := 0
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "|") {
+			continue
+		}
+		count++
+	}
+	return count
+}
@@ -0,0 +1,82 @@
+package downloader
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseYtdlpInfo(t *testing.T) {
+	output := `{"id": "abc", "title": "My Video", "uploader": "Someone", "upload_date": "20240131",
+		"duration": 212.5, "is_live": false, "formats": [
+		{"format_id": "140", "ext": "m4a", "resolution": "audio only", "vcodec": "none", "acodec": "mp4a.40.2", "filesize": 3433000, "format_note": "medium"},
+		{"format_id": "137", "ext": "mp4", "resolution": "1920x1080", "height": 1080, "fps": 30, "vcodec": "avc1.640028", "acodec": "none", "filesize_approx": 80000000, "format_note": "1080p"}
+	]}`
+
+	var info URLInfo
+	if err := parseYtdlpInfo([]byte(output), &info); err != nil {
+		t.Fatalf("parseYtdlpInfo() error: %v", err)
+	}
+
+	if info.Title != "My Video" || info.Uploader != "Someone" || info.UploadDate != "2024-01-31" || info.Duration != 212.5 {
+		t.Errorf("unexpected metadata: %+v", info)
+	}
+
+	want := []Format{
+		{ID: "140", Ext: "m4a", Resolution: "audio only", VideoCodec: "none", AudioCodec: "mp4a.40.2", FileSize: 3433000, Note: "medium"},
+		{ID: "137", Ext: "mp4", Resolution: "1920x1080", Height: 1080, FPS: 30, VideoCodec: "avc1.640028", AudioCodec: "none", FileSize: 80000000, Note: "1080p"},
+	}
+	if !reflect.DeepEqual(info.Formats, want) {
+		t.Errorf("Formats = %+v, want %+v", info.Formats, want)
+	}
+
+	if err := parseYtdlpInfo([]byte("ERROR: something"), &info); err == nil {
+		t.Error("parseYtdlpInfo() with invalid output: want an error")
+	}
+}
+
+func TestCountURLs(t *testing.T) {
+	output := "https://i.redd.it/a.jpg\n| https://preview.redd.it/a.jpg\nhttps://i.redd.it/b.jpg\n\n"
+	if got := countURLs(output); got != 2 {
+		t.Errorf("countURLs() = %d, want 2", got)
+	}
+}
+
+func TestParseProfileListing(t *testing.T) {
+	output := "https://www.youtube.com/watch?v=a\tChannel - Videos\n" +
+		"https://www.youtube.com/watch?v=b\tChannel - Videos\n" +
+		"NA\tChannel - Videos\n" +
+		"https://www.youtube.com/watch?v=a\tChannel - Videos\n\n"
+
+	listing := parseProfileListing(output)
+	if listing.Title != "Channel - Videos" {
+		t.Errorf("Title = %q, want %q", listing.Title, "Channel - Videos")
+	}
+	want := []string{"https://www.youtube.com/watch?v=a", "https://www.youtube.com/watch?v=b"}
+	if !reflect.DeepEqual(listing.URLs, want) {
+		t.Errorf("URLs = %v, want %v", listing.URLs, want)
+	}
+
+	if listing := parseProfileListing("https://www.tiktok.com/@user/video/1\tNA\n"); listing.Title != "" {
+		t.Errorf("Title without playlist_title = %q, want empty", listing.Title)
+	}
+}
+
+func TestProfileListURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://www.youtube.com/@channel", "https://www.youtube.com/@channel/videos"},
+		{"https://youtube.com/@channel/", "https://youtube.com/@channel/videos"},
+		{"https://www.youtube.com/channel/UC123", "https://www.youtube.com/channel/UC123/videos"},
+		{"https://www.youtube.com/@channel/shorts", "https://www.youtube.com/@channel/shorts"},
+		{"https://www.youtube.com/playlist?list=PL1", "https://www.youtube.com/playlist?list=PL1"},
+		{"https://www.tiktok.com/@user", "https://www.tiktok.com/@user"},
+	}
+
+	for _, tt := range tests {
+		if got := profileListURL(tt.url); got != tt.want {
+			t.Errorf("profileListURL(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
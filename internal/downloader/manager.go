@@ -3,6 +3,9 @@ package downloader
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
 
 	"github.com/elsanchez/smart-download/internal/domain"
 )
@@ -14,15 +17,18 @@ type Manager struct {
 }
 
 // NewManager crea un nuevo manager de downloaders
-func NewManager(outputDir string, cookiesDir string, accountRepo AccountGetter) *Manager {
+func NewManager(outputDir string, cookiesDir string, archiveDir string, accountRepo AccountGetter) *Manager {
 	return &Manager{
-		ytdlp:     NewYtDlp(outputDir, cookiesDir, accountRepo),
-		gallerydl: NewGalleryDl(outputDir, cookiesDir, accountRepo),
+		ytdlp:     NewYtDlp(outputDir, cookiesDir, archiveDir, accountRepo),
+		gallerydl: NewGalleryDl(outputDir, cookiesDir, archiveDir, accountRepo),
 	}
 }
 
 // Download selecciona el downloader apropiado y ejecuta la descarga
 func (m *Manager) Download(ctx context.Context, dl *domain.Download) (string, error) {
+	// Normalizar URL (descargas creadas antes de la normalización)
+	dl.URL = NormalizeURL(dl.URL)
+
 	// Detectar plataforma si no está especificada
 	if dl.Platform == "" {
 		dl.Platform = DetectPlatform(dl.URL)
@@ -33,18 +39,36 @@ func (m *Manager) Download(ctx context.Context, dl *domain.Download) (string, er
 		dl.Username = ExtractUsername(dl.URL)
 	}
 
-	// Seleccionar downloader
-	var downloader Downloader
+	// Seleccionar downloader según el routing; el otro queda como fallback
+	primary, fallback := Downloader(m.ytdlp), Downloader(m.gallerydl)
+	primaryName, fallbackName := BackendYtDlp, BackendGalleryDl
 	if m.gallerydl.Supports(dl.URL) {
-		downloader = m.gallerydl
-	} else if m.ytdlp.Supports(dl.URL) {
-		downloader = m.ytdlp
-	} else {
-		return "", fmt.Errorf("no downloader supports URL: %s", dl.URL)
+		primary, fallback = fallback, primary
+		primaryName, fallbackName = fallbackName, primaryName
+	}
+
+	// Ejecutar descarga; dl.Backend queda con el downloader que la resolvió
+	dl.Backend = primaryName
+	outputPath, err := primary.Download(ctx, dl)
+	if err == nil || !isUnsupportedURL(err) || ctx.Err() != nil {
+		return outputPath, err
 	}
 
-	// Ejecutar descarga
-	return downloader.Download(ctx, dl)
+	// El routing se equivocó: el primero no reconoce la URL, probar con el otro
+	slog.Warn("Downloader doesn't support the URL, falling back", "download_id", dl.ID, "backend", primaryName, "fallback", fallbackName, "error", err)
+	outputPath, fallbackErr := fallback.Download(ctx, dl)
+	if fallbackErr != nil && isUnsupportedURL(fallbackErr) {
+		// Ninguno la reconoce: el error del primero es el más representativo
+		return "", err
+	}
+	dl.Backend = fallbackName
+	return outputPath, fallbackErr
+}
+
+// RemovePartial elimina los archivos a medias que se conservan para retomar una descarga
+// interrumpida. El daemon la llama cuando el usuario la cancela o la elimina
+func (m *Manager) RemovePartial(dl *domain.Download) error {
+	return os.RemoveAll(partialDir(filepath.Join(m.ytdlp.outputDir, dl.Platform), dl.ID))
 }
 
 // CheckDependencies verifica que los downloaders estén instalados
@@ -0,0 +1,135 @@
+package downloader
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elsanchez/smart-download/internal/domain"
+)
+
+// fakeBackends instala yt-dlp y gallery-dl falsos en el PATH. Cada uno anota su nombre en
+// el archivo retornado y se comporta según FAKE_YTDLP / FAKE_GALLERYDL
+func fakeBackends(t *testing.T) (calls, output string) {
+	t.Helper()
+	dir := t.TempDir()
+	calls = filepath.Join(dir, "calls")
+	output = filepath.Join(dir, "gallery.jpg")
+
+	binDir := filepath.Join(dir, "bin")
+	if err := os.MkdirAll(binDir, 0755); err != nil {
+		t.Fatalf("failed to create bin dir: %v", err)
+	}
+	scripts := map[string]string{
+		// La consulta de metadatos (--dump-json) falla siempre: la descarga se intenta igual
+		"yt-dlp": `#!/bin/sh
+[ "$1" = --dump-json ] && exit 1
+echo yt-dlp >> "` + calls + `"
+case "$FAKE_YTDLP" in
+unsupported) echo "ERROR: Unsupported URL: https://example.com/video" >&2; exit 1 ;;
+auth) echo "ERROR: HTTP Error 403: Forbidden" >&2; exit 1 ;;
+hang) echo "ERROR: Unsupported URL: https://example.com/video" >&2; exec sleep 30 ;;
+esac
+exit 1
+`,
+		"gallery-dl": `#!/bin/sh
+echo gallery-dl >> "` + calls + `"
+case "$FAKE_GALLERYDL" in
+unsupported) echo "[gallery-dl][error] No suitable extractor found" >&2; exit 1 ;;
+esac
+touch "` + output + `"
+echo "` + output + `"
+`,
+	}
+	for name, script := range scripts {
+		if err := os.WriteFile(filepath.Join(binDir, name), []byte(script), 0755); err != nil {
+			t.Fatalf("failed to write fake %s: %v", name, err)
+		}
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	return calls, output
+}
+
+// readCalls retorna los downloaders ejecutados, en orden
+func readCalls(t *testing.T, path string) []string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatalf("failed to read calls: %v", err)
+	}
+	return strings.Fields(string(data))
+}
+
+func TestManager_Download_Fallback(t *testing.T) {
+	tests := []struct {
+		name        string
+		ytdlp       string
+		galleryDL   string
+		wantCalls   []string
+		wantErr     string // "" = la descarga termina bien
+		wantBackend string
+	}{
+		{"unsupported falls back", "unsupported", "", []string{"yt-dlp", "gallery-dl"}, "", BackendGalleryDl},
+		{"auth error does not fall back", "auth", "", []string{"yt-dlp"}, "403", BackendYtDlp},
+		{"neither supports the URL", "unsupported", "unsupported", []string{"yt-dlp", "gallery-dl"}, "Unsupported URL", BackendYtDlp},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls, output := fakeBackends(t)
+			t.Setenv("FAKE_YTDLP", tt.ytdlp)
+			t.Setenv("FAKE_GALLERYDL", tt.galleryDL)
+
+			m := NewManager(t.TempDir(), "", "", nil)
+			dl := &domain.Download{ID: 1, URL: "https://example.com/video"}
+			path, err := m.Download(context.Background(), dl)
+
+			if got := readCalls(t, calls); strings.Join(got, " ") != strings.Join(tt.wantCalls, " ") {
+				t.Errorf("ran %v, want %v", got, tt.wantCalls)
+			}
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Download() error: %v", err)
+				}
+				if path != output {
+					t.Errorf("Download() = %q, want %q", path, output)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Download() error = %v, want it to mention %q", err, tt.wantErr)
+			}
+			if dl.Backend != tt.wantBackend {
+				t.Errorf("Backend = %q, want %q", dl.Backend, tt.wantBackend)
+			}
+		})
+	}
+}
+
+func TestManager_Download_NoFallbackAfterCancel(t *testing.T) {
+	calls, _ := fakeBackends(t)
+
+	// yt-dlp ya escribió "Unsupported URL" cuando se cancela: aun así no se prueba gallery-dl
+	t.Setenv("FAKE_YTDLP", "hang")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	m := NewManager(t.TempDir(), "", "", nil)
+	dl := &domain.Download{ID: 1, URL: "https://example.com/video"}
+	_, err := m.Download(ctx, dl)
+	if err == nil || !strings.Contains(err.Error(), "yt-dlp") {
+		t.Fatalf("Download() error = %v, want the yt-dlp error", err)
+	}
+
+	// Con el contexto cancelado exec ni siquiera lanzaría gallery-dl: lo que importa es que
+	// la descarga no pase a él
+	if got := readCalls(t, calls); strings.Join(got, " ") != "yt-dlp" {
+		t.Errorf("ran %v, want only yt-dlp", got)
+	}
+	if dl.Backend != BackendYtDlp {
+		t.Errorf("Backend = %q, want %q", dl.Backend, BackendYtDlp)
+	}
+}
@@ -0,0 +1,129 @@
+package downloader
+
+import (
+	"net/url"
+	"strings"
+)
+
+// trackingParams son parámetros de query que no identifican el contenido
+var trackingParams = map[string]bool{
+	"si":      true,
+	"feature": true,
+	"fbclid":  true,
+	"gclid":   true,
+	"igshid":  true,
+}
+
+// youtubeHosts son los hosts que sirven las mismas páginas que www.youtube.com
+var youtubeHosts = map[string]bool{
+	"youtube.com":       true,
+	"www.youtube.com":   true,
+	"m.youtube.com":     true,
+	"music.youtube.com": true,
+}
+
+// youtubeContextParams son parámetros de un /watch que no cambian el video
+var youtubeContextParams = map[string]bool{
+	"list":       true,
+	"index":      true,
+	"pp":         true,
+	"ab_channel": true,
+}
+
+// NormalizeURL canonicaliza una URL para que la misma media tenga siempre la misma URL:
+// elimina parámetros de tracking y lleva las variantes de YouTube (youtu.be, m.youtube.com,
+// /shorts/, /embed/, /live/) a www.youtube.com/watch?v=ID
+func NormalizeURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return raw
+	}
+
+	u.Host = strings.ToLower(u.Host)
+	u.Fragment = ""
+
+	// YouTube: la query se re-arma; en otros hosts solo se quitan los parámetros de
+	// tracking, sin reordenar ni re-escapar el resto (el orden puede importarle al sitio)
+	if !youtubeHosts[u.Host] && u.Host != "youtu.be" && u.Host != "www.youtu.be" {
+		u.RawQuery = stripTracking(u.RawQuery)
+		return u.String()
+	}
+
+	// Eliminar parámetros de tracking
+	query := u.Query()
+	for key := range query {
+		if isTrackingParam(key) {
+			query.Del(key)
+		}
+	}
+
+	// YouTube: unificar a www.youtube.com/watch?v=ID
+	if id := youtubeVideoID(u, query); id != "" {
+		for key := range query {
+			if youtubeContextParams[key] {
+				query.Del(key)
+			}
+		}
+		u.Scheme = "https"
+		u.Host = "www.youtube.com"
+		u.Path = "/watch"
+		query.Set("v", id)
+	} else if youtubeHosts[u.Host] {
+		// Canales, playlists, etc.: solo unificar el host
+		u.Host = "www.youtube.com"
+	}
+
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
+
+// isTrackingParam indica si un parámetro de query es de tracking
+func isTrackingParam(key string) bool {
+	return strings.HasPrefix(key, "utm_") || trackingParams[key]
+}
+
+// stripTracking quita los parámetros de tracking de una query cruda, dejando el resto tal cual
+func stripTracking(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+
+	var kept []string
+	for _, pair := range strings.Split(rawQuery, "&") {
+		key, _, _ := strings.Cut(pair, "=")
+		if unescaped, err := url.QueryUnescape(key); err == nil {
+			key = unescaped
+		}
+		if !isTrackingParam(key) {
+			kept = append(kept, pair)
+		}
+	}
+	return strings.Join(kept, "&")
+}
+
+// youtubeVideoID extrae el ID del video de una URL de YouTube (vacío si no es un video)
+func youtubeVideoID(u *url.URL, query url.Values) string {
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+
+	if u.Host == "youtu.be" || u.Host == "www.youtu.be" {
+		return parts[0]
+	}
+
+	if !youtubeHosts[u.Host] {
+		return ""
+	}
+
+	switch parts[0] {
+	case "watch":
+		return query.Get("v")
+	case "shorts", "embed", "live", "v":
+		if len(parts) > 1 {
+			return parts[1]
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,44 @@
+package downloader
+
+import "testing"
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		// YouTube
+		{"https://www.youtube.com/watch?v=dQw4w9WgXcQ", "https://www.youtube.com/watch?v=dQw4w9WgXcQ"},
+		{"https://youtu.be/dQw4w9WgXcQ", "https://www.youtube.com/watch?v=dQw4w9WgXcQ"},
+		{"https://youtu.be/dQw4w9WgXcQ?si=abc123", "https://www.youtube.com/watch?v=dQw4w9WgXcQ"},
+		{"https://m.youtube.com/watch?v=dQw4w9WgXcQ", "https://www.youtube.com/watch?v=dQw4w9WgXcQ"},
+		{"https://youtube.com/shorts/abcDEF12345", "https://www.youtube.com/watch?v=abcDEF12345"},
+		{"https://www.youtube.com/shorts/abcDEF12345?feature=share", "https://www.youtube.com/watch?v=abcDEF12345"},
+		{"https://www.youtube.com/embed/dQw4w9WgXcQ", "https://www.youtube.com/watch?v=dQw4w9WgXcQ"},
+		{"https://www.youtube.com/watch?v=dQw4w9WgXcQ&list=PL123&index=2", "https://www.youtube.com/watch?v=dQw4w9WgXcQ"},
+		{"https://www.youtube.com/watch?v=dQw4w9WgXcQ&t=42", "https://www.youtube.com/watch?t=42&v=dQw4w9WgXcQ"},
+		{"https://m.youtube.com/@MrBeast/videos", "https://www.youtube.com/@MrBeast/videos"},
+
+		// Tracking params
+		{"https://vimeo.com/123456789?utm_source=x&utm_medium=y", "https://vimeo.com/123456789"},
+		{"https://www.instagram.com/p/ABC123/?igshid=xyz", "https://www.instagram.com/p/ABC123/"},
+		{"https://example.com/video?id=5&fbclid=abc#comments", "https://example.com/video?id=5"},
+
+		// Otros hosts: la query queda en su orden y con su escape
+		{"https://example.com/view?z=1&a=2&utm_source=x", "https://example.com/view?z=1&a=2"},
+		{"https://example.com/search?q=a+b&tag=%7E", "https://example.com/search?q=a+b&tag=%7E"},
+
+		// Sin cambios
+		{"https://twitter.com/user/status/123", "https://twitter.com/user/status/123"},
+		{"not a url", "not a url"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			result := NormalizeURL(tt.url)
+			if result != tt.expected {
+				t.Errorf("NormalizeURL(%q) = %q, want %q", tt.url, result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"net/url"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/elsanchez/smart-download/internal/domain"
+)
+
+// DefaultProfileMax es la cantidad de items que se encolan de un canal/perfil si no se indica max_items
+const DefaultProfileMax = 50
+
+// ProfileListing son los items de un canal/perfil listados con yt-dlp --flat-playlist
+type ProfileListing struct {
+	Title string   // Nombre de la lista (p.ej. "Canal - Videos"); vacío si yt-dlp no lo da
+	URLs  []string // Más recientes primero, sin los ya registrados en el download archive
+}
+
+// youtubeChannelPath reconoce la raíz de un canal de YouTube, sin pestaña (/videos, /shorts...)
+var youtubeChannelPath = regexp.MustCompile(`^/(@[^/]+|channel/[^/]+|c/[^/]+|user/[^/]+)/?$`)
+
+// ListProfile lista los últimos max items del canal/perfil de dl con yt-dlp --flat-playlist,
+// con las cookies, el proxy y el download archive que usaría la descarga. Los perfiles de
+// gallery-dl no se listan: se descargan como una galería con --range
+func (m *Manager) ListProfile(ctx context.Context, dl *domain.Download, max int) (*ProfileListing, error) {
+	y := m.ytdlp
+
+	args := []string{
+		"--flat-playlist",
+		"--print", "%(url)s\t%(playlist_title)s",
+		"--playlist-end", strconv.Itoa(max),
+		"--no-warnings",
+	}
+
+	account, err := resolveAccount(ctx, y.accountRepo, dl)
+	if err != nil {
+		return nil, err
+	}
+	if account != nil && account.CookiePath != "" {
+		args = append(args, "--cookies", account.CookiePath)
+	}
+	if proxy := proxyFor(dl); proxy != "" {
+		args = append(args, "--proxy", proxy)
+	}
+
+	// Los items ya descargados (de un add anterior del mismo perfil) se saltan
+	if y.archiveDir != "" {
+		args = append(args, "--download-archive", archivePath(y.archiveDir, "yt-dlp", dl.Platform, account, ".txt"))
+	}
+
+	args = append(args, profileListURL(dl.URL))
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "yt-dlp", args...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		dlErr := newDownloadError("yt-dlp", err, stderr.String())
+		dlErr.UsedCookies = account != nil && account.CookiePath != ""
+		return nil, dlErr
+	}
+
+	return parseProfileListing(stdout.String()), nil
+}
+
+// parseProfileListing lee la salida de --print "url<TAB>playlist_title": una línea por item.
+// Ignora los items sin URL ("NA") y los repetidos
+func parseProfileListing(output string) *ProfileListing {
+	listing := &ProfileListing{}
+	seen := make(map[string]bool)
+
+	for _, line := range strings.Split(output, "\n") {
+		itemURL, title, _ := strings.Cut(strings.TrimSpace(line), "\t")
+		if listing.Title == "" && title != "NA" {
+			listing.Title = title
+		}
+		if !strings.HasPrefix(itemURL, "http") || seen[itemURL] {
+			continue
+		}
+		seen[itemURL] = true
+		listing.URLs = append(listing.URLs, itemURL)
+	}
+
+	return listing
+}
+
+// profileListURL retorna la URL a listar: la raíz de un canal de YouTube lista sus pestañas
+// (Videos, Shorts, En vivo) en lugar de los videos, así que se usa /videos
+func profileListURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || !strings.HasSuffix(strings.TrimPrefix(u.Host, "www."), "youtube.com") {
+		return rawURL
+	}
+	if !youtubeChannelPath.MatchString(u.Path) {
+		return rawURL
+	}
+
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/videos"
+	return u.String()
+}
@@ -0,0 +1,127 @@
+package downloader
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Backends de descarga
+const (
+	BackendYtDlp     = "yt-dlp"
+	BackendGalleryDl = "gallery-dl"
+)
+
+// routing decide qué downloader usa cada URL
+type routing struct {
+	sites     []string          // Fragmentos de URL que van a gallery-dl
+	overrides map[string]string // Dominio → backend; gana sobre sites y las reglas de reddit
+}
+
+var (
+	routingMu     sync.RWMutex
+	activeRouting = newRouting(nil, nil)
+)
+
+// newRouting crea un routing con galleryDLSites más extraSites
+func newRouting(extraSites []string, overrides map[string]string) *routing {
+	r := &routing{
+		sites:     append([]string{}, galleryDLSites...),
+		overrides: make(map[string]string, len(overrides)),
+	}
+	for _, site := range extraSites {
+		r.sites = append(r.sites, strings.ToLower(site))
+	}
+	for domain, backend := range overrides {
+		r.overrides[strings.ToLower(strings.TrimPrefix(domain, "www."))] = backend
+	}
+	return r
+}
+
+// ConfigureRouting añade sitios a gallery-dl y fija el backend de dominios concretos
+// (configuración del daemon). Se llama una vez al iniciar, antes de procesar descargas
+func ConfigureRouting(extraSites []string, overrides map[string]string) error {
+	for domain, backend := range overrides {
+		if err := ValidateBackend(backend); err != nil {
+			return fmt.Errorf("routing override for %s: %w", domain, err)
+		}
+	}
+
+	routingMu.Lock()
+	activeRouting = newRouting(extraSites, overrides)
+	routingMu.Unlock()
+	return nil
+}
+
+// ValidateBackend verifica que backend sea uno de los downloaders (BackendYtDlp o BackendGalleryDl)
+func ValidateBackend(backend string) error {
+	if backend != BackendYtDlp && backend != BackendGalleryDl {
+		return fmt.Errorf("unknown backend %q (use %s or %s)", backend, BackendYtDlp, BackendGalleryDl)
+	}
+	return nil
+}
+
+// currentRouting retorna el routing configurado
+func currentRouting() *routing {
+	routingMu.RLock()
+	defer routingMu.RUnlock()
+	return activeRouting
+}
+
+// backend retorna el downloader para la URL
+func (r *routing) backend(urlStr string) string {
+	urlStr = strings.ToLower(urlStr)
+	host := urlHost(urlStr)
+
+	// Overrides del usuario: gana el dominio más específico (sub.example.com sobre example.com)
+	best := ""
+	for domain := range r.overrides {
+		if (host == domain || strings.HasSuffix(host, "."+domain)) && len(domain) > len(best) {
+			best = domain
+		}
+	}
+	if best != "" {
+		return r.overrides[best]
+	}
+
+	// Reddit: los videos (v.redd.it) y los posts funcionan mejor con yt-dlp; imágenes y galerías con gallery-dl
+	if backend, ok := redditBackend(host, urlStr); ok {
+		return backend
+	}
+
+	for _, site := range r.sites {
+		if strings.Contains(urlStr, site) {
+			return BackendGalleryDl
+		}
+	}
+
+	return BackendYtDlp
+}
+
+// redditBackend clasifica las URLs de reddit según su forma. Los posts (/comments/) pueden
+// ser video o imagen: van a yt-dlp, y si es una galería que no reconoce ("Unsupported URL")
+// el Manager la reintenta con gallery-dl
+func redditBackend(host, urlStr string) (string, bool) {
+	switch {
+	case host == "v.redd.it":
+		return BackendYtDlp, true
+	case host == "i.redd.it", host == "preview.redd.it", strings.Contains(urlStr, "reddit.com/gallery/"):
+		return BackendGalleryDl, true
+	case strings.Contains(urlStr, "reddit.com/") && strings.Contains(urlStr, "/comments/"):
+		return BackendYtDlp, true
+	}
+	return "", false
+}
+
+// urlHost retorna el host de la URL sin "www." ("" si no se puede parsear)
+func urlHost(urlStr string) string {
+	if !strings.Contains(urlStr, "://") {
+		urlStr = "https://" + urlStr
+	}
+	parsed, err := url.Parse(urlStr)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(parsed.Hostname(), "www.")
+}
@@ -1,8 +1,11 @@
 package downloader
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -16,19 +19,40 @@ import (
 type YtDlp struct {
 	outputDir   string
 	cookiesDir  string
+	archiveDir  string        // Directorio de download archives
 	accountRepo AccountGetter // Interfaz para obtener cuentas
 }
 
+// SponsorBlockCategories son las categorías de SponsorBlock que acepta yt-dlp
+var SponsorBlockCategories = []string{
+	"sponsor",
+	"intro",
+	"outro",
+	"selfpromo",
+	"preview",
+	"filler",
+	"interaction",
+	"music_offtopic",
+}
+
+// DefaultLiveDuration es lo que se graba de un directo si no se indica duración
+const DefaultLiveDuration = 10 * time.Minute
+
+// probeTimeout limita la consulta de metadatos previa a la descarga
+const probeTimeout = 30 * time.Second
+
 // AccountGetter define la interfaz para obtener cuentas (evita dependencia circular)
 type AccountGetter interface {
+	GetByID(ctx context.Context, id int64) (*domain.Account, error)
 	GetActive(ctx context.Context, platform string) (*domain.Account, error)
 }
 
 // NewYtDlp crea un nuevo downloader de yt-dlp
-func NewYtDlp(outputDir string, cookiesDir string, accountRepo AccountGetter) *YtDlp {
+func NewYtDlp(outputDir string, cookiesDir string, archiveDir string, accountRepo AccountGetter) *YtDlp {
 	return &YtDlp{
 		outputDir:   outputDir,
 		cookiesDir:  cookiesDir,
+		archiveDir:  archiveDir,
 		accountRepo: accountRepo,
 	}
 }
@@ -44,45 +68,133 @@ func (y *YtDlp) Download(ctx context.Context, dl *domain.Download) (string, erro
 	// Generar filename base
 	filenameBase := y.generateFilename(dl)
 
+	// Archivos a medias en un directorio propio de la descarga: si el daemon se reinicia,
+	// --continue retoma los .part en vez de bajar todo de nuevo
+	partDir := partialDir(platformDir, dl.ID)
+
 	// Construir argumentos
 	args := []string{
-		"-o", filepath.Join(platformDir, filenameBase+".%(ext)s"),
+		"-P", "home:" + platformDir,
+		"-P", "temp:" + partDir,
+		"-o", filenameBase + ".%(ext)s",
+		"--continue",
+	}
+
+	// Con filename template el nombre final depende de los metadatos: yt-dlp imprime el path
+	// final en stdout (--print implica --quiet; los errores siguen saliendo por stderr)
+	if dl.Options.FilenameTemplate != "" {
+		args = append(args, "--print", "after_move:filepath")
 	}
 
 	// Opciones según configuración
 	if dl.Options.AudioOnly {
-		args = append(args, "-x", "--audio-format", "mp3")
+		format, bitrate := dl.Options.Audio()
+		args = append(args, "-x", "--audio-format", format, "--audio-quality", fmt.Sprintf("%dK", bitrate))
+		if dl.Options.FormatID != "" {
+			args = append(args, "-f", dl.Options.FormatID)
+		}
 	} else {
-		// Formato de video
-		format := y.buildFormatString(dl.Options.Resolution)
+		// Formato de video: el format id pedido o el preset de la resolución
+		format := dl.Options.FormatID
+		if format == "" {
+			format = y.buildFormatString(dl.Options.Resolution)
+		}
 		args = append(args, "-f", format)
 		args = append(args, "--merge-output-format", "mp4")
 	}
 
-	// Cookies: siempre buscar cuenta activa para la plataforma
-	if y.accountRepo != nil {
-		account, err := y.accountRepo.GetActive(ctx, dl.Platform)
-		if err == nil && account != nil && account.CookiePath != "" {
-			args = append(args, "--cookies", account.CookiePath)
+	// SponsorBlock: solo tiene datos para YouTube
+	if len(dl.Options.SponsorBlock) > 0 && dl.Platform == "youtube" {
+		args = append(args, "--sponsorblock-remove", strings.Join(dl.Options.SponsorBlock, ","))
+	}
+
+	// Cookies: cuenta fijada en la descarga o, si no, la activa de la plataforma
+	account, err := resolveAccount(ctx, y.accountRepo, dl)
+	if err != nil {
+		return "", err
+	}
+	if account != nil && account.CookiePath != "" {
+		args = append(args, "--cookies", account.CookiePath)
+	}
+
+	// Proxy (sitios bloqueados por región o salida por VPN)
+	if proxy := proxyFor(dl); proxy != "" {
+		args = append(args, "--proxy", proxy)
+	}
+
+	// Metadatos (título, autor, fecha) y detección de directos. Si la consulta falla
+	// se descarga igual y la descarga se muestra por su URL
+	info, err := y.probe(ctx, dl.URL, account, proxyFor(dl))
+	if err != nil {
+		slog.Debug("Metadata probe failed, downloading as usual", "download_id", dl.ID, "error", err)
+	} else {
+		dl.Title, dl.Uploader, dl.UploadDate = info.Title, info.Uploader, info.uploadDate()
+
+		// Directos: sin --live se rechazan (yt-dlp grabaría indefinidamente);
+		// con --live se graba un segmento acotado
+		if info.IsLive {
+			liveArgs, err := liveRecordingArgs(&dl.Options)
+			if err != nil {
+				return "", err
+			}
+			args = append(args, liveArgs...)
 		}
 	}
 
+	// Download archive: yt-dlp registra los IDs descargados y salta los repetidos
+	if dl.Options.Archive && y.archiveDir != "" {
+		args = append(args, "--download-archive", archivePath(y.archiveDir, "yt-dlp", dl.Platform, account, ".txt"))
+	}
+
 	// Opciones adicionales
 	args = append(args,
 		"--no-check-certificate",
-		"--no-playlist", // Por defecto no descargar playlists
+		"--no-playlist",        // Por defecto no descargar playlists
 		"--restrict-filenames", // POSIX-compliant filenames
 	)
 
+	// Argumentos extra del usuario (sin validar)
+	args = append(args, dl.Options.ExtraArgs...)
+
 	// URL al final
 	args = append(args, dl.URL)
 
 	// Ejecutar yt-dlp
 	cmd := exec.CommandContext(ctx, "yt-dlp", args...)
-	output, err := cmd.CombinedOutput()
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	output := stderr.String() + stdout.String()
 
 	if err != nil {
-		return "", fmt.Errorf("yt-dlp failed: %w\nOutput: %s", err, output)
+		dlErr := newDownloadError("yt-dlp", err, output)
+		dlErr.UsedCookies = account != nil && account.CookiePath != ""
+		if !resumable(ctx, dlErr) {
+			os.RemoveAll(partDir)
+		}
+		return "", dlErr
+	}
+	os.RemoveAll(partDir)
+
+	// Con filename template se usa el path que imprimió yt-dlp. Si no imprimió ninguno no
+	// hubo descarga: en modo --quiet no se ve el aviso del archive, así que con --archive
+	// se asume que ya estaba registrada
+	if dl.Options.FilenameTemplate != "" {
+		if path := printedFilepath(stdout.String()); path != "" {
+			return path, nil
+		}
+		if dl.Options.Archive {
+			return "", fmt.Errorf("already downloaded (recorded in download archive)")
+		}
+		return "", fmt.Errorf("yt-dlp did not report the downloaded file\nyt-dlp output: %s", output)
+	}
+
+	// Todo ya estaba en el archive: no hay archivo nuevo que buscar
+	if dl.Options.Archive &&
+		strings.Contains(output, "has already been recorded in the archive") &&
+		!strings.Contains(output, "Destination:") {
+		return "", fmt.Errorf("already downloaded (recorded in download archive)")
 	}
 
 	// Buscar el archivo descargado
@@ -94,6 +206,96 @@ func (y *YtDlp) Download(ctx context.Context, dl *domain.Download) (string, erro
 	return outputPath, nil
 }
 
+// printedFilepath retorna el último path de --print after_move:filepath que existe ("" si
+// no hay ninguno)
+func printedFilepath(stdout string) string {
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		path := strings.TrimSpace(lines[i])
+		if !filepath.IsAbs(path) {
+			continue
+		}
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// mediaInfo son los campos de --dump-json que se usan
+type mediaInfo struct {
+	Title      string `json:"title"`
+	Uploader   string `json:"uploader"`
+	UploadDate string `json:"upload_date"` // YYYYMMDD
+	IsLive     bool   `json:"is_live"`
+}
+
+// uploadDate retorna la fecha de subida como YYYY-MM-DD ("" si falta o no se reconoce)
+func (m *mediaInfo) uploadDate() string {
+	t, err := time.Parse("20060102", m.UploadDate)
+	if err != nil {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+// probe consulta los metadatos de la URL con yt-dlp --dump-json, sin descargar
+func (y *YtDlp) probe(ctx context.Context, url string, account *domain.Account, proxy string) (*mediaInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	args := []string{"--dump-json", "--no-playlist", "--no-warnings"}
+	if account != nil && account.CookiePath != "" {
+		args = append(args, "--cookies", account.CookiePath)
+	}
+	if proxy != "" {
+		args = append(args, "--proxy", proxy)
+	}
+	args = append(args, url)
+
+	output, err := exec.CommandContext(ctx, "yt-dlp", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("yt-dlp --dump-json: %w", err)
+	}
+
+	return parseMediaInfo(output)
+}
+
+// parseMediaInfo lee la salida de --dump-json (un objeto JSON por línea; se usa el primero)
+func parseMediaInfo(output []byte) (*mediaInfo, error) {
+	line, _, _ := bytes.Cut(bytes.TrimSpace(output), []byte("\n"))
+
+	var info mediaInfo
+	if err := json.Unmarshal(line, &info); err != nil {
+		return nil, fmt.Errorf("parse yt-dlp metadata: %w", err)
+	}
+
+	return &info, nil
+}
+
+// liveRecordingArgs retorna los argumentos para grabar un segmento de un directo,
+// o un error si la descarga no pidió grabarlo
+func liveRecordingArgs(opts *domain.DownloadOptions) ([]string, error) {
+	if !opts.Live {
+		return nil, fmt.Errorf("URL is a live stream; use --live --duration 10m to record a segment of it")
+	}
+
+	duration := DefaultLiveDuration
+	if opts.LiveDuration != "" {
+		d, err := time.ParseDuration(opts.LiveDuration)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid live duration %q (use e.g. 10m or 1h30m)", opts.LiveDuration)
+		}
+		duration = d
+	}
+
+	// ffmpeg corta la entrada tras la duración pedida y yt-dlp termina normalmente
+	return []string{
+		"--downloader", "ffmpeg",
+		"--downloader-args", fmt.Sprintf("ffmpeg_i:-t %d", int(duration.Seconds())),
+	}, nil
+}
+
 // Supports verifica si yt-dlp soporta la URL
 func (y *YtDlp) Supports(url string) bool {
 	// yt-dlp soporta todo excepto las plataformas específicas de gallery-dl
@@ -102,9 +304,13 @@ func (y *YtDlp) Supports(url string) bool {
 
 // generateFilename genera el nombre de archivo base
 func (y *YtDlp) generateFilename(dl *domain.Download) string {
+	if dl.Options.FilenameTemplate != "" {
+		return ytdlpFilename(dl.Options.FilenameTemplate, dl)
+	}
+
 	// Formato: platform_username_DDMMYYYY_### para redes sociales
 	// Formato: platform_DDMMYYYY_###_%(title)s para YouTube
-	timestamp := time.Now().Format("02012006")
+	timestamp := filenameDate(dl)
 
 	if dl.Platform == "youtube" {
 		// YouTube: incluir título del video
@@ -150,13 +356,13 @@ func (y *YtDlp) findDownloadedFile(dir, basePattern string) (string, error) {
 	var newestTime time.Time
 
 	for _, entry := range entries {
-		if entry.IsDir() {
+		if entry.IsDir() || isPartialFile(entry.Name()) {
 			continue
 		}
 
 		// Verificar si el nombre contiene el patrón base
 		if strings.Contains(entry.Name(), basePattern) ||
-		   strings.HasPrefix(entry.Name(), strings.Split(basePattern, "_")[0]) {
+			strings.HasPrefix(entry.Name(), strings.Split(basePattern, "_")[0]) {
 
 			info, err := entry.Info()
 			if err != nil {
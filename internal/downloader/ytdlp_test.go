@@ -0,0 +1,139 @@
+package downloader
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/elsanchez/smart-download/internal/domain"
+)
+
+func TestParseMediaInfo(t *testing.T) {
+	tests := []struct {
+		output  string
+		want    mediaInfo
+		date    string
+		wantErr bool
+	}{
+		{
+			output: `{"id": "abc", "title": "My Video", "uploader": "Someone", "upload_date": "20240131", "is_live": false}`,
+			want:   mediaInfo{Title: "My Video", Uploader: "Someone", UploadDate: "20240131"},
+			date:   "2024-01-31",
+		},
+		{
+			output: `{"id": "abc", "title": "Live now", "is_live": true}` + "\n",
+			want:   mediaInfo{Title: "Live now", IsLive: true},
+		},
+		{
+			output: `{"id": "a", "title": "First", "is_live": true}` + "\n" + `{"id": "b", "title": "Second"}`,
+			want:   mediaInfo{Title: "First", IsLive: true},
+		},
+		{
+			output: `{"id": "abc", "upload_date": "yesterday"}`,
+			want:   mediaInfo{UploadDate: "yesterday"},
+		},
+		{output: "ERROR: something", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseMediaInfo([]byte(tt.output))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseMediaInfo(%q) error = %v, wantErr %v", tt.output, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if *got != tt.want {
+			t.Errorf("parseMediaInfo(%q) = %+v, want %+v", tt.output, *got, tt.want)
+		}
+		if date := got.uploadDate(); date != tt.date {
+			t.Errorf("uploadDate() = %q, want %q", date, tt.date)
+		}
+	}
+}
+
+func TestLiveRecordingArgs(t *testing.T) {
+	// Sin --live: se rechaza
+	if _, err := liveRecordingArgs(&domain.DownloadOptions{}); err == nil {
+		t.Error("expected error for a live stream without --live")
+	}
+
+	// Duración por defecto
+	args, err := liveRecordingArgs(&domain.DownloadOptions{Live: true})
+	if err != nil {
+		t.Fatalf("liveRecordingArgs() error: %v", err)
+	}
+	if !slices.Contains(args, "ffmpeg_i:-t 600") {
+		t.Errorf("args = %v, want a 600s limit", args)
+	}
+
+	args, err = liveRecordingArgs(&domain.DownloadOptions{Live: true, LiveDuration: "1h30m"})
+	if err != nil {
+		t.Fatalf("liveRecordingArgs() error: %v", err)
+	}
+	if !slices.Contains(args, "ffmpeg_i:-t 5400") {
+		t.Errorf("args = %v, want a 5400s limit", args)
+	}
+
+	if _, err := liveRecordingArgs(&domain.DownloadOptions{Live: true, LiveDuration: "forever"}); err == nil {
+		t.Error("expected error for an invalid duration")
+	}
+}
+
+func TestFindDownloadedFile_IgnoresPartialFiles(t *testing.T) {
+	dir := t.TempDir()
+	base := "twitter_user_01012025"
+
+	// Los restos de descargas a medias son más recientes que el archivo final
+	old := time.Now().Add(-time.Minute)
+	final := filepath.Join(dir, base+".mp4")
+	if err := os.WriteFile(final, []byte("video"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Chtimes(final, old, old)
+	for _, name := range []string{base + ".mp4.part", base + ".mp4.ytdl", base + ".f137.mp4.part-Frag3"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("partial"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(partialDir(dir, 7), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	y := &YtDlp{}
+	got, err := y.findDownloadedFile(dir, base)
+	if err != nil {
+		t.Fatalf("findDownloadedFile() error: %v", err)
+	}
+	if got != final {
+		t.Errorf("findDownloadedFile() = %q, want %q", got, final)
+	}
+}
+
+func TestResumable(t *testing.T) {
+	ctx := context.Background()
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	tests := []struct {
+		ctx  context.Context
+		err  error
+		want bool
+	}{
+		{cancelled, &DownloadError{Type: domain.ErrorTypeNotFound}, true},
+		{ctx, &DownloadError{Type: domain.ErrorTypeNetwork}, true},
+		{ctx, &DownloadError{Type: domain.ErrorTypeTimeout}, true},
+		{ctx, &DownloadError{Type: domain.ErrorTypeNotFound}, false},
+		{ctx, &DownloadError{}, false},
+	}
+
+	for _, tt := range tests {
+		if got := resumable(tt.ctx, tt.err); got != tt.want {
+			t.Errorf("resumable(ctx err=%v, %+v) = %v, want %v", tt.ctx.Err(), tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+// Package logging configura el logger estructurado (log/slog) del daemon
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Formatos de salida soportados
+const (
+	FormatText = "text" // Legible, para ejecuciones interactivas
+	FormatJSON = "json" // Una línea JSON por registro, para producción
+)
+
+// ParseLevel convierte "debug", "info", "warn" o "error" en un slog.Level
+func ParseLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("unknown log level %q (use debug, info, warn or error)", level)
+	}
+}
+
+// New crea un logger que escribe en w con el nivel y formato indicados
+func New(w io.Writer, level, format string) (*slog.Logger, error) {
+	lvl, err := ParseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl}
+
+	switch strings.ToLower(format) {
+	case "", FormatText:
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case FormatJSON:
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	default:
+		return nil, fmt.Errorf("unknown log format %q (use text or json)", format)
+	}
+}
+
+// Setup crea el logger y lo instala como default, también para el paquete log:
+// los log.Printf que queden salen por el mismo handler con nivel info
+func Setup(w io.Writer, level, format string) error {
+	logger, err := New(w, level, format)
+	if err != nil {
+		return err
+	}
+
+	slog.SetDefault(logger)
+	return nil
+}
+
+// RotatingFile retorna un writer que escribe en path y lo rota al superar maxSizeMB,
+// conservando maxBackups archivos anteriores (0 = todos)
+func RotatingFile(path string, maxSizeMB, maxBackups int) (io.WriteCloser, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("create log directory: %w", err)
+	}
+
+	return &lumberjack.Logger{
+		Filename:   path,
+		MaxSize:    maxSizeMB,
+		MaxBackups: maxBackups,
+	}, nil
+}
+
+// IsTerminal indica si f es una terminal (ejecución interactiva, no systemd)
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
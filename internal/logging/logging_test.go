@@ -0,0 +1,122 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNew_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, "warn", FormatJSON)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	logger.Info("hidden", "download_id", 1)
+	logger.Warn("Download failed", "download_id", int64(42), "platform", "youtube")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 record above warn, got %d: %q", len(lines), buf.String())
+	}
+
+	var record map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("record is not JSON: %v", err)
+	}
+	if record["level"] != "WARN" || record["msg"] != "Download failed" {
+		t.Errorf("unexpected record: %v", record)
+	}
+	if record["download_id"] != float64(42) || record["platform"] != "youtube" {
+		t.Errorf("missing fields: %v", record)
+	}
+}
+
+func TestNew_Text(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, "", "")
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	logger.Debug("hidden")
+	logger.Info("Received request", "action", "list")
+
+	out := buf.String()
+	if strings.Contains(out, "hidden") {
+		t.Errorf("debug record logged at default level: %q", out)
+	}
+	if !strings.Contains(out, "level=INFO") || !strings.Contains(out, "action=list") {
+		t.Errorf("unexpected text output: %q", out)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    slog.Level
+		wantErr bool
+	}{
+		{"debug", slog.LevelDebug, false},
+		{"INFO", slog.LevelInfo, false},
+		{"", slog.LevelInfo, false},
+		{"warning", slog.LevelWarn, false},
+		{"error", slog.LevelError, false},
+		{"verbose", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLevel(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseLevel(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+
+	if _, err := New(&bytes.Buffer{}, "info", "xml"); err == nil {
+		t.Error("New() with unknown format should fail")
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "logs", "daemon.log")
+
+	w, err := RotatingFile(path, 1, 2)
+	if err != nil {
+		t.Fatalf("RotatingFile() error: %v", err)
+	}
+	defer w.Close()
+
+	// Superar 1 MB fuerza una rotación
+	line := []byte(strings.Repeat("x", 1023) + "\n")
+	for i := 0; i < 1100; i++ {
+		if _, err := w.Write(line); err != nil {
+			t.Fatalf("Write() error: %v", err)
+		}
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("failed to read log dir: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("expected the log file and one backup, got %d files", len(entries))
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("log file missing: %v", err)
+	}
+	if info.Size() >= 1024*1024 {
+		t.Errorf("log file size = %d, want it rotated below 1 MB", info.Size())
+	}
+}
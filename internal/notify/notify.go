@@ -0,0 +1,157 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Notification es una notificación de escritorio
+type Notification struct {
+	Title   string
+	Message string
+	File    string // Archivo relacionado (opcional), usado para buscar una miniatura
+	Open    string // Archivo que abre la acción "Open" de la notificación (opcional)
+}
+
+// Notifier envía notificaciones de escritorio
+type Notifier interface {
+	Notify(n Notification) error
+}
+
+// New retorna el notifier adecuado para el SO. Si está deshabilitado o no hay
+// herramienta disponible retorna un Noop
+func New(enabled bool) Notifier {
+	if !enabled {
+		return Noop{}
+	}
+
+	switch runtime.GOOS {
+	case "darwin":
+		if _, err := exec.LookPath("osascript"); err == nil {
+			return OSAScript{}
+		}
+	default:
+		if _, err := exec.LookPath("notify-send"); err == nil {
+			return NotifySend{Actions: supportsActions()}
+		}
+	}
+
+	return Noop{}
+}
+
+// Noop descarta las notificaciones
+type Noop struct{}
+
+// Notify no hace nada
+func (Noop) Notify(Notification) error {
+	return nil
+}
+
+// NotifySend notifica con notify-send (Linux/BSD con libnotify)
+type NotifySend struct {
+	Actions bool // notify-send acepta --action (libnotify 0.7.10+)
+}
+
+// actionWait es cuánto se espera un click en la acción "Open"; después notify-send se
+// termina y la notificación queda solo informativa
+var actionWait = 10 * time.Minute
+
+// Notify envía la notificación, con la miniatura del archivo como icono si existe. Con
+// n.Open agrega la acción "Open", que abre el archivo con xdg-open: notify-send queda
+// esperando el click en segundo plano, así que Notify no bloquea
+func (s NotifySend) Notify(n Notification) error {
+	args := []string{"--app-name", "smart-download"}
+	if thumb := FindThumbnail(n.File); thumb != "" {
+		args = append(args, "--icon", thumb)
+	}
+
+	if n.Open == "" || !s.Actions {
+		args = append(args, n.Title, n.Message)
+		if err := exec.Command("notify-send", args...).Run(); err != nil {
+			return fmt.Errorf("notify-send: %w", err)
+		}
+		return nil
+	}
+
+	args = append(args, "--action=open=Open", n.Title, n.Message)
+
+	ctx, cancel := context.WithTimeout(context.Background(), actionWait)
+	cmd := exec.CommandContext(ctx, "notify-send", args...)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return fmt.Errorf("notify-send: %w", err)
+	}
+
+	// notify-send imprime la acción elegida al hacer click y termina al cerrarse la notificación
+	go func() {
+		defer cancel()
+		if cmd.Wait() == nil && strings.TrimSpace(stdout.String()) == "open" {
+			exec.Command("xdg-open", n.Open).Run()
+		}
+	}()
+	return nil
+}
+
+// supportsActions indica si el notify-send instalado acepta --action y hay con qué abrir
+// los archivos (xdg-open)
+func supportsActions() bool {
+	if _, err := exec.LookPath("xdg-open"); err != nil {
+		return false
+	}
+	help, err := exec.Command("notify-send", "--help").Output()
+	return err == nil && bytes.Contains(help, []byte("--action"))
+}
+
+// OSAScript notifica con osascript (macOS)
+type OSAScript struct{}
+
+// osascriptProgram muestra la notificación con el título y mensaje recibidos como argumentos:
+// así no hace falta escaparlos (AppleScript no entiende los escapes de Go como \u00e9)
+const osascriptProgram = `on run argv
+display notification (item 2 of argv) with title (item 1 of argv)
+end run`
+
+// Notify envía la notificación mediante AppleScript
+func (OSAScript) Notify(n Notification) error {
+	if err := exec.Command("osascript", "-e", osascriptProgram, n.Title, n.Message).Run(); err != nil {
+		return fmt.Errorf("osascript: %w", err)
+	}
+	return nil
+}
+
+// imageExts son las extensiones que notify-send puede usar como icono
+var imageExts = []string{".jpg", ".jpeg", ".png", ".webp"}
+
+// FindThumbnail retorna una imagen para representar path: el propio archivo si es una
+// imagen, o una miniatura con el mismo nombre base (p. ej. video.jpg junto a video.mp4)
+func FindThumbnail(path string) string {
+	if path == "" {
+		return ""
+	}
+
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, imgExt := range imageExts {
+		if ext == imgExt {
+			return path
+		}
+	}
+
+	base := strings.TrimSuffix(path, filepath.Ext(path))
+	for _, imgExt := range imageExts {
+		candidate := base + imgExt
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,121 @@
+package notify
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFindThumbnail(t *testing.T) {
+	dir := t.TempDir()
+
+	video := filepath.Join(dir, "youtube_user_01012025.mp4")
+	thumb := filepath.Join(dir, "youtube_user_01012025.webp")
+	image := filepath.Join(dir, "instagram_user_1.jpg")
+	lonely := filepath.Join(dir, "twitter_user.mp4")
+
+	for _, path := range []string{video, thumb, image, lonely} {
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatalf("failed to create file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{"video with thumbnail", video, thumb},
+		{"image is its own thumbnail", image, image},
+		{"video without thumbnail", lonely, ""},
+		{"empty path", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FindThumbnail(tt.path); got != tt.expected {
+				t.Errorf("FindThumbnail(%q) = %q, want %q", tt.path, got, tt.expected)
+			}
+		})
+	}
+}
+
+// fakeCommand crea un script ejecutable en dir con el nombre y cuerpo dados
+func fakeCommand(t *testing.T, dir, name, body string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+body), 0755); err != nil {
+		t.Fatalf("failed to create %s: %v", name, err)
+	}
+}
+
+func TestNotifySend_OpenAction(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell")
+	}
+
+	dir := t.TempDir()
+	opened := filepath.Join(dir, "opened")
+
+	// notify-send anuncia --action y simula un click en "Open"; xdg-open registra qué abrió
+	fakeCommand(t, dir, "notify-send", `if [ "$1" = "--help" ]; then echo "  -A, --action=[NAME=]Text..."; exit 0; fi
+for arg; do [ "$arg" = "--action=open=Open" ] && echo open; done
+exit 0
+`)
+	fakeCommand(t, dir, "xdg-open", `echo "$1" > "`+opened+`"`+"\n")
+	t.Setenv("PATH", dir)
+
+	if !supportsActions() {
+		t.Fatal("supportsActions() = false, want true")
+	}
+
+	file := filepath.Join(dir, "video.mp4")
+	n := Notification{Title: "Download Complete", Message: "Video\n" + file, File: file, Open: file}
+	if err := (NotifySend{Actions: true}).Notify(n); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		data, err := os.ReadFile(opened)
+		if err == nil && len(data) > 0 {
+			if got := string(data); got != file+"\n" {
+				t.Errorf("xdg-open got %q, want %q", got, file)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("xdg-open was not called after clicking Open")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestOSAScript_PassesTextAsArguments(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell")
+	}
+
+	dir := t.TempDir()
+	argsFile := filepath.Join(dir, "args")
+	fakeCommand(t, dir, "osascript", `printf '%s\0' "$@" > "`+argsFile+`"`+"\n")
+	t.Setenv("PATH", dir)
+
+	// Comillas, barras y acentos llegan tal cual, sin escapes de Go
+	n := Notification{Title: `Download "Complete"`, Message: `Café \ 100% ✓`}
+	if err := (OSAScript{}).Notify(n); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	data, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatalf("osascript was not run: %v", err)
+	}
+	args := strings.Split(strings.TrimSuffix(string(data), "\x00"), "\x00")
+	want := []string{"-e", osascriptProgram, n.Title, n.Message}
+	if strings.Join(args, "|") != strings.Join(want, "|") {
+		t.Errorf("osascript args = %q, want %q", args, want)
+	}
+}
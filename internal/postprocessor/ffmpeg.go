@@ -7,9 +7,9 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/elsanchez/smart-download/internal/domain"
 )
@@ -26,17 +26,44 @@ func NewFFmpegProcessor(tempDir string) *FFmpegProcessor {
 	}
 }
 
+// workDir crea un subdirectorio de tempDir propio de una operación, para que sus intermedios
+// (p.ej. la paleta del GIF) no choquen con los de otras operaciones concurrentes (workers de
+// la cola, smd convert --jobs). cleanup lo borra con todo su contenido
+func (f *FFmpegProcessor) workDir(prefix string) (dir string, cleanup func(), err error) {
+	if f.tempDir != "" {
+		if err := os.MkdirAll(f.tempDir, 0755); err != nil {
+			return "", nil, fmt.Errorf("create temp dir: %w", err)
+		}
+	}
+	dir, err = os.MkdirTemp(f.tempDir, prefix+"-*")
+	if err != nil {
+		return "", nil, fmt.Errorf("create work dir: %w", err)
+	}
+	return dir, func() { os.RemoveAll(dir) }, nil
+}
+
 // VideoInfo contiene información del video
 type VideoInfo struct {
-	Width         int
-	Height        int
-	VideoCodec    string
-	AudioCodec    string
-	Duration      float64
-	Bitrate       int64
-	FrameRate     float64
-	HasVideo      bool
-	HasAudio      bool
+	Width          int
+	Height         int
+	VideoCodec     string
+	PixelFormat    string // p.ej. yuv420p; "" si ffprobe no lo informa
+	ColorTransfer  string // Función de transferencia: smpte2084 (PQ) y arib-std-b67 (HLG) son HDR
+	ColorPrimaries string // p.ej. bt2020; se conserva al re-encodear un HDR sin tonemapping
+	ColorSpace     string // Matriz de color (p.ej. bt2020nc), ídem
+	AudioCodec     string
+	AudioChannels  int // 2 = estéreo, 6 = 5.1; 0 si ffprobe no lo informa
+	Duration       float64
+	Bitrate        int64
+	FrameRate      float64
+	HasVideo       bool
+	HasAudio       bool
+}
+
+// IsHDR indica si el video es HDR según su función de transferencia (PQ o HLG). Los videos
+// con primarios BT.2020 pero transferencia SDR no cuentan
+func (v *VideoInfo) IsHDR() bool {
+	return v.ColorTransfer == "smpte2084" || v.ColorTransfer == "arib-std-b67"
 }
 
 // GetVideoInfo obtiene información del video usando ffprobe
@@ -55,10 +82,20 @@ func (f *FFmpegProcessor) GetVideoInfo(ctx context.Context, inputPath string) (*
 		return nil, fmt.Errorf("ffprobe failed: %w", err)
 	}
 
+	return parseVideoInfo(output)
+}
+
+// parseVideoInfo lee la salida JSON de ffprobe -show_format -show_streams
+func parseVideoInfo(output []byte) (*VideoInfo, error) {
 	var result struct {
 		Streams []struct {
 			CodecType     string  `json:"codec_type"`
 			CodecName     string  `json:"codec_name"`
+			PixFmt        string  `json:"pix_fmt"`
+			Transfer      string  `json:"color_transfer"`
+			Primaries     string  `json:"color_primaries"`
+			ColorSpace    string  `json:"color_space"`
+			Channels      int     `json:"channels"`
 			Width         int     `json:"width"`
 			Height        int     `json:"height"`
 			RFrameRate    string  `json:"r_frame_rate"`
@@ -80,6 +117,10 @@ func (f *FFmpegProcessor) GetVideoInfo(ctx context.Context, inputPath string) (*
 		case "video":
 			info.HasVideo = true
 			info.VideoCodec = stream.CodecName
+			info.PixelFormat = stream.PixFmt
+			info.ColorTransfer = stream.Transfer
+			info.ColorPrimaries = stream.Primaries
+			info.ColorSpace = stream.ColorSpace
 			info.Width = stream.Width
 			info.Height = stream.Height
 
@@ -97,6 +138,7 @@ func (f *FFmpegProcessor) GetVideoInfo(ctx context.Context, inputPath string) (*
 		case "audio":
 			info.HasAudio = true
 			info.AudioCodec = stream.CodecName
+			info.AudioChannels = stream.Channels
 		}
 	}
 
@@ -113,43 +155,25 @@ func (f *FFmpegProcessor) GetVideoInfo(ctx context.Context, inputPath string) (*
 	return info, nil
 }
 
-// IsWhatsAppCompatible verifica si el video es compatible con WhatsApp
-func (f *FFmpegProcessor) IsWhatsAppCompatible(ctx context.Context, inputPath string) (bool, string, error) {
+// IsCompatible verifica si el video cumple el perfil target y las opciones que afectan al
+// formato (p.ej. quitar el audio). options puede ser nil
+func (f *FFmpegProcessor) IsCompatible(ctx context.Context, inputPath string, target Target, options *domain.DownloadOptions) (bool, string, error) {
 	info, err := f.GetVideoInfo(ctx, inputPath)
 	if err != nil {
 		return false, "", err
 	}
 
-	reasons := []string{}
-
-	// Verificar codec de video (H.264)
-	if info.VideoCodec != "h264" {
-		reasons = append(reasons, fmt.Sprintf("video codec is %s (needs h264)", info.VideoCodec))
-	}
-
-	// Verificar codec de audio (AAC)
-	if info.HasAudio && info.AudioCodec != "aac" {
-		reasons = append(reasons, fmt.Sprintf("audio codec is %s (needs aac)", info.AudioCodec))
-	}
-
-	// Verificar resolución (máximo 1080p)
-	if info.Height > 1080 {
-		reasons = append(reasons, fmt.Sprintf("resolution is %dx%d (max 1920x1080)", info.Width, info.Height))
-	}
-
-	if len(reasons) > 0 {
-		return false, strings.Join(reasons, "; "), nil
-	}
-
-	return true, "", nil
+	compatible, reason := target.Check(info, options)
+	return compatible, reason, nil
 }
 
-// ConvertToWhatsAppMP4 convierte el video a formato compatible con WhatsApp
-func (f *FFmpegProcessor) ConvertToWhatsAppMP4(ctx context.Context, inputPath string) (string, error) {
+// Convert convierte el video a un MP4 compatible con el perfil target, aplicando las
+// ediciones de options (recorte, rotación, velocidad, sin audio, metadata). options puede ser nil
+func (f *FFmpegProcessor) Convert(ctx context.Context, inputPath string, target Target, options *domain.DownloadOptions) (string, error) {
 	// Generar path de salida
 	ext := filepath.Ext(inputPath)
 	base := strings.TrimSuffix(inputPath, ext)
-	outputPath := base + "_whatsapp.mp4"
+	outputPath := base + target.Suffix()
 
 	// Obtener info del video
 	info, err := f.GetVideoInfo(ctx, inputPath)
@@ -164,40 +188,72 @@ func (f *FFmpegProcessor) ConvertToWhatsAppMP4(ctx context.Context, inputPath st
 		"-loglevel", "error",
 	}
 
-	// Video: H.264 con escala si es necesario
-	if info.Height > 1080 {
-		// Escalar manteniendo aspect ratio
-		args = append(args,
-			"-vf", "scale=-2:1080", // -2 asegura width divisible por 2
-			"-c:v", "libx264",
-			"-preset", "medium",
-			"-crf", "23",
-		)
-	} else if info.VideoCodec != "h264" {
-		// Solo re-encodear video
+	// Pista muda para videos sin audio: segunda entrada generada por lavfi, cortada a la
+	// duración del video con -shortest
+	addSilence := needsSilentTrack(info, options)
+	if addSilence {
 		args = append(args,
-			"-c:v", "libx264",
-			"-preset", "medium",
-			"-crf", "23",
+			"-f", "lavfi",
+			"-i", silentAudioSource,
+			"-map", "0:v:0",
+			"-map", "1:a:0",
+			"-shortest",
 		)
+	}
+
+	// Filtros: recorte, rotación y escala a la resolución máxima del perfil si es necesario
+	filters, err := f.videoFilters(ctx, inputPath, info, target.MaxShortSide, options)
+	if err != nil {
+		return "", err
+	}
+
+	// Video: H.264, salvo que el codec ya sirva y no haya filtros
+	if len(filters) > 0 {
+		args = append(args, "-vf", strings.Join(filters, ","))
+	}
+	if len(filters) > 0 || !target.copiesVideo(info.VideoCodec) || !target.copiesPixelFormat(info, options) {
+		args = append(args, videoEncodeArgs(info, target, options)...)
 	} else {
 		// Copiar video sin re-encodear
 		args = append(args, "-c:v", "copy")
+		if info.VideoCodec == "hevc" {
+			// hvc1 en lugar de hev1 para que los reproductores de Apple lo abran
+			args = append(args, "-tag:v", "hvc1")
+		}
 	}
 
-	// Audio: AAC
-	if info.HasAudio {
-		if info.AudioCodec != "aac" {
+	// Audio: sin pista si se pidió quitarla; si no, AAC (con filtros, p.ej. cambio de
+	// velocidad, hay que re-encodear)
+	if options != nil && options.RemoveAudio {
+		args = append(args, "-an")
+	} else if addSilence {
+		args = append(args,
+			"-c:a", "aac",
+			"-b:a", target.AudioBitrate,
+		)
+	} else if info.HasAudio {
+		audioFilters := audioFilters(options)
+		if len(audioFilters) > 0 {
+			args = append(args, "-af", strings.Join(audioFilters, ","))
+		}
+		downmix := target.downmixes(info, options)
+		if !target.copiesAudio(info.AudioCodec) || len(audioFilters) > 0 || downmix {
 			args = append(args,
 				"-c:a", "aac",
-				"-b:a", "128k",
+				"-b:a", target.AudioBitrate,
 			)
+			if downmix {
+				args = append(args, "-ac", strconv.Itoa(target.MaxAudioChannels))
+			}
 		} else {
 			// Copiar audio sin re-encodear
 			args = append(args, "-c:a", "copy")
 		}
 	}
 
+	// Metadata: tags del original (o ninguno) y título/comentario
+	args = append(args, metadataArgs(options)...)
+
 	// Formato MP4
 	args = append(args,
 		"-f", "mp4",
@@ -206,8 +262,13 @@ func (f *FFmpegProcessor) ConvertToWhatsAppMP4(ctx context.Context, inputPath st
 		outputPath,
 	)
 
-	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
-	output, err := cmd.CombinedOutput()
+	// Duración de la salida, para el avance: con cambio de velocidad no es la del original
+	duration := info.Duration
+	if hasSpeedChange(options) {
+		duration /= options.Speed
+	}
+
+	output, err := runFFmpeg(ctx, args, duration)
 	if err != nil {
 		return "", fmt.Errorf("ffmpeg failed: %w\nOutput: %s", err, output)
 	}
@@ -215,16 +276,199 @@ func (f *FFmpegProcessor) ConvertToWhatsAppMP4(ctx context.Context, inputPath st
 	return outputPath, nil
 }
 
+// hdrPixelFormat es el formato de un HDR re-encodeado sin tonemapping: en 8 bits se ve con
+// bandas y lavado
+const hdrPixelFormat = "yuv420p10le"
+
+// videoEncodeArgs arma los argumentos de libx264. -pix_fmt: libx264 conserva el submuestreo
+// de la entrada (p.ej. yuv444p), que muchos teléfonos no reproducen. Un HDR con NoTonemap
+// queda en 10 bits y con su metadata de color, sin la cual los reproductores lo toman por SDR
+func videoEncodeArgs(info *VideoInfo, target Target, options *domain.DownloadOptions) []string {
+	args := []string{
+		"-c:v", "libx264",
+		"-preset", target.Preset,
+		"-crf", strconv.Itoa(target.CRF),
+	}
+	if !info.IsHDR() || needsTonemap(info, options) {
+		return append(args, "-pix_fmt", target.PixelFormat)
+	}
+
+	args = append(args, "-pix_fmt", hdrPixelFormat, "-color_trc", info.ColorTransfer)
+	if info.ColorPrimaries != "" {
+		args = append(args, "-color_primaries", info.ColorPrimaries)
+	}
+	if info.ColorSpace != "" {
+		args = append(args, "-colorspace", info.ColorSpace)
+	}
+	return args
+}
+
+// silentAudioSource es la pista muda que agrega EnsureAudio (estéreo a 44.1kHz, lo más común)
+const silentAudioSource = "anullsrc=channel_layout=stereo:sample_rate=44100"
+
+// needsSilentTrack indica si la conversión tiene que agregar una pista muda: se pidió
+// EnsureAudio y el video no tiene audio
+func needsSilentTrack(info *VideoInfo, options *domain.DownloadOptions) bool {
+	return options != nil && options.EnsureAudio && !options.RemoveAudio && !info.HasAudio
+}
+
+// audioCodecs es el encoder de ffmpeg de cada formato de audio-only
+var audioCodecs = map[string]string{
+	"mp3":  "libmp3lame",
+	"opus": "libopus",
+	"m4a":  "aac",
+	"flac": "flac",
+}
+
+// isAudioFormat indica si el archivo ya está en el formato de audio pedido (p.ej. lo que deja yt-dlp -x)
+func isAudioFormat(path, format string) bool {
+	return strings.EqualFold(filepath.Ext(path), "."+format)
+}
+
+// ExtractAudio extrae la pista de audio al formato y bitrate (kbps) indicados, equivalente a
+// yt-dlp -x, para las plataformas de gallery-dl que no tienen extracción propia
+func (f *FFmpegProcessor) ExtractAudio(ctx context.Context, inputPath, format string, bitrate int) (string, error) {
+	codec, ok := audioCodecs[format]
+	if !ok {
+		return "", fmt.Errorf("unsupported audio format: %s", format)
+	}
+
+	info, err := f.GetVideoInfo(ctx, inputPath)
+	if err != nil {
+		return "", fmt.Errorf("get video info: %w", err)
+	}
+	if !info.HasAudio {
+		return "", fmt.Errorf("%s has no audio stream", filepath.Base(inputPath))
+	}
+
+	ext := filepath.Ext(inputPath)
+	outputPath := strings.TrimSuffix(inputPath, ext) + "." + format
+
+	cmd := exec.CommandContext(ctx, "ffmpeg", extractAudioArgs(inputPath, outputPath, codec, format, bitrate)...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("ffmpeg extract audio: %w\nOutput: %s", err, output)
+	}
+
+	return outputPath, nil
+}
+
+// extractAudioArgs arma los argumentos de ffmpeg para ExtractAudio
+func extractAudioArgs(inputPath, outputPath, codec, format string, bitrate int) []string {
+	args := []string{
+		"-i", inputPath,
+		"-hide_banner",
+		"-loglevel", "error",
+		"-vn", // Sin video (ni carátulas)
+		"-c:a", codec,
+	}
+	// FLAC es sin pérdida: el bitrate no aplica
+	if format != "flac" {
+		args = append(args, "-b:a", fmt.Sprintf("%dk", bitrate))
+	}
+	return append(args, "-y", outputPath)
+}
+
+// Concat une los videos en un único MP4 compatible con WhatsApp (re-encodeando, así que
+// pueden tener codecs y resoluciones distintos). outputPath vacío = <primero>_joined.mp4
+func (f *FFmpegProcessor) Concat(ctx context.Context, inputs []string, outputPath string) (string, error) {
+	if len(inputs) < 2 {
+		return "", fmt.Errorf("concat needs at least 2 videos, got %d", len(inputs))
+	}
+
+	infos := make([]*VideoInfo, len(inputs))
+	for i, input := range inputs {
+		info, err := f.GetVideoInfo(ctx, input)
+		if err != nil {
+			return "", fmt.Errorf("probe %s: %w", input, err)
+		}
+		if !info.HasVideo {
+			return "", fmt.Errorf("probe %s: no video stream", input)
+		}
+		infos[i] = info
+	}
+
+	if outputPath == "" {
+		outputPath = strings.TrimSuffix(inputs[0], filepath.Ext(inputs[0])) + "_joined.mp4"
+	}
+
+	var duration float64
+	for _, info := range infos {
+		duration += info.Duration
+	}
+
+	if output, err := runFFmpeg(ctx, concatArgs(inputs, infos, outputPath), duration); err != nil {
+		return "", fmt.Errorf("ffmpeg concat: %w\nOutput: %s", err, output)
+	}
+
+	return outputPath, nil
+}
+
+// Defaults de la conversión a GIF
+const (
+	DefaultGIFWidth  = 480
+	DefaultGIFFPS    = 15
+	DefaultGIFDither = "bayer" // Con bayer_scale=5: poco ruido y archivos chicos
+	maxGIFFPS        = 50      // Los navegadores ignoran delays menores a 2 centésimas
+)
+
+// GIFDitherModes son los modos de dither de paletteuse en ffmpeg (los tres últimos
+// requieren ffmpeg 6 o posterior)
+var GIFDitherModes = []string{"bayer", "heckbert", "floyd_steinberg", "sierra2", "sierra2_4a", "none", "sierra3", "burkes", "atkinson"}
+
+// GIFOptions son los parámetros de ConvertToGIF. Los campos vacíos usan los defaults
+type GIFOptions struct {
+	Width     int
+	StartTime string // Segundos o formato ffmpeg ("" = desde el inicio)
+	Duration  string // Ídem ("" = hasta el final)
+	FPS       int
+	Dither    string
+}
+
+// withDefaults completa los campos vacíos
+func (g GIFOptions) withDefaults() GIFOptions {
+	if g.Width <= 0 {
+		g.Width = DefaultGIFWidth
+	}
+	if g.FPS <= 0 {
+		g.FPS = DefaultGIFFPS
+	}
+	if g.Dither == "" {
+		g.Dither = DefaultGIFDither
+	}
+	return g
+}
+
+// paletteFilter es el filtro del paso 1 (palettegen)
+func (g GIFOptions) paletteFilter() string {
+	return fmt.Sprintf("fps=%d,scale=%d:-1:flags=lanczos,palettegen=stats_mode=diff", g.FPS, g.Width)
+}
+
+// gifFilter es el filtro del paso 2 (paletteuse con la paleta como segunda entrada)
+func (g GIFOptions) gifFilter() string {
+	dither := "dither=" + g.Dither
+	if g.Dither == "bayer" {
+		dither += ":bayer_scale=5"
+	}
+	return fmt.Sprintf("fps=%d,scale=%d:-1:flags=lanczos[x];[x][1:v]paletteuse=%s", g.FPS, g.Width, dither)
+}
+
 // ConvertToGIF convierte el video a GIF optimizado
-func (f *FFmpegProcessor) ConvertToGIF(ctx context.Context, inputPath string, width int, startTime, duration string) (string, error) {
+func (f *FFmpegProcessor) ConvertToGIF(ctx context.Context, inputPath string, gif GIFOptions) (string, error) {
+	gif = gif.withDefaults()
+	startTime, duration := gif.StartTime, gif.Duration
+
 	// Generar path de salida
 	ext := filepath.Ext(inputPath)
 	base := strings.TrimSuffix(inputPath, ext)
 	outputPath := base + ".gif"
 
-	// Palette temporal para mejor calidad
-	palettePath := filepath.Join(f.tempDir, "palette.png")
-	defer os.Remove(palettePath)
+	// Palette temporal para mejor calidad, en un directorio propio de esta conversión
+	workDir, cleanup, err := f.workDir("gif")
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+	palettePath := filepath.Join(workDir, "palette.png")
 
 	// Paso 1: Generar paleta de colores
 	paletteArgs := []string{
@@ -241,7 +485,7 @@ func (f *FFmpegProcessor) ConvertToGIF(ctx context.Context, inputPath string, wi
 	}
 
 	paletteArgs = append(paletteArgs,
-		"-vf", fmt.Sprintf("fps=15,scale=%d:-1:flags=lanczos,palettegen=stats_mode=diff", width),
+		"-vf", gif.paletteFilter(),
 		"-y",
 		palettePath,
 	)
@@ -267,7 +511,7 @@ func (f *FFmpegProcessor) ConvertToGIF(ctx context.Context, inputPath string, wi
 	}
 
 	gifArgs = append(gifArgs,
-		"-lavfi", fmt.Sprintf("fps=15,scale=%d:-1:flags=lanczos[x];[x][1:v]paletteuse=dither=bayer:bayer_scale=5", width),
+		"-lavfi", gif.gifFilter(),
 		"-y",
 		outputPath,
 	)
@@ -304,38 +548,150 @@ func (f *FFmpegProcessor) OptimizeGIF(ctx context.Context, inputPath string) (st
 	}
 
 	// Re-generar con ancho correcto
-	return f.ConvertToGIF(ctx, inputPath, maxWidth, "", "")
+	return f.ConvertToGIF(ctx, inputPath, GIFOptions{Width: maxWidth})
 }
 
-// parseTimeToSeconds convierte varios formatos de tiempo a segundos
-// Soporta:
-// - Duraciones Go: "1m30s", "90s", "1h4m10s"
-// - Segundos: "90"
-// - HH:MM:SS: "00:01:30"
-func parseTimeToSeconds(timeStr string) (string, error) {
-	// Intentar parsear como duración Go (1m30s, 90s, 1h4m10s)
-	if duration, err := time.ParseDuration(timeStr); err == nil {
-		return fmt.Sprintf("%.3f", duration.Seconds()), nil
+// gifSegment normaliza a segundos el inicio y la duración del GIF ("" = no indicado)
+func gifSegment(options *domain.DownloadOptions) (start, duration string, err error) {
+	if options.GIFStart != "" {
+		if start, err = parseTimeToSeconds(options.GIFStart); err != nil {
+			return "", "", fmt.Errorf("gif start: %w", err)
+		}
+	}
+	if options.GIFDuration != "" {
+		if duration, err = parseTimeToSeconds(options.GIFDuration); err != nil {
+			return "", "", fmt.Errorf("gif duration: %w", err)
+		}
+		if seconds, _ := strconv.ParseFloat(duration, 64); seconds <= 0 {
+			return "", "", fmt.Errorf("gif duration must be positive, got %s", options.GIFDuration)
+		}
+	}
+	return start, duration, nil
+}
+
+// ValidateGIF verifica el segmento, los fps y el dither del GIF, que solo aplican con ConvertToGIF
+func ValidateGIF(options *domain.DownloadOptions) error {
+	if (options.GIFStart != "" || options.GIFDuration != "" || options.GIFFPS != 0 || options.GIFDither != "") && !options.ConvertToGIF {
+		return fmt.Errorf("gif_start, gif_duration, gif_fps and gif_dither require convert_to_gif")
+	}
+	if options.GIFFPS < 0 || options.GIFFPS > maxGIFFPS {
+		return fmt.Errorf("invalid gif fps %d (use 1-%d)", options.GIFFPS, maxGIFFPS)
+	}
+	if options.GIFDither != "" && !slices.Contains(GIFDitherModes, options.GIFDither) {
+		return fmt.Errorf("unsupported gif dither %q (use %s)", options.GIFDither, strings.Join(GIFDitherModes, ", "))
 	}
+	_, _, err := gifSegment(options)
+	return err
+}
 
-	// Verificar si es un número simple (segundos)
+// parseTimeToSeconds convierte un tiempo (formatos de domain.ParseSeconds) a segundos para
+// ffmpeg. Un número simple se pasa tal cual
+func parseTimeToSeconds(timeStr string) (string, error) {
 	if _, err := strconv.ParseFloat(timeStr, 64); err == nil {
 		return timeStr, nil
 	}
 
-	// Verificar si es formato HH:MM:SS
-	parts := strings.Split(timeStr, ":")
-	if len(parts) == 3 {
-		h, err1 := strconv.Atoi(parts[0])
-		m, err2 := strconv.Atoi(parts[1])
-		s, err3 := strconv.ParseFloat(parts[2], 64)
-		if err1 == nil && err2 == nil && err3 == nil {
-			totalSeconds := float64(h*3600 + m*60) + s
-			return fmt.Sprintf("%.3f", totalSeconds), nil
+	seconds, err := domain.ParseSeconds(timeStr)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%.3f", seconds), nil
+}
+
+// ExtractFrame guarda el fotograma en atTime como imagen. El formato (JPG o PNG) sale de la
+// extensión de outputPath; vacío = <video>_frame_<tiempo>.jpg junto al video
+func (f *FFmpegProcessor) ExtractFrame(ctx context.Context, inputPath, atTime, outputPath string) (string, error) {
+	seconds, err := parseTimeToSeconds(atTime)
+	if err != nil {
+		return "", err
+	}
+
+	info, err := f.GetVideoInfo(ctx, inputPath)
+	if err != nil {
+		return "", fmt.Errorf("get video info: %w", err)
+	}
+	if !info.HasVideo {
+		return "", fmt.Errorf("%s has no video stream", filepath.Base(inputPath))
+	}
+
+	// Validar que el tiempo esté dentro del video
+	at, _ := strconv.ParseFloat(seconds, 64)
+	if at >= info.Duration {
+		return "", fmt.Errorf("time %.1fs exceeds video duration %.1fs", at, info.Duration)
+	}
+
+	if outputPath == "" {
+		base := strings.TrimSuffix(inputPath, filepath.Ext(inputPath))
+		outputPath = fmt.Sprintf("%s_frame_%s.jpg", base, strings.ReplaceAll(atTime, ":", "-"))
+	}
+
+	if err := writeFrame(ctx, inputPath, seconds, outputPath); err != nil {
+		return "", err
+	}
+	return outputPath, nil
+}
+
+// ExtractFrames guarda count fotogramas repartidos uniformemente por el video (p.ej. para una
+// hoja de contactos) como <video>_frame_NN.jpg en outputDir (vacío = junto al video)
+func (f *FFmpegProcessor) ExtractFrames(ctx context.Context, inputPath string, count int, outputDir string) ([]string, error) {
+	if count <= 0 {
+		return nil, fmt.Errorf("invalid frame count: %d", count)
+	}
+
+	info, err := f.GetVideoInfo(ctx, inputPath)
+	if err != nil {
+		return nil, fmt.Errorf("get video info: %w", err)
+	}
+	if !info.HasVideo || info.Duration <= 0 {
+		return nil, fmt.Errorf("%s has no video stream", filepath.Base(inputPath))
+	}
+
+	if outputDir == "" {
+		outputDir = filepath.Dir(inputPath)
+	}
+	base := strings.TrimSuffix(filepath.Base(inputPath), filepath.Ext(inputPath))
+
+	var frames []string
+	for i, at := range frameTimestamps(info.Duration, count) {
+		outputPath := filepath.Join(outputDir, fmt.Sprintf("%s_frame_%02d.jpg", base, i+1))
+		if err := writeFrame(ctx, inputPath, fmt.Sprintf("%.3f", at), outputPath); err != nil {
+			return frames, err
 		}
+		frames = append(frames, outputPath)
 	}
 
-	return "", fmt.Errorf("invalid time format: %s (expected: 1m30s, 90, or 00:01:30)", timeStr)
+	return frames, nil
+}
+
+// frameTimestamps reparte count instantes uniformemente, en el centro de cada tramo
+// (evita el primer fotograma, que suele ser negro, y el final del video)
+func frameTimestamps(duration float64, count int) []float64 {
+	timestamps := make([]float64, count)
+	for i := range timestamps {
+		timestamps[i] = duration * (float64(i) + 0.5) / float64(count)
+	}
+	return timestamps
+}
+
+// writeFrame extrae un fotograma en seconds a outputPath
+func writeFrame(ctx context.Context, inputPath, seconds, outputPath string) error {
+	args := []string{
+		"-ss", seconds, // Antes de -i: búsqueda rápida
+		"-i", inputPath,
+		"-hide_banner",
+		"-loglevel", "error",
+		"-frames:v", "1",
+	}
+	if ext := strings.ToLower(filepath.Ext(outputPath)); ext == ".jpg" || ext == ".jpeg" {
+		args = append(args, "-q:v", "2") // Calidad JPEG alta
+	}
+	args = append(args, "-y", outputPath)
+
+	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("ffmpeg extract frame: %w\nOutput: %s", err, output)
+	}
+	return nil
 }
 
 // ClipVideo extrae un segmento del video
@@ -448,8 +804,23 @@ func (f *FFmpegProcessor) Process(ctx context.Context, inputPath string, options
 	currentPath := inputPath
 	var err error
 
-	// 1. Clipping si está especificado
-	if options.ClipStart != "" && options.ClipEnd != "" {
+	// Audio-only: yt-dlp ya entrega audio; lo de gallery-dl se extrae con ffmpeg
+	if options.AudioOnly {
+		format, bitrate := options.Audio()
+		if isAudioFormat(inputPath, format) {
+			return inputPath, nil
+		}
+
+		audioPath, err := f.ExtractAudio(ctx, inputPath, format, bitrate)
+		if err != nil {
+			return "", fmt.Errorf("extract audio: %w", err)
+		}
+		os.Remove(inputPath)
+		return audioPath, nil
+	}
+
+	// 1. Clipping si está especificado (Validate exige el fin; sin inicio, desde el principio)
+	if options.ClipEnd != "" {
 		currentPath, err = f.ClipVideo(ctx, currentPath, options.ClipStart, options.ClipEnd)
 		if err != nil {
 			return "", fmt.Errorf("clip video: %w", err)
@@ -460,14 +831,20 @@ func (f *FFmpegProcessor) Process(ctx context.Context, inputPath string, options
 		}
 	}
 
-	// 2. Conversión a GIF si está especificado
+	// 2. Conversión a GIF si está especificado. El inicio es relativo al clip, si lo hay
 	if options.ConvertToGIF {
-		width := 480
-		if options.GIFWidth > 0 {
-			width = options.GIFWidth
+		start, duration, err := gifSegment(options)
+		if err != nil {
+			return "", err
 		}
 
-		currentPath, err = f.ConvertToGIF(ctx, currentPath, width, "", "")
+		currentPath, err = f.ConvertToGIF(ctx, currentPath, GIFOptions{
+			Width:     options.GIFWidth,
+			StartTime: start,
+			Duration:  duration,
+			FPS:       options.GIFFPS,
+			Dither:    options.GIFDither,
+		})
 		if err != nil {
 			return "", fmt.Errorf("convert to gif: %w", err)
 		}
@@ -478,22 +855,30 @@ func (f *FFmpegProcessor) Process(ctx context.Context, inputPath string, options
 		return currentPath, nil
 	}
 
-	// 3. Conversión a WhatsApp MP4 (siempre, a menos que ya sea compatible)
-	compatible, reason, err := f.IsWhatsAppCompatible(ctx, currentPath)
+	// 3. Conversión al MP4 del perfil (siempre, a menos que ya sea compatible o se haya
+	// desactivado sin ediciones de imagen pendientes)
+	if options.SkipsConversion() && !forcesConversion(options) {
+		return currentPath, nil
+	}
+	target, err := TargetFor(options)
+	if err != nil {
+		return "", err
+	}
+	compatible, reason, err := f.IsCompatible(ctx, currentPath, target, options)
 	if err != nil {
-		return "", fmt.Errorf("check whatsapp compatibility: %w", err)
+		return "", fmt.Errorf("check %s compatibility: %w", target.Name, err)
 	}
 
-	if !compatible {
-		whatsappPath, err := f.ConvertToWhatsAppMP4(ctx, currentPath)
+	if !compatible || forcesConversion(options) {
+		convertedPath, err := f.Convert(ctx, currentPath, target, options)
 		if err != nil {
-			return "", fmt.Errorf("convert to whatsapp mp4: %w (reason: %s)", err, reason)
+			return "", fmt.Errorf("convert to %s mp4: %w (reason: %s)", target.Name, err, reason)
 		}
 		// Eliminar original después de conversión exitosa
-		if whatsappPath != currentPath {
+		if convertedPath != currentPath {
 			os.Remove(currentPath)
 		}
-		currentPath = whatsappPath
+		currentPath = convertedPath
 	}
 
 	return currentPath, nil
@@ -501,14 +886,27 @@ func (f *FFmpegProcessor) Process(ctx context.Context, inputPath string, options
 
 // NeedsProcessing implementa PostProcessor.NeedsProcessing
 func (f *FFmpegProcessor) NeedsProcessing(inputPath string, options *domain.DownloadOptions) (bool, error) {
-	// Siempre procesar si hay clipping o conversión a GIF
-	if options.ClipStart != "" || options.ClipEnd != "" || options.ConvertToGIF {
+	// Audio-only: solo falta extraer si el archivo no es ya audio
+	if options.AudioOnly {
+		format, _ := options.Audio()
+		return !isAudioFormat(inputPath, format), nil
+	}
+
+	// Siempre procesar si hay clipping, conversión a GIF o ediciones de imagen
+	if options.ClipStart != "" || options.ClipEnd != "" || options.ConvertToGIF || forcesConversion(options) {
 		return true, nil
 	}
+	if options.SkipsConversion() {
+		return false, nil
+	}
 
-	// Para videos, verificar compatibilidad WhatsApp
+	// Para videos, verificar compatibilidad con el perfil
+	target, err := TargetFor(options)
+	if err != nil {
+		return false, err
+	}
 	ctx := context.Background()
-	compatible, _, err := f.IsWhatsAppCompatible(ctx, inputPath)
+	compatible, _, err := f.IsCompatible(ctx, inputPath, target, options)
 	if err != nil {
 		// Si no podemos verificar, asumir que necesita procesamiento
 		return true, nil
@@ -0,0 +1,469 @@
+package postprocessor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/elsanchez/smart-download/internal/domain"
+)
+
+func TestParseTimeToSeconds(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"1m30s", "90.000", false},
+		{"90", "90", false},
+		{"00:01:30", "90.000", false},
+		{"01:30", "90.000", false},
+		{"00:10", "10.000", false},
+		{"1:2:3:4", "", true},
+		{"abc", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseTimeToSeconds(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseTimeToSeconds(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseTimeToSeconds(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestFrameTimestamps(t *testing.T) {
+	got := frameTimestamps(100, 4)
+	want := []float64{12.5, 37.5, 62.5, 87.5}
+
+	if len(got) != len(want) {
+		t.Fatalf("frameTimestamps() returned %d timestamps, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("timestamp %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestWhatsAppIssues(t *testing.T) {
+	compatible := VideoInfo{Width: 1280, Height: 720, VideoCodec: "h264", AudioCodec: "aac", HasVideo: true, HasAudio: true}
+	opusAudio := VideoInfo{Width: 1280, Height: 720, VideoCodec: "h264", AudioCodec: "opus", HasVideo: true, HasAudio: true}
+	silent := VideoInfo{Width: 1280, Height: 720, VideoCodec: "h264", HasVideo: true}
+	noAudio := &domain.DownloadOptions{RemoveAudio: true}
+	hdr := VideoInfo{Width: 1920, Height: 1080, VideoCodec: "h264", PixelFormat: "yuv420p10le", ColorTransfer: "smpte2084"}
+	surround := func(channels int) VideoInfo {
+		return VideoInfo{Width: 1280, Height: 720, VideoCodec: "h264", AudioCodec: "aac", AudioChannels: channels, HasVideo: true, HasAudio: true}
+	}
+
+	tests := []struct {
+		name    string
+		info    VideoInfo
+		options *domain.DownloadOptions
+		want    []string
+	}{
+		{"compatible", compatible, nil, []string{}},
+		{"opus audio", opusAudio, nil, []string{"audio codec is opus (needs aac)"}},
+		{"remove audio ignores its codec", opusAudio, noAudio, []string{"has an audio track (removal requested)"}},
+		{"remove audio from a silent video", silent, noAudio, []string{}},
+		{"silent video", silent, nil, []string{}},
+		{"silent video with ensure audio", silent, &domain.DownloadOptions{EnsureAudio: true}, []string{"has no audio track (silent track requested)"}},
+		{"ensure audio with audio", compatible, &domain.DownloadOptions{EnsureAudio: true}, []string{}},
+		{"portrait reel", VideoInfo{Width: 1080, Height: 1920, VideoCodec: "h264"}, nil, []string{}},
+		{"portrait 4k", VideoInfo{Width: 2160, Height: 3840, VideoCodec: "h264"}, nil, []string{"resolution is 2160x3840 (max 1080x1920)"}},
+		{"square too big", VideoInfo{Width: 1440, Height: 1440, VideoCodec: "h264"}, nil, []string{"resolution is 1440x1440 (max 1920x1080)"}},
+		{"yuv420p", VideoInfo{Width: 1280, Height: 720, VideoCodec: "h264", PixelFormat: "yuv420p"}, nil, []string{}},
+		{"yuvj420p", VideoInfo{Width: 1280, Height: 720, VideoCodec: "h264", PixelFormat: "yuvj420p"}, nil, []string{}},
+		{"yuvj444p", VideoInfo{Width: 1280, Height: 720, VideoCodec: "h264", PixelFormat: "yuvj444p"}, nil, []string{"pixel format is yuvj444p (needs yuv420p)"}},
+		{"yuv444p", VideoInfo{Width: 1280, Height: 720, VideoCodec: "h264", PixelFormat: "yuv444p"}, nil, []string{"pixel format is yuv444p (needs yuv420p)"}},
+		{"yuv422p", VideoInfo{Width: 1280, Height: 720, VideoCodec: "h264", PixelFormat: "yuv422p"}, nil, []string{"pixel format is yuv422p (needs yuv420p)"}},
+		{"stereo", surround(2), nil, []string{}},
+		{"hdr", hdr, nil, []string{"pixel format is yuv420p10le (needs yuv420p)", "is HDR (smpte2084, needs tonemapping to SDR)"}},
+		{"hdr without tonemapping", hdr, &domain.DownloadOptions{NoTonemap: true}, []string{}},
+		{"10-bit sdr", VideoInfo{Width: 1280, Height: 720, VideoCodec: "h264", PixelFormat: "yuv420p10le", ColorTransfer: "bt709"}, nil, []string{"pixel format is yuv420p10le (needs yuv420p)"}},
+		{"5.1 audio", surround(6), nil, []string{"audio has 6 channels (max 2)"}},
+		{"5.1 audio kept", surround(6), &domain.DownloadOptions{KeepChannels: true}, []string{}},
+		{"5.1 audio removed", surround(6), noAudio, []string{"has an audio track (removal requested)"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := targets[TargetWhatsApp].issues(&tt.info, tt.options); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("issues() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseVideoInfo(t *testing.T) {
+	output := []byte(`{
+		"streams": [
+			{"codec_type": "video", "codec_name": "h264", "pix_fmt": "yuv444p", "color_primaries": "bt709", "color_space": "bt709", "width": 1920, "height": 1080, "r_frame_rate": "30000/1001"},
+			{"codec_type": "audio", "codec_name": "aac", "channels": 6}
+		],
+		"format": {"duration": "12.5", "bit_rate": "4000000"}
+	}`)
+
+	info, err := parseVideoInfo(output)
+	if err != nil {
+		t.Fatalf("parseVideoInfo() error: %v", err)
+	}
+	if info.VideoCodec != "h264" || info.PixelFormat != "yuv444p" || info.AudioCodec != "aac" || info.AudioChannels != 6 || info.Width != 1920 || info.Height != 1080 {
+		t.Errorf("parseVideoInfo() = %+v", info)
+	}
+	if info.ColorPrimaries != "bt709" || info.ColorSpace != "bt709" {
+		t.Errorf("color primaries/space = %q/%q, want bt709/bt709", info.ColorPrimaries, info.ColorSpace)
+	}
+	if info.Duration != 12.5 || info.Bitrate != 4000000 || info.FrameRate < 29.9 || info.FrameRate > 30 {
+		t.Errorf("duration/bitrate/fps = %v/%v/%v, want 12.5/4000000/29.97", info.Duration, info.Bitrate, info.FrameRate)
+	}
+
+	compatible, reason := targets[TargetWhatsApp].Check(info, nil)
+	if want := "pixel format is yuv444p (needs yuv420p); audio has 6 channels (max 2)"; compatible || reason != want {
+		t.Errorf("Check() = %v, %q, want incompatible: %q", compatible, reason, want)
+	}
+}
+
+func TestVideoEncodeArgs(t *testing.T) {
+	whatsapp := targets[TargetWhatsApp]
+	sdr := &VideoInfo{VideoCodec: "vp9", PixelFormat: "yuv420p"}
+	hdr := &VideoInfo{VideoCodec: "vp9", PixelFormat: "yuv420p10le", ColorTransfer: "smpte2084", ColorPrimaries: "bt2020", ColorSpace: "bt2020nc"}
+	base := []string{"-c:v", "libx264", "-preset", whatsapp.Preset, "-crf", strconv.Itoa(whatsapp.CRF)}
+
+	tests := []struct {
+		name    string
+		info    *VideoInfo
+		options *domain.DownloadOptions
+		want    []string
+	}{
+		{"sdr", sdr, nil, append(slices.Clone(base), "-pix_fmt", "yuv420p")},
+		{"hdr tonemapped", hdr, nil, append(slices.Clone(base), "-pix_fmt", "yuv420p")},
+		{
+			"hdr without tonemapping keeps 10 bits and color metadata",
+			hdr,
+			&domain.DownloadOptions{NoTonemap: true},
+			append(slices.Clone(base), "-pix_fmt", "yuv420p10le", "-color_trc", "smpte2084", "-color_primaries", "bt2020", "-colorspace", "bt2020nc"),
+		},
+		{
+			"hlg without primaries",
+			&VideoInfo{VideoCodec: "hevc", ColorTransfer: "arib-std-b67"},
+			&domain.DownloadOptions{NoTonemap: true},
+			append(slices.Clone(base), "-pix_fmt", "yuv420p10le", "-color_trc", "arib-std-b67"),
+		},
+		{"sdr with no tonemap", sdr, &domain.DownloadOptions{NoTonemap: true}, append(slices.Clone(base), "-pix_fmt", "yuv420p")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := videoEncodeArgs(tt.info, whatsapp, tt.options); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("videoEncodeArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseProgress(t *testing.T) {
+	output := `frame=10
+out_time_ms=N/A
+total_size=48
+progress=continue
+out_time_ms=5000000
+total_size=1024
+progress=continue
+out_time_us=25000000
+progress=continue
+progress=end
+`
+	var got []Progress
+	parseProgress(strings.NewReader(output), 20, func(p Progress) { got = append(got, p) })
+
+	want := []Progress{
+		{Percent: 0, OutputSize: 48},
+		{Percent: 25, OutputSize: 1024},
+		{Percent: 100, OutputSize: 1024}, // Más allá de la duración esperada
+		{Percent: 100, OutputSize: 1024},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseProgress() = %v, want %v", got, want)
+	}
+}
+
+func TestCheckOutputSize(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int64
+		isMedia bool
+		wantErr bool
+	}{
+		{"empty video", 0, true, true},
+		{"truncated video", 2048, true, true},
+		{"video", 5 * 1024 * 1024, true, false},
+		{"empty image", 0, false, true},
+		{"small image", 300, false, false},
+	}
+
+	for _, tt := range tests {
+		err := checkOutputSize("file", tt.size, tt.isMedia)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: checkOutputSize() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if err != nil && !errors.Is(err, ErrCorruptOutput) {
+			t.Errorf("%s: error %v should wrap ErrCorruptOutput", tt.name, err)
+		}
+	}
+}
+
+func TestVerifyOutput_SkipsProbeForAudioOnly(t *testing.T) {
+	dir := t.TempDir()
+	f := NewFFmpegProcessor(dir)
+
+	// Un "mp3" sin contenido válido pero con tamaño suficiente: audio-only no lo pasa por ffprobe
+	path := filepath.Join(dir, "a.mp3")
+	os.WriteFile(path, make([]byte, minMediaSize), 0644)
+	if err := f.VerifyOutput(context.Background(), path, &domain.DownloadOptions{AudioOnly: true}); err != nil {
+		t.Errorf("VerifyOutput() error = %v, want nil", err)
+	}
+
+	if err := f.VerifyOutput(context.Background(), filepath.Join(dir, "missing.mp4"), nil); !errors.Is(err, ErrCorruptOutput) {
+		t.Errorf("VerifyOutput(missing) error = %v, want ErrCorruptOutput", err)
+	}
+}
+
+func TestIsAudioFormat(t *testing.T) {
+	tests := []struct {
+		path, format string
+		want         bool
+	}{
+		{"song.mp3", "mp3", true},
+		{"SONG.MP3", "mp3", true},
+		{"song.opus", "mp3", false},
+		{"clip.mp4", "mp3", false},
+		{"song.m4a", "m4a", true},
+		{"noext", "mp3", false},
+	}
+
+	for _, tt := range tests {
+		if got := isAudioFormat(tt.path, tt.format); got != tt.want {
+			t.Errorf("isAudioFormat(%q, %q) = %v, want %v", tt.path, tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestNeedsProcessing_AudioOnly(t *testing.T) {
+	f := NewFFmpegProcessor(t.TempDir())
+
+	// Audio-only decide por la extensión, sin ffprobe: los archivos no existen
+	tests := []struct {
+		path    string
+		options *domain.DownloadOptions
+		want    bool
+	}{
+		{"song.mp3", &domain.DownloadOptions{AudioOnly: true}, false},
+		{"post.mp4", &domain.DownloadOptions{AudioOnly: true}, true},
+		{"song.mp3", &domain.DownloadOptions{AudioOnly: true, AudioFormat: "opus"}, true},
+		{"song.opus", &domain.DownloadOptions{AudioOnly: true, AudioFormat: "opus"}, false},
+	}
+
+	for _, tt := range tests {
+		got, err := f.NeedsProcessing(tt.path, tt.options)
+		if err != nil {
+			t.Fatalf("NeedsProcessing(%q) error = %v", tt.path, err)
+		}
+		if got != tt.want {
+			t.Errorf("NeedsProcessing(%q, format %q) = %v, want %v", tt.path, tt.options.AudioFormat, got, tt.want)
+		}
+	}
+}
+
+func TestExtractAudioArgs(t *testing.T) {
+	got := extractAudioArgs("post.mp4", "post.mp3", "libmp3lame", "mp3", 192)
+	want := []string{"-i", "post.mp4", "-hide_banner", "-loglevel", "error", "-vn", "-c:a", "libmp3lame", "-b:a", "192k", "-y", "post.mp3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("extractAudioArgs(mp3) = %v, want %v", got, want)
+	}
+
+	// FLAC es sin pérdida: sin -b:a
+	got = extractAudioArgs("post.mp4", "post.flac", "flac", "flac", 192)
+	want = []string{"-i", "post.mp4", "-hide_banner", "-loglevel", "error", "-vn", "-c:a", "flac", "-y", "post.flac"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("extractAudioArgs(flac) = %v, want %v", got, want)
+	}
+}
+
+func TestGIFSegment(t *testing.T) {
+	tests := []struct {
+		start, duration string
+		wantStart       string
+		wantDuration    string
+		wantErr         bool
+	}{
+		{"", "", "", "", false},
+		{"00:10", "5", "10.000", "5", false},
+		{"1m30s", "00:05", "90.000", "5.000", false},
+		{"", "2.5", "", "2.5", false},
+		{"soon", "", "", "", true},
+		{"", "0", "", "", true},
+		{"", "-5s", "", "", true},
+	}
+
+	for _, tt := range tests {
+		start, duration, err := gifSegment(&domain.DownloadOptions{ConvertToGIF: true, GIFStart: tt.start, GIFDuration: tt.duration})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("gifSegment(%q, %q) error = %v, wantErr %v", tt.start, tt.duration, err, tt.wantErr)
+			continue
+		}
+		if start != tt.wantStart || duration != tt.wantDuration {
+			t.Errorf("gifSegment(%q, %q) = %q, %q, want %q, %q", tt.start, tt.duration, start, duration, tt.wantStart, tt.wantDuration)
+		}
+	}
+
+	if err := ValidateGIF(&domain.DownloadOptions{GIFStart: "00:10"}); err == nil {
+		t.Error("expected error for gif_start without convert_to_gif")
+	}
+}
+
+func TestGIFOptions_Filters(t *testing.T) {
+	tests := []struct {
+		gif     GIFOptions
+		palette string
+		filter  string
+	}{
+		{
+			// Defaults: los valores de siempre
+			gif:     GIFOptions{},
+			palette: "fps=15,scale=480:-1:flags=lanczos,palettegen=stats_mode=diff",
+			filter:  "fps=15,scale=480:-1:flags=lanczos[x];[x][1:v]paletteuse=dither=bayer:bayer_scale=5",
+		},
+		{
+			gif:     GIFOptions{Width: 320, FPS: 10, Dither: "floyd_steinberg"},
+			palette: "fps=10,scale=320:-1:flags=lanczos,palettegen=stats_mode=diff",
+			filter:  "fps=10,scale=320:-1:flags=lanczos[x];[x][1:v]paletteuse=dither=floyd_steinberg",
+		},
+		{
+			gif:     GIFOptions{FPS: 25, Dither: "none"},
+			palette: "fps=25,scale=480:-1:flags=lanczos,palettegen=stats_mode=diff",
+			filter:  "fps=25,scale=480:-1:flags=lanczos[x];[x][1:v]paletteuse=dither=none",
+		},
+	}
+
+	for _, tt := range tests {
+		gif := tt.gif.withDefaults()
+		if got := gif.paletteFilter(); got != tt.palette {
+			t.Errorf("paletteFilter(%+v) = %q, want %q", tt.gif, got, tt.palette)
+		}
+		if got := gif.gifFilter(); got != tt.filter {
+			t.Errorf("gifFilter(%+v) = %q, want %q", tt.gif, got, tt.filter)
+		}
+	}
+}
+
+func TestValidateGIF(t *testing.T) {
+	tests := []struct {
+		options domain.DownloadOptions
+		wantErr bool
+	}{
+		{domain.DownloadOptions{}, false},
+		{domain.DownloadOptions{ConvertToGIF: true, GIFFPS: 10, GIFDither: "sierra2_4a"}, false},
+		{domain.DownloadOptions{ConvertToGIF: true, GIFFPS: 51}, true},
+		{domain.DownloadOptions{ConvertToGIF: true, GIFFPS: -1}, true},
+		{domain.DownloadOptions{ConvertToGIF: true, GIFDither: "ordered"}, true},
+		{domain.DownloadOptions{GIFFPS: 10}, true},
+		{domain.DownloadOptions{GIFDither: "bayer"}, true},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateGIF(&tt.options); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateGIF(%+v) error = %v, wantErr %v", tt.options, err, tt.wantErr)
+		}
+	}
+}
+
+func TestWorkDir(t *testing.T) {
+	tempDir := filepath.Join(t.TempDir(), "temp")
+	f := NewFFmpegProcessor(tempDir)
+
+	first, cleanupFirst, err := f.workDir("gif")
+	if err != nil {
+		t.Fatalf("workDir() error: %v", err)
+	}
+	second, cleanupSecond, err := f.workDir("gif")
+	if err != nil {
+		t.Fatalf("workDir() error: %v", err)
+	}
+	defer cleanupSecond()
+
+	// Cada operación tiene su directorio dentro de tempDir
+	if first == second {
+		t.Errorf("workDir() returned %s twice", first)
+	}
+	if filepath.Dir(first) != tempDir {
+		t.Errorf("workDir() = %s, want a directory inside %s", first, tempDir)
+	}
+
+	os.WriteFile(filepath.Join(first, "palette.png"), []byte("x"), 0644)
+	cleanupFirst()
+	if _, err := os.Stat(first); !os.IsNotExist(err) {
+		t.Errorf("cleanup didn't remove %s", first)
+	}
+	if _, err := os.Stat(second); err != nil {
+		t.Errorf("cleanup removed another operation's directory: %v", err)
+	}
+}
+
+func TestConvert_EnsureAudioArgs(t *testing.T) {
+	dir := t.TempDir()
+
+	// ffprobe falso (video H.264 sin audio) y ffmpeg falso que guarda sus argumentos
+	binDir := filepath.Join(dir, "bin")
+	if err := os.MkdirAll(binDir, 0755); err != nil {
+		t.Fatalf("failed to create bin dir: %v", err)
+	}
+	probe := `{"streams": [{"codec_type": "video", "codec_name": "h264", "pix_fmt": "yuv420p", "width": 1280, "height": 720}], "format": {"duration": "5"}}`
+	argsFile := filepath.Join(dir, "args")
+	scripts := map[string]string{
+		"ffprobe": "#!/bin/sh\necho '" + probe + "'\n",
+		"ffmpeg":  "#!/bin/sh\nfor arg in \"$@\"; do echo \"$arg\"; done > " + argsFile + "\n",
+	}
+	for name, script := range scripts {
+		if err := os.WriteFile(filepath.Join(binDir, name), []byte(script), 0755); err != nil {
+			t.Fatalf("failed to write fake %s: %v", name, err)
+		}
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	f := NewFFmpegProcessor(dir)
+	input := filepath.Join(dir, "silent.mp4")
+	if _, err := f.Convert(context.Background(), input, targets[TargetWhatsApp], &domain.DownloadOptions{EnsureAudio: true}); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	data, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatalf("ffmpeg was not run: %v", err)
+	}
+	joined := strings.Join(strings.Fields(string(data)), " ")
+
+	// La pista muda es la segunda entrada; el video se copia y el silencio va a AAC
+	want := "-i " + input + " -hide_banner -loglevel error -f lavfi -i " + silentAudioSource +
+		" -map 0:v:0 -map 1:a:0 -shortest -c:v copy -c:a aac -b:a " + targets[TargetWhatsApp].AudioBitrate
+	if !strings.HasPrefix(joined, want) {
+		t.Errorf("ffmpeg args = %q, want prefix %q", joined, want)
+	}
+
+	// Si el video ya tiene audio no se agrega nada
+	if needsSilentTrack(&VideoInfo{HasVideo: true, HasAudio: true}, &domain.DownloadOptions{EnsureAudio: true}) {
+		t.Error("needsSilentTrack() = true for a video with audio")
+	}
+	if needsSilentTrack(&VideoInfo{HasVideo: true}, &domain.DownloadOptions{EnsureAudio: true, RemoveAudio: true}) {
+		t.Error("needsSilentTrack() = true with RemoveAudio")
+	}
+}
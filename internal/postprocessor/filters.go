@@ -0,0 +1,377 @@
+package postprocessor
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/elsanchez/smart-download/internal/domain"
+)
+
+// maxShortSide es el lado corto máximo de la conversión a WhatsApp MP4 y de Concat (1920x1080
+// apaisado o 1080x1920 vertical)
+const maxShortSide = 1080
+
+// maxBounds retorna la resolución máxima con la orientación del video: el lado corto hasta
+// shortSide y el largo hasta shortSide*16/9. Un video cuadrado cuenta como apaisado
+func maxBounds(width, height, shortSide int) (maxWidth, maxHeight int) {
+	longSide := shortSide * 16 / 9
+	if height > width {
+		return shortSide, longSide
+	}
+	return longSide, shortSide
+}
+
+// exceedsBounds indica si alguna dimensión supera la resolución máxima de su orientación
+func exceedsBounds(width, height, shortSide int) bool {
+	maxWidth, maxHeight := maxBounds(width, height, shortSide)
+	return width > maxWidth || height > maxHeight
+}
+
+// scaleFilter retorna el filtro que ajusta el video a la resolución máxima manteniendo el
+// aspect ratio ("" si ya entra). Escala por el lado que más se pasa; -2 deja el otro par
+func scaleFilter(width, height, shortSide int) string {
+	if !exceedsBounds(width, height, shortSide) {
+		return ""
+	}
+	maxWidth, maxHeight := maxBounds(width, height, shortSide)
+	if width*maxHeight > height*maxWidth {
+		return fmt.Sprintf("scale=%d:-2", maxWidth)
+	}
+	return fmt.Sprintf("scale=-2:%d", maxHeight)
+}
+
+// cropRect es un recorte w:h:x:y en píxeles del video original
+type cropRect struct {
+	W, H, X, Y int
+}
+
+// filter retorna el filtro crop de ffmpeg
+func (c cropRect) filter() string {
+	return fmt.Sprintf("crop=%d:%d:%d:%d", c.W, c.H, c.X, c.Y)
+}
+
+// parseCrop parsea un recorte "w:h:x:y". Ancho y alto se redondean a par (x264 no acepta impares)
+func parseCrop(s string) (cropRect, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 4 {
+		return cropRect{}, fmt.Errorf("invalid crop %q (use w:h:x:y or auto)", s)
+	}
+
+	var values [4]int
+	for i, part := range parts {
+		v, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || v < 0 {
+			return cropRect{}, fmt.Errorf("invalid crop %q (use w:h:x:y or auto)", s)
+		}
+		values[i] = v
+	}
+
+	rect := cropRect{W: values[0] &^ 1, H: values[1] &^ 1, X: values[2], Y: values[3]}
+	if rect.W == 0 || rect.H == 0 {
+		return cropRect{}, fmt.Errorf("invalid crop %q: width and height must be at least 2", s)
+	}
+	return rect, nil
+}
+
+// ValidateCrop verifica la sintaxis de la opción crop ("w:h:x:y" o "auto")
+func ValidateCrop(s string) error {
+	if s == "auto" {
+		return nil
+	}
+	_, err := parseCrop(s)
+	return err
+}
+
+// ValidateRotate verifica que la rotación sea 90, 180 o 270 grados (0 = sin rotar)
+func ValidateRotate(degrees int) error {
+	_, err := rotateFilter(degrees)
+	return err
+}
+
+// rotateFilter retorna el filtro de ffmpeg para rotar en sentido horario
+func rotateFilter(degrees int) (string, error) {
+	switch degrees {
+	case 0:
+		return "", nil
+	case 90:
+		return "transpose=clock", nil
+	case 180:
+		return "hflip,vflip", nil
+	case 270:
+		return "transpose=cclock", nil
+	default:
+		return "", fmt.Errorf("invalid rotation %d (use 90, 180 or 270)", degrees)
+	}
+}
+
+// videoFilters arma la cadena de filtros de video de la conversión: recorte, rotación,
+// escala a la resolución máxima (shortSide en el lado corto) y velocidad. El recorte usa las
+// coordenadas del original y la escala las ya rotadas
+func (f *FFmpegProcessor) videoFilters(ctx context.Context, inputPath string, info *VideoInfo, shortSide int, options *domain.DownloadOptions) ([]string, error) {
+	var filters []string
+	width, height := info.Width, info.Height
+
+	if options != nil && options.Crop != "" {
+		crop := options.Crop
+		if crop == "auto" {
+			detected, err := f.detectCrop(ctx, inputPath)
+			if err != nil {
+				return nil, err
+			}
+			crop = detected
+		}
+
+		// "auto" sin bordes detectados: no hay nada que recortar
+		if crop != "" {
+			rect, err := parseCrop(crop)
+			if err != nil {
+				return nil, err
+			}
+			if rect.X+rect.W > width || rect.Y+rect.H > height {
+				return nil, fmt.Errorf("crop %s exceeds the video size %dx%d", crop, width, height)
+			}
+			filters = append(filters, rect.filter())
+			width, height = rect.W, rect.H
+		}
+	}
+
+	if options != nil && options.Rotate != 0 {
+		rotate, err := rotateFilter(options.Rotate)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, rotate)
+		if options.Rotate != 180 {
+			width, height = height, width
+		}
+	}
+
+	if scale := scaleFilter(width, height, shortSide); scale != "" {
+		filters = append(filters, scale)
+	}
+
+	// Después de escalar: el tonemapping en punto flotante es lo más costoso de la cadena
+	if needsTonemap(info, options) {
+		filters = append(filters, tonemapFilter)
+	}
+
+	if hasSpeedChange(options) {
+		filters = append(filters, fmt.Sprintf("setpts=PTS/%s", formatFactor(options.Speed)))
+	}
+
+	return filters, nil
+}
+
+// tonemapFilter convierte HDR (PQ/HLG, BT.2020) a SDR BT.709: pasa a luz lineal, cambia
+// los primarios, comprime el rango con hable y vuelve a yuv420p. Requiere ffmpeg con zimg
+const tonemapFilter = "zscale=t=linear:npl=100,format=gbrpf32le,zscale=p=bt709," +
+	"tonemap=tonemap=hable:desat=0,zscale=t=bt709:m=bt709:r=tv,format=yuv420p"
+
+// needsTonemap indica si el video es HDR y hay que convertirlo a SDR (salvo NoTonemap)
+func needsTonemap(info *VideoInfo, options *domain.DownloadOptions) bool {
+	return info.IsHDR() && (options == nil || !options.NoTonemap)
+}
+
+// audioFilters arma la cadena de filtros de audio de la conversión (velocidad)
+func audioFilters(options *domain.DownloadOptions) []string {
+	if !hasSpeedChange(options) {
+		return nil
+	}
+	return atempoChain(options.Speed)
+}
+
+// atempoChain encadena filtros atempo para factores fuera de su rango de 0.5 a 2.0
+// por filtro, p.ej. 8x = atempo=2,atempo=2,atempo=2
+func atempoChain(speed float64) []string {
+	var filters []string
+	for speed > 2 {
+		filters = append(filters, "atempo=2")
+		speed /= 2
+	}
+	for speed < 0.5 {
+		filters = append(filters, "atempo=0.5")
+		speed /= 0.5
+	}
+	if speed != 1 {
+		filters = append(filters, "atempo="+formatFactor(speed))
+	}
+	return filters
+}
+
+// formatFactor formatea un factor de velocidad sin ceros de sobra (2, 0.5, 1.25)
+func formatFactor(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// Límites de la opción speed: más allá el resultado no tiene sentido (o es un typo)
+const (
+	minSpeed = 0.1
+	maxSpeed = 100
+)
+
+// ValidateSpeed verifica el factor de velocidad (0 = sin cambio)
+func ValidateSpeed(speed float64) error {
+	if speed != 0 && (speed < minSpeed || speed > maxSpeed) {
+		return fmt.Errorf("invalid speed %g (use %g to %g, e.g. 2 for double speed or 0.5 for half)", speed, float64(minSpeed), float64(maxSpeed))
+	}
+	return nil
+}
+
+// hasSpeedChange indica si las opciones piden cambiar la velocidad
+func hasSpeedChange(options *domain.DownloadOptions) bool {
+	return options != nil && options.Speed != 0 && options.Speed != 1
+}
+
+// cropdetectPattern captura los recortes que sugiere cropdetect en su salida
+var cropdetectPattern = regexp.MustCompile(`crop=(\d+:\d+:\d+:\d+)`)
+
+// detectCrop busca bordes negros (letterboxing) con cropdetect en el primer minuto del video.
+// Retorna "" si no encontró ninguno
+func (f *FFmpegProcessor) detectCrop(ctx context.Context, inputPath string) (string, error) {
+	args := []string{
+		"-hide_banner",
+		"-i", inputPath,
+		"-t", "60",
+		"-vf", "cropdetect=round=2",
+		"-f", "null",
+		"-",
+	}
+
+	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("ffmpeg cropdetect: %w\nOutput: %s", err, output)
+	}
+
+	return mostFrequentCrop(string(output)), nil
+}
+
+// mostFrequentCrop retorna el recorte más sugerido por cropdetect: los fotogramas oscuros
+// (fundidos, intros) dan recortes sueltos que no representan el video
+func mostFrequentCrop(output string) string {
+	counts := make(map[string]int)
+	best := ""
+	for _, match := range cropdetectPattern.FindAllStringSubmatch(output, -1) {
+		crop := match[1]
+		counts[crop]++
+		if counts[crop] > counts[best] {
+			best = crop
+		}
+	}
+	return best
+}
+
+// forcesConversion indica si las opciones piden cambios (imagen, velocidad, metadata) que
+// obligan a pasar por la conversión aunque el video ya sea compatible
+func forcesConversion(options *domain.DownloadOptions) bool {
+	if options == nil {
+		return false
+	}
+	return options.Crop != "" || options.Rotate != 0 || hasSpeedChange(options) ||
+		options.StripMetadata || options.MetadataTitle != "" || options.MetadataComment != ""
+}
+
+// metadataArgs retorna los argumentos de metadata de la conversión: copiar los tags del
+// original (o quitarlos todos) y fijar título/comentario si se indicaron
+func metadataArgs(options *domain.DownloadOptions) []string {
+	args := []string{"-map_metadata", "0"}
+	if options == nil {
+		return args
+	}
+
+	if !options.PreserveMetadata() {
+		args = []string{"-map_metadata", "-1", "-map_chapters", "-1"}
+	}
+	if options.MetadataTitle != "" {
+		args = append(args, "-metadata", "title="+options.MetadataTitle)
+	}
+	if options.MetadataComment != "" {
+		args = append(args, "-metadata", "comment="+options.MetadataComment)
+	}
+	return args
+}
+
+// concatSize calcula la resolución común de una concatenación: la del primer video, limitada
+// a 1920x1080 (o 1080x1920 si es vertical) y con dimensiones pares
+func concatSize(first *VideoInfo) (width, height int) {
+	width, height = first.Width, first.Height
+	if exceedsBounds(width, height, maxShortSide) {
+		maxWidth, maxHeight := maxBounds(width, height, maxShortSide)
+		if width*maxHeight > height*maxWidth {
+			width, height = maxWidth, height*maxWidth/width
+		} else {
+			width, height = width*maxHeight/height, maxHeight
+		}
+	}
+	return width &^ 1, height &^ 1
+}
+
+// concatArgs arma los argumentos de ffmpeg para unir inputs con el filtro concat. Cada video
+// se escala (con bandas si cambia el aspect ratio) a la resolución y fps del primero; a los
+// que no tienen audio se les pone silencio para que las pistas sigan alineadas
+func concatArgs(inputs []string, infos []*VideoInfo, outputPath string) []string {
+	width, height := concatSize(infos[0])
+	fps := infos[0].FrameRate
+	if fps <= 0 {
+		fps = 30
+	}
+
+	anyAudio := false
+	for _, info := range infos {
+		anyAudio = anyAudio || info.HasAudio
+	}
+
+	args := []string{"-hide_banner", "-loglevel", "error"}
+	for _, input := range inputs {
+		args = append(args, "-i", input)
+	}
+
+	var graph, streams strings.Builder
+	silent := len(inputs) // Los silencios van después de los videos
+	for i, info := range infos {
+		fmt.Fprintf(&graph, "[%d:v]scale=%d:%d:force_original_aspect_ratio=decrease,pad=%d:%d:(ow-iw)/2:(oh-ih)/2,setsar=1,fps=%s,format=yuv420p[v%d];",
+			i, width, height, width, height, formatFactor(fps), i)
+		fmt.Fprintf(&streams, "[v%d]", i)
+
+		if !anyAudio {
+			continue
+		}
+		audioInput := i
+		if !info.HasAudio {
+			args = append(args, "-f", "lavfi", "-t", fmt.Sprintf("%.3f", info.Duration), "-i", "anullsrc=r=48000:cl=stereo")
+			audioInput = silent
+			silent++
+		}
+		fmt.Fprintf(&graph, "[%d:a]aresample=48000,aformat=channel_layouts=stereo[a%d];", audioInput, i)
+		fmt.Fprintf(&streams, "[a%d]", i)
+	}
+
+	audio := 0
+	if anyAudio {
+		audio = 1
+	}
+	fmt.Fprintf(&graph, "%sconcat=n=%d:v=1:a=%d[v]", streams.String(), len(inputs), audio)
+	if anyAudio {
+		graph.WriteString("[a]")
+	}
+
+	args = append(args, "-filter_complex", graph.String(), "-map", "[v]")
+	if anyAudio {
+		args = append(args, "-map", "[a]", "-c:a", "aac", "-b:a", "128k")
+	}
+	args = append(args,
+		"-c:v", "libx264",
+		"-preset", "medium",
+		"-crf", "23",
+		"-f", "mp4",
+		"-movflags", "+faststart",
+		"-y",
+		outputPath,
+	)
+
+	return args
+}
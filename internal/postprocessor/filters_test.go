@@ -0,0 +1,301 @@
+package postprocessor
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/elsanchez/smart-download/internal/domain"
+)
+
+func TestParseCrop(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    cropRect
+		wantErr bool
+	}{
+		{"1280:720:0:180", cropRect{1280, 720, 0, 180}, false},
+		{"1279:719:1:1", cropRect{1278, 718, 1, 1}, false}, // redondeado a par
+		{"1280:720", cropRect{}, true},
+		{"1280:720:-1:0", cropRect{}, true},
+		{"1:720:0:0", cropRect{}, true},
+		{"auto", cropRect{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseCrop(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseCrop(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseCrop(%q) = %+v, want %+v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestValidateRotate(t *testing.T) {
+	for _, degrees := range []int{0, 90, 180, 270} {
+		if err := ValidateRotate(degrees); err != nil {
+			t.Errorf("ValidateRotate(%d) error: %v", degrees, err)
+		}
+	}
+	for _, degrees := range []int{45, -90, 360} {
+		if err := ValidateRotate(degrees); err == nil {
+			t.Errorf("ValidateRotate(%d) should fail", degrees)
+		}
+	}
+}
+
+func TestVideoFilters(t *testing.T) {
+	f := NewFFmpegProcessor(t.TempDir())
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		info    VideoInfo
+		options *domain.DownloadOptions
+		want    []string
+	}{
+		{
+			name: "compatible size, no edits",
+			info: VideoInfo{Width: 1920, Height: 1080},
+			want: nil,
+		},
+		{
+			name: "4k is scaled",
+			info: VideoInfo{Width: 3840, Height: 2160},
+			want: []string{"scale=-2:1080"},
+		},
+		{
+			name:    "rotated portrait phone video is scaled after rotating",
+			info:    VideoInfo{Width: 2160, Height: 1080},
+			options: &domain.DownloadOptions{Rotate: 90},
+			want:    []string{"transpose=clock", "scale=-2:1920"},
+		},
+		{
+			name:    "crop removes letterboxing before scaling",
+			info:    VideoInfo{Width: 3840, Height: 2160},
+			options: &domain.DownloadOptions{Crop: "3840:1600:0:280"},
+			want:    []string{"crop=3840:1600:0:280", "scale=1920:-2"},
+		},
+		{
+			name:    "crop and 180 rotation",
+			info:    VideoInfo{Width: 1280, Height: 720},
+			options: &domain.DownloadOptions{Crop: "1280:536:0:92", Rotate: 180},
+			want:    []string{"crop=1280:536:0:92", "hflip,vflip"},
+		},
+		{
+			name:    "timelapse",
+			info:    VideoInfo{Width: 1280, Height: 720},
+			options: &domain.DownloadOptions{Speed: 4},
+			want:    []string{"setpts=PTS/4"},
+		},
+		{
+			name:    "speed 1 is no change",
+			info:    VideoInfo{Width: 1280, Height: 720},
+			options: &domain.DownloadOptions{Speed: 1},
+			want:    nil,
+		},
+		{
+			name: "hdr is tonemapped after scaling",
+			info: VideoInfo{Width: 3840, Height: 2160, ColorTransfer: "smpte2084"},
+			want: []string{"scale=-2:1080", tonemapFilter},
+		},
+		{
+			name: "hlg is tonemapped",
+			info: VideoInfo{Width: 1920, Height: 1080, ColorTransfer: "arib-std-b67"},
+			want: []string{tonemapFilter},
+		},
+		{
+			name: "bt709 is not hdr",
+			info: VideoInfo{Width: 1920, Height: 1080, ColorTransfer: "bt709"},
+			want: nil,
+		},
+		{
+			name:    "no tonemap",
+			info:    VideoInfo{Width: 1920, Height: 1080, ColorTransfer: "smpte2084"},
+			options: &domain.DownloadOptions{NoTonemap: true},
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := f.videoFilters(ctx, "video.mp4", &tt.info, maxShortSide, tt.options)
+			if err != nil {
+				t.Fatalf("videoFilters() error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("videoFilters() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Un recorte fuera del video es un error
+	info := VideoInfo{Width: 1280, Height: 720}
+	if _, err := f.videoFilters(ctx, "video.mp4", &info, maxShortSide, &domain.DownloadOptions{Crop: "1280:720:10:0"}); err == nil {
+		t.Error("videoFilters() should reject a crop outside the video")
+	}
+}
+
+func TestScaleFilter(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		want          string
+	}{
+		{"landscape 1080p fits", 1920, 1080, ""},
+		{"landscape 4k", 3840, 2160, "scale=-2:1080"},
+		{"landscape ultrawide is capped by width", 2560, 1080, "scale=1920:-2"},
+		{"portrait reel fits", 1080, 1920, ""},
+		{"portrait 4k", 2160, 3840, "scale=-2:1920"},
+		{"portrait taller than 9:16 is capped by height", 1080, 2400, "scale=-2:1920"},
+		{"square fits", 1080, 1080, ""},
+		{"square too big", 2000, 2000, "scale=-2:1080"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scaleFilter(tt.width, tt.height, maxShortSide); got != tt.want {
+				t.Errorf("scaleFilter(%d, %d) = %q, want %q", tt.width, tt.height, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConcatSize(t *testing.T) {
+	tests := []struct {
+		info                  VideoInfo
+		wantWidth, wantHeight int
+	}{
+		{VideoInfo{Width: 1280, Height: 720}, 1280, 720},
+		{VideoInfo{Width: 3840, Height: 2160}, 1920, 1080},
+		{VideoInfo{Width: 1080, Height: 1920}, 1080, 1920},
+		{VideoInfo{Width: 2160, Height: 3840}, 1080, 1920},
+		{VideoInfo{Width: 2000, Height: 2000}, 1080, 1080},
+	}
+
+	for _, tt := range tests {
+		width, height := concatSize(&tt.info)
+		if width != tt.wantWidth || height != tt.wantHeight {
+			t.Errorf("concatSize(%dx%d) = %dx%d, want %dx%d", tt.info.Width, tt.info.Height, width, height, tt.wantWidth, tt.wantHeight)
+		}
+	}
+}
+
+func TestAtempoChain(t *testing.T) {
+	tests := []struct {
+		speed float64
+		want  []string
+	}{
+		{1.5, []string{"atempo=1.5"}},
+		{2, []string{"atempo=2"}},
+		{0.5, []string{"atempo=0.5"}},
+		{8, []string{"atempo=2", "atempo=2", "atempo=2"}},
+		{10, []string{"atempo=2", "atempo=2", "atempo=2", "atempo=1.25"}},
+		{0.25, []string{"atempo=0.5", "atempo=0.5"}},
+		{0.3, []string{"atempo=0.5", "atempo=0.6"}},
+	}
+
+	for _, tt := range tests {
+		if got := atempoChain(tt.speed); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("atempoChain(%v) = %v, want %v", tt.speed, got, tt.want)
+		}
+	}
+}
+
+func TestValidateSpeed(t *testing.T) {
+	for _, speed := range []float64{0, 0.5, 2, 16} {
+		if err := ValidateSpeed(speed); err != nil {
+			t.Errorf("ValidateSpeed(%v) error: %v", speed, err)
+		}
+	}
+	for _, speed := range []float64{-1, 0.01, 1000} {
+		if err := ValidateSpeed(speed); err == nil {
+			t.Errorf("ValidateSpeed(%v) should fail", speed)
+		}
+	}
+}
+
+func TestMostFrequentCrop(t *testing.T) {
+	output := `[Parsed_cropdetect_0 @ 0x1] x1:0 x2:1919 y1:0 y2:1079 w:1920 h:1072 x:0 y:4 pts:1 t:0.04 crop=1920:1072:0:4
+[Parsed_cropdetect_0 @ 0x1] x1:0 x2:1919 y1:140 y2:939 w:1920 h:800 x:0 y:140 pts:2 t:0.08 crop=1920:800:0:140
+[Parsed_cropdetect_0 @ 0x1] x1:0 x2:1919 y1:140 y2:939 w:1920 h:800 x:0 y:140 pts:3 t:0.12 crop=1920:800:0:140`
+
+	if got := mostFrequentCrop(output); got != "1920:800:0:140" {
+		t.Errorf("mostFrequentCrop() = %q, want 1920:800:0:140", got)
+	}
+	if got := mostFrequentCrop("no crop lines"); got != "" {
+		t.Errorf("mostFrequentCrop() without matches = %q, want empty", got)
+	}
+}
+
+func TestConcatArgs(t *testing.T) {
+	infos := []*VideoInfo{
+		{Width: 3840, Height: 2160, FrameRate: 30, Duration: 10, HasVideo: true, HasAudio: true},
+		{Width: 720, Height: 1280, FrameRate: 60, Duration: 5.5, HasVideo: true}, // vertical y sin audio
+	}
+
+	args := concatArgs([]string{"a.mkv", "b.mp4"}, infos, "joined.mp4")
+	joined := strings.Join(args, " ")
+
+	// El video sin audio recibe un silencio de su duración como input extra (índice 2)
+	if !strings.Contains(joined, "-i a.mkv -i b.mp4 -f lavfi -t 5.500 -i anullsrc=r=48000:cl=stereo") {
+		t.Errorf("missing inputs or silence in %q", joined)
+	}
+
+	// Todo a la resolución del primero limitada a 1080p, y a sus fps
+	for _, want := range []string{
+		"[0:v]scale=1920:1080:force_original_aspect_ratio=decrease,pad=1920:1080:(ow-iw)/2:(oh-ih)/2,setsar=1,fps=30,format=yuv420p[v0];",
+		"[1:v]scale=1920:1080:",
+		"[0:a]aresample=48000,aformat=channel_layouts=stereo[a0];",
+		"[2:a]aresample=48000,aformat=channel_layouts=stereo[a1];",
+		"[v0][a0][v1][a1]concat=n=2:v=1:a=1[v][a]",
+		"-map [v] -map [a]",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("concat args missing %q:\n%s", want, joined)
+		}
+	}
+	if args[len(args)-1] != "joined.mp4" {
+		t.Errorf("output = %q, want joined.mp4", args[len(args)-1])
+	}
+
+	// Sin audio en ninguno: solo video
+	silent := []*VideoInfo{{Width: 1280, Height: 720, HasVideo: true}, {Width: 1280, Height: 720, HasVideo: true}}
+	joined = strings.Join(concatArgs([]string{"a.mp4", "b.mp4"}, silent, "out.mp4"), " ")
+	if !strings.Contains(joined, "[v0][v1]concat=n=2:v=1:a=0[v]") || strings.Contains(joined, "anullsrc") || strings.Contains(joined, "[a]") {
+		t.Errorf("unexpected args for silent inputs: %s", joined)
+	}
+}
+
+func TestMetadataArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		options *domain.DownloadOptions
+		want    []string
+	}{
+		{"default keeps tags", nil, []string{"-map_metadata", "0"}},
+		{"strip", &domain.DownloadOptions{StripMetadata: true}, []string{"-map_metadata", "-1", "-map_chapters", "-1"}},
+		{
+			"title and comment",
+			&domain.DownloadOptions{MetadataTitle: "Holidays", MetadataComment: "from grandma"},
+			[]string{"-map_metadata", "0", "-metadata", "title=Holidays", "-metadata", "comment=from grandma"},
+		},
+		{
+			"strip but set title",
+			&domain.DownloadOptions{StripMetadata: true, MetadataTitle: "Clean"},
+			[]string{"-map_metadata", "-1", "-map_chapters", "-1", "-metadata", "title=Clean"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := metadataArgs(tt.options); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("metadataArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
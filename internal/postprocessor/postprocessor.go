@@ -13,6 +13,9 @@ type PostProcessor interface {
 
 	// NeedsProcessing verifica si el archivo necesita procesamiento
 	NeedsProcessing(inputPath string, options *domain.DownloadOptions) (bool, error)
+
+	// VerifyOutput verifica que un archivo descargado no esté vacío ni truncado
+	VerifyOutput(ctx context.Context, path string, options *domain.DownloadOptions) error
 }
 
 // ProcessingResult contiene el resultado del procesamiento
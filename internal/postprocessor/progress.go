@@ -0,0 +1,91 @@
+package postprocessor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Progress es el avance de una operación de ffmpeg
+type Progress struct {
+	Percent    float64 // 0-100, sobre la duración esperada de la salida
+	OutputSize int64   // Bytes escritos hasta ahora
+}
+
+// ProgressFunc recibe el avance de las operaciones largas (conversión, concat)
+type ProgressFunc func(Progress)
+
+type progressKey struct{}
+
+// WithProgress retorna un contexto con el que las operaciones de ffmpeg informan su avance a fn.
+// Va por el contexto para no cambiar la interfaz PostProcessor ni cada wrapper
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// progressFrom retorna el ProgressFunc del contexto (nil si no hay)
+func progressFrom(ctx context.Context) ProgressFunc {
+	fn, _ := ctx.Value(progressKey{}).(ProgressFunc)
+	return fn
+}
+
+// runFFmpeg ejecuta ffmpeg y retorna su salida de errores. Si el contexto tiene un ProgressFunc
+// y se conoce la duración de la salida (segundos), añade -progress pipe:1 e informa el avance
+func runFFmpeg(ctx context.Context, args []string, duration float64) ([]byte, error) {
+	fn := progressFrom(ctx)
+	if fn == nil || duration <= 0 {
+		return exec.CommandContext(ctx, "ffmpeg", args...).CombinedOutput()
+	}
+
+	// -progress es una opción global: va antes de los inputs
+	args = append([]string{"-progress", "pipe:1", "-nostats"}, args...)
+	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	parseProgress(stdout, duration, fn)
+	err = cmd.Wait()
+	return stderr.Bytes(), err
+}
+
+// parseProgress lee la salida de -progress (bloques clave=valor terminados en "progress=...")
+// y llama a fn al final de cada bloque
+func parseProgress(r io.Reader, duration float64, fn ProgressFunc) {
+	var current Progress
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !ok {
+			continue
+		}
+
+		switch key {
+		case "out_time_ms", "out_time_us":
+			// out_time_ms está en microsegundos pese al nombre; "N/A" al principio
+			if us, err := strconv.ParseInt(value, 10, 64); err == nil && us >= 0 {
+				current.Percent = min(float64(us)/1e6/duration*100, 100)
+			}
+		case "total_size":
+			if size, err := strconv.ParseInt(value, 10, 64); err == nil {
+				current.OutputSize = size
+			}
+		case "progress":
+			if value == "end" {
+				current.Percent = 100
+			}
+			fn(current)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package postprocessor
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/elsanchez/smart-download/internal/domain"
+)
+
+// Target es un perfil de salida: qué acepta la plataforma tal cual (codecs y resolución) y
+// con qué parámetros se re-encodea lo que no cumple
+type Target struct {
+	Name             string
+	Label            string   // Nombre para mostrar (p.ej. "Telegram")
+	VideoCodecs      []string // Codecs de video que se copian sin re-encodear
+	PixelFormat      string   // Formato de pixel que se copia sin re-encodear (y el de la salida al re-encodear)
+	AudioCodecs      []string // Codecs de audio que se copian sin re-encodear
+	MaxAudioChannels int      // Más canales (5.1) se mezclan a estéreo salvo KeepChannels
+	MaxShortSide     int      // Lado corto máximo (1080 = 1920x1080 o 1080x1920); lo que lo supere se escala
+	CRF              int      // Calidad de libx264 al re-encodear (menor = mejor y más pesado)
+	Preset           string   // Preset de libx264
+	AudioBitrate     string   // Bitrate de AAC al re-encodear el audio
+}
+
+// Perfiles soportados
+const (
+	TargetWhatsApp  = "whatsapp"
+	TargetTelegram  = "telegram"
+	TargetInstagram = "instagram"
+
+	DefaultTarget = TargetWhatsApp
+)
+
+// TargetNames son los perfiles válidos para --target, en orden de ayuda
+var TargetNames = []string{TargetWhatsApp, TargetTelegram, TargetInstagram}
+
+var targets = map[string]Target{
+	TargetWhatsApp: {
+		Name:             TargetWhatsApp,
+		Label:            "WhatsApp",
+		VideoCodecs:      []string{"h264"},
+		PixelFormat:      "yuv420p",
+		AudioCodecs:      []string{"aac"},
+		MaxAudioChannels: 2,
+		MaxShortSide:     maxShortSide,
+		CRF:              23,
+		Preset:           "medium",
+		AudioBitrate:     "128k",
+	},
+	// Telegram admite archivos de hasta 2GB y reproduce HEVC y MP3/Opus en streaming,
+	// así que se re-encodea menos y con más calidad
+	TargetTelegram: {
+		Name:             TargetTelegram,
+		Label:            "Telegram",
+		VideoCodecs:      []string{"h264", "hevc"},
+		PixelFormat:      "yuv420p",
+		AudioCodecs:      []string{"aac", "mp3", "opus"},
+		MaxAudioChannels: 2,
+		MaxShortSide:     2160,
+		CRF:              20,
+		Preset:           "medium",
+		AudioBitrate:     "160k",
+	},
+	// Instagram re-comprime todo lo que recibe: conviene subir H.264 de buena calidad
+	TargetInstagram: {
+		Name:             TargetInstagram,
+		Label:            "Instagram",
+		VideoCodecs:      []string{"h264"},
+		PixelFormat:      "yuv420p",
+		AudioCodecs:      []string{"aac"},
+		MaxAudioChannels: 2,
+		MaxShortSide:     maxShortSide,
+		CRF:              21,
+		Preset:           "slow",
+		AudioBitrate:     "128k",
+	},
+}
+
+// ValidateTarget verifica que el perfil exista ("" = el default)
+func ValidateTarget(name string) error {
+	if name == "" {
+		return nil
+	}
+	if _, ok := targets[strings.ToLower(name)]; !ok {
+		return fmt.Errorf("invalid target %q (use %s)", name, strings.Join(TargetNames, ", "))
+	}
+	return nil
+}
+
+// TargetFor retorna el perfil pedido en options (WhatsApp si no se indicó). options puede ser nil
+func TargetFor(options *domain.DownloadOptions) (Target, error) {
+	name := DefaultTarget
+	if options != nil && options.Target != "" {
+		name = strings.ToLower(options.Target)
+	}
+	target, ok := targets[name]
+	if !ok {
+		return Target{}, ValidateTarget(name)
+	}
+	return target, nil
+}
+
+// Suffix es el sufijo del archivo convertido: "_whatsapp.mp4", "_telegram.mp4"...
+func (t Target) Suffix() string {
+	return "_" + t.Name + ".mp4"
+}
+
+// copiesVideo indica si el codec de video se puede copiar sin re-encodear
+func (t Target) copiesVideo(codec string) bool {
+	return slices.Contains(t.VideoCodecs, codec)
+}
+
+// copiesPixelFormat indica si el formato de pixel se puede copiar sin re-encodear. Si
+// ffprobe no lo informó se asume que sí. Un HDR con NoTonemap conserva el suyo (10 bits)
+func (t Target) copiesPixelFormat(info *VideoInfo, options *domain.DownloadOptions) bool {
+	if info.IsHDR() && options != nil && options.NoTonemap {
+		return true
+	}
+	return info.PixelFormat == "" || info.PixelFormat == t.PixelFormat || fullRangeFormats[info.PixelFormat] == t.PixelFormat
+}
+
+// fullRangeFormats son los formatos "j" (rango completo, típicos de cámaras de teléfonos y
+// MJPEG) con su equivalente: se reproducen igual, así que no obligan a re-encodear
+var fullRangeFormats = map[string]string{
+	"yuvj420p": "yuv420p",
+	"yuvj422p": "yuv422p",
+	"yuvj444p": "yuv444p",
+}
+
+// downmixes indica si el audio tiene más canales de los que admite el perfil y hay que
+// mezclarlo (en 5.1 los diálogos van al canal central, que muchos teléfonos no reproducen)
+func (t Target) downmixes(info *VideoInfo, options *domain.DownloadOptions) bool {
+	if options != nil && options.KeepChannels {
+		return false
+	}
+	return info.AudioChannels > t.MaxAudioChannels
+}
+
+// copiesAudio indica si el codec de audio se puede copiar sin re-encodear
+func (t Target) copiesAudio(codec string) bool {
+	return slices.Contains(t.AudioCodecs, codec)
+}
+
+// Check indica si un video ya analizado con GetVideoInfo cumple el perfil y, si no, por qué
+func (t Target) Check(info *VideoInfo, options *domain.DownloadOptions) (bool, string) {
+	if reasons := t.issues(info, options); len(reasons) > 0 {
+		return false, strings.Join(reasons, "; ")
+	}
+	return true, ""
+}
+
+// issues lista por qué el video no cumple el perfil (vacío = compatible)
+func (t Target) issues(info *VideoInfo, options *domain.DownloadOptions) []string {
+	reasons := []string{}
+
+	if !t.copiesVideo(info.VideoCodec) {
+		reasons = append(reasons, fmt.Sprintf("video codec is %s (needs %s)", info.VideoCodec, strings.Join(t.VideoCodecs, " or ")))
+	}
+
+	// El codec no alcanza: H.264 en yuv444p/yuv422p (p.ej. capturas de pantalla) no se
+	// reproduce en WhatsApp ni en muchos teléfonos
+	if !t.copiesPixelFormat(info, options) {
+		reasons = append(reasons, fmt.Sprintf("pixel format is %s (needs %s)", info.PixelFormat, t.PixelFormat))
+	}
+	if needsTonemap(info, options) {
+		reasons = append(reasons, fmt.Sprintf("is HDR (%s, needs tonemapping to SDR)", info.ColorTransfer))
+	}
+
+	// Si se va a quitar el audio su codec no importa: basta con quitar la pista, sin
+	// re-encodear el video
+	if options != nil && options.RemoveAudio {
+		if info.HasAudio {
+			reasons = append(reasons, "has an audio track (removal requested)")
+		}
+	} else if info.HasAudio && !t.copiesAudio(info.AudioCodec) {
+		reasons = append(reasons, fmt.Sprintf("audio codec is %s (needs %s)", info.AudioCodec, strings.Join(t.AudioCodecs, " or ")))
+	} else if !info.HasAudio && options != nil && options.EnsureAudio {
+		reasons = append(reasons, "has no audio track (silent track requested)")
+	}
+	if info.HasAudio && (options == nil || !options.RemoveAudio) && t.downmixes(info, options) {
+		reasons = append(reasons, fmt.Sprintf("audio has %d channels (max %d)", info.AudioChannels, t.MaxAudioChannels))
+	}
+
+	if exceedsBounds(info.Width, info.Height, t.MaxShortSide) {
+		maxWidth, maxHeight := maxBounds(info.Width, info.Height, t.MaxShortSide)
+		reasons = append(reasons, fmt.Sprintf("resolution is %dx%d (max %dx%d)", info.Width, info.Height, maxWidth, maxHeight))
+	}
+
+	return reasons
+}
@@ -0,0 +1,70 @@
+package postprocessor
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/elsanchez/smart-download/internal/domain"
+)
+
+func TestTargetFor(t *testing.T) {
+	tests := []struct {
+		name    string
+		options *domain.DownloadOptions
+		want    string
+		wantErr bool
+	}{
+		{"nil options", nil, TargetWhatsApp, false},
+		{"default", &domain.DownloadOptions{}, TargetWhatsApp, false},
+		{"telegram", &domain.DownloadOptions{Target: "telegram"}, TargetTelegram, false},
+		{"case insensitive", &domain.DownloadOptions{Target: "Instagram"}, TargetInstagram, false},
+		{"unknown", &domain.DownloadOptions{Target: "tiktok"}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TargetFor(tt.options)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TargetFor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got.Name != tt.want {
+				t.Errorf("TargetFor() = %q, want %q", got.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestTargetIssues_Telegram(t *testing.T) {
+	telegram := targets[TargetTelegram]
+
+	tests := []struct {
+		name string
+		info VideoInfo
+		want []string
+	}{
+		{"hevc with opus", VideoInfo{Width: 1920, Height: 1080, VideoCodec: "hevc", AudioCodec: "opus", HasAudio: true}, []string{}},
+		{"4k h264", VideoInfo{Width: 3840, Height: 2160, VideoCodec: "h264", AudioCodec: "aac", HasAudio: true}, []string{}},
+		{"vp9 with vorbis", VideoInfo{Width: 1280, Height: 720, VideoCodec: "vp9", AudioCodec: "vorbis", HasAudio: true}, []string{
+			"video codec is vp9 (needs h264 or hevc)",
+			"audio codec is vorbis (needs aac or mp3 or opus)",
+		}},
+		{"8k", VideoInfo{Width: 7680, Height: 4320, VideoCodec: "h264"}, []string{"resolution is 7680x4320 (max 3840x2160)"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := telegram.issues(&tt.info, nil); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("issues() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTarget_Suffix(t *testing.T) {
+	if got := targets[TargetWhatsApp].Suffix(); got != "_whatsapp.mp4" {
+		t.Errorf("Suffix() = %q, want _whatsapp.mp4", got)
+	}
+	if got := targets[TargetTelegram].Suffix(); got != "_telegram.mp4" {
+		t.Errorf("Suffix() = %q, want _telegram.mp4", got)
+	}
+}
@@ -0,0 +1,68 @@
+package postprocessor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/elsanchez/smart-download/internal/domain"
+)
+
+// ErrCorruptOutput indica que el archivo descargado está vacío, truncado o no se puede leer
+var ErrCorruptOutput = errors.New("corrupt/incomplete output")
+
+// minMediaSize es el tamaño mínimo de un video o audio descargado: por debajo es casi
+// seguro una descarga truncada (las imágenes solo deben no estar vacías)
+const minMediaSize = 10 * 1024
+
+// mediaExts son las extensiones de video y audio que se verifican con ffprobe
+var mediaExts = map[string]bool{
+	".mp4": true, ".webm": true, ".mkv": true, ".mov": true, ".m4v": true, ".avi": true, ".flv": true,
+	".mp3": true, ".m4a": true, ".opus": true, ".ogg": true, ".flac": true, ".wav": true, ".aac": true,
+}
+
+// VerifyOutput verifica un archivo recién descargado: tamaño mínimo y, para videos, que
+// ffprobe lo lea y tenga algún stream. En audio-only no se hace el probe (solo el tamaño).
+// Los errores envuelven ErrCorruptOutput
+func (f *FFmpegProcessor) VerifyOutput(ctx context.Context, path string, options *domain.DownloadOptions) error {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrCorruptOutput, err)
+	}
+
+	isMedia := mediaExts[strings.ToLower(filepath.Ext(path))]
+	if err := checkOutputSize(filepath.Base(path), stat.Size(), isMedia); err != nil {
+		return err
+	}
+
+	if !isMedia || (options != nil && options.AudioOnly) {
+		return nil
+	}
+
+	info, err := f.GetVideoInfo(ctx, path)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("%w: %s can't be read (%v)", ErrCorruptOutput, filepath.Base(path), err)
+	}
+	if !info.HasVideo && !info.HasAudio {
+		return fmt.Errorf("%w: %s has no video or audio stream", ErrCorruptOutput, filepath.Base(path))
+	}
+
+	return nil
+}
+
+// checkOutputSize rechaza archivos vacíos y videos/audios sospechosamente pequeños
+func checkOutputSize(name string, size int64, isMedia bool) error {
+	if size == 0 {
+		return fmt.Errorf("%w: %s is empty", ErrCorruptOutput, name)
+	}
+	if isMedia && size < minMediaSize {
+		return fmt.Errorf("%w: %s is only %d bytes", ErrCorruptOutput, name, size)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package protocol
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Version es la versión actual del protocolo del socket.
+// Cada mensaje se envía como: [versión: 1 byte][longitud: 4 bytes big-endian][JSON]
+const Version byte = 1
+
+// MaxMessageSize limita el tamaño de un mensaje para no reservar memoria sin control
+const MaxMessageSize = 16 << 20 // 16 MiB
+
+var (
+	// ErrLegacyMessage indica que el otro extremo envió JSON sin framing (versión antigua)
+	ErrLegacyMessage = errors.New("unframed JSON message (peer uses the legacy protocol)")
+
+	// ErrUnsupportedVersion indica un byte de versión desconocido
+	ErrUnsupportedVersion = errors.New("unsupported protocol version")
+)
+
+// WriteMessage serializa v como JSON y lo escribe con versión y longitud
+func WriteMessage(w io.Writer, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("marshal message: %w", err)
+	}
+
+	if len(data) > MaxMessageSize {
+		return fmt.Errorf("message too large: %d bytes (max %d)", len(data), MaxMessageSize)
+	}
+
+	header := make([]byte, 5)
+	header[0] = Version
+	binary.BigEndian.PutUint32(header[1:], uint32(len(data)))
+
+	if _, err := w.Write(header); err != nil {
+		return fmt.Errorf("write header: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("write body: %w", err)
+	}
+
+	return nil
+}
+
+// ReadMessage lee un mensaje completo y lo decodifica en v
+func ReadMessage(r io.Reader, v interface{}) error {
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(r, header[:1]); err != nil {
+		return fmt.Errorf("read version: %w", err)
+	}
+
+	// Un mensaje antiguo empieza directamente con el objeto JSON
+	if header[0] == '{' {
+		return ErrLegacyMessage
+	}
+	if header[0] != Version {
+		return fmt.Errorf("%w: got %d, want %d", ErrUnsupportedVersion, header[0], Version)
+	}
+
+	if _, err := io.ReadFull(r, header[1:]); err != nil {
+		return fmt.Errorf("read length: %w", err)
+	}
+
+	size := binary.BigEndian.Uint32(header[1:])
+	if size > MaxMessageSize {
+		return fmt.Errorf("message too large: %d bytes (max %d)", size, MaxMessageSize)
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return fmt.Errorf("read body: %w", err)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("unmarshal message: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package protocol
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type message struct {
+	Action string `json:"action"`
+	Data   string `json:"data"`
+}
+
+func TestWriteReadMessage(t *testing.T) {
+	var buf bytes.Buffer
+
+	// Dos mensajes seguidos en el mismo stream
+	first := message{Action: "list", Data: strings.Repeat("x", 100000)}
+	second := message{Action: "ping"}
+	if err := WriteMessage(&buf, first); err != nil {
+		t.Fatalf("WriteMessage() error: %v", err)
+	}
+	if err := WriteMessage(&buf, second); err != nil {
+		t.Fatalf("WriteMessage() error: %v", err)
+	}
+
+	for _, want := range []message{first, second} {
+		var got message
+		if err := ReadMessage(&buf, &got); err != nil {
+			t.Fatalf("ReadMessage() error: %v", err)
+		}
+		if got != want {
+			t.Errorf("ReadMessage() action = %q, want %q", got.Action, want.Action)
+		}
+	}
+}
+
+func TestReadMessage_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []byte
+		want  error
+	}{
+		{"legacy json", []byte(`{"action":"ping"}`), ErrLegacyMessage},
+		{"unknown version", []byte{9, 0, 0, 0, 2, '{', '}'}, ErrUnsupportedVersion},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got message
+			err := ReadMessage(bytes.NewReader(tt.input), &got)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("ReadMessage() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	// Mensaje truncado
+	var got message
+	if err := ReadMessage(bytes.NewReader([]byte{Version, 0, 0, 0, 10, '{'}), &got); err == nil {
+		t.Error("expected error for truncated message")
+	}
+}
@@ -2,10 +2,41 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/elsanchez/smart-download/internal/domain"
 )
 
+// DownloadFilter filtra y pagina el historial de descargas. Los campos vacíos no filtran
+type DownloadFilter struct {
+	Platform string
+	Status   domain.DownloadStatus
+	Tag      string
+	Since    *time.Time // created_at >= Since
+	Until    *time.Time // created_at < Until
+	Finished *time.Time // completed_at < Finished (solo descargas terminadas)
+	ParentID int64      // Solo los items de este canal/perfil
+	TopLevel bool       // Solo descargas sin padre: los items de un canal/perfil se agrupan bajo él
+	Limit    int        // 0 = sin límite
+	Offset   int
+}
+
+// PlatformCount es la cantidad de descargas de una plataforma en un status y el tamaño
+// total de sus archivos
+type PlatformCount struct {
+	Platform string                `db:"platform"`
+	Status   domain.DownloadStatus `db:"status"`
+	Count    int                   `db:"count"`
+	Bytes    int64                 `db:"bytes"`
+}
+
+// ChildCount es la cantidad de items de un canal/perfil en un status
+type ChildCount struct {
+	ParentID int64                 `db:"parent_id"`
+	Status   domain.DownloadStatus `db:"status"`
+	Count    int                   `db:"count"`
+}
+
 // DownloadRepository define las operaciones sobre descargas
 type DownloadRepository interface {
 	// CRUD básico
@@ -13,18 +44,33 @@ type DownloadRepository interface {
 	GetByID(ctx context.Context, id int64) (*domain.Download, error)
 	Update(ctx context.Context, dl *domain.Download) error
 	Delete(ctx context.Context, id int64) error
+	DeleteOlderThan(ctx context.Context, status domain.DownloadStatus, cutoff time.Time) (int64, error)
 
 	// Queries especializadas
 	GetPending(ctx context.Context) ([]*domain.Download, error)
 	GetActive(ctx context.Context) ([]*domain.Download, error)
 	GetRecent(ctx context.Context, limit int) ([]*domain.Download, error)
 	GetByStatus(ctx context.Context, status domain.DownloadStatus) ([]*domain.Download, error)
+	GetByURL(ctx context.Context, url string) (*domain.Download, error)
+	Query(ctx context.Context, filter DownloadFilter) ([]*domain.Download, int, error)
 
 	// Updates parciales
 	UpdateStatus(ctx context.Context, id int64, status domain.DownloadStatus, errMsg string) error
+	MarkFailed(ctx context.Context, id int64, errType, errMsg string) error
+	RequeueFailed(ctx context.Context, platform, errType string) (int64, error)
 	UpdateOutputPath(ctx context.Context, id int64, path string) error
+	UpdateFiles(ctx context.Context, id int64, files []string) error
+	UpdateMetadata(ctx context.Context, id int64, title, uploader, uploadDate string) error
+	UpdateBackend(ctx context.Context, id int64, backend string) error
+	UpdateUsedAccount(ctx context.Context, id int64, accountID int64) error
+	UpdateProgress(ctx context.Context, id int64, percent int) error
+	UpdateFileSize(ctx context.Context, id int64, size int64) error
+	UpdateTags(ctx context.Context, id int64, tags []string) error
 
 	// Estadísticas
 	CountByStatus(ctx context.Context, status domain.DownloadStatus) (int, error)
 	CountTotal(ctx context.Context) (int, error)
+	CountByPlatform(ctx context.Context) ([]PlatformCount, error)
+	CountFinishedSince(ctx context.Context, since time.Time) (completed, failed int, err error)
+	CountChildren(ctx context.Context, parentIDs []int64) ([]ChildCount, error)
 }
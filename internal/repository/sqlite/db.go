@@ -17,6 +17,18 @@ import (
 //go:embed migrations/*.sql
 var migrationsFS embed.FS
 
+// maxOpenConns limita el pool: con WAL los lectores no bloquean al escritor, y las
+// escrituras concurrentes esperan su turno gracias a busy_timeout
+const maxOpenConns = 4
+
+// dsnParams se aplican a cada conexión del pool:
+//   - WAL: lecturas concurrentes con una escritura (el modo persiste en el archivo;
+//     las bases existentes se convierten al abrirlas)
+//   - busy_timeout: esperar al lock de escritura en lugar de fallar con SQLITE_BUSY
+//   - txlock=immediate: las transacciones toman el lock de escritura al empezar,
+//     evitando el deadlock de dos lectores que intentan escribir a la vez
+const dsnParams = "?_journal_mode=WAL&_busy_timeout=5000&_synchronous=NORMAL&_txlock=immediate"
+
 // Database encapsula la conexión a SQLite
 type Database struct {
 	DB               *sqlx.DB
@@ -35,7 +47,7 @@ func NewDatabase(dataDir string) (*Database, error) {
 	dbPath := filepath.Join(dataDir, "downloads.db")
 
 	// Abrir con database/sql (para migrations)
-	sqlDB, err := sql.Open("sqlite3", dbPath)
+	sqlDB, err := sql.Open("sqlite3", dbPath+dsnParams)
 	if err != nil {
 		return nil, fmt.Errorf("open database: %w", err)
 	}
@@ -50,7 +62,8 @@ func NewDatabase(dataDir string) (*Database, error) {
 	db := sqlx.NewDb(sqlDB, "sqlite3")
 
 	// Configuraciones SQLite
-	db.SetMaxOpenConns(1) // SQLite no soporta concurrencia de escritura
+	db.SetMaxOpenConns(maxOpenConns)
+	db.SetMaxIdleConns(maxOpenConns)
 
 	// Inicializar repositorios
 	database := &Database{
@@ -2,11 +2,15 @@ package sqlite
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/elsanchez/smart-download/internal/domain"
+	"github.com/elsanchez/smart-download/internal/repository"
 )
 
 func TestDatabase_CreateAndGetDownload(t *testing.T) {
@@ -180,3 +184,834 @@ func TestDatabase_AccountActiveSwitch(t *testing.T) {
 
 	t.Log("✅ Account switching works correctly")
 }
+
+func TestDatabase_GetByURLIgnoresFailed(t *testing.T) {
+	tmpDir := t.TempDir()
+	db, err := NewDatabase(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	url := "https://www.youtube.com/watch?v=dup"
+
+	// Sin descargas: no hay duplicado
+	existing, err := db.DownloadRepo.GetByURL(ctx, url)
+	if err != nil {
+		t.Fatalf("failed to get by url: %v", err)
+	}
+	if existing != nil {
+		t.Fatalf("expected no download, got %d", existing.ID)
+	}
+
+	// Una descarga fallida no cuenta como duplicado
+	failedID, err := db.DownloadRepo.Create(ctx, &domain.Download{URL: url, Platform: "youtube", Status: domain.StatusPending})
+	if err != nil {
+		t.Fatalf("failed to create download: %v", err)
+	}
+	if err := db.DownloadRepo.UpdateStatus(ctx, failedID, domain.StatusFailed, "boom"); err != nil {
+		t.Fatalf("failed to update status: %v", err)
+	}
+
+	existing, err = db.DownloadRepo.GetByURL(ctx, url)
+	if err != nil {
+		t.Fatalf("failed to get by url: %v", err)
+	}
+	if existing != nil {
+		t.Fatalf("failed download %d should not be reported as duplicate", existing.ID)
+	}
+
+	// Una descarga pendiente sí es duplicado
+	pendingID, err := db.DownloadRepo.Create(ctx, &domain.Download{URL: url, Platform: "youtube", Status: domain.StatusPending})
+	if err != nil {
+		t.Fatalf("failed to create download: %v", err)
+	}
+
+	existing, err = db.DownloadRepo.GetByURL(ctx, url)
+	if err != nil {
+		t.Fatalf("failed to get by url: %v", err)
+	}
+	if existing == nil || existing.ID != pendingID {
+		t.Fatalf("expected duplicate %d, got %+v", pendingID, existing)
+	}
+}
+
+func TestDatabase_UpdateFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	db, err := NewDatabase(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+
+	id, err := db.DownloadRepo.Create(ctx, &domain.Download{URL: "https://www.instagram.com/p/ABC123/", Platform: "instagram", Status: domain.StatusPending})
+	if err != nil {
+		t.Fatalf("failed to create download: %v", err)
+	}
+
+	// Sin archivos: lista vacía
+	dl, err := db.DownloadRepo.GetByID(ctx, id)
+	if err != nil {
+		t.Fatalf("failed to get download: %v", err)
+	}
+	if len(dl.Files) != 0 {
+		t.Errorf("expected no files, got %v", dl.Files)
+	}
+
+	files := []string{"/tmp/instagram_user_1.jpg", "/tmp/instagram_user_2.jpg", "/tmp/instagram_user_3.mp4"}
+	if err := db.DownloadRepo.UpdateFiles(ctx, id, files); err != nil {
+		t.Fatalf("failed to update files: %v", err)
+	}
+
+	dl, err = db.DownloadRepo.GetByID(ctx, id)
+	if err != nil {
+		t.Fatalf("failed to get download: %v", err)
+	}
+	if len(dl.Files) != len(files) {
+		t.Fatalf("expected %d files, got %d", len(files), len(dl.Files))
+	}
+	for i, file := range files {
+		if dl.Files[i] != file {
+			t.Errorf("file %d: expected %s, got %s", i, file, dl.Files[i])
+		}
+	}
+}
+
+func TestDatabase_GetPendingByPriority(t *testing.T) {
+	tmpDir := t.TempDir()
+	db, err := NewDatabase(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+
+	// Insertadas en este orden (mismo segundo: el desempate es FIFO por ID)
+	downloads := []struct {
+		url      string
+		priority int
+		status   domain.DownloadStatus
+	}{
+		{"https://example.com/low", -1, domain.StatusPending},
+		{"https://example.com/normal-1", 0, domain.StatusPending},
+		{"https://example.com/urgent", 10, domain.StatusPending},
+		{"https://example.com/done", 20, domain.StatusCompleted},
+		{"https://example.com/normal-2", 0, domain.StatusPending},
+		{"https://example.com/high", 5, domain.StatusPending},
+	}
+
+	for _, d := range downloads {
+		dl := &domain.Download{URL: d.url, Platform: "generic", Status: d.status, Priority: d.priority}
+		if _, err := db.DownloadRepo.Create(ctx, dl); err != nil {
+			t.Fatalf("failed to create download: %v", err)
+		}
+	}
+
+	pending, err := db.DownloadRepo.GetPending(ctx)
+	if err != nil {
+		t.Fatalf("GetPending() error: %v", err)
+	}
+
+	expected := []string{
+		"https://example.com/urgent",
+		"https://example.com/high",
+		"https://example.com/normal-1",
+		"https://example.com/normal-2",
+		"https://example.com/low",
+	}
+	if len(pending) != len(expected) {
+		t.Fatalf("GetPending() returned %d downloads, want %d", len(pending), len(expected))
+	}
+	for i, dl := range pending {
+		if dl.URL != expected[i] {
+			t.Errorf("pending[%d] = %s (priority %d), want %s", i, dl.URL, dl.Priority, expected[i])
+		}
+	}
+}
+
+func TestDatabase_Query(t *testing.T) {
+	tmpDir := t.TempDir()
+	db, err := NewDatabase(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+
+	// 5 de youtube (2 fallidas) y 2 de twitter
+	for i := 0; i < 7; i++ {
+		platform, status := "youtube", domain.StatusCompleted
+		if i >= 5 {
+			platform = "twitter"
+		} else if i%2 == 1 {
+			status = domain.StatusFailed
+		}
+
+		dl := &domain.Download{URL: fmt.Sprintf("https://example.com/%d", i), Platform: platform, Status: status}
+		if _, err := db.DownloadRepo.Create(ctx, dl); err != nil {
+			t.Fatalf("failed to create download: %v", err)
+		}
+	}
+
+	// created_at: una descarga por día, la 0 es la más antigua
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 7; i++ {
+		if _, err := db.DB.ExecContext(ctx, "UPDATE downloads SET created_at = ? WHERE url = ?",
+			base.AddDate(0, 0, i).Unix(), fmt.Sprintf("https://example.com/%d", i)); err != nil {
+			t.Fatalf("failed to set created_at: %v", err)
+		}
+	}
+
+	since := base.AddDate(0, 0, 2)
+	until := base.AddDate(0, 0, 6)
+
+	tests := []struct {
+		name     string
+		filter   repository.DownloadFilter
+		expected []int // índices de las URLs, más recientes primero
+		total    int
+	}{
+		{"all", repository.DownloadFilter{}, []int{6, 5, 4, 3, 2, 1, 0}, 7},
+		{"platform", repository.DownloadFilter{Platform: "twitter"}, []int{6, 5}, 2},
+		{"platform and status", repository.DownloadFilter{Platform: "youtube", Status: domain.StatusFailed}, []int{3, 1}, 2},
+		{"page", repository.DownloadFilter{Platform: "youtube", Limit: 2, Offset: 2}, []int{2, 1}, 5},
+		{"offset without limit", repository.DownloadFilter{Offset: 5}, []int{1, 0}, 7},
+		{"date range", repository.DownloadFilter{Since: &since, Until: &until}, []int{5, 4, 3, 2}, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			downloads, total, err := db.DownloadRepo.Query(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("Query() error: %v", err)
+			}
+
+			if total != tt.total {
+				t.Errorf("total = %d, want %d", total, tt.total)
+			}
+			if len(downloads) != len(tt.expected) {
+				t.Fatalf("Query() returned %d downloads, want %d", len(downloads), len(tt.expected))
+			}
+			for i, dl := range downloads {
+				if want := fmt.Sprintf("https://example.com/%d", tt.expected[i]); dl.URL != want {
+					t.Errorf("downloads[%d] = %s, want %s", i, dl.URL, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDatabase_DeleteOlderThan(t *testing.T) {
+	tmpDir := t.TempDir()
+	db, err := NewDatabase(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	old := time.Now().AddDate(0, 0, -60).Unix()
+
+	for _, status := range []domain.DownloadStatus{domain.StatusCompleted, domain.StatusCompleted, domain.StatusPending} {
+		if _, err := db.DownloadRepo.Create(ctx, &domain.Download{URL: "https://example.com/" + string(status), Platform: "generic", Status: status}); err != nil {
+			t.Fatalf("failed to create download: %v", err)
+		}
+	}
+
+	// Todas creadas hace tiempo, pero la segunda completada terminó hoy
+	if _, err := db.DB.ExecContext(ctx, "UPDATE downloads SET created_at = ?", old); err != nil {
+		t.Fatalf("failed to set created_at: %v", err)
+	}
+	if _, err := db.DB.ExecContext(ctx, "UPDATE downloads SET completed_at = CASE id WHEN 2 THEN ? ELSE ? END WHERE status = 'completed'", time.Now().Unix(), old); err != nil {
+		t.Fatalf("failed to set completed_at: %v", err)
+	}
+
+	if _, err := db.DownloadRepo.DeleteOlderThan(ctx, domain.StatusPending, time.Now()); err == nil {
+		t.Error("expected error when pruning pending downloads")
+	}
+
+	deleted, err := db.DownloadRepo.DeleteOlderThan(ctx, domain.StatusCompleted, time.Now().AddDate(0, 0, -30))
+	if err != nil {
+		t.Fatalf("DeleteOlderThan() error: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("deleted %d downloads, want 1", deleted)
+	}
+
+	total, err := db.DownloadRepo.CountTotal(ctx)
+	if err != nil {
+		t.Fatalf("CountTotal() error: %v", err)
+	}
+	if total != 2 {
+		t.Errorf("%d downloads left, want 2 (recent completed + pending)", total)
+	}
+}
+
+func TestDatabase_WALMode(t *testing.T) {
+	tmpDir := t.TempDir()
+	db, err := NewDatabase(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	var mode string
+	if err := db.DB.Get(&mode, "PRAGMA journal_mode"); err != nil {
+		t.Fatalf("failed to query journal_mode: %v", err)
+	}
+	if mode != "wal" {
+		t.Errorf("journal_mode = %s, want wal", mode)
+	}
+
+	var timeout int
+	if err := db.DB.Get(&timeout, "PRAGMA busy_timeout"); err != nil {
+		t.Fatalf("failed to query busy_timeout: %v", err)
+	}
+	if timeout == 0 {
+		t.Error("busy_timeout is not set")
+	}
+}
+
+// BenchmarkConcurrentUpdateAndList simula workers actualizando status mientras el CLI
+// lista el historial. Compara el pool anterior (1 conexión) con el actual
+func BenchmarkConcurrentUpdateAndList(b *testing.B) {
+	for _, conns := range []int{1, maxOpenConns} {
+		b.Run(fmt.Sprintf("conns=%d", conns), func(b *testing.B) {
+			db, err := NewDatabase(b.TempDir())
+			if err != nil {
+				b.Fatalf("failed to create database: %v", err)
+			}
+			defer db.Close()
+			db.DB.SetMaxOpenConns(conns)
+
+			ctx := context.Background()
+			ids := make([]int64, 0, 1000)
+			for i := 0; i < 1000; i++ {
+				id, err := db.DownloadRepo.Create(ctx, &domain.Download{
+					URL:      fmt.Sprintf("https://example.com/%d", i),
+					Platform: "generic",
+					Status:   domain.StatusPending,
+				})
+				if err != nil {
+					b.Fatalf("failed to create download: %v", err)
+				}
+				ids = append(ids, id)
+			}
+
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					i++
+					// 1 de cada 4 operaciones es un listado completo (lento), el resto escrituras
+					if i%4 == 0 {
+						if _, err := db.DownloadRepo.GetRecent(ctx, 1000); err != nil {
+							b.Errorf("GetRecent() error: %v", err)
+						}
+						continue
+					}
+					if err := db.DownloadRepo.UpdateStatus(ctx, ids[i%len(ids)], domain.StatusDownloading, ""); err != nil {
+						b.Errorf("UpdateStatus() error: %v", err)
+					}
+				}
+			})
+		})
+	}
+}
+
+func TestDatabase_Tags(t *testing.T) {
+	tmpDir := t.TempDir()
+	db, err := NewDatabase(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+
+	tagged := &domain.Download{URL: "https://example.com/a", Platform: "generic", Status: domain.StatusPending}
+	tagged.AddTags("Work", " urgent ", "work")
+	taggedID, err := db.DownloadRepo.Create(ctx, tagged)
+	if err != nil {
+		t.Fatalf("failed to create download: %v", err)
+	}
+	if _, err := db.DownloadRepo.Create(ctx, &domain.Download{URL: "https://example.com/b", Platform: "generic", Status: domain.StatusPending}); err != nil {
+		t.Fatalf("failed to create download: %v", err)
+	}
+
+	got, err := db.DownloadRepo.GetByID(ctx, taggedID)
+	if err != nil {
+		t.Fatalf("GetByID() error: %v", err)
+	}
+	if len(got.Tags) != 2 || got.Tags[0] != "work" || got.Tags[1] != "urgent" {
+		t.Errorf("tags = %v, want [work urgent]", got.Tags)
+	}
+
+	downloads, total, err := db.DownloadRepo.Query(ctx, repository.DownloadFilter{Tag: "work"})
+	if err != nil {
+		t.Fatalf("Query() error: %v", err)
+	}
+	if total != 1 || len(downloads) != 1 || downloads[0].ID != taggedID {
+		t.Errorf("Query(tag=work) returned %d of %d downloads, want only %d", len(downloads), total, taggedID)
+	}
+
+	// Quitar todas las etiquetas deja la columna en NULL
+	got.RemoveTags("work", "urgent")
+	if err := db.DownloadRepo.UpdateTags(ctx, taggedID, got.Tags); err != nil {
+		t.Fatalf("UpdateTags() error: %v", err)
+	}
+
+	_, total, err = db.DownloadRepo.Query(ctx, repository.DownloadFilter{Tag: "work"})
+	if err != nil {
+		t.Fatalf("Query() error: %v", err)
+	}
+	if total != 0 {
+		t.Errorf("Query(tag=work) total = %d after removing tags, want 0", total)
+	}
+}
+
+func TestDatabase_UpdateValidationSetsTimestamp(t *testing.T) {
+	tmpDir := t.TempDir()
+	db, err := NewDatabase(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+
+	id, err := db.AccountRepo.Create(ctx, &domain.Account{
+		Platform:   domain.PlatformTwitter,
+		Name:       "main",
+		CookiePath: "/path/to/cookies.txt",
+	})
+	if err != nil {
+		t.Fatalf("failed to create account: %v", err)
+	}
+
+	// Sin validar todavía
+	acc, err := db.AccountRepo.GetByID(ctx, id)
+	if err != nil {
+		t.Fatalf("failed to get account: %v", err)
+	}
+	if acc.ValidatedAt != nil {
+		t.Errorf("ValidatedAt = %v before validation, want nil", acc.ValidatedAt)
+	}
+
+	before := time.Now().Add(-time.Second)
+	if err := db.AccountRepo.UpdateValidation(ctx, id, domain.ValidationStatusValid, nil); err != nil {
+		t.Fatalf("failed to update validation: %v", err)
+	}
+
+	acc, err = db.AccountRepo.GetByID(ctx, id)
+	if err != nil {
+		t.Fatalf("failed to get account: %v", err)
+	}
+	if acc.ValidatedAt == nil || acc.ValidatedAt.Before(before) {
+		t.Errorf("ValidatedAt = %v, want at or after %v", acc.ValidatedAt, before)
+	}
+	if acc.ValidationStatus != domain.ValidationStatusValid {
+		t.Errorf("ValidationStatus = %s, want valid", acc.ValidationStatus)
+	}
+}
+
+func TestDatabase_ScheduledAfter(t *testing.T) {
+	db, err := NewDatabase(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	at := time.Date(2030, 6, 1, 2, 0, 0, 0, time.Local)
+
+	scheduled := &domain.Download{URL: "https://example.com/night", Status: domain.StatusPending, ScheduledAfter: &at}
+	id, err := db.DownloadRepo.Create(ctx, scheduled)
+	if err != nil {
+		t.Fatalf("failed to create download: %v", err)
+	}
+	if _, err := db.DownloadRepo.Create(ctx, &domain.Download{URL: "https://example.com/now", Status: domain.StatusPending}); err != nil {
+		t.Fatalf("failed to create download: %v", err)
+	}
+
+	got, err := db.DownloadRepo.GetByID(ctx, id)
+	if err != nil {
+		t.Fatalf("GetByID() error: %v", err)
+	}
+	if got.ScheduledAfter == nil || !got.ScheduledAfter.Equal(at) {
+		t.Fatalf("ScheduledAfter = %v, want %v", got.ScheduledAfter, at)
+	}
+
+	// Update con ScheduledAfter nil deja la columna en NULL
+	got.ScheduledAfter = nil
+	if err := db.DownloadRepo.Update(ctx, got); err != nil {
+		t.Fatalf("Update() error: %v", err)
+	}
+	got, _ = db.DownloadRepo.GetByID(ctx, id)
+	if got.ScheduledAfter != nil {
+		t.Errorf("ScheduledAfter = %v after clearing, want nil", got.ScheduledAfter)
+	}
+
+	// GetPending retorna ambas: el filtro por hora lo aplica la cola
+	pending, err := db.DownloadRepo.GetPending(ctx)
+	if err != nil {
+		t.Fatalf("GetPending() error: %v", err)
+	}
+	if len(pending) != 2 {
+		t.Errorf("GetPending() returned %d downloads, want 2", len(pending))
+	}
+}
+
+func TestDatabase_MarkFailed(t *testing.T) {
+	db, err := NewDatabase(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	id, err := db.DownloadRepo.Create(ctx, &domain.Download{URL: "https://example.com/private", Status: domain.StatusDownloading})
+	if err != nil {
+		t.Fatalf("failed to create download: %v", err)
+	}
+
+	if err := db.DownloadRepo.MarkFailed(ctx, id, domain.ErrorTypeAuth, "yt-dlp: ERROR: HTTP Error 403: Forbidden"); err != nil {
+		t.Fatalf("MarkFailed() error: %v", err)
+	}
+
+	dl, err := db.DownloadRepo.GetByID(ctx, id)
+	if err != nil {
+		t.Fatalf("GetByID() error: %v", err)
+	}
+	if dl.Status != domain.StatusFailed || dl.ErrorType != domain.ErrorTypeAuth || dl.CompletedAt == nil {
+		t.Errorf("unexpected download after MarkFailed: status=%s error_type=%q completed_at=%v", dl.Status, dl.ErrorType, dl.CompletedAt)
+	}
+
+	// Reintentar (UpdateStatus) limpia la categoría
+	if err := db.DownloadRepo.UpdateStatus(ctx, id, domain.StatusPending, ""); err != nil {
+		t.Fatalf("UpdateStatus() error: %v", err)
+	}
+	dl, _ = db.DownloadRepo.GetByID(ctx, id)
+	if dl.ErrorType != "" {
+		t.Errorf("error_type = %q after retry, want empty", dl.ErrorType)
+	}
+}
+
+func TestDatabase_UpdateMetadata(t *testing.T) {
+	db, err := NewDatabase(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	id, err := db.DownloadRepo.Create(ctx, &domain.Download{URL: "https://www.youtube.com/watch?v=abc", Status: domain.StatusPending})
+	if err != nil {
+		t.Fatalf("failed to create download: %v", err)
+	}
+
+	dl, _ := db.DownloadRepo.GetByID(ctx, id)
+	if dl.DisplayName() != dl.URL {
+		t.Errorf("DisplayName() without metadata = %q, want the URL", dl.DisplayName())
+	}
+
+	if err := db.DownloadRepo.UpdateMetadata(ctx, id, "Some video", "Some channel", "2024-03-01"); err != nil {
+		t.Fatalf("UpdateMetadata() error: %v", err)
+	}
+
+	dl, err = db.DownloadRepo.GetByID(ctx, id)
+	if err != nil {
+		t.Fatalf("GetByID() error: %v", err)
+	}
+	if dl.Title != "Some video" || dl.Uploader != "Some channel" || dl.UploadDate != "2024-03-01" {
+		t.Errorf("unexpected metadata: title=%q uploader=%q upload_date=%q", dl.Title, dl.Uploader, dl.UploadDate)
+	}
+	if dl.DisplayName() != "Some video" {
+		t.Errorf("DisplayName() = %q, want the title", dl.DisplayName())
+	}
+}
+
+func TestDatabase_UpdateBackend(t *testing.T) {
+	db, err := NewDatabase(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	id, err := db.DownloadRepo.Create(ctx, &domain.Download{URL: "https://www.instagram.com/p/abc/", Status: domain.StatusPending})
+	if err != nil {
+		t.Fatalf("failed to create download: %v", err)
+	}
+
+	dl, _ := db.DownloadRepo.GetByID(ctx, id)
+	if dl.Backend != "" {
+		t.Errorf("Backend before downloading = %q, want empty", dl.Backend)
+	}
+
+	if err := db.DownloadRepo.UpdateBackend(ctx, id, "gallery-dl"); err != nil {
+		t.Fatalf("UpdateBackend() error: %v", err)
+	}
+	dl, _ = db.DownloadRepo.GetByID(ctx, id)
+	if dl.Backend != "gallery-dl" {
+		t.Errorf("Backend = %q, want gallery-dl", dl.Backend)
+	}
+
+	// Update completo conserva el backend
+	dl.Status = domain.StatusCompleted
+	if err := db.DownloadRepo.Update(ctx, dl); err != nil {
+		t.Fatalf("Update() error: %v", err)
+	}
+	dl, _ = db.DownloadRepo.GetByID(ctx, id)
+	if dl.Backend != "gallery-dl" {
+		t.Errorf("Backend after Update = %q, want gallery-dl", dl.Backend)
+	}
+}
+
+func TestDatabase_UpdateProgress(t *testing.T) {
+	db, err := NewDatabase(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	id, err := db.DownloadRepo.Create(ctx, &domain.Download{URL: "https://www.youtube.com/watch?v=abc", Status: domain.StatusProcessing})
+	if err != nil {
+		t.Fatalf("failed to create download: %v", err)
+	}
+
+	if err := db.DownloadRepo.UpdateProgress(ctx, id, 42); err != nil {
+		t.Fatalf("UpdateProgress() error: %v", err)
+	}
+	dl, _ := db.DownloadRepo.GetByID(ctx, id)
+	if dl.Progress != 42 {
+		t.Errorf("Progress = %d, want 42", dl.Progress)
+	}
+
+	// Un cambio de estado empieza una fase nueva
+	if err := db.DownloadRepo.UpdateStatus(ctx, id, domain.StatusPending, ""); err != nil {
+		t.Fatalf("UpdateStatus() error: %v", err)
+	}
+	dl, _ = db.DownloadRepo.GetByID(ctx, id)
+	if dl.Progress != 0 {
+		t.Errorf("Progress after UpdateStatus = %d, want 0", dl.Progress)
+	}
+}
+
+func TestDatabase_CountByPlatform(t *testing.T) {
+	db, err := NewDatabase(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	create := func(platform string, status domain.DownloadStatus, size int64) int64 {
+		t.Helper()
+		id, err := db.DownloadRepo.Create(ctx, &domain.Download{URL: "https://example.com/" + platform, Platform: platform, Status: domain.StatusPending})
+		if err != nil {
+			t.Fatalf("failed to create download: %v", err)
+		}
+		db.DownloadRepo.UpdateFileSize(ctx, id, size)
+		db.DownloadRepo.UpdateStatus(ctx, id, status, "")
+		return id
+	}
+	create("youtube", domain.StatusCompleted, 1000)
+	create("youtube", domain.StatusCompleted, 500)
+	create("youtube", domain.StatusFailed, 0)
+	create("reddit", domain.StatusPending, 0)
+
+	counts, err := db.DownloadRepo.CountByPlatform(ctx)
+	if err != nil {
+		t.Fatalf("CountByPlatform() error: %v", err)
+	}
+	want := []repository.PlatformCount{
+		{Platform: "reddit", Status: domain.StatusPending, Count: 1},
+		{Platform: "youtube", Status: domain.StatusCompleted, Count: 2, Bytes: 1500},
+		{Platform: "youtube", Status: domain.StatusFailed, Count: 1},
+	}
+	if !reflect.DeepEqual(counts, want) {
+		t.Errorf("CountByPlatform() = %+v, want %+v", counts, want)
+	}
+
+	completed, failed, err := db.DownloadRepo.CountFinishedSince(ctx, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("CountFinishedSince() error: %v", err)
+	}
+	if completed != 2 || failed != 1 {
+		t.Errorf("CountFinishedSince() = %d completed, %d failed, want 2 and 1", completed, failed)
+	}
+
+	completed, failed, _ = db.DownloadRepo.CountFinishedSince(ctx, time.Now().Add(time.Hour))
+	if completed != 0 || failed != 0 {
+		t.Errorf("CountFinishedSince(future) = %d, %d, want 0, 0", completed, failed)
+	}
+}
+
+func TestDatabase_ParentID(t *testing.T) {
+	db, err := NewDatabase(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	parentID, err := db.DownloadRepo.Create(ctx, &domain.Download{URL: "https://www.youtube.com/@channel", Status: domain.StatusPending})
+	if err != nil {
+		t.Fatalf("failed to create parent: %v", err)
+	}
+	childID, err := db.DownloadRepo.Create(ctx, &domain.Download{URL: "https://www.youtube.com/watch?v=abc", Status: domain.StatusPending, ParentID: &parentID})
+	if err != nil {
+		t.Fatalf("failed to create child: %v", err)
+	}
+
+	parent, _ := db.DownloadRepo.GetByID(ctx, parentID)
+	if parent.ParentID != nil {
+		t.Errorf("parent ParentID = %v, want nil", *parent.ParentID)
+	}
+	child, _ := db.DownloadRepo.GetByID(ctx, childID)
+	if child.ParentID == nil || *child.ParentID != parentID {
+		t.Fatalf("child ParentID = %v, want %d", child.ParentID, parentID)
+	}
+
+	// Update completo conserva el padre
+	child.Status = domain.StatusCompleted
+	if err := db.DownloadRepo.Update(ctx, child); err != nil {
+		t.Fatalf("Update() error: %v", err)
+	}
+	child, _ = db.DownloadRepo.GetByID(ctx, childID)
+	if child.ParentID == nil || *child.ParentID != parentID {
+		t.Errorf("ParentID after Update = %v, want %d", child.ParentID, parentID)
+	}
+}
+
+func TestDatabase_CountChildren(t *testing.T) {
+	db, err := NewDatabase(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	parentID, _ := db.DownloadRepo.Create(ctx, &domain.Download{URL: "https://www.youtube.com/@channel", Status: domain.StatusCompleted})
+	otherID, _ := db.DownloadRepo.Create(ctx, &domain.Download{URL: "https://example.com/video.mp4", Status: domain.StatusPending})
+	for i, status := range []domain.DownloadStatus{domain.StatusCompleted, domain.StatusFailed, domain.StatusFailed} {
+		url := fmt.Sprintf("https://www.youtube.com/watch?v=%d", i)
+		if _, err := db.DownloadRepo.Create(ctx, &domain.Download{URL: url, Status: status, ParentID: &parentID}); err != nil {
+			t.Fatalf("failed to create item: %v", err)
+		}
+	}
+
+	counts, err := db.DownloadRepo.CountChildren(ctx, []int64{parentID, otherID})
+	if err != nil {
+		t.Fatalf("CountChildren() error: %v", err)
+	}
+	want := []repository.ChildCount{
+		{ParentID: parentID, Status: domain.StatusCompleted, Count: 1},
+		{ParentID: parentID, Status: domain.StatusFailed, Count: 2},
+	}
+	if !reflect.DeepEqual(counts, want) {
+		t.Errorf("CountChildren() = %+v, want %+v", counts, want)
+	}
+
+	if counts, err := db.DownloadRepo.CountChildren(ctx, nil); err != nil || len(counts) != 0 {
+		t.Errorf("CountChildren(nil) = %+v, %v, want nothing", counts, err)
+	}
+
+	// TopLevel oculta los items; ParentID lista solo los del perfil
+	_, total, err := db.DownloadRepo.Query(ctx, repository.DownloadFilter{TopLevel: true})
+	if err != nil || total != 2 {
+		t.Errorf("Query(TopLevel) total = %d, %v, want 2", total, err)
+	}
+	_, total, err = db.DownloadRepo.Query(ctx, repository.DownloadFilter{ParentID: parentID})
+	if err != nil || total != 3 {
+		t.Errorf("Query(ParentID) total = %d, %v, want 3", total, err)
+	}
+}
+
+func TestDatabase_RequeueFailed(t *testing.T) {
+	db, err := NewDatabase(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	failed := func(url, platform, errType string) int64 {
+		t.Helper()
+		id, err := db.DownloadRepo.Create(ctx, &domain.Download{URL: url, Platform: platform, Status: domain.StatusPending})
+		if err != nil {
+			t.Fatalf("failed to create download: %v", err)
+		}
+		if err := db.DownloadRepo.MarkFailed(ctx, id, errType, "failed"); err != nil {
+			t.Fatalf("MarkFailed() error: %v", err)
+		}
+		return id
+	}
+
+	authID := failed("https://x.com/a/status/1", "twitter", domain.ErrorTypeAuth)
+	failed("https://x.com/a/status/2", "twitter", domain.ErrorTypeNetwork)
+	failed("https://www.instagram.com/p/abc/", "instagram", domain.ErrorTypeAuth)
+	completedID, _ := db.DownloadRepo.Create(ctx, &domain.Download{URL: "https://x.com/a/status/3", Platform: "twitter", Status: domain.StatusCompleted})
+
+	requeued, err := db.DownloadRepo.RequeueFailed(ctx, "twitter", domain.ErrorTypeAuth)
+	if err != nil {
+		t.Fatalf("RequeueFailed() error: %v", err)
+	}
+	if requeued != 1 {
+		t.Errorf("RequeueFailed(twitter, auth) = %d, want 1", requeued)
+	}
+
+	dl, _ := db.DownloadRepo.GetByID(ctx, authID)
+	if dl.Status != domain.StatusPending || dl.ErrorType != "" || dl.ErrorMessage != "" || dl.CompletedAt != nil {
+		t.Errorf("requeued download = %+v, want pending without error", dl)
+	}
+
+	// Sin filtros: el resto de las fallidas; las completadas no se tocan
+	requeued, err = db.DownloadRepo.RequeueFailed(ctx, "", "")
+	if err != nil {
+		t.Fatalf("RequeueFailed() error: %v", err)
+	}
+	if requeued != 2 {
+		t.Errorf("RequeueFailed() = %d, want 2", requeued)
+	}
+	if dl, _ := db.DownloadRepo.GetByID(ctx, completedID); dl.Status != domain.StatusCompleted {
+		t.Errorf("completed download status = %s, want completed", dl.Status)
+	}
+}
+
+func TestDatabase_UpdateUsedAccount(t *testing.T) {
+	db, err := NewDatabase(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	accID, err := db.AccountRepo.Create(ctx, &domain.Account{Platform: "twitter", Name: "main", CookiePath: "/tmp/cookies.txt"})
+	if err != nil {
+		t.Fatalf("failed to create account: %v", err)
+	}
+	id, err := db.DownloadRepo.Create(ctx, &domain.Download{URL: "https://x.com/user/status/1", Status: domain.StatusPending})
+	if err != nil {
+		t.Fatalf("failed to create download: %v", err)
+	}
+
+	if err := db.DownloadRepo.UpdateUsedAccount(ctx, id, accID); err != nil {
+		t.Fatalf("UpdateUsedAccount() error: %v", err)
+	}
+	dl, _ := db.DownloadRepo.GetByID(ctx, id)
+	if dl.UsedAccountID == nil || *dl.UsedAccountID != accID {
+		t.Errorf("UsedAccountID = %v, want %d", dl.UsedAccountID, accID)
+	}
+	// La cuenta usada no fija la descarga a esa cuenta
+	if dl.AccountID != nil {
+		t.Errorf("AccountID = %v, want nil", *dl.AccountID)
+	}
+}
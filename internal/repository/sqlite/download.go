@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -28,17 +29,30 @@ func NewDownloadRepository(db *sqlx.DB) *DownloadRepository {
 
 // downloadRow mapea la tabla SQL a struct Go
 type downloadRow struct {
-	ID           int64          `db:"id"`
-	URL          string         `db:"url"`
-	Platform     sql.NullString `db:"platform"`
-	Username     sql.NullString `db:"username"`
-	Status       string         `db:"status"`
-	OutputPath   sql.NullString `db:"output_path"`
-	OptionsJSON  string         `db:"options"`
-	AccountID    sql.NullInt64  `db:"account_id"`
-	CreatedAt    int64          `db:"created_at"`
-	CompletedAt  sql.NullInt64  `db:"completed_at"`
-	ErrorMessage sql.NullString `db:"error_message"`
+	ID             int64          `db:"id"`
+	URL            string         `db:"url"`
+	Platform       sql.NullString `db:"platform"`
+	Username       sql.NullString `db:"username"`
+	Status         string         `db:"status"`
+	OutputPath     sql.NullString `db:"output_path"`
+	FilesJSON      sql.NullString `db:"files"`
+	TagsJSON       sql.NullString `db:"tags"`
+	OptionsJSON    string         `db:"options"`
+	AccountID      sql.NullInt64  `db:"account_id"`
+	UsedAccountID  sql.NullInt64  `db:"used_account_id"`
+	Priority       int            `db:"priority"`
+	Progress       int            `db:"progress"`
+	FileSize       int64          `db:"file_size"`
+	ScheduledAfter sql.NullInt64  `db:"scheduled_after"`
+	CreatedAt      int64          `db:"created_at"`
+	CompletedAt    sql.NullInt64  `db:"completed_at"`
+	ErrorMessage   sql.NullString `db:"error_message"`
+	ErrorType      sql.NullString `db:"error_type"`
+	Backend        sql.NullString `db:"backend"`
+	ParentID       sql.NullInt64  `db:"parent_id"`
+	Title          sql.NullString `db:"title"`
+	Uploader       sql.NullString `db:"uploader"`
+	UploadDate     sql.NullString `db:"upload_date"`
 }
 
 // Create inserta una nueva descarga
@@ -48,18 +62,27 @@ func (r *DownloadRepository) Create(ctx context.Context, dl *domain.Download) (i
 		return 0, fmt.Errorf("marshal options: %w", err)
 	}
 
+	tagsJSON, err := marshalList("tags", dl.Tags)
+	if err != nil {
+		return 0, err
+	}
+
 	query := `
-		INSERT INTO downloads (url, platform, username, status, options, account_id)
-		VALUES (:url, :platform, :username, :status, :options, :account_id)
+		INSERT INTO downloads (url, platform, username, status, options, account_id, priority, tags, scheduled_after, parent_id)
+		VALUES (:url, :platform, :username, :status, :options, :account_id, :priority, :tags, :scheduled_after, :parent_id)
 	`
 
 	result, err := r.db.NamedExecContext(ctx, query, map[string]interface{}{
-		"url":        dl.URL,
-		"platform":   dl.Platform,
-		"username":   dl.Username,
-		"status":     string(dl.Status),
-		"options":    string(optJSON),
-		"account_id": dl.AccountID,
+		"url":             dl.URL,
+		"platform":        dl.Platform,
+		"username":        dl.Username,
+		"status":          string(dl.Status),
+		"options":         string(optJSON),
+		"account_id":      dl.AccountID,
+		"priority":        dl.Priority,
+		"tags":            tagsJSON,
+		"scheduled_after": unixOrNil(dl.ScheduledAfter),
+		"parent_id":       dl.ParentID,
 	})
 
 	if err != nil {
@@ -96,31 +119,49 @@ func (r *DownloadRepository) Update(ctx context.Context, dl *domain.Download) er
 		return fmt.Errorf("marshal options: %w", err)
 	}
 
-	var completedAt interface{}
-	if dl.CompletedAt != nil {
-		completedAt = dl.CompletedAt.Unix()
+	filesJSON, err := marshalList("files", dl.Files)
+	if err != nil {
+		return err
+	}
+
+	tagsJSON, err := marshalList("tags", dl.Tags)
+	if err != nil {
+		return err
 	}
 
 	query := `
 		UPDATE downloads
 		SET url = :url, platform = :platform, username = :username,
-		    status = :status, output_path = :output_path, options = :options,
-		    account_id = :account_id, completed_at = :completed_at,
-		    error_message = :error_message
+		    status = :status, output_path = :output_path, files = :files,
+		    options = :options, account_id = :account_id, used_account_id = :used_account_id, priority = :priority, tags = :tags,
+		    scheduled_after = :scheduled_after, completed_at = :completed_at,
+		    error_message = :error_message, error_type = :error_type, backend = :backend, parent_id = :parent_id,
+		    title = :title, uploader = :uploader, upload_date = :upload_date
 		WHERE id = :id
 	`
 
 	_, err = r.db.NamedExecContext(ctx, query, map[string]interface{}{
-		"id":            dl.ID,
-		"url":           dl.URL,
-		"platform":      dl.Platform,
-		"username":      dl.Username,
-		"status":        string(dl.Status),
-		"output_path":   dl.OutputPath,
-		"options":       string(optJSON),
-		"account_id":    dl.AccountID,
-		"completed_at":  completedAt,
-		"error_message": dl.ErrorMessage,
+		"id":              dl.ID,
+		"url":             dl.URL,
+		"platform":        dl.Platform,
+		"username":        dl.Username,
+		"status":          string(dl.Status),
+		"output_path":     dl.OutputPath,
+		"files":           filesJSON,
+		"options":         string(optJSON),
+		"account_id":      dl.AccountID,
+		"used_account_id": dl.UsedAccountID,
+		"priority":        dl.Priority,
+		"tags":            tagsJSON,
+		"scheduled_after": unixOrNil(dl.ScheduledAfter),
+		"completed_at":    unixOrNil(dl.CompletedAt),
+		"error_message":   dl.ErrorMessage,
+		"error_type":      nullIfEmpty(dl.ErrorType),
+		"backend":         nullIfEmpty(dl.Backend),
+		"parent_id":       dl.ParentID,
+		"title":           nullIfEmpty(dl.Title),
+		"uploader":        nullIfEmpty(dl.Uploader),
+		"upload_date":     nullIfEmpty(dl.UploadDate),
 	})
 
 	return err
@@ -133,9 +174,42 @@ func (r *DownloadRepository) Delete(ctx context.Context, id int64) error {
 	return err
 }
 
-// GetPending obtiene todas las descargas pendientes
+// DeleteOlderThan elimina las descargas con el status dado terminadas antes de cutoff y
+// retorna cuántas se eliminaron. Las descargas en cola o en curso nunca se eliminan
+func (r *DownloadRepository) DeleteOlderThan(ctx context.Context, status domain.DownloadStatus, cutoff time.Time) (int64, error) {
+	if status != domain.StatusCompleted && status != domain.StatusFailed {
+		return 0, fmt.Errorf("cannot delete %s downloads", status)
+	}
+
+	query := `DELETE FROM downloads WHERE status = ? AND completed_at < ?`
+	result, err := r.db.ExecContext(ctx, query, string(status), cutoff.Unix())
+	if err != nil {
+		return 0, fmt.Errorf("delete old downloads: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("get rows affected: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// GetPending obtiene todas las descargas pendientes, por prioridad (mayor primero) y luego FIFO
 func (r *DownloadRepository) GetPending(ctx context.Context) ([]*domain.Download, error) {
-	return r.GetByStatus(ctx, domain.StatusPending)
+	var rows []downloadRow
+
+	query := `
+		SELECT * FROM downloads
+		WHERE status = ?
+		ORDER BY priority DESC, created_at ASC, id ASC
+	`
+
+	if err := r.db.SelectContext(ctx, &rows, query, string(domain.StatusPending)); err != nil {
+		return nil, fmt.Errorf("get pending downloads: %w", err)
+	}
+
+	return rowsToDomain(rows)
 }
 
 // GetActive obtiene descargas en proceso
@@ -172,6 +246,75 @@ func (r *DownloadRepository) GetRecent(ctx context.Context, limit int) ([]*domai
 	return rowsToDomain(rows)
 }
 
+// Query obtiene las descargas que cumplen el filtro (más recientes primero) y el total
+// de coincidencias sin paginar
+func (r *DownloadRepository) Query(ctx context.Context, filter repository.DownloadFilter) ([]*domain.Download, int, error) {
+	var conditions []string
+	var args []interface{}
+
+	if filter.Platform != "" {
+		conditions = append(conditions, "platform = ?")
+		args = append(args, filter.Platform)
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, string(filter.Status))
+	}
+	if filter.Tag != "" {
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM json_each(downloads.tags) WHERE value = ?)")
+		args = append(args, filter.Tag)
+	}
+	if filter.Since != nil {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, filter.Since.Unix())
+	}
+	if filter.Until != nil {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, filter.Until.Unix())
+	}
+	if filter.Finished != nil {
+		conditions = append(conditions, "completed_at < ?")
+		args = append(args, filter.Finished.Unix())
+	}
+	if filter.ParentID != 0 {
+		conditions = append(conditions, "parent_id = ?")
+		args = append(args, filter.ParentID)
+	}
+	if filter.TopLevel {
+		conditions = append(conditions, "parent_id IS NULL")
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := r.db.GetContext(ctx, &total, "SELECT COUNT(*) FROM downloads "+where, args...); err != nil {
+		return nil, 0, fmt.Errorf("count downloads: %w", err)
+	}
+
+	// LIMIT -1 = sin límite en SQLite (OFFSET requiere LIMIT)
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = -1
+	}
+
+	query := "SELECT * FROM downloads " + where + " ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?"
+
+	var rows []downloadRow
+	if err := r.db.SelectContext(ctx, &rows, query, append(args, limit, filter.Offset)...); err != nil {
+		return nil, 0, fmt.Errorf("query downloads: %w", err)
+	}
+
+	downloads, err := rowsToDomain(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return downloads, total, nil
+}
+
 // GetByStatus obtiene descargas por status
 func (r *DownloadRepository) GetByStatus(ctx context.Context, status domain.DownloadStatus) ([]*domain.Download, error) {
 	var rows []downloadRow
@@ -184,7 +327,30 @@ func (r *DownloadRepository) GetByStatus(ctx context.Context, status domain.Down
 	return rowsToDomain(rows)
 }
 
-// UpdateStatus actualiza solo el status y mensaje de error
+// GetByURL obtiene la descarga no fallida más reciente con la URL dada.
+// Retorna nil si no existe ninguna (no es error)
+func (r *DownloadRepository) GetByURL(ctx context.Context, url string) (*domain.Download, error) {
+	var row downloadRow
+
+	query := `
+		SELECT * FROM downloads
+		WHERE url = ? AND status != 'failed'
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1
+	`
+
+	if err := r.db.GetContext(ctx, &row, query, url); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get download by url: %w", err)
+	}
+
+	return rowToDomain(&row)
+}
+
+// UpdateStatus actualiza solo el status y mensaje de error (el error_type queda sin clasificar
+// y el progress vuelve a 0 para la nueva fase)
 func (r *DownloadRepository) UpdateStatus(ctx context.Context, id int64, status domain.DownloadStatus, errMsg string) error {
 	var completedAt interface{}
 	if status == domain.StatusCompleted || status == domain.StatusFailed {
@@ -193,7 +359,7 @@ func (r *DownloadRepository) UpdateStatus(ctx context.Context, id int64, status
 
 	query := `
 		UPDATE downloads
-		SET status = ?, error_message = ?, completed_at = ?
+		SET status = ?, error_message = ?, error_type = NULL, progress = 0, completed_at = ?
 		WHERE id = ?
 	`
 
@@ -201,6 +367,51 @@ func (r *DownloadRepository) UpdateStatus(ctx context.Context, id int64, status
 	return err
 }
 
+// MarkFailed marca la descarga como fallida con un error clasificado
+func (r *DownloadRepository) MarkFailed(ctx context.Context, id int64, errType, errMsg string) error {
+	query := `
+		UPDATE downloads
+		SET status = ?, error_message = ?, error_type = ?, completed_at = ?
+		WHERE id = ?
+	`
+
+	_, err := r.db.ExecContext(ctx, query, string(domain.StatusFailed), errMsg, nullIfEmpty(errType), time.Now().Unix(), id)
+	return err
+}
+
+// RequeueFailed vuelve a poner en pendiente las descargas fallidas, opcionalmente solo las
+// de una plataforma y/o un tipo de error ("" = todas). Retorna cuántas se re-encolaron
+func (r *DownloadRepository) RequeueFailed(ctx context.Context, platform, errType string) (int64, error) {
+	conditions := []string{"status = ?"}
+	args := []interface{}{string(domain.StatusPending), string(domain.StatusFailed)}
+
+	if platform != "" {
+		conditions = append(conditions, "platform = ?")
+		args = append(args, platform)
+	}
+	if errType != "" {
+		conditions = append(conditions, "error_type = ?")
+		args = append(args, errType)
+	}
+
+	query := `
+		UPDATE downloads
+		SET status = ?, error_message = '', error_type = NULL, progress = 0, completed_at = NULL
+		WHERE ` + strings.Join(conditions, " AND ")
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("requeue failed downloads: %w", err)
+	}
+
+	requeued, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("get rows affected: %w", err)
+	}
+
+	return requeued, nil
+}
+
 // UpdateOutputPath actualiza solo el path de salida
 func (r *DownloadRepository) UpdateOutputPath(ctx context.Context, id int64, path string) error {
 	query := `UPDATE downloads SET output_path = ? WHERE id = ?`
@@ -208,6 +419,83 @@ func (r *DownloadRepository) UpdateOutputPath(ctx context.Context, id int64, pat
 	return err
 }
 
+// UpdateFiles actualiza la lista de archivos descargados
+func (r *DownloadRepository) UpdateFiles(ctx context.Context, id int64, files []string) error {
+	filesJSON, err := marshalList("files", files)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE downloads SET files = ? WHERE id = ?`
+	_, err = r.db.ExecContext(ctx, query, filesJSON, id)
+	return err
+}
+
+// UpdateMetadata guarda título, autor y fecha de publicación extraídos por el downloader
+func (r *DownloadRepository) UpdateMetadata(ctx context.Context, id int64, title, uploader, uploadDate string) error {
+	query := `UPDATE downloads SET title = ?, uploader = ?, upload_date = ? WHERE id = ?`
+	if _, err := r.db.ExecContext(ctx, query, nullIfEmpty(title), nullIfEmpty(uploader), nullIfEmpty(uploadDate), id); err != nil {
+		return fmt.Errorf("update metadata: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateBackend guarda el downloader que ejecutó la descarga
+func (r *DownloadRepository) UpdateBackend(ctx context.Context, id int64, backend string) error {
+	query := `UPDATE downloads SET backend = ? WHERE id = ?`
+	if _, err := r.db.ExecContext(ctx, query, nullIfEmpty(backend), id); err != nil {
+		return fmt.Errorf("update backend: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateUsedAccount registra la cuenta cuyas cookies usa el intento en curso (no la fija)
+func (r *DownloadRepository) UpdateUsedAccount(ctx context.Context, id int64, accountID int64) error {
+	query := `UPDATE downloads SET used_account_id = ? WHERE id = ?`
+	if _, err := r.db.ExecContext(ctx, query, accountID, id); err != nil {
+		return fmt.Errorf("update account: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateProgress guarda el avance (0-100) de la fase en curso
+func (r *DownloadRepository) UpdateProgress(ctx context.Context, id int64, percent int) error {
+	query := `UPDATE downloads SET progress = ? WHERE id = ?`
+	if _, err := r.db.ExecContext(ctx, query, percent, id); err != nil {
+		return fmt.Errorf("update progress: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateFileSize guarda el tamaño en bytes de los archivos finales
+func (r *DownloadRepository) UpdateFileSize(ctx context.Context, id int64, size int64) error {
+	query := `UPDATE downloads SET file_size = ? WHERE id = ?`
+	if _, err := r.db.ExecContext(ctx, query, size, id); err != nil {
+		return fmt.Errorf("update file size: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateTags reemplaza las etiquetas de una descarga
+func (r *DownloadRepository) UpdateTags(ctx context.Context, id int64, tags []string) error {
+	tagsJSON, err := marshalList("tags", tags)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE downloads SET tags = ? WHERE id = ?`
+	if _, err := r.db.ExecContext(ctx, query, tagsJSON, id); err != nil {
+		return fmt.Errorf("update tags: %w", err)
+	}
+
+	return nil
+}
+
 // CountByStatus cuenta descargas por status
 func (r *DownloadRepository) CountByStatus(ctx context.Context, status domain.DownloadStatus) (int, error) {
 	var count int
@@ -224,6 +512,67 @@ func (r *DownloadRepository) CountTotal(ctx context.Context) (int, error) {
 	return count, err
 }
 
+// CountByPlatform cuenta descargas por plataforma y status, con el tamaño total de sus archivos
+func (r *DownloadRepository) CountByPlatform(ctx context.Context) ([]repository.PlatformCount, error) {
+	var counts []repository.PlatformCount
+	query := `
+		SELECT platform, status, COUNT(*) AS count, COALESCE(SUM(file_size), 0) AS bytes
+		FROM downloads
+		GROUP BY platform, status
+		ORDER BY platform, status
+	`
+	if err := r.db.SelectContext(ctx, &counts, query); err != nil {
+		return nil, fmt.Errorf("count by platform: %w", err)
+	}
+
+	return counts, nil
+}
+
+// CountChildren cuenta los items de cada canal/perfil de parentIDs por status. Los que no
+// tienen items no aparecen
+func (r *DownloadRepository) CountChildren(ctx context.Context, parentIDs []int64) ([]repository.ChildCount, error) {
+	if len(parentIDs) == 0 {
+		return nil, nil
+	}
+
+	query, args, err := sqlx.In(`
+		SELECT parent_id, status, COUNT(*) AS count
+		FROM downloads
+		WHERE parent_id IN (?)
+		GROUP BY parent_id, status
+		ORDER BY parent_id, status
+	`, parentIDs)
+	if err != nil {
+		return nil, fmt.Errorf("count children: %w", err)
+	}
+
+	var counts []repository.ChildCount
+	if err := r.db.SelectContext(ctx, &counts, query, args...); err != nil {
+		return nil, fmt.Errorf("count children: %w", err)
+	}
+
+	return counts, nil
+}
+
+// CountFinishedSince cuenta las descargas completadas y fallidas que terminaron desde since
+func (r *DownloadRepository) CountFinishedSince(ctx context.Context, since time.Time) (completed, failed int, err error) {
+	var row struct {
+		Completed int `db:"completed"`
+		Failed    int `db:"failed"`
+	}
+	query := `
+		SELECT COALESCE(SUM(status = ?), 0) AS completed, COALESCE(SUM(status = ?), 0) AS failed
+		FROM downloads
+		WHERE completed_at >= ?
+	`
+	err = r.db.GetContext(ctx, &row, query, string(domain.StatusCompleted), string(domain.StatusFailed), since.Unix())
+	if err != nil {
+		return 0, 0, fmt.Errorf("count finished: %w", err)
+	}
+
+	return row.Completed, row.Failed, nil
+}
+
 // Helper: conversión row → domain
 func rowToDomain(row *downloadRow) (*domain.Download, error) {
 	var opts domain.DownloadOptions
@@ -231,6 +580,20 @@ func rowToDomain(row *downloadRow) (*domain.Download, error) {
 		return nil, fmt.Errorf("unmarshal options: %w", err)
 	}
 
+	var files []string
+	if row.FilesJSON.Valid && row.FilesJSON.String != "" {
+		if err := json.Unmarshal([]byte(row.FilesJSON.String), &files); err != nil {
+			return nil, fmt.Errorf("unmarshal files: %w", err)
+		}
+	}
+
+	var tags []string
+	if row.TagsJSON.Valid && row.TagsJSON.String != "" {
+		if err := json.Unmarshal([]byte(row.TagsJSON.String), &tags); err != nil {
+			return nil, fmt.Errorf("unmarshal tags: %w", err)
+		}
+	}
+
 	dl := &domain.Download{
 		ID:           row.ID,
 		URL:          row.URL,
@@ -238,8 +601,18 @@ func rowToDomain(row *downloadRow) (*domain.Download, error) {
 		Username:     row.Username.String,
 		Status:       domain.DownloadStatus(row.Status),
 		OutputPath:   row.OutputPath.String,
+		Files:        files,
+		Tags:         tags,
 		Options:      opts,
+		Priority:     row.Priority,
+		Progress:     row.Progress,
+		FileSize:     row.FileSize,
 		ErrorMessage: row.ErrorMessage.String,
+		ErrorType:    row.ErrorType.String,
+		Backend:      row.Backend.String,
+		Title:        row.Title.String,
+		Uploader:     row.Uploader.String,
+		UploadDate:   row.UploadDate.String,
 		CreatedAt:    time.Unix(row.CreatedAt, 0),
 	}
 
@@ -247,14 +620,57 @@ func rowToDomain(row *downloadRow) (*domain.Download, error) {
 		dl.AccountID = &row.AccountID.Int64
 	}
 
+	if row.UsedAccountID.Valid {
+		dl.UsedAccountID = &row.UsedAccountID.Int64
+	}
+
+	if row.ParentID.Valid {
+		dl.ParentID = &row.ParentID.Int64
+	}
+
 	if row.CompletedAt.Valid {
 		t := time.Unix(row.CompletedAt.Int64, 0)
 		dl.CompletedAt = &t
 	}
 
+	if row.ScheduledAfter.Valid {
+		t := time.Unix(row.ScheduledAfter.Int64, 0)
+		dl.ScheduledAfter = &t
+	}
+
 	return dl, nil
 }
 
+// Helper: convierte un string vacío en NULL
+func nullIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// Helper: convierte un timestamp opcional a unix (NULL si es nil)
+func unixOrNil(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.Unix()
+}
+
+// Helper: serializa una lista de strings como JSON (NULL si está vacía)
+func marshalList(name string, items []string) (interface{}, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(items)
+	if err != nil {
+		return nil, fmt.Errorf("marshal %s: %w", name, err)
+	}
+
+	return string(data), nil
+}
+
 // Helper: conversión múltiples rows → domain
 func rowsToDomain(rows []downloadRow) ([]*domain.Download, error) {
 	downloads := make([]*domain.Download, 0, len(rows))
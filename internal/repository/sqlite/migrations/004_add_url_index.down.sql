@@ -0,0 +1,2 @@
+-- Rollback índice de URL
+DROP INDEX IF EXISTS idx_downloads_url;
@@ -0,0 +1,2 @@
+-- Downloads: buscar duplicados por URL
+CREATE INDEX idx_downloads_url ON downloads(url);
@@ -0,0 +1,2 @@
+-- Lista de archivos descargados (JSON array) para galerías con múltiples archivos
+ALTER TABLE downloads ADD COLUMN files TEXT;
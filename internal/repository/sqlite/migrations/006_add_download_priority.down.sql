@@ -0,0 +1,3 @@
+-- Rollback prioridad (DROP COLUMN requiere SQLite >= 3.35)
+DROP INDEX IF EXISTS idx_downloads_pending_priority;
+ALTER TABLE downloads DROP COLUMN priority;
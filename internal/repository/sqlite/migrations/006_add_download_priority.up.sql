@@ -0,0 +1,6 @@
+-- Prioridad de la descarga: las pendientes se despachan por prioridad (mayor primero) y luego FIFO
+ALTER TABLE downloads ADD COLUMN priority INTEGER NOT NULL DEFAULT 0;
+
+-- Downloads: cola de pendientes ordenada por prioridad
+CREATE INDEX idx_downloads_pending_priority ON downloads(priority DESC, created_at ASC)
+WHERE status = 'pending';
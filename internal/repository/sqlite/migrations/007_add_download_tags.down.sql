@@ -0,0 +1,2 @@
+-- Rollback etiquetas (DROP COLUMN requiere SQLite >= 3.35)
+ALTER TABLE downloads DROP COLUMN tags;
@@ -0,0 +1,2 @@
+-- Etiquetas de la descarga (JSON array) para agrupar por proyecto
+ALTER TABLE downloads ADD COLUMN tags TEXT;
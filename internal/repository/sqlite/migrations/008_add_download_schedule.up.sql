@@ -0,0 +1,2 @@
+-- Descargas programadas: no se despachan antes de scheduled_after (unix, NULL = en cuanto haya un worker)
+ALTER TABLE downloads ADD COLUMN scheduled_after INTEGER;
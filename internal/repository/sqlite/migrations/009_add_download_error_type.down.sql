@@ -0,0 +1,2 @@
+-- Rollback error_type (DROP COLUMN requiere SQLite >= 3.35)
+ALTER TABLE downloads DROP COLUMN error_type;
@@ -0,0 +1,2 @@
+-- Categoría del error de las descargas fallidas (auth, network, not_found...)
+ALTER TABLE downloads ADD COLUMN error_type TEXT;
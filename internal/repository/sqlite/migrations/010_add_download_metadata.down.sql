@@ -0,0 +1,4 @@
+-- Rollback metadata (DROP COLUMN requiere SQLite >= 3.35)
+ALTER TABLE downloads DROP COLUMN upload_date;
+ALTER TABLE downloads DROP COLUMN uploader;
+ALTER TABLE downloads DROP COLUMN title;
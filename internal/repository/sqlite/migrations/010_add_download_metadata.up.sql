@@ -0,0 +1,4 @@
+-- Metadata del contenido: título, autor y fecha de publicación (YYYY-MM-DD)
+ALTER TABLE downloads ADD COLUMN title TEXT;
+ALTER TABLE downloads ADD COLUMN uploader TEXT;
+ALTER TABLE downloads ADD COLUMN upload_date TEXT;
@@ -0,0 +1,2 @@
+-- Avance (0-100) de la fase en curso, p.ej. la conversión con ffmpeg
+ALTER TABLE downloads ADD COLUMN progress INTEGER NOT NULL DEFAULT 0;
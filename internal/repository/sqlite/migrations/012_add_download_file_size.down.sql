@@ -0,0 +1,2 @@
+-- Rollback file_size (DROP COLUMN requiere SQLite >= 3.35)
+ALTER TABLE downloads DROP COLUMN file_size;
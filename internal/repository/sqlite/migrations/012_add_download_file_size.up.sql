@@ -0,0 +1,2 @@
+-- Tamaño en bytes de los archivos finales, guardado al completarse (0 = desconocido)
+ALTER TABLE downloads ADD COLUMN file_size INTEGER NOT NULL DEFAULT 0;
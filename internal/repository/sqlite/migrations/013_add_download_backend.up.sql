@@ -0,0 +1,2 @@
+-- Downloader que hizo la descarga (yt-dlp o gallery-dl; el del fallback si se usó)
+ALTER TABLE downloads ADD COLUMN backend TEXT;
@@ -0,0 +1,3 @@
+-- Rollback parent_id (DROP COLUMN requiere SQLite >= 3.35)
+DROP INDEX IF EXISTS idx_downloads_parent_id;
+ALTER TABLE downloads DROP COLUMN parent_id;
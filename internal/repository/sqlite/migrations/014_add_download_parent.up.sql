@@ -0,0 +1,3 @@
+-- Descarga de canal/perfil que encoló a esta (NULL = añadida directamente)
+ALTER TABLE downloads ADD COLUMN parent_id INTEGER REFERENCES downloads(id) ON DELETE SET NULL;
+CREATE INDEX idx_downloads_parent_id ON downloads(parent_id);
@@ -0,0 +1,2 @@
+-- Rollback used_account_id (DROP COLUMN requiere SQLite >= 3.35)
+ALTER TABLE downloads DROP COLUMN used_account_id;
@@ -0,0 +1,3 @@
+-- Cuenta cuyas cookies usó el último intento (para status). account_id queda solo para
+-- la cuenta fijada con --account: la usada no se fija para reintentos ni requeue
+ALTER TABLE downloads ADD COLUMN used_account_id INTEGER;
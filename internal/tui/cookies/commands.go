@@ -2,6 +2,9 @@ package cookies
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 
@@ -10,6 +13,10 @@ import (
 	"github.com/elsanchez/smart-download/internal/repository"
 )
 
+// refreshInterval is how often the accounts are reloaded from the database, so changes
+// made by other processes (e.g. the daemon's cookie checker) show up
+const refreshInterval = 5 * time.Second
+
 // Async commands that return tea.Msg
 
 func loadAccounts(repo repository.AccountRepository) tea.Cmd {
@@ -34,6 +41,12 @@ func loadAccounts(repo repository.AccountRepository) tea.Cmd {
 	}
 }
 
+func tick() tea.Cmd {
+	return tea.Tick(refreshInterval, func(t time.Time) tea.Msg {
+		return tickMsg(t)
+	})
+}
+
 func loadPlatforms(repo repository.AccountRepository) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
@@ -111,10 +124,47 @@ func activateAccount(repo repository.AccountRepository, platform, name string) t
 	}
 }
 
-func exportAccount(exporter *cookies.CookieExporter, platform, name, outputPath string) tea.Cmd {
+func exportAccount(exporter *cookies.CookieExporter, platform, name, outputPath, format string) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		err := exporter.Export(ctx, platform, name, outputPath)
+		err := exporter.Export(ctx, platform, name, outputPath, format)
 		return exportCompleteMsg{path: outputPath, err: err}
 	}
 }
+
+func editAccount(editor *cookies.CookieEditor, id int64, opts cookies.EditOptions) tea.Cmd {
+	return func() tea.Msg {
+		result, err := editor.Edit(context.Background(), id, opts)
+		return editCompleteMsg{result: result, err: err}
+	}
+}
+
+// extractAndImport reads the cookies of domain from browser into a temp file and imports it
+func extractAndImport(extractor *cookies.BrowserExtractor, importer *cookies.CookieImporter, browser, domain string, opts cookies.ImportOptions) tea.Cmd {
+	return func() tea.Msg {
+		tmp, err := os.CreateTemp("", "smd-cookies-*.txt")
+		if err != nil {
+			return importCompleteMsg{err: fmt.Errorf("create temp file: %w", err)}
+		}
+		tmp.Close()
+		defer os.Remove(tmp.Name())
+
+		extracted, err := extractor.Extract(cookies.ExtractOptions{
+			Browser:    browser,
+			Domain:     domain,
+			OutputPath: tmp.Name(),
+		})
+		if err != nil {
+			return importCompleteMsg{err: err}
+		}
+
+		// The importer copies the file to the cookies dir, so the temp file can go
+		opts.FilePath = tmp.Name()
+		account, err := importer.Import(context.Background(), opts)
+		if err != nil {
+			return importCompleteMsg{err: fmt.Errorf("import: %w", err)}
+		}
+
+		return importCompleteMsg{account: account, browser: browser, extracted: len(extracted)}
+	}
+}
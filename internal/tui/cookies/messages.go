@@ -1,6 +1,11 @@
 package cookies
 
-import "github.com/elsanchez/smart-download/internal/domain"
+import (
+	"time"
+
+	"github.com/elsanchez/smart-download/internal/cookies"
+	"github.com/elsanchez/smart-download/internal/domain"
+)
 
 // Message types for async operations
 
@@ -9,14 +14,18 @@ type accountsLoadedMsg struct {
 	err      error
 }
 
+type tickMsg time.Time
+
 type platformsLoadedMsg struct {
 	platforms []string
 	err       error
 }
 
 type importCompleteMsg struct {
-	account *domain.Account
-	err     error
+	account   *domain.Account
+	browser   string // Set when imported from a browser
+	extracted int    // Number of cookies extracted from the browser
+	err       error
 }
 
 type validationCompleteMsg struct {
@@ -43,6 +52,11 @@ type exportCompleteMsg struct {
 	err  error
 }
 
+type editCompleteMsg struct {
+	result *cookies.EditResult
+	err    error
+}
+
 type errorMsg struct {
 	err error
 }
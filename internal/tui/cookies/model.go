@@ -1,6 +1,11 @@
 package cookies
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
@@ -18,9 +23,26 @@ const (
 	viewList view = iota
 	viewImport
 	viewValidation
+	viewExport
+	viewEdit
 	viewHelp
 )
 
+// importMode is the source of the cookies in the import form
+type importMode int
+
+const (
+	importFromFile importMode = iota
+	importFromBrowser
+)
+
+// editFieldCount is the number of fields in the edit form: platform, name, cookie path
+const editFieldCount = 3
+
+// importFieldCount is the number of focusable import fields: source, platform, name
+// and the activate/validate checkboxes
+const importFieldCount = 5
+
 // Model is the Bubbletea model for the cookie manager
 type Model struct {
 	// Navigation
@@ -34,6 +56,8 @@ type Model struct {
 	importer    *cookies.CookieImporter
 	validator   *cookies.CookieValidator
 	exporter    *cookies.CookieExporter
+	editor      *cookies.CookieEditor
+	extractor   *cookies.BrowserExtractor
 
 	// State
 	accounts        []*domain.Account
@@ -44,11 +68,22 @@ type Model struct {
 	// Components
 	accountList   list.Model
 	pathInput     textinput.Model
+	domainInput   textinput.Model
 	platformInput textinput.Model
 	nameInput     textinput.Model
+	exportInput   textinput.Model
+	editInputs    [editFieldCount]textinput.Model // Platform, name, cookie path
+	searchInput   textinput.Model
 	spinner       spinner.Model
 
+	// Search state
+	searching bool // Typing in the search box
+
 	// Import state
+	importMode   importMode
+	browsers     []string
+	browserIndex int
+
 	importPath      string
 	importPlatform  string
 	importName      string
@@ -56,17 +91,31 @@ type Model struct {
 	importValidate  bool
 	importFocusedField int
 
+	// Export state
+	exportAccount   *domain.Account
+	exportFormat    int    // Index in cookies.ExportFormats
+	exportOverwrite string // Path the user already confirmed to overwrite
+
+	// Edit state
+	editAccount      *domain.Account
+	editFocusedField int
+
+	// Contents of the cookie files, parsed on demand for the selected account
+	cookieSummaries map[string]*cookieSummary
+
 	// Validation state
 	validationResults map[int64]*validationResult
+	validating        map[int64]bool // Accounts being validated on their own (r/R)
 
 	// UI state
 	loading       bool
+	loadingText   string // Replaces "Loading..." for long operations
 	statusMessage string
 	errorMessage  string
 }
 
 // NewModel creates a new cookie manager TUI model
-func NewModel(accountRepo repository.AccountRepository) Model {
+func NewModel(accountRepo repository.AccountRepository, validator *cookies.CookieValidator) Model {
 	// Create text inputs
 	pathInput := textinput.New()
 	pathInput.Placeholder = "Path to cookie file"
@@ -74,6 +123,11 @@ func NewModel(accountRepo repository.AccountRepository) Model {
 	pathInput.CharLimit = 256
 	pathInput.Width = 60
 
+	domainInput := textinput.New()
+	domainInput.Placeholder = "Cookie domain (e.g. instagram.com)"
+	domainInput.CharLimit = 100
+	domainInput.Width = 40
+
 	platformInput := textinput.New()
 	platformInput.Placeholder = "Platform (auto-detect if empty)"
 	platformInput.CharLimit = 50
@@ -84,11 +138,32 @@ func NewModel(accountRepo repository.AccountRepository) Model {
 	nameInput.CharLimit = 50
 	nameInput.Width = 40
 
+	exportInput := textinput.New()
+	exportInput.Placeholder = "Output file path"
+	exportInput.CharLimit = 256
+	exportInput.Width = 60
+
+	var editInputs [editFieldCount]textinput.Model
+	for i, placeholder := range []string{"Platform", "Account name", "Path to cookie file"} {
+		editInputs[i] = textinput.New()
+		editInputs[i].Placeholder = placeholder
+		editInputs[i].CharLimit = 256
+		editInputs[i].Width = 60
+	}
+
+	searchInput := textinput.New()
+	searchInput.Placeholder = "platform or name"
+	searchInput.Prompt = "/ "
+	searchInput.CharLimit = 50
+	searchInput.Width = 40
+
 	// Create spinner
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 	s.Style = spinnerStyle
 
+	extractor := cookies.NewBrowserExtractor()
+
 	// Create list
 	accountList := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
 	accountList.Title = "Cookie Accounts"
@@ -99,14 +174,23 @@ func NewModel(accountRepo repository.AccountRepository) Model {
 		currentView:       viewList,
 		accountRepo:       accountRepo,
 		importer:          cookies.NewCookieImporter(accountRepo),
-		validator:         cookies.NewCookieValidator(),
+		validator:         validator,
 		exporter:          cookies.NewCookieExporter(accountRepo),
+		editor:            cookies.NewCookieEditor(accountRepo),
+		extractor:         extractor,
+		browsers:          extractor.SupportedBrowsers(),
 		accountList:       accountList,
 		pathInput:         pathInput,
+		domainInput:       domainInput,
 		platformInput:     platformInput,
 		nameInput:         nameInput,
+		exportInput:       exportInput,
+		editInputs:        editInputs,
+		searchInput:       searchInput,
 		spinner:           s,
 		validationResults: make(map[int64]*validationResult),
+		validating:        make(map[int64]bool),
+		cookieSummaries:   make(map[string]*cookieSummary),
 		importActivate:    false,
 		importValidate:    true,
 	}
@@ -117,10 +201,112 @@ func (m Model) Init() tea.Cmd {
 	return tea.Batch(
 		loadAccounts(m.accountRepo),
 		loadPlatforms(m.accountRepo),
+		tick(),
 		m.spinner.Tick,
 	)
 }
 
+// visibleAccounts returns the accounts matching the search query (all when empty)
+func (m Model) visibleAccounts() []*domain.Account {
+	query := strings.ToLower(strings.TrimSpace(m.searchInput.Value()))
+	if query == "" {
+		return m.accounts
+	}
+
+	var visible []*domain.Account
+	for _, acc := range m.accounts {
+		if strings.Contains(strings.ToLower(accountItem{account: acc}.FilterValue()), query) {
+			visible = append(visible, acc)
+		}
+	}
+	return visible
+}
+
+// currentAccount returns the account under the cursor in the visible list
+func (m Model) currentAccount() (*domain.Account, bool) {
+	visible := m.visibleAccounts()
+	if m.cursor < 0 || m.cursor >= len(visible) {
+		return nil, false
+	}
+	return visible[m.cursor], true
+}
+
+// setAccounts replaces the accounts keeping the cursor on the same account when it's
+// still there, since a reload can add or remove accounts above it
+func (m *Model) setAccounts(accounts []*domain.Account) {
+	current, ok := m.currentAccount()
+	m.accounts = accounts
+
+	if ok {
+		for i, acc := range m.visibleAccounts() {
+			if acc.ID == current.ID {
+				m.cursor = i
+				break
+			}
+		}
+	}
+	m.clampCursor()
+}
+
+// clampCursor keeps the cursor inside the visible list after it shrinks
+func (m *Model) clampCursor() {
+	if n := len(m.visibleAccounts()); m.cursor >= n {
+		m.cursor = n - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+}
+
+// cookieSummary is what an account's cookie file contains
+type cookieSummary struct {
+	modTime time.Time // The file is parsed again when it changes (e.g. re-imported)
+	count   int
+	domains []string // Most cookies first
+	err     error
+}
+
+// cookieSummaryFor parses the account's cookie file, reusing the cached result while the
+// file is unchanged so moving through the list stays snappy
+func (m Model) cookieSummaryFor(acc *domain.Account) *cookieSummary {
+	info, err := os.Stat(acc.CookiePath)
+	if err != nil {
+		return &cookieSummary{err: err}
+	}
+	if cached, ok := m.cookieSummaries[acc.CookiePath]; ok && cached.modTime.Equal(info.ModTime()) {
+		return cached
+	}
+
+	summary := &cookieSummary{modTime: info.ModTime()}
+	parser := cookies.NewCookieParser()
+	if parsed, err := parser.ParseFile(acc.CookiePath); err != nil {
+		summary.err = err
+	} else {
+		summary.count = parser.CountCookies(parsed)
+		summary.domains = parser.GetDomains(parsed)
+	}
+
+	m.cookieSummaries[acc.CookiePath] = summary
+	return summary
+}
+
+// exportExtensions are the file extensions of the export formats
+var exportExtensions = map[string]string{
+	cookies.FormatNetscape: ".txt",
+	cookies.FormatJSON:     ".json",
+}
+
+// defaultExportPath returns the suggested export file for an account, under the home dir
+func defaultExportPath(acc *domain.Account, format string) string {
+	filename := "cookies_" + acc.Platform + "_" + acc.Name + exportExtensions[format]
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filename
+	}
+	return filepath.Join(homeDir, filename)
+}
+
 // accountItem implements list.Item for the account list
 type accountItem struct {
 	account *domain.Account
@@ -1,11 +1,17 @@
 package cookies
 
 import (
+	"fmt"
+	"os"
+	"strings"
+
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 
+	"github.com/elsanchez/smart-download/internal/config"
 	"github.com/elsanchez/smart-download/internal/cookies"
+	"github.com/elsanchez/smart-download/internal/domain"
 )
 
 // Update handles messages and updates the model
@@ -28,16 +34,19 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case accountsLoadedMsg:
-		m.loading = false
+		// Doesn't touch m.loading: periodic reloads arrive while other operations run
 		if msg.err != nil {
 			m.errorMessage = msg.err.Error()
 			return m, nil
 		}
-		m.accounts = msg.accounts
+		m.setAccounts(msg.accounts)
 		return m, nil
 
+	case tickMsg:
+		// Periodic refresh; picks up accounts validated, imported or deleted elsewhere
+		return m, tea.Batch(loadAccounts(m.accountRepo), loadPlatforms(m.accountRepo), tick())
+
 	case platformsLoadedMsg:
-		m.loading = false
 		if msg.err != nil {
 			m.errorMessage = msg.err.Error()
 			return m, nil
@@ -47,11 +56,16 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case importCompleteMsg:
 		m.loading = false
+		m.loadingText = ""
 		if msg.err != nil {
 			m.errorMessage = msg.err.Error()
 			return m, nil
 		}
 		m.statusMessage = "✓ Cookie imported successfully"
+		if msg.browser != "" {
+			m.statusMessage = fmt.Sprintf("✓ Imported %d cookies from %s as %s/%s",
+				msg.extracted, msg.browser, msg.account.Platform, msg.account.Name)
+		}
 		m.currentView = viewList
 		return m, tea.Batch(
 			loadAccounts(m.accountRepo),
@@ -59,10 +73,17 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		)
 
 	case validationCompleteMsg:
+		// A single account validated with r/R reports in the list instead of the results view
+		if result, ok := m.singleValidation(msg); ok {
+			delete(m.validating, result.AccountID)
+			m.reportValidation(result)
+			return m, loadAccounts(m.accountRepo)
+		}
+
 		m.loading = false
 		m.validationResults = msg.results
 		m.currentView = viewValidation
-		return m, nil
+		return m, loadAccounts(m.accountRepo)
 
 	case deleteCompleteMsg:
 		m.loading = false
@@ -71,7 +92,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 		m.statusMessage = "✓ Account deleted"
-		return m, loadAccounts(m.accountRepo)
+		return m, tea.Batch(loadAccounts(m.accountRepo), loadPlatforms(m.accountRepo))
 
 	case activateCompleteMsg:
 		m.loading = false
@@ -82,6 +103,24 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.statusMessage = "✓ Account activated"
 		return m, loadAccounts(m.accountRepo)
 
+	case editCompleteMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.errorMessage = msg.err.Error()
+			return m, nil
+		}
+		acc := msg.result.Account
+		m.statusMessage = fmt.Sprintf("✓ Account updated: %s/%s", acc.Platform, acc.Name)
+		m.currentView = viewList
+		cmds := []tea.Cmd{loadAccounts(m.accountRepo), loadPlatforms(m.accountRepo)}
+
+		// A different cookie file needs checking; reported like r in the list
+		if msg.result.PathChanged && !m.validating[acc.ID] {
+			m.validating[acc.ID] = true
+			cmds = append(cmds, validateAccounts(m.validator, m.accountRepo, []*domain.Account{acc}, false))
+		}
+		return m, tea.Batch(cmds...)
+
 	case exportCompleteMsg:
 		m.loading = false
 		if msg.err != nil {
@@ -89,6 +128,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 		m.statusMessage = "✓ Exported to " + msg.path
+		m.currentView = viewList
 		return m, nil
 
 	case spinner.TickMsg:
@@ -101,7 +141,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case viewImport:
 		switch m.importFocusedField {
 		case 0:
-			m.pathInput, cmd = m.pathInput.Update(msg)
+			if m.importMode == importFromBrowser {
+				m.domainInput, cmd = m.domainInput.Update(msg)
+			} else {
+				m.pathInput, cmd = m.pathInput.Update(msg)
+			}
 			cmds = append(cmds, cmd)
 		case 1:
 			m.platformInput, cmd = m.platformInput.Update(msg)
@@ -110,6 +154,17 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.nameInput, cmd = m.nameInput.Update(msg)
 			cmds = append(cmds, cmd)
 		}
+	case viewExport:
+		m.exportInput, cmd = m.exportInput.Update(msg)
+		cmds = append(cmds, cmd)
+	case viewEdit:
+		m.editInputs[m.editFocusedField], cmd = m.editInputs[m.editFocusedField].Update(msg)
+		cmds = append(cmds, cmd)
+	case viewList:
+		if m.searching {
+			m.searchInput, cmd = m.searchInput.Update(msg)
+			cmds = append(cmds, cmd)
+		}
 	}
 
 	return m, tea.Batch(cmds...)
@@ -119,9 +174,16 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch m.currentView {
 	case viewList:
+		if m.searching {
+			return m.handleSearchKeys(msg)
+		}
 		return m.handleListKeys(msg)
 	case viewImport:
 		return m.handleImportKeys(msg)
+	case viewExport:
+		return m.handleExportKeys(msg)
+	case viewEdit:
+		return m.handleEditKeys(msg)
 	case viewValidation, viewHelp:
 		return m.handleDialogKeys(msg)
 	}
@@ -142,18 +204,25 @@ func (m Model) handleListKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case key.Matches(msg, key.NewBinding(key.WithKeys("down", "j"))):
-		if m.cursor < len(m.accounts)-1 {
+		if m.cursor < len(m.visibleAccounts())-1 {
 			m.cursor++
 		}
 		return m, nil
 
 	case key.Matches(msg, key.NewBinding(key.WithKeys("i"))):
-		// Import
+		// Import from file
 		m.currentView = viewImport
+		m.importMode = importFromFile
 		m.importFocusedField = 0
-		m.pathInput.Focus()
-		m.platformInput.Blur()
-		m.nameInput.Blur()
+		m.updateImportFocus()
+		return m, nil
+
+	case key.Matches(msg, key.NewBinding(key.WithKeys("b"))):
+		// Import from browser
+		m.currentView = viewImport
+		m.importMode = importFromBrowser
+		m.importFocusedField = 0
+		m.updateImportFocus()
 		return m, nil
 
 	case key.Matches(msg, key.NewBinding(key.WithKeys("v"))):
@@ -166,10 +235,17 @@ func (m Model) handleListKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.loading = true
 		return m, validateAccounts(m.validator, m.accountRepo, m.accounts, true)
 
+	case key.Matches(msg, key.NewBinding(key.WithKeys("r", "R"))):
+		// Validate only the selected account (R = HTTP); runs alongside other operations
+		if acc, ok := m.currentAccount(); ok && !m.validating[acc.ID] {
+			m.validating[acc.ID] = true
+			return m, validateAccounts(m.validator, m.accountRepo, []*domain.Account{acc}, msg.String() == "R")
+		}
+		return m, nil
+
 	case key.Matches(msg, key.NewBinding(key.WithKeys("a"))):
 		// Activate selected
-		if len(m.accounts) > 0 && m.cursor < len(m.accounts) {
-			acc := m.accounts[m.cursor]
+		if acc, ok := m.currentAccount(); ok {
 			m.loading = true
 			return m, activateAccount(m.accountRepo, acc.Platform, acc.Name)
 		}
@@ -177,23 +253,51 @@ func (m Model) handleListKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case key.Matches(msg, key.NewBinding(key.WithKeys("d"))):
 		// Delete selected
-		if len(m.accounts) > 0 && m.cursor < len(m.accounts) {
-			acc := m.accounts[m.cursor]
+		if acc, ok := m.currentAccount(); ok {
 			m.loading = true
 			return m, deleteAccount(m.accountRepo, acc.ID)
 		}
 		return m, nil
 
 	case key.Matches(msg, key.NewBinding(key.WithKeys("e"))):
-		// Export selected (to temp file for demo)
-		if len(m.accounts) > 0 && m.cursor < len(m.accounts) {
-			acc := m.accounts[m.cursor]
-			outputPath := "/tmp/cookie_export_" + acc.Platform + "_" + acc.Name + ".txt"
-			m.loading = true
-			return m, exportAccount(m.exporter, acc.Platform, acc.Name, outputPath)
+		// Export selected (asks for the output path)
+		if acc, ok := m.currentAccount(); ok {
+			m.currentView = viewExport
+			m.exportAccount = acc
+			m.exportFormat = 0
+			m.exportOverwrite = ""
+			m.exportInput.SetValue(defaultExportPath(acc, cookies.ExportFormats[m.exportFormat]))
+			m.exportInput.CursorEnd()
+			m.exportInput.Focus()
 		}
 		return m, nil
 
+	case key.Matches(msg, key.NewBinding(key.WithKeys("E"))):
+		// Edit selected (rename or point at another cookie file)
+		if acc, ok := m.currentAccount(); ok {
+			m.currentView = viewEdit
+			m.editAccount = acc
+			for i, value := range []string{acc.Platform, acc.Name, acc.CookiePath} {
+				m.editInputs[i].SetValue(value)
+				m.editInputs[i].CursorEnd()
+			}
+			m.editFocusedField = 1
+			m.updateEditFocus()
+		}
+		return m, nil
+
+	case key.Matches(msg, key.NewBinding(key.WithKeys("/"))):
+		// Search by platform/name
+		m.searching = true
+		m.searchInput.Focus()
+		return m, nil
+
+	case key.Matches(msg, key.NewBinding(key.WithKeys("esc"))):
+		// Clear search
+		m.searchInput.SetValue("")
+		m.clampCursor()
+		return m, nil
+
 	case key.Matches(msg, key.NewBinding(key.WithKeys("?"))):
 		// Help
 		m.currentView = viewHelp
@@ -203,6 +307,77 @@ func (m Model) handleListKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// singleValidation returns the result of a validation started with r/R for one account
+func (m Model) singleValidation(msg validationCompleteMsg) (*validationResult, bool) {
+	if len(msg.results) != 1 {
+		return nil, false
+	}
+	for id, result := range msg.results {
+		if m.validating[id] {
+			return result, true
+		}
+	}
+	return nil, false
+}
+
+// reportValidation shows the result of validating one account in the status line
+func (m *Model) reportValidation(result *validationResult) {
+	name := "account"
+	for _, acc := range m.accounts {
+		if acc.ID == result.AccountID {
+			name = acc.Platform + "/" + acc.Name
+			break
+		}
+	}
+
+	text := fmt.Sprintf("%s: %s", name, result.Status)
+	if result.Message != "" {
+		text += " - " + result.Message
+	}
+
+	if result.IsValid {
+		m.statusMessage = "✓ " + text
+	} else {
+		m.errorMessage = text
+	}
+}
+
+// handleSearchKeys handles keys while typing in the search box
+func (m Model) handleSearchKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, key.NewBinding(key.WithKeys("esc"))):
+		// Clear search
+		m.searching = false
+		m.searchInput.SetValue("")
+		m.searchInput.Blur()
+		m.clampCursor()
+		return m, nil
+
+	case key.Matches(msg, key.NewBinding(key.WithKeys("enter"))):
+		// Keep the filter and go back to list keys
+		m.searching = false
+		m.searchInput.Blur()
+		return m, nil
+
+	case key.Matches(msg, key.NewBinding(key.WithKeys("up"))):
+		if m.cursor > 0 {
+			m.cursor--
+		}
+		return m, nil
+
+	case key.Matches(msg, key.NewBinding(key.WithKeys("down"))):
+		if m.cursor < len(m.visibleAccounts())-1 {
+			m.cursor++
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.searchInput, cmd = m.searchInput.Update(msg)
+	m.cursor = 0
+	return m, cmd
+}
+
 // handleImportKeys handles keys in the import view
 func (m Model) handleImportKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch {
@@ -210,13 +385,14 @@ func (m Model) handleImportKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		// Cancel import
 		m.currentView = viewList
 		m.pathInput.SetValue("")
+		m.domainInput.SetValue("")
 		m.platformInput.SetValue("")
 		m.nameInput.SetValue("")
 		return m, nil
 
 	case key.Matches(msg, key.NewBinding(key.WithKeys("tab"))):
-		// Next field
-		m.importFocusedField = (m.importFocusedField + 1) % 3
+		// Next field (text inputs, then the checkboxes)
+		m.importFocusedField = (m.importFocusedField + 1) % importFieldCount
 		m.updateImportFocus()
 		return m, nil
 
@@ -224,11 +400,23 @@ func (m Model) handleImportKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		// Previous field
 		m.importFocusedField--
 		if m.importFocusedField < 0 {
-			m.importFocusedField = 2
+			m.importFocusedField = importFieldCount - 1
 		}
 		m.updateImportFocus()
 		return m, nil
 
+	case key.Matches(msg, key.NewBinding(key.WithKeys("up", "down"))):
+		// Pick the browser while on the domain field
+		if m.importMode == importFromBrowser && m.importFocusedField == 0 {
+			n := len(m.browsers)
+			if msg.String() == "up" {
+				m.browserIndex = (m.browserIndex + n - 1) % n
+			} else {
+				m.browserIndex = (m.browserIndex + 1) % n
+			}
+		}
+		return m, nil
+
 	case key.Matches(msg, key.NewBinding(key.WithKeys(" "))):
 		// Toggle checkboxes only - let space pass through to text inputs
 		if m.importFocusedField == 3 {
@@ -241,14 +429,7 @@ func (m Model) handleImportKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		// Don't return here - let space propagate to text inputs
 
 	case key.Matches(msg, key.NewBinding(key.WithKeys("enter"))):
-		// Import
-		if m.pathInput.Value() == "" {
-			m.errorMessage = "Cookie file path is required"
-			return m, nil
-		}
-
 		opts := cookies.ImportOptions{
-			FilePath: m.pathInput.Value(),
 			Platform: m.platformInput.Value(),
 			Name:     m.nameInput.Value(),
 			Activate: m.importActivate,
@@ -256,11 +437,143 @@ func (m Model) handleImportKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			Force:    false,
 		}
 
+		// Import from browser
+		if m.importMode == importFromBrowser {
+			cookieDomain := strings.TrimSpace(m.domainInput.Value())
+			if cookieDomain == "" {
+				m.errorMessage = "Cookie domain is required (e.g. instagram.com)"
+				return m, nil
+			}
+
+			browser := m.browsers[m.browserIndex]
+			m.loading = true
+			m.loadingText = "Extracting cookies from " + browser + "..."
+			return m, extractAndImport(m.extractor, m.importer, browser, cookieDomain, opts)
+		}
+
+		// Import from file
+		if m.pathInput.Value() == "" {
+			m.errorMessage = "Cookie file path is required"
+			return m, nil
+		}
+
+		opts.FilePath = m.pathInput.Value()
 		m.loading = true
 		return m, importCookie(m.importer, opts)
 	}
 
-	return m, nil
+	// Typing goes to the focused text input
+	var cmd tea.Cmd
+	switch m.importFocusedField {
+	case 0:
+		if m.importMode == importFromBrowser {
+			m.domainInput, cmd = m.domainInput.Update(msg)
+		} else {
+			m.pathInput, cmd = m.pathInput.Update(msg)
+		}
+	case 1:
+		m.platformInput, cmd = m.platformInput.Update(msg)
+	case 2:
+		m.nameInput, cmd = m.nameInput.Update(msg)
+	}
+	return m, cmd
+}
+
+// handleExportKeys handles keys in the export path prompt
+func (m Model) handleExportKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, key.NewBinding(key.WithKeys("esc"))):
+		// Cancel export
+		m.currentView = viewList
+		m.exportInput.Blur()
+		return m, nil
+
+	case key.Matches(msg, key.NewBinding(key.WithKeys("tab"))):
+		// Next format; a path with the previous format's extension follows along
+		previous := exportExtensions[cookies.ExportFormats[m.exportFormat]]
+		m.exportFormat = (m.exportFormat + 1) % len(cookies.ExportFormats)
+		if path := m.exportInput.Value(); strings.HasSuffix(path, previous) {
+			m.exportInput.SetValue(strings.TrimSuffix(path, previous) + exportExtensions[cookies.ExportFormats[m.exportFormat]])
+			m.exportInput.CursorEnd()
+		}
+		m.exportOverwrite = ""
+		return m, nil
+
+	case key.Matches(msg, key.NewBinding(key.WithKeys("enter"))):
+		outputPath := config.ExpandHome(strings.TrimSpace(m.exportInput.Value()))
+		if outputPath == "" {
+			m.errorMessage = "Output path is required"
+			return m, nil
+		}
+
+		// Ask for a second Enter before overwriting an existing file
+		if _, err := os.Stat(outputPath); err == nil && m.exportOverwrite != outputPath {
+			m.exportOverwrite = outputPath
+			m.errorMessage = outputPath + " already exists. Press Enter again to overwrite"
+			return m, nil
+		}
+
+		acc := m.exportAccount
+		m.exportInput.Blur()
+		m.loading = true
+		return m, exportAccount(m.exporter, acc.Platform, acc.Name, outputPath, cookies.ExportFormats[m.exportFormat])
+	}
+
+	var cmd tea.Cmd
+	m.exportInput, cmd = m.exportInput.Update(msg)
+	return m, cmd
+}
+
+// handleEditKeys handles keys in the edit form
+func (m Model) handleEditKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, key.NewBinding(key.WithKeys("esc"))):
+		// Cancel edit
+		m.currentView = viewList
+		for i := range m.editInputs {
+			m.editInputs[i].Blur()
+		}
+		return m, nil
+
+	case key.Matches(msg, key.NewBinding(key.WithKeys("tab", "down"))):
+		m.editFocusedField = (m.editFocusedField + 1) % editFieldCount
+		m.updateEditFocus()
+		return m, nil
+
+	case key.Matches(msg, key.NewBinding(key.WithKeys("shift+tab", "up"))):
+		m.editFocusedField = (m.editFocusedField + editFieldCount - 1) % editFieldCount
+		m.updateEditFocus()
+		return m, nil
+
+	case key.Matches(msg, key.NewBinding(key.WithKeys("enter"))):
+		opts := cookies.EditOptions{
+			Platform:   strings.TrimSpace(m.editInputs[0].Value()),
+			Name:       strings.TrimSpace(m.editInputs[1].Value()),
+			CookiePath: config.ExpandHome(strings.TrimSpace(m.editInputs[2].Value())),
+		}
+		if opts.Platform == "" || opts.Name == "" || opts.CookiePath == "" {
+			m.errorMessage = "Platform, name and cookie path are required"
+			return m, nil
+		}
+
+		m.loading = true
+		return m, editAccount(m.editor, m.editAccount.ID, opts)
+	}
+
+	var cmd tea.Cmd
+	m.editInputs[m.editFocusedField], cmd = m.editInputs[m.editFocusedField].Update(msg)
+	return m, cmd
+}
+
+// updateEditFocus focuses the edit field under the cursor
+func (m *Model) updateEditFocus() {
+	for i := range m.editInputs {
+		if i == m.editFocusedField {
+			m.editInputs[i].Focus()
+		} else {
+			m.editInputs[i].Blur()
+		}
+	}
 }
 
 // handleDialogKeys handles keys in dialog views (validation, help)
@@ -270,20 +583,23 @@ func (m Model) handleDialogKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-// updateImportFocus updates which input field is focused
+// updateImportFocus updates which input field is focused (none on the checkboxes)
 func (m *Model) updateImportFocus() {
+	m.pathInput.Blur()
+	m.domainInput.Blur()
+	m.platformInput.Blur()
+	m.nameInput.Blur()
+
 	switch m.importFocusedField {
 	case 0:
-		m.pathInput.Focus()
-		m.platformInput.Blur()
-		m.nameInput.Blur()
+		if m.importMode == importFromBrowser {
+			m.domainInput.Focus()
+		} else {
+			m.pathInput.Focus()
+		}
 	case 1:
-		m.pathInput.Blur()
 		m.platformInput.Focus()
-		m.nameInput.Blur()
 	case 2:
-		m.pathInput.Blur()
-		m.platformInput.Blur()
 		m.nameInput.Focus()
 	}
 }
@@ -3,42 +3,23 @@ package cookies
 import (
 	"fmt"
 	"strings"
+	"time"
 
-	"github.com/charmbracelet/lipgloss"
+	"github.com/elsanchez/smart-download/internal/cookies"
 	"github.com/elsanchez/smart-download/internal/domain"
+	"github.com/elsanchez/smart-download/internal/tui/styles"
 )
 
-// Styles with adaptive colors for light/dark backgrounds
+// Styles shared with the other TUIs
 var (
-	titleStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.AdaptiveColor{Light: "63", Dark: "205"}).
-			MarginLeft(2)
-
-	helpStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.AdaptiveColor{Light: "240", Dark: "250"})
-
-	errorStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.AdaptiveColor{Light: "160", Dark: "9"}).
-			Bold(true)
-
-	successStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.AdaptiveColor{Light: "34", Dark: "10"}).
-			Bold(true)
-
-	spinnerStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.AdaptiveColor{Light: "63", Dark: "205"})
-
-	boxStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.AdaptiveColor{Light: "63", Dark: "63"}).
-			Padding(1, 2)
-
-	activeInputStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.AdaptiveColor{Light: "63", Dark: "205"})
-
-	inactiveInputStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.AdaptiveColor{Light: "240", Dark: "250"})
+	titleStyle         = styles.Title
+	helpStyle          = styles.Help
+	errorStyle         = styles.Error
+	successStyle       = styles.Success
+	spinnerStyle       = styles.Spinner
+	boxStyle           = styles.Box
+	activeInputStyle   = styles.ActiveInput
+	inactiveInputStyle = styles.InactiveInput
 )
 
 // View renders the current view
@@ -56,6 +37,10 @@ func (m Model) View() string {
 		content = m.viewImport()
 	case viewValidation:
 		content = m.viewValidation()
+	case viewExport:
+		content = m.viewExport()
+	case viewEdit:
+		content = m.viewEdit()
 	case viewHelp:
 		content = m.viewHelp()
 	default:
@@ -70,7 +55,11 @@ func (m Model) View() string {
 	}
 
 	if m.loading {
-		content += "\n" + m.spinner.View() + " Loading..."
+		text := "Loading..."
+		if m.loadingText != "" {
+			text = m.loadingText
+		}
+		content += "\n" + m.spinner.View() + " " + text
 	}
 
 	return content
@@ -80,9 +69,12 @@ func (m Model) View() string {
 func (m Model) viewList() string {
 	title := titleStyle.Render("🍪 Cookie Manager")
 
+	visible := m.visibleAccounts()
+	now := time.Now()
+
 	// Group accounts by platform
 	platformGroups := make(map[string][]*accountItem)
-	for _, acc := range m.accounts {
+	for _, acc := range visible {
 		item := &accountItem{account: acc}
 		platformGroups[acc.Platform] = append(platformGroups[acc.Platform], item)
 	}
@@ -90,10 +82,26 @@ func (m Model) viewList() string {
 	var content strings.Builder
 	content.WriteString(title + "\n\n")
 
+	// Search box
+	if m.searching || m.searchInput.Value() != "" {
+		content.WriteString("  " + m.searchInput.View() + "\n\n")
+	}
+
 	if len(m.accounts) == 0 {
 		content.WriteString("  No accounts found. Press 'i' to import cookies.\n")
+	} else if len(visible) == 0 {
+		content.WriteString("  No accounts match the search. Press Esc to clear it.\n")
 	} else {
-		content.WriteString(fmt.Sprintf("  %d accounts across %d platforms\n\n", len(m.accounts), len(platformGroups)))
+		if len(visible) < len(m.accounts) {
+			content.WriteString(fmt.Sprintf("  %d of %d accounts across %d platforms\n\n", len(visible), len(m.accounts), len(platformGroups)))
+		} else {
+			content.WriteString(fmt.Sprintf("  %d accounts across %d platforms\n\n", len(m.accounts), len(platformGroups)))
+		}
+
+		if stale := countStale(visible, now); stale > 0 {
+			content.WriteString(fmt.Sprintf("  ⏰ %d not validated in the last %s. Press 'v' to re-validate\n\n",
+				stale, cookies.FormatAge(domain.StaleValidationAge)))
+		}
 
 		// Render accounts by platform
 		for _, platform := range m.platforms {
@@ -127,8 +135,19 @@ func (m Model) viewList() string {
 					validIcon = "❓"
 				}
 
-				content.WriteString(fmt.Sprintf("  %s%s %s %-20s\n",
-					cursor, status, validIcon, item.account.Name))
+				// Flag accounts not validated recently so they can be re-checked
+				age := cookies.DescribeValidationAge(item.account, now)
+				if item.account.IsValidationStale(now, domain.StaleValidationAge) {
+					age = "⏰ " + age
+				}
+
+				// Account being validated on its own (r/R)
+				if m.validating[item.account.ID] {
+					age = m.spinner.View() + " validating..."
+				}
+
+				content.WriteString(fmt.Sprintf("  %s%s %s %-20s %s\n",
+					cursor, status, validIcon, item.account.Name, helpStyle.Render(age)))
 
 				if item.account.ValidationError != nil && m.cursor == m.findAccountIndex(item.account) {
 					content.WriteString(fmt.Sprintf("     %s\n", helpStyle.Render(*item.account.ValidationError)))
@@ -138,28 +157,90 @@ func (m Model) viewList() string {
 		}
 	}
 
+	// What the selected account's file actually holds, to spot a wrong import
+	if acc, ok := m.currentAccount(); ok {
+		content.WriteString(m.viewAccountDetail(acc))
+	}
+
 	// Help
 	help := "\n" + helpStyle.Render(
-		"  ↑/k up • ↓/j down • i import • v validate • V HTTP validate • a activate • d delete • e export • ? help • q quit",
+		"  ↑/k up • ↓/j down • / search • i import • b browser import • v validate • V HTTP validate • r/R validate selected • a activate • d delete • e export • E edit • ? help • q quit",
 	)
 
 	return content.String() + help
 }
 
+// maxDetailDomains is how many cookie domains the account detail lists
+const maxDetailDomains = 5
+
+// viewAccountDetail renders the cookie file of the selected account: how many cookies it
+// has and for which domains
+func (m Model) viewAccountDetail(acc *domain.Account) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("  %s/%s\n", acc.Platform, acc.Name))
+	b.WriteString(fmt.Sprintf("  File:    %s\n", acc.CookiePath))
+
+	summary := m.cookieSummaryFor(acc)
+	switch {
+	case summary.err != nil:
+		b.WriteString("  Cookies: " + errorStyle.Render(summary.err.Error()) + "\n")
+	case summary.count == 0:
+		b.WriteString("  Cookies: none\n")
+	default:
+		domains := summary.domains
+		more := ""
+		if len(domains) > maxDetailDomains {
+			more = fmt.Sprintf(", +%d more", len(domains)-maxDetailDomains)
+			domains = domains[:maxDetailDomains]
+		}
+		noun := "domains"
+		if len(summary.domains) == 1 {
+			noun = "domain"
+		}
+		b.WriteString(fmt.Sprintf("  Cookies: %d across %d %s\n", summary.count, len(summary.domains), noun))
+		b.WriteString(fmt.Sprintf("  Domains: %s%s\n", strings.Join(domains, ", "), more))
+	}
+
+	return boxStyle.Render(b.String()) + "\n"
+}
+
 // viewImport renders the import form
 func (m Model) viewImport() string {
 	title := titleStyle.Render("Import Cookie File")
+	if m.importMode == importFromBrowser {
+		title = titleStyle.Render("Import Cookies from Browser")
+	}
 
 	var b strings.Builder
 	b.WriteString(title + "\n\n")
 
-	// Path input
-	if m.importFocusedField == 0 {
-		b.WriteString(activeInputStyle.Render("  Cookie File Path:") + "\n")
+	if m.importMode == importFromBrowser {
+		// Browser picker and domain input
+		var browsers []string
+		for i, browser := range m.browsers {
+			if i == m.browserIndex {
+				browsers = append(browsers, activeInputStyle.Render("["+browser+"]"))
+			} else {
+				browsers = append(browsers, inactiveInputStyle.Render(browser))
+			}
+		}
+		b.WriteString("  Browser: " + strings.Join(browsers, " ") + "\n\n")
+
+		if m.importFocusedField == 0 {
+			b.WriteString(activeInputStyle.Render("  Cookie Domain:") + "\n")
+		} else {
+			b.WriteString(inactiveInputStyle.Render("  Cookie Domain:") + "\n")
+		}
+		b.WriteString("  " + m.domainInput.View() + "\n\n")
 	} else {
-		b.WriteString(inactiveInputStyle.Render("  Cookie File Path:") + "\n")
+		// Path input
+		if m.importFocusedField == 0 {
+			b.WriteString(activeInputStyle.Render("  Cookie File Path:") + "\n")
+		} else {
+			b.WriteString(inactiveInputStyle.Render("  Cookie File Path:") + "\n")
+		}
+		b.WriteString("  " + m.pathInput.View() + "\n\n")
 	}
-	b.WriteString("  " + m.pathInput.View() + "\n\n")
 
 	// Platform input
 	if m.importFocusedField == 1 {
@@ -188,11 +269,75 @@ func (m Model) viewImport() string {
 		validateBox = "[✓]"
 	}
 
-	b.WriteString(fmt.Sprintf("  %s Set as active\n", activateBox))
-	b.WriteString(fmt.Sprintf("  %s Validate cookies\n\n", validateBox))
+	b.WriteString(m.renderCheckbox(3, activateBox+" Set as active") + "\n")
+	b.WriteString(m.renderCheckbox(4, validateBox+" Validate cookies") + "\n\n")
 
 	// Help
 	help := helpStyle.Render("  Tab next field • Enter import • Esc cancel • Space toggle checkbox")
+	if m.importMode == importFromBrowser {
+		help = helpStyle.Render("  ↑/↓ browser • Tab next field • Enter extract and import • Esc cancel • Space toggle checkbox")
+	}
+
+	return boxStyle.Render(b.String()) + "\n\n" + help
+}
+
+// renderCheckbox highlights a checkbox line when its field is focused
+func (m Model) renderCheckbox(field int, label string) string {
+	if m.importFocusedField == field {
+		return activeInputStyle.Render("  " + label)
+	}
+	return "  " + label
+}
+
+// viewExport renders the export path prompt
+func (m Model) viewExport() string {
+	title := titleStyle.Render("Export Cookie File")
+
+	var b strings.Builder
+	b.WriteString(title + "\n\n")
+
+	if m.exportAccount != nil {
+		b.WriteString(fmt.Sprintf("  Account: %s/%s\n\n", m.exportAccount.Platform, m.exportAccount.Name))
+	}
+
+	// Netscape for yt-dlp/gallery-dl, JSON for browser extensions (Cookie-Editor)
+	var formats []string
+	for i, format := range cookies.ExportFormats {
+		if i == m.exportFormat {
+			formats = append(formats, activeInputStyle.Render("["+format+"]"))
+		} else {
+			formats = append(formats, inactiveInputStyle.Render(format))
+		}
+	}
+	b.WriteString("  Format: " + strings.Join(formats, " ") + "\n\n")
+
+	b.WriteString(activeInputStyle.Render("  Output Path:") + "\n")
+	b.WriteString("  " + m.exportInput.View() + "\n")
+
+	help := helpStyle.Render("  Tab format • Enter export • Esc cancel")
+
+	return boxStyle.Render(b.String()) + "\n\n" + help
+}
+
+// viewEdit renders the edit form of an account
+func (m Model) viewEdit() string {
+	title := titleStyle.Render("Edit Account")
+
+	var b strings.Builder
+	b.WriteString(title + "\n\n")
+
+	for i, label := range []string{"Platform:", "Account Name:", "Cookie File Path:"} {
+		if m.editFocusedField == i {
+			b.WriteString(activeInputStyle.Render("  "+label) + "\n")
+		} else {
+			b.WriteString(inactiveInputStyle.Render("  "+label) + "\n")
+		}
+		b.WriteString("  " + m.editInputs[i].View() + "\n\n")
+	}
+
+	b.WriteString(helpStyle.Render("  Renaming also renames the stored platform_name.txt file.\n  A new cookie file path is validated after saving."))
+
+	help := helpStyle.Render("  Tab/↑/↓ field • Enter save • Esc cancel")
 
 	return boxStyle.Render(b.String()) + "\n\n" + help
 }
@@ -261,19 +406,24 @@ func (m Model) viewHelp() string {
     ↑/k        Move up
     ↓/j        Move down
     Enter      Select
-    Esc        Go back / Cancel
+    /          Search accounts by platform or name
+    Esc        Go back / Cancel / Clear search
     q          Quit
 
   Actions (from list view):
-    i          Import new cookie
+    i          Import new cookie file
+    b          Import cookies straight from a browser
     v          Validate expiration (fast)
     V          Validate HTTP (slow but reliable)
+    r / R      Validate only the selected account (R = HTTP)
     a          Activate selected
     d          Delete selected
-    e          Export selected
+    e          Export selected (asks for the format and output path)
+    E          Edit selected (platform, name, cookie file path)
     ?          Show this help
 
   Import Form:
+    ↑/↓        Pick the browser (browser import)
     Tab        Next field
     Shift+Tab  Previous field
     Space      Toggle checkbox
@@ -283,15 +433,28 @@ func (m Model) viewHelp() string {
     - Platform auto-detection from cookie domains
     - Account names auto-generated if not provided
     - Validation checks cookie expiration timestamps
+    - ⏰ marks accounts not validated in the last 24h
     - Active account is used for downloads
+    - The list reloads every few seconds, so checks run by the daemon show up
 `
 
 	return title + "\n" + help + "\n" + helpStyle.Render("  Press any key to return")
 }
 
-// Helper function to find account index
+// countStale counts the accounts whose validation is missing or older than the threshold
+func countStale(accounts []*domain.Account, now time.Time) int {
+	count := 0
+	for _, acc := range accounts {
+		if acc.IsValidationStale(now, domain.StaleValidationAge) {
+			count++
+		}
+	}
+	return count
+}
+
+// Helper function to find account index in the visible list
 func (m Model) findAccountIndex(acc *domain.Account) int {
-	for i, a := range m.accounts {
+	for i, a := range m.visibleAccounts() {
 		if a.ID == acc.ID {
 			return i
 		}
@@ -0,0 +1,69 @@
+package downloads
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/elsanchez/smart-download/pkg/client"
+)
+
+// refreshInterval is how often the download list is reloaded from the daemon
+const refreshInterval = 2 * time.Second
+
+// listLimit is the number of recent downloads shown
+const listLimit = 100
+
+// Client is the part of the daemon client used by the TUI
+type Client interface {
+	Call(action string, payload interface{}) (json.RawMessage, error)
+}
+
+// Async commands that return tea.Msg
+
+func loadDownloads(c Client) tea.Cmd {
+	return func() tea.Msg {
+		data, err := c.Call("list", map[string]int{"limit": listLimit})
+		if err != nil {
+			return downloadsLoadedMsg{err: err}
+		}
+
+		downloads, _, err := client.DecodeDownloadList(data)
+		return downloadsLoadedMsg{downloads: downloads, err: err}
+	}
+}
+
+func tick() tea.Cmd {
+	return tea.Tick(refreshInterval, func(t time.Time) tea.Msg {
+		return tickMsg(t)
+	})
+}
+
+// runAction sends an action on a single download (cancel, retry, delete)
+func runAction(c Client, action string, id int64, done string) tea.Cmd {
+	return func() tea.Msg {
+		_, err := c.Call(action, map[string]int64{"id": id})
+		return actionCompleteMsg{message: done, err: err}
+	}
+}
+
+func addDownload(c Client, url string) tea.Cmd {
+	return func() tea.Msg {
+		data, err := c.Call("add", &client.AddDownloadPayload{URL: url})
+		if err != nil {
+			return actionCompleteMsg{err: err}
+		}
+
+		var result client.AddDownloadResult
+		if err := json.Unmarshal(data, &result); err != nil {
+			return actionCompleteMsg{err: fmt.Errorf("unmarshal response: %w", err)}
+		}
+
+		if result.Duplicate {
+			return actionCompleteMsg{message: fmt.Sprintf("⚠ Already in queue as #%d (%s)", result.ID, result.Status)}
+		}
+		return actionCompleteMsg{message: fmt.Sprintf("✓ Added #%d (%s)", result.ID, result.Platform)}
+	}
+}
@@ -0,0 +1,21 @@
+package downloads
+
+import (
+	"time"
+
+	"github.com/elsanchez/smart-download/pkg/client"
+)
+
+// Message types for async operations
+
+type downloadsLoadedMsg struct {
+	downloads []client.DownloadInfo
+	err       error
+}
+
+type tickMsg time.Time
+
+type actionCompleteMsg struct {
+	message string
+	err     error
+}
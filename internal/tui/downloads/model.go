@@ -0,0 +1,103 @@
+package downloads
+
+import (
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/elsanchez/smart-download/internal/tui/styles"
+	"github.com/elsanchez/smart-download/pkg/client"
+)
+
+// view represents different screens in the TUI
+type view int
+
+const (
+	viewList view = iota
+	viewAdd
+	viewHelp
+)
+
+// Model is the Bubbletea model for the downloads dashboard
+type Model struct {
+	// Navigation
+	currentView view
+	width       int
+	height      int
+	quitting    bool
+
+	// Dependencies
+	client Client
+
+	// State
+	downloads       []client.DownloadInfo
+	cursor          int
+	selectedID      int64 // Keeps the selection on the same download across refreshes
+	confirmDeleteID int64 // Download the user pressed 'd' on once; a second 'd' deletes it
+
+	// Components
+	urlInput textinput.Model
+	spinner  spinner.Model
+
+	// UI state
+	loading       bool
+	statusMessage string
+	errorMessage  string
+}
+
+// NewModel creates a new downloads TUI model talking to the daemon through c
+func NewModel(c Client) Model {
+	urlInput := textinput.New()
+	urlInput.Placeholder = "https://..."
+	urlInput.CharLimit = 2048
+	urlInput.Width = 60
+
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = styles.Spinner
+
+	return Model{
+		currentView: viewList,
+		client:      c,
+		urlInput:    urlInput,
+		spinner:     s,
+		loading:     true,
+	}
+}
+
+// Init initializes the model
+func (m Model) Init() tea.Cmd {
+	return tea.Batch(
+		loadDownloads(m.client),
+		tick(),
+		m.spinner.Tick,
+	)
+}
+
+// selected returns the download under the cursor
+func (m Model) selected() (client.DownloadInfo, bool) {
+	if m.cursor < 0 || m.cursor >= len(m.downloads) {
+		return client.DownloadInfo{}, false
+	}
+	return m.downloads[m.cursor], true
+}
+
+// restoreCursor moves the cursor back to the selected download after a reload
+func (m *Model) restoreCursor() {
+	for i, dl := range m.downloads {
+		if dl.ID == m.selectedID {
+			m.cursor = i
+			return
+		}
+	}
+
+	if m.cursor >= len(m.downloads) {
+		m.cursor = len(m.downloads) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+	if dl, ok := m.selected(); ok {
+		m.selectedID = dl.ID
+	}
+}
@@ -0,0 +1,190 @@
+package downloads
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/elsanchez/smart-download/internal/domain"
+)
+
+// Update handles messages and updates the model
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	var cmds []tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		// Clear previous messages on keypress
+		m.errorMessage = ""
+		m.statusMessage = ""
+
+		return m.handleKeyPress(msg)
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case downloadsLoadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.errorMessage = msg.err.Error()
+			return m, nil
+		}
+		m.downloads = msg.downloads
+		m.restoreCursor()
+		return m, nil
+
+	case tickMsg:
+		// Periodic refresh; keeps ticking even if the daemon is down so the view recovers
+		return m, tea.Batch(loadDownloads(m.client), tick())
+
+	case actionCompleteMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.errorMessage = msg.err.Error()
+			return m, nil
+		}
+		m.statusMessage = msg.message
+		return m, loadDownloads(m.client)
+
+	case spinner.TickMsg:
+		m.spinner, cmd = m.spinner.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
+	if m.currentView == viewAdd {
+		m.urlInput, cmd = m.urlInput.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
+	return m, tea.Batch(cmds...)
+}
+
+// handleKeyPress handles keyboard input
+func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch m.currentView {
+	case viewList:
+		return m.handleListKeys(msg)
+	case viewAdd:
+		return m.handleAddKeys(msg)
+	case viewHelp:
+		// Any key returns to list
+		m.currentView = viewList
+		return m, nil
+	}
+	return m, nil
+}
+
+// handleListKeys handles keys in the list view
+func (m Model) handleListKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Any key other than a second 'd' drops a pending delete confirmation
+	confirmDeleteID := m.confirmDeleteID
+	m.confirmDeleteID = 0
+
+	switch {
+	case key.Matches(msg, key.NewBinding(key.WithKeys("q", "ctrl+c"))):
+		m.quitting = true
+		return m, tea.Quit
+
+	case key.Matches(msg, key.NewBinding(key.WithKeys("up", "k"))):
+		if m.cursor > 0 {
+			m.cursor--
+			m.selectedID = m.downloads[m.cursor].ID
+		}
+		return m, nil
+
+	case key.Matches(msg, key.NewBinding(key.WithKeys("down", "j"))):
+		if m.cursor < len(m.downloads)-1 {
+			m.cursor++
+			m.selectedID = m.downloads[m.cursor].ID
+		}
+		return m, nil
+
+	case key.Matches(msg, key.NewBinding(key.WithKeys("a"))):
+		// Add URL
+		m.currentView = viewAdd
+		m.urlInput.SetValue("")
+		m.urlInput.Focus()
+		return m, nil
+
+	case key.Matches(msg, key.NewBinding(key.WithKeys("c"))):
+		// Cancel selected
+		if dl, ok := m.selected(); ok {
+			if dl.Status == string(domain.StatusCompleted) || dl.Status == string(domain.StatusFailed) {
+				m.errorMessage = "Download is already " + dl.Status
+				return m, nil
+			}
+			m.loading = true
+			return m, runAction(m.client, "cancel", dl.ID, "✓ Cancelled download")
+		}
+		return m, nil
+
+	case key.Matches(msg, key.NewBinding(key.WithKeys("r"))):
+		// Retry selected
+		if dl, ok := m.selected(); ok {
+			if dl.Status != string(domain.StatusFailed) {
+				m.errorMessage = "Only failed downloads can be retried"
+				return m, nil
+			}
+			m.loading = true
+			return m, runAction(m.client, "retry", dl.ID, "✓ Download requeued")
+		}
+		return m, nil
+
+	case key.Matches(msg, key.NewBinding(key.WithKeys("d"))):
+		// Delete selected from history (files are kept), after pressing 'd' again
+		if dl, ok := m.selected(); ok {
+			if confirmDeleteID != dl.ID {
+				m.confirmDeleteID = dl.ID
+				m.statusMessage = fmt.Sprintf("Press d again to delete #%d from history (files are kept)", dl.ID)
+				return m, nil
+			}
+			m.loading = true
+			return m, runAction(m.client, "delete", dl.ID, "✓ Download removed from history")
+		}
+		return m, nil
+
+	case key.Matches(msg, key.NewBinding(key.WithKeys("R"))):
+		// Refresh now
+		m.loading = true
+		return m, loadDownloads(m.client)
+
+	case key.Matches(msg, key.NewBinding(key.WithKeys("?"))):
+		// Help
+		m.currentView = viewHelp
+		return m, nil
+	}
+
+	return m, nil
+}
+
+// handleAddKeys handles keys in the add URL view
+func (m Model) handleAddKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, key.NewBinding(key.WithKeys("esc"))):
+		m.currentView = viewList
+		m.urlInput.Blur()
+		return m, nil
+
+	case key.Matches(msg, key.NewBinding(key.WithKeys("enter"))):
+		url := strings.TrimSpace(m.urlInput.Value())
+		if url == "" {
+			m.errorMessage = "URL is required"
+			return m, nil
+		}
+
+		m.currentView = viewList
+		m.urlInput.Blur()
+		m.loading = true
+		return m, addDownload(m.client, url)
+	}
+
+	var cmd tea.Cmd
+	m.urlInput, cmd = m.urlInput.Update(msg)
+	return m, cmd
+}
@@ -0,0 +1,129 @@
+package downloads
+
+import (
+	"encoding/json"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/elsanchez/smart-download/pkg/client"
+)
+
+// fakeClient records the actions sent to the daemon
+type fakeClient struct {
+	calls []string
+}
+
+func (c *fakeClient) Call(action string, payload interface{}) (json.RawMessage, error) {
+	c.calls = append(c.calls, action)
+	return json.RawMessage(`{}`), nil
+}
+
+// press sends a key to the model and runs the returned command, if any
+func press(t *testing.T, m Model, keys string) (Model, tea.Msg) {
+	t.Helper()
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(keys)})
+	var msg tea.Msg
+	if cmd != nil {
+		msg = cmd()
+	}
+	return updated.(Model), msg
+}
+
+func loadedModel(c Client) Model {
+	m := NewModel(c)
+	updated, _ := m.Update(downloadsLoadedMsg{downloads: []client.DownloadInfo{
+		{ID: 3, Status: "downloading"},
+		{ID: 2, Status: "failed"},
+		{ID: 1, Status: "completed"},
+	}})
+	return updated.(Model)
+}
+
+func TestUpdate_DeleteNeedsConfirmation(t *testing.T) {
+	c := &fakeClient{}
+	m := loadedModel(c)
+
+	m, _ = press(t, m, "d")
+	if len(c.calls) != 0 {
+		t.Fatalf("first d sent %v, want no action", c.calls)
+	}
+	if m.confirmDeleteID != 3 {
+		t.Errorf("confirmDeleteID = %d, want 3", m.confirmDeleteID)
+	}
+
+	// Another key drops the confirmation
+	m, _ = press(t, m, "j")
+	m, _ = press(t, m, "k")
+	m, _ = press(t, m, "d")
+	if len(c.calls) != 0 {
+		t.Fatalf("d after moving sent %v, want no action", c.calls)
+	}
+
+	m, msg := press(t, m, "d")
+	if len(c.calls) != 1 || c.calls[0] != "delete" {
+		t.Fatalf("second d sent %v, want [delete]", c.calls)
+	}
+	if done, ok := msg.(actionCompleteMsg); !ok || done.err != nil {
+		t.Errorf("delete returned %#v", msg)
+	}
+	if m.confirmDeleteID != 0 {
+		t.Errorf("confirmDeleteID = %d after deleting, want 0", m.confirmDeleteID)
+	}
+}
+
+func TestUpdate_Navigation(t *testing.T) {
+	m := loadedModel(&fakeClient{})
+
+	m, _ = press(t, m, "j")
+	m, _ = press(t, m, "j")
+	m, _ = press(t, m, "j")
+	if m.cursor != 2 || m.selectedID != 1 {
+		t.Fatalf("cursor = %d (#%d), want 2 (#1)", m.cursor, m.selectedID)
+	}
+
+	// A refresh keeps the selection on the same download even if it moved
+	updated, _ := m.Update(downloadsLoadedMsg{downloads: []client.DownloadInfo{
+		{ID: 4, Status: "pending"},
+		{ID: 3, Status: "completed"},
+		{ID: 1, Status: "completed"},
+	}})
+	m = updated.(Model)
+	if m.cursor != 2 || m.selectedID != 1 {
+		t.Errorf("after reload cursor = %d (#%d), want 2 (#1)", m.cursor, m.selectedID)
+	}
+}
+
+func TestUpdate_RetryOnlyFailed(t *testing.T) {
+	c := &fakeClient{}
+	m := loadedModel(c)
+
+	m, _ = press(t, m, "r")
+	if len(c.calls) != 0 || m.errorMessage == "" {
+		t.Errorf("retry on a running download: calls %v, error %q", c.calls, m.errorMessage)
+	}
+
+	m, _ = press(t, m, "j")
+	press(t, m, "r")
+	if len(c.calls) != 1 || c.calls[0] != "retry" {
+		t.Errorf("retry on a failed download sent %v, want [retry]", c.calls)
+	}
+}
+
+func TestProgressLabel(t *testing.T) {
+	tests := []struct {
+		dl   client.DownloadInfo
+		want string
+	}{
+		{client.DownloadInfo{Status: "processing", Progress: 42}, " 42%"},
+		{client.DownloadInfo{Status: "downloading"}, "  …"},
+		{client.DownloadInfo{Status: "completed", Progress: 100}, ""},
+		{client.DownloadInfo{Status: "pending"}, ""},
+	}
+
+	for _, tt := range tests {
+		if got := progressLabel(tt.dl); got != tt.want {
+			t.Errorf("progressLabel(%s, %d) = %q, want %q", tt.dl.Status, tt.dl.Progress, got, tt.want)
+		}
+	}
+}
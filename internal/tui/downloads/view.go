@@ -0,0 +1,213 @@
+package downloads
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/elsanchez/smart-download/internal/domain"
+	"github.com/elsanchez/smart-download/internal/tui/styles"
+	"github.com/elsanchez/smart-download/pkg/client"
+)
+
+// minURLWidth is the narrowest the URL column gets before the terminal size is known
+const minURLWidth = 40
+
+// View renders the current view
+func (m Model) View() string {
+	if m.quitting {
+		return "Goodbye!\n"
+	}
+
+	var content string
+
+	switch m.currentView {
+	case viewList:
+		content = m.viewList()
+	case viewAdd:
+		content = m.viewAdd()
+	case viewHelp:
+		content = m.viewHelp()
+	default:
+		content = m.viewList()
+	}
+
+	// Add status/error messages
+	if m.errorMessage != "" {
+		content += "\n" + styles.Error.Render("Error: "+m.errorMessage)
+	} else if m.statusMessage != "" {
+		content += "\n" + styles.Success.Render(m.statusMessage)
+	}
+
+	if m.loading {
+		content += "\n" + m.spinner.View() + " Loading..."
+	}
+
+	return content
+}
+
+// viewList renders the download table
+func (m Model) viewList() string {
+	title := styles.Title.Render("⬇ Downloads")
+
+	var content strings.Builder
+	content.WriteString(title + "\n\n")
+
+	if len(m.downloads) == 0 {
+		content.WriteString("  No downloads yet. Press 'a' to add a URL.\n")
+	} else {
+		content.WriteString(fmt.Sprintf("  %s\n\n", m.summary()))
+		content.WriteString(fmt.Sprintf("    %-6s %-13s %-8s %-12s %s\n", "ID", "Status", "Progress", "Platform", "Title / URL"))
+		content.WriteString("    " + strings.Repeat("─", 40+m.urlWidth()) + "\n")
+
+		for i, dl := range m.downloads {
+			cursor := "  "
+			if i == m.cursor {
+				cursor = "▸ "
+			}
+
+			content.WriteString(fmt.Sprintf("  %s%-6d %s %-11s %-8s %-12s %s\n",
+				cursor, dl.ID, m.statusIcon(dl.Status), dl.Status, progressLabel(dl), dl.Platform, truncate(dl.DisplayName(), m.urlWidth())))
+
+			if i == m.cursor {
+				content.WriteString(m.viewDetail(dl))
+			}
+		}
+	}
+
+	// Help
+	help := "\n" + styles.Help.Render(
+		"  ↑/k up • ↓/j down • a add • c cancel • r retry • d delete • R refresh • ? help • q quit",
+	)
+
+	return content.String() + help
+}
+
+// viewDetail renders the extra lines shown under the selected download
+func (m Model) viewDetail(dl client.DownloadInfo) string {
+	var b strings.Builder
+
+	// The list shows the title when known, so keep the URL visible here
+	if dl.Title != "" {
+		b.WriteString(fmt.Sprintf("      %s\n", styles.Help.Render(dl.URL)))
+	}
+	if dl.ErrorMessage != "" {
+		message := dl.ErrorMessage
+		if dl.ErrorType != "" {
+			message = "[" + dl.ErrorType + "] " + message
+		}
+		b.WriteString(fmt.Sprintf("      %s\n", styles.Error.Render(message)))
+	}
+	if dl.OutputPath != "" {
+		b.WriteString(fmt.Sprintf("      %s\n", styles.Help.Render("→ "+dl.OutputPath)))
+	}
+	if len(dl.Tags) > 0 {
+		b.WriteString(fmt.Sprintf("      %s\n", styles.Help.Render("Tags: "+strings.Join(dl.Tags, ", "))))
+	}
+
+	return b.String()
+}
+
+// viewAdd renders the add URL form
+func (m Model) viewAdd() string {
+	title := styles.Title.Render("Add Download")
+
+	var b strings.Builder
+	b.WriteString(title + "\n\n")
+	b.WriteString(styles.ActiveInput.Render("  URL:") + "\n")
+	b.WriteString("  " + m.urlInput.View() + "\n")
+
+	help := styles.Help.Render("  Enter add • Esc cancel")
+
+	return styles.Box.Render(b.String()) + "\n\n" + help
+}
+
+// viewHelp renders the help screen
+func (m Model) viewHelp() string {
+	title := styles.Title.Render("Help")
+
+	help := `
+  Navigation:
+    ↑/k        Move up
+    ↓/j        Move down
+    q          Quit
+
+  Actions (from list view):
+    a          Add a URL to the queue
+    c          Cancel selected (pending or in progress)
+    r          Retry selected (failed only)
+    d          Delete selected from history (press twice; files are kept)
+    R          Refresh now
+    ?          Show this help
+
+  Tips:
+    - The list refreshes automatically every few seconds
+    - The daemon must be running (smart-downloadd)
+    - Use 'smd cookies tui' to manage cookie accounts
+`
+
+	return title + "\n" + help + "\n" + styles.Help.Render("  Press any key to return")
+}
+
+// summary counts the listed downloads by status
+func (m Model) summary() string {
+	counts := make(map[string]int)
+	for _, dl := range m.downloads {
+		counts[dl.Status]++
+	}
+
+	return fmt.Sprintf("%d downloads • %d pending • %d active • %d completed • %d failed",
+		len(m.downloads),
+		counts[string(domain.StatusPending)],
+		counts[string(domain.StatusDownloading)]+counts[string(domain.StatusProcessing)],
+		counts[string(domain.StatusCompleted)],
+		counts[string(domain.StatusFailed)],
+	)
+}
+
+// statusIcon returns the icon for a download status; active downloads get the spinner
+func (m Model) statusIcon(status string) string {
+	switch domain.DownloadStatus(status) {
+	case domain.StatusPending:
+		return "⏳"
+	case domain.StatusDownloading, domain.StatusProcessing:
+		return m.spinner.View()
+	case domain.StatusCompleted:
+		return "✓"
+	case domain.StatusFailed:
+		return "✗"
+	default:
+		return "?"
+	}
+}
+
+// progressLabel returns the progress column: the percentage of active downloads once
+// the daemon reports one, blank otherwise
+func progressLabel(dl client.DownloadInfo) string {
+	switch domain.DownloadStatus(dl.Status) {
+	case domain.StatusDownloading, domain.StatusProcessing:
+		if dl.Progress > 0 {
+			return fmt.Sprintf("%3d%%", dl.Progress)
+		}
+		return "  …"
+	default:
+		return ""
+	}
+}
+
+// urlWidth returns the room left for the URL column
+func (m Model) urlWidth() int {
+	width := m.width - 46
+	if width < minURLWidth {
+		return minURLWidth
+	}
+	return width
+}
+
+// truncate shortens s to at most n runes
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
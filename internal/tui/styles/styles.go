@@ -0,0 +1,37 @@
+// Package styles holds the lipgloss styles shared by the smd TUIs
+package styles
+
+import "github.com/charmbracelet/lipgloss"
+
+// Styles with adaptive colors for light/dark backgrounds
+var (
+	Title = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.AdaptiveColor{Light: "63", Dark: "205"}).
+		MarginLeft(2)
+
+	Help = lipgloss.NewStyle().
+		Foreground(lipgloss.AdaptiveColor{Light: "240", Dark: "250"})
+
+	Error = lipgloss.NewStyle().
+		Foreground(lipgloss.AdaptiveColor{Light: "160", Dark: "9"}).
+		Bold(true)
+
+	Success = lipgloss.NewStyle().
+		Foreground(lipgloss.AdaptiveColor{Light: "34", Dark: "10"}).
+		Bold(true)
+
+	Spinner = lipgloss.NewStyle().
+		Foreground(lipgloss.AdaptiveColor{Light: "63", Dark: "205"})
+
+	Box = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.AdaptiveColor{Light: "63", Dark: "63"}).
+		Padding(1, 2)
+
+	ActiveInput = lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "63", Dark: "205"})
+
+	InactiveInput = lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "240", Dark: "250"})
+)
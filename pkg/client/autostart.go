@@ -0,0 +1,76 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// DefaultDaemonPath es el ejecutable del daemon que se inicia con autostart (buscado en el PATH)
+const DefaultDaemonPath = "smart-downloadd"
+
+// Reintentos de conexión tras iniciar el daemon: autostartDelay, el doble, ... (~6s en total)
+const (
+	autostartAttempts = 6
+	autostartDelay    = 100 * time.Millisecond
+)
+
+// SetAutostart hace que Send inicie el daemon si no está corriendo. daemonPath es su
+// ejecutable ("" = DefaultDaemonPath)
+func (c *Client) SetAutostart(enabled bool, daemonPath string) {
+	if daemonPath == "" {
+		daemonPath = DefaultDaemonPath
+	}
+	c.autostart = enabled
+	c.daemonPath = daemonPath
+}
+
+// dial conecta con el daemon. Si no está corriendo y autostart está habilitado, lo inicia
+// y reintenta la conexión con backoff mientras crea el socket
+func (c *Client) dial() (net.Conn, error) {
+	dialer := net.Dialer{Timeout: c.timeout}
+	conn, err := dialer.Dial("unix", c.socketPath)
+	if err == nil {
+		return conn, nil
+	}
+	if !daemonDown(err) {
+		return nil, fmt.Errorf("connect to daemon: %w", err)
+	}
+	if !c.autostart {
+		return nil, fmt.Errorf("connect to daemon: %w (daemon is not running: start smart-downloadd or use smd --autostart)", err)
+	}
+
+	if err := c.startDaemon(); err != nil {
+		return nil, fmt.Errorf("daemon is not running and autostart failed: %w", err)
+	}
+
+	delay := autostartDelay
+	for attempt := 0; attempt < autostartAttempts; attempt++ {
+		time.Sleep(delay)
+		if conn, err = dialer.Dial("unix", c.socketPath); err == nil {
+			return conn, nil
+		}
+		delay *= 2
+	}
+
+	return nil, fmt.Errorf("connect to daemon: started %s but it isn't accepting connections: %w (check its log)", c.daemonPath, err)
+}
+
+// daemonDown retorna true si el error de conexión indica que el daemon no está corriendo:
+// el socket no existe o quedó de una ejecución anterior
+func daemonDown(err error) bool {
+	return errors.Is(err, syscall.ENOENT) || errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// startDaemon lanza el daemon en segundo plano, en su propia sesión para que sobreviva a smd
+func (c *Client) startDaemon() error {
+	cmd := exec.Command(c.daemonPath)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("start %s: %w", c.daemonPath, err)
+	}
+	return cmd.Process.Release()
+}
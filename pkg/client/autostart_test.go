@@ -0,0 +1,128 @@
+package client
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// fakeDaemonEnv hace que el binario de test actúe como daemon: escucha en ese socket,
+// acepta una conexión y termina
+const fakeDaemonEnv = "SMD_FAKE_DAEMON_SOCKET"
+
+func TestMain(m *testing.M) {
+	if socketPath := os.Getenv(fakeDaemonEnv); socketPath != "" {
+		runFakeDaemon(socketPath)
+		return
+	}
+	os.Exit(m.Run())
+}
+
+// runFakeDaemon crea el socket con un pequeño retraso (como un daemon que arranca) y
+// atiende una conexión. Si nadie conecta termina igual a los pocos segundos
+func runFakeDaemon(socketPath string) {
+	time.Sleep(150 * time.Millisecond)
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		os.Exit(1)
+	}
+	defer listener.Close()
+
+	listener.(*net.UnixListener).SetDeadline(time.Now().Add(5 * time.Second))
+	if conn, err := listener.Accept(); err == nil {
+		conn.Close()
+	}
+}
+
+func TestDaemonDown(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"socket missing", &net.OpError{Op: "dial", Net: "unix", Err: os.NewSyscallError("connect", syscall.ENOENT)}, true},
+		{"stale socket", &net.OpError{Op: "dial", Net: "unix", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, true},
+		{"permission denied", &net.OpError{Op: "dial", Net: "unix", Err: os.NewSyscallError("connect", syscall.EACCES)}, false},
+		{"other", errors.New("boom"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := daemonDown(tt.err); got != tt.want {
+				t.Errorf("daemonDown(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDaemonDown_RealSockets(t *testing.T) {
+	dir := t.TempDir()
+
+	// Socket que no existe
+	_, err := net.Dial("unix", filepath.Join(dir, "missing.sock"))
+	if !daemonDown(err) {
+		t.Errorf("daemonDown() = false for a missing socket (%v)", err)
+	}
+
+	// Socket que quedó de una ejecución anterior (nadie escucha)
+	stale := filepath.Join(dir, "stale.sock")
+	listener, err := net.Listen("unix", stale)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	listener.Close()
+
+	_, err = net.Dial("unix", stale)
+	if !daemonDown(err) {
+		t.Errorf("daemonDown() = false for a stale socket (%v)", err)
+	}
+}
+
+func TestDial_DaemonNotRunning(t *testing.T) {
+	c := NewClient(filepath.Join(t.TempDir(), "smart-download.sock"))
+
+	_, err := c.dial()
+	if err == nil || !strings.Contains(err.Error(), "daemon is not running") {
+		t.Errorf("dial() error = %v, want a hint to start the daemon", err)
+	}
+}
+
+func TestDial_AutostartMissingDaemon(t *testing.T) {
+	c := NewClient(filepath.Join(t.TempDir(), "smart-download.sock"))
+	c.SetAutostart(true, filepath.Join(t.TempDir(), "no-such-daemon"))
+
+	_, err := c.dial()
+	if err == nil || !strings.Contains(err.Error(), "autostart failed") {
+		t.Errorf("dial() error = %v, want autostart failure", err)
+	}
+}
+
+func TestDial_Autostart(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "smart-download.sock")
+	t.Setenv(fakeDaemonEnv, socketPath)
+
+	// El daemon falso es el propio binario de test
+	c := NewClient(socketPath)
+	c.SetAutostart(true, os.Args[0])
+
+	conn, err := c.dial()
+	if err != nil {
+		t.Fatalf("dial() error: %v", err)
+	}
+	conn.Close()
+}
+
+func TestSetAutostart_DefaultPath(t *testing.T) {
+	c := NewClient("")
+	c.SetAutostart(true, "")
+	if !c.autostart || c.daemonPath != DefaultDaemonPath {
+		t.Errorf("SetAutostart(true, \"\") = %v %q, want true %q", c.autostart, c.daemonPath, DefaultDaemonPath)
+	}
+}
@@ -2,10 +2,14 @@ package client
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"net"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/elsanchez/smart-download/internal/protocol"
 )
 
 // GetDefaultSocketPath retorna el path del socket usando XDG_RUNTIME_DIR
@@ -21,25 +25,73 @@ func GetDefaultSocketPath() string {
 	return filepath.Join(runtimeDir, "smart-download.sock")
 }
 
+// GetDefaultTokenPath retorna el path del token de autenticación compartido
+func GetDefaultTokenPath() string {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		homeDir, _ := os.UserHomeDir()
+		configDir = filepath.Join(homeDir, ".config")
+	}
+
+	return filepath.Join(configDir, "smart-download", "token")
+}
+
+// LoadToken lee el token de un archivo. Si el archivo no existe retorna "" (auth deshabilitada)
+func LoadToken(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("read token: %w", err)
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
+
 // Client representa un cliente del daemon
 type Client struct {
 	socketPath string
+	token      string
+	autostart  bool   // Iniciar el daemon si no está corriendo
+	daemonPath string // Ejecutable del daemon para autostart
+	timeout    time.Duration
+}
+
+// DefaultTimeout es el tiempo máximo de una petición al daemon (conexión, envío y respuesta)
+const DefaultTimeout = 10 * time.Second
+
+// slowActions son las acciones que pueden tardar más que el timeout normal: se usa el mayor
+var slowActions = map[string]time.Duration{
+	"info": 2 * time.Minute, // Consulta la URL con yt-dlp/gallery-dl
 }
 
 // NewClient crea un cliente con socket path personalizado
 func NewClient(socketPath string) *Client {
-	return &Client{socketPath: socketPath}
+	return &Client{socketPath: socketPath, timeout: DefaultTimeout}
 }
 
-// NewDefaultClient crea un cliente con el socket path por defecto
+// NewDefaultClient crea un cliente con el socket path y el token por defecto
 func NewDefaultClient() *Client {
-	return &Client{socketPath: GetDefaultSocketPath()}
+	token, _ := LoadToken(GetDefaultTokenPath())
+	return &Client{socketPath: GetDefaultSocketPath(), token: token, timeout: DefaultTimeout}
+}
+
+// SetTimeout configura el tiempo máximo de cada petición (0 = sin límite)
+func (c *Client) SetTimeout(timeout time.Duration) {
+	c.timeout = timeout
+}
+
+// SetToken configura el token enviado en cada petición
+func (c *Client) SetToken(token string) {
+	c.token = token
 }
 
 // Request representa una petición al daemon
 type Request struct {
 	Action  string          `json:"action"`
 	Payload json.RawMessage `json:"payload"`
+	Token   string          `json:"token,omitempty"`
 }
 
 // Response representa una respuesta del daemon
@@ -51,40 +103,81 @@ type Response struct {
 
 // Send envía una petición al daemon y retorna la respuesta
 func (c *Client) Send(req *Request) (*Response, error) {
-	// Conectar al socket
-	conn, err := net.Dial("unix", c.socketPath)
+	// Conectar al socket (iniciando el daemon si está habilitado autostart)
+	conn, err := c.dial()
 	if err != nil {
-		return nil, fmt.Errorf("connect to daemon: %w (is daemon running?)", err)
+		return nil, err
 	}
 	defer conn.Close()
 
+	// Un daemon bloqueado no debe colgar smd: plazo para el envío y la respuesta
+	timeout := c.timeout
+	if slow, ok := slowActions[req.Action]; ok && timeout > 0 && slow > timeout {
+		timeout = slow
+	}
+	if timeout > 0 {
+		conn.SetDeadline(time.Now().Add(timeout))
+	}
+
+	if req.Token == "" {
+		req.Token = c.token
+	}
+
 	// Enviar request
-	if err := json.NewEncoder(conn).Encode(req); err != nil {
+	if err := protocol.WriteMessage(conn, req); err != nil {
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			return nil, timeoutError(timeout)
+		}
 		return nil, fmt.Errorf("encode request: %w", err)
 	}
 
 	// Leer response
 	var resp Response
-	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+	if err := protocol.ReadMessage(conn, &resp); err != nil {
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			return nil, timeoutError(timeout)
+		}
+		if errors.Is(err, protocol.ErrLegacyMessage) {
+			return nil, fmt.Errorf("daemon uses an older protocol, restart smart-downloadd after upgrading")
+		}
 		return nil, fmt.Errorf("decode response: %w", err)
 	}
 
 	return &resp, nil
 }
 
+// timeoutError es el error de una petición que superó su plazo
+func timeoutError(timeout time.Duration) error {
+	return fmt.Errorf("daemon didn't respond within %s; it may be stuck (check its log or restart smart-downloadd, or raise smd --timeout)", timeout)
+}
+
 // AddDownloadPayload representa el payload para añadir una descarga
 type AddDownloadPayload struct {
-	URL        string                 `json:"url"`
-	Options    map[string]interface{} `json:"options,omitempty"`
-	AccountID  *int64                 `json:"account_id,omitempty"`
-	Background bool                   `json:"background,omitempty"`
+	URL            string                 `json:"url"`
+	Options        map[string]interface{} `json:"options,omitempty"`
+	AccountID      *int64                 `json:"account_id,omitempty"`
+	Account        string                 `json:"account,omitempty"`         // Nombre de cuenta (resuelto en el daemon según la plataforma)
+	Priority       int                    `json:"priority,omitempty"`        // Mayor = se despacha antes
+	ScheduledAfter *time.Time             `json:"scheduled_after,omitempty"` // No se despacha antes de este momento
+	Tags           []string               `json:"tags,omitempty"`
+	Background     bool                   `json:"background,omitempty"`
+	Force          bool                   `json:"force,omitempty"`
+}
+
+// AddDownloadResult representa la respuesta del daemon al añadir una descarga
+type AddDownloadResult struct {
+	ID        int64  `json:"id"`
+	Platform  string `json:"platform"`
+	Username  string `json:"username"`
+	Status    string `json:"status"`
+	Duplicate bool   `json:"duplicate"` // true si ya existía una descarga con la misma URL
 }
 
 // AddDownload añade una descarga a la cola
-func (c *Client) AddDownload(payload *AddDownloadPayload) (int64, error) {
+func (c *Client) AddDownload(payload *AddDownloadPayload) (*AddDownloadResult, error) {
 	payloadJSON, err := json.Marshal(payload)
 	if err != nil {
-		return 0, fmt.Errorf("marshal payload: %w", err)
+		return nil, fmt.Errorf("marshal payload: %w", err)
 	}
 
 	resp, err := c.Send(&Request{
@@ -92,21 +185,190 @@ func (c *Client) AddDownload(payload *AddDownloadPayload) (int64, error) {
 		Payload: payloadJSON,
 	})
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
 	if !resp.Success {
-		return 0, fmt.Errorf("add download failed: %s", resp.Error)
+		return nil, fmt.Errorf("add download failed: %s", resp.Error)
 	}
 
-	var result struct {
-		ID int64 `json:"id"`
-	}
+	var result AddDownloadResult
 	if err := json.Unmarshal(resp.Data, &result); err != nil {
-		return 0, fmt.Errorf("unmarshal response: %w", err)
+		return nil, fmt.Errorf("unmarshal response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// ResolveResult es cómo procesaría el daemon una descarga, sin crearla (smd add --dry-run)
+type ResolveResult struct {
+	URL         string                 `json:"url"` // Normalizada
+	Platform    string                 `json:"platform"`
+	Username    string                 `json:"username"`
+	Backend     string                 `json:"backend"` // yt-dlp o gallery-dl
+	HasAccount  bool                   `json:"has_account"`
+	Account     string                 `json:"account"` // Cuenta de cookies que se usaría
+	Options     map[string]interface{} `json:"options"` // Con los defaults del daemon aplicados
+	DuplicateOf int64                  `json:"duplicate_of"`
+}
+
+// Resolve consulta cómo se procesaría una descarga sin añadirla a la cola
+func (c *Client) Resolve(payload *AddDownloadPayload) (*ResolveResult, error) {
+	data, err := c.Call("resolve", payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var result ResolveResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("unmarshal response: %w", err)
 	}
+	return &result, nil
+}
+
+// URLInfo es lo que el daemon sabe de una URL sin descargarla (smd info)
+type URLInfo struct {
+	URL          string   `json:"url"`
+	Platform     string   `json:"platform"`
+	Backend      string   `json:"backend"` // yt-dlp o gallery-dl
+	Title        string   `json:"title"`
+	Uploader     string   `json:"uploader"`
+	UploadDate   string   `json:"upload_date"`
+	Duration     float64  `json:"duration"` // Segundos
+	IsLive       bool     `json:"is_live"`
+	Formats      []Format `json:"formats"`    // Solo yt-dlp
+	FileCount    int      `json:"file_count"` // Solo gallery-dl
+	Account      string   `json:"account"`    // Cuenta cuyas cookies se usaron
+	NeedsCookies bool     `json:"needs_cookies"`
+	Error        string   `json:"error"`
+}
+
+// Format es un formato disponible en yt-dlp
+type Format struct {
+	ID         string  `json:"id"`
+	Ext        string  `json:"ext"`
+	Resolution string  `json:"resolution"`
+	Height     int     `json:"height"`
+	FPS        float64 `json:"fps"`
+	VideoCodec string  `json:"vcodec"`
+	AudioCodec string  `json:"acodec"`
+	FileSize   int64   `json:"filesize"`
+	Note       string  `json:"note"`
+}
+
+// Info consulta una URL sin descargarla. account elige las cookies ("" = la cuenta activa)
+// y proxy el proxy ("" = el del config del daemon)
+func (c *Client) Info(url, account, proxy string) (*URLInfo, error) {
+	data, err := c.Call("info", map[string]string{"url": url, "account": account, "proxy": proxy})
+	if err != nil {
+		return nil, err
+	}
+
+	var info URLInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, fmt.Errorf("unmarshal response: %w", err)
+	}
+	return &info, nil
+}
 
-	return result.ID, nil
+// DownloadInfo representa el detalle de una descarga devuelto por el daemon
+type DownloadInfo struct {
+	ID             int64          `json:"id"`
+	URL            string         `json:"url"`
+	Title          string         `json:"title"`
+	Uploader       string         `json:"uploader"`
+	UploadDate     string         `json:"upload_date"` // YYYY-MM-DD
+	Platform       string         `json:"platform"`
+	Username       string         `json:"username"`
+	Status         string         `json:"status"`
+	Progress       int            `json:"progress"` // 0-100 durante el post-procesamiento
+	Priority       int            `json:"priority"`
+	ScheduledAfter *time.Time     `json:"scheduled_after"`
+	OutputPath     string         `json:"output_path"`
+	Files          []string       `json:"files"`
+	FileCount      int            `json:"file_count"`
+	Tags           []string       `json:"tags"`
+	CreatedAt      time.Time      `json:"created_at"`
+	CompletedAt    *time.Time     `json:"completed_at"`
+	ErrorMessage   string         `json:"error_message"`
+	ErrorType      string         `json:"error_type"`     // auth, needs_cookies, age_restricted, not_found, network, timeout, unsupported o "" (sin clasificar)
+	Hint           string         `json:"hint"`           // Sugerencia para resolver el error (solo en "status")
+	Account        string         `json:"account"`        // Cuenta de cookies usada (solo en "status")
+	AccountStatus  string         `json:"account_status"` // Validación de esa cuenta: valid, expired, invalid o unknown
+	Backend        string         `json:"backend"`        // yt-dlp o gallery-dl ("" = todavía no se intentó)
+	ParentID       *int64         `json:"parent_id"`      // Descarga del canal/perfil que la encoló (smd add --profile)
+	Children       *ChildSummary  `json:"children"`       // Resumen de los items de un canal/perfil (nil = no tiene)
+	Items          []DownloadInfo `json:"items"`          // Items de un canal/perfil (solo en "status")
+}
+
+// ChildSummary es la cantidad de items de un canal/perfil, en total y por status
+type ChildSummary struct {
+	Total  int            `json:"total"`
+	Counts map[string]int `json:"counts"`
+}
+
+// childStatusOrder es el orden en que se resumen los status de los items
+var childStatusOrder = []string{"completed", "failed", "downloading", "processing", "pending"}
+
+// String resume los items, p.ej. "12 items, 10 completed, 2 failed"
+func (s *ChildSummary) String() string {
+	parts := []string{fmt.Sprintf("%d items", s.Total)}
+	if s.Total == 1 {
+		parts[0] = "1 item"
+	}
+	for _, status := range childStatusOrder {
+		if n := s.Counts[status]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, status))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// GetDownload obtiene el detalle de una descarga
+func (c *Client) GetDownload(id int64) (*DownloadInfo, error) {
+	payload, _ := json.Marshal(map[string]int64{"id": id})
+
+	resp, err := c.Send(&Request{
+		Action:  "status",
+		Payload: payload,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !resp.Success {
+		return nil, fmt.Errorf("get status failed: %s", resp.Error)
+	}
+
+	var info DownloadInfo
+	if err := json.Unmarshal(resp.Data, &info); err != nil {
+		return nil, fmt.Errorf("unmarshal response: %w", err)
+	}
+
+	return &info, nil
+}
+
+// Call envía una acción al daemon y retorna el Data crudo de la respuesta
+func (c *Client) Call(action string, payload interface{}) (json.RawMessage, error) {
+	req := &Request{Action: action}
+	if payload != nil {
+		payloadJSON, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("marshal payload: %w", err)
+		}
+		req.Payload = payloadJSON
+	}
+
+	resp, err := c.Send(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if !resp.Success {
+		return nil, fmt.Errorf("%s failed: %s", action, resp.Error)
+	}
+
+	return resp.Data, nil
 }
 
 // GetDownloadStatus obtiene el status de una descarga
@@ -135,28 +397,169 @@ func (c *Client) GetDownloadStatus(id int64) (string, error) {
 	return result.Status, nil
 }
 
-// ListRecentDownloads lista las descargas recientes
-func (c *Client) ListRecentDownloads(limit int) ([]map[string]interface{}, error) {
-	payload, _ := json.Marshal(map[string]int{"limit": limit})
-
-	resp, err := c.Send(&Request{
-		Action:  "list",
-		Payload: payload,
-	})
+// ListRecentDownloads lista las descargas recientes (las entradas malformadas se descartan)
+func (c *Client) ListRecentDownloads(limit int) ([]DownloadInfo, error) {
+	data, err := c.Call("list", map[string]int{"limit": limit})
 	if err != nil {
 		return nil, err
 	}
 
-	if !resp.Success {
-		return nil, fmt.Errorf("list failed: %s", resp.Error)
+	downloads, _, err := DecodeDownloadList(data)
+	return downloads, err
+}
+
+// DisplayName retorna el título del contenido o, si no se pudo extraer, la URL
+func (d *DownloadInfo) DisplayName() string {
+	if d.Title != "" {
+		return d.Title
+	}
+	return d.URL
+}
+
+// StatusLabel retorna el status con la categoría del error, p.ej. "failed (auth)",
+// o con el avance del post-procesamiento, p.ej. "processing (42%)"
+func (d *DownloadInfo) StatusLabel() string {
+	if d.Status == "failed" && d.ErrorType != "" {
+		return fmt.Sprintf("%s (%s)", d.Status, d.ErrorType)
 	}
+	if d.Status == "processing" && d.Progress > 0 {
+		return fmt.Sprintf("%s (%d%%)", d.Status, d.Progress)
+	}
+	return d.Status
+}
 
+// DecodeDownloadList decodifica la respuesta de "list" entrada por entrada, de modo que
+// una entrada malformada no invalida el resto. Retorna además cuántas se descartaron
+func DecodeDownloadList(data json.RawMessage) ([]DownloadInfo, int, error) {
 	var result struct {
-		Downloads []map[string]interface{} `json:"downloads"`
+		Downloads []json.RawMessage `json:"downloads"`
 	}
-	if err := json.Unmarshal(resp.Data, &result); err != nil {
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, 0, fmt.Errorf("unmarshal response: %w", err)
+	}
+
+	downloads := make([]DownloadInfo, 0, len(result.Downloads))
+	invalid := 0
+
+	for _, raw := range result.Downloads {
+		var info DownloadInfo
+		if err := json.Unmarshal(raw, &info); err != nil || info.ID == 0 {
+			invalid++
+			continue
+		}
+		downloads = append(downloads, info)
+	}
+
+	return downloads, invalid, nil
+}
+
+// HealthInfo es el estado del daemon retornado por "health"
+type HealthInfo struct {
+	Healthy       bool              `json:"healthy"`
+	Version       string            `json:"version"`
+	Uptime        string            `json:"uptime"`
+	UptimeSeconds int64             `json:"uptime_seconds"`
+	WorkersBusy   int               `json:"workers_busy"`
+	WorkersTotal  int               `json:"workers_total"`
+	Database      string            `json:"database"`     // "ok" o el error
+	Dependencies  map[string]string `json:"dependencies"` // Herramienta → "ok" o el error
+}
+
+// Stats son las estadísticas de la cola (acción "stats")
+type Stats struct {
+	Pending      int             `json:"pending"`
+	Downloading  int             `json:"downloading"`
+	Processing   int             `json:"processing"`
+	Completed    int             `json:"completed"`
+	Failed       int             `json:"failed"`
+	WorkersBusy  int             `json:"workers_busy"`
+	WorkersTotal int             `json:"workers_total"`
+	Paused       bool            `json:"paused"`      // No se inician descargas nuevas (smd pause)
+	TotalBytes   int64           `json:"total_bytes"` // Tamaño de los archivos de las descargas completadas
+	Platforms    []PlatformStats `json:"platforms"`
+	Last24h      FinishedStats   `json:"last_24h"`
+	Last7d       FinishedStats   `json:"last_7d"`
+}
+
+// PlatformStats son las descargas de una plataforma por status
+type PlatformStats struct {
+	Platform    string `json:"platform"`
+	Pending     int    `json:"pending"`
+	Downloading int    `json:"downloading"`
+	Processing  int    `json:"processing"`
+	Completed   int    `json:"completed"`
+	Failed      int    `json:"failed"`
+	Total       int    `json:"total"`
+	Bytes       int64  `json:"bytes"`
+}
+
+// FinishedStats son las descargas terminadas en una ventana de tiempo
+type FinishedStats struct {
+	Completed   int     `json:"completed"`
+	Failed      int     `json:"failed"`
+	SuccessRate float64 `json:"success_rate"` // Porcentaje (0 si no terminó ninguna)
+}
+
+// Stats obtiene las estadísticas de la cola
+func (c *Client) Stats() (*Stats, error) {
+	data, err := c.Call("stats", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var stats Stats
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return nil, fmt.Errorf("unmarshal response: %w", err)
+	}
+	return &stats, nil
+}
+
+// SetWorkers cambia la cantidad de descargas en paralelo del daemon sin reiniciarlo
+func (c *Client) SetWorkers(workers int) error {
+	_, err := c.Call("set-workers", map[string]int{"workers": workers})
+	return err
+}
+
+// QueueState es el estado de la cola tras pausarla o reanudarla
+type QueueState struct {
+	Paused      bool `json:"paused"`
+	WorkersBusy int  `json:"workers_busy"` // Descargas en curso, que terminan aunque la cola esté pausada
+}
+
+// Pause deja de iniciar descargas pendientes; las que están en curso terminan normalmente
+func (c *Client) Pause() (*QueueState, error) {
+	return c.queueState("pause")
+}
+
+// Resume reanuda una cola pausada
+func (c *Client) Resume() (*QueueState, error) {
+	return c.queueState("resume")
+}
+
+// queueState envía "pause" o "resume" y retorna el estado resultante
+func (c *Client) queueState(action string) (*QueueState, error) {
+	data, err := c.Call(action, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var state QueueState
+	if err := json.Unmarshal(data, &state); err != nil {
 		return nil, fmt.Errorf("unmarshal response: %w", err)
 	}
+	return &state, nil
+}
 
-	return result.Downloads, nil
+// Health obtiene el estado del daemon
+func (c *Client) Health() (*HealthInfo, error) {
+	data, err := c.Call("health", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var info HealthInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, fmt.Errorf("unmarshal response: %w", err)
+	}
+	return &info, nil
 }
@@ -0,0 +1,93 @@
+package client
+
+import (
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// silentDaemon escucha en un socket y acepta conexiones sin responder nunca, como un
+// daemon bloqueado
+func silentDaemon(t *testing.T) string {
+	t.Helper()
+	socketPath := filepath.Join(t.TempDir(), "smart-download.sock")
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	var conns []net.Conn
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conns = append(conns, conn)
+		}
+	}()
+
+	t.Cleanup(func() {
+		listener.Close()
+		<-done
+		for _, conn := range conns {
+			conn.Close()
+		}
+	})
+	return socketPath
+}
+
+func TestSend_Timeout(t *testing.T) {
+	c := NewClient(silentDaemon(t))
+	c.SetTimeout(100 * time.Millisecond)
+
+	start := time.Now()
+	_, err := c.Send(&Request{Action: "stats"})
+	if err == nil {
+		t.Fatal("Send() to a daemon that never replies should fail")
+	}
+	if err.Error() != timeoutError(100*time.Millisecond).Error() {
+		t.Errorf("Send() error = %v, want the timeout error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Send() took %s, want about 100ms", elapsed)
+	}
+}
+
+func TestSend_SlowActionTimeout(t *testing.T) {
+	slowActions["slow-test"] = 300 * time.Millisecond
+	t.Cleanup(func() { delete(slowActions, "slow-test") })
+
+	c := NewClient(silentDaemon(t))
+	c.SetTimeout(100 * time.Millisecond)
+
+	// Las acciones lentas usan su plazo si es mayor que el del cliente
+	start := time.Now()
+	_, err := c.Send(&Request{Action: "slow-test"})
+	if err == nil || err.Error() != timeoutError(300*time.Millisecond).Error() {
+		t.Errorf("Send() error = %v, want the 300ms timeout error", err)
+	}
+	if elapsed := time.Since(start); elapsed < 300*time.Millisecond {
+		t.Errorf("Send() gave up after %s, before the slow action timeout", elapsed)
+	}
+
+	// Si el del cliente es mayor se usa ese
+	c.SetTimeout(400 * time.Millisecond)
+	if _, err := c.Send(&Request{Action: "slow-test"}); err == nil || err.Error() != timeoutError(400*time.Millisecond).Error() {
+		t.Errorf("Send() error = %v, want the 400ms timeout error", err)
+	}
+}
+
+func TestTimeoutError(t *testing.T) {
+	err := timeoutError(5 * time.Second)
+	for _, want := range []string{"5s", "--timeout"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("timeoutError() = %q, want it to mention %q", err, want)
+		}
+	}
+}